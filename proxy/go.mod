@@ -6,6 +6,7 @@ require (
 	github.com/ekomobile/dadata/v2 v2.14.0
 	github.com/go-chi/chi v1.5.5
 	github.com/go-chi/jwtauth v1.2.0
+	github.com/oschwald/maxminddb-golang v1.12.0
 	github.com/rs/cors v1.11.0
 	github.com/stretchr/testify v1.9.0
 	github.com/swaggo/http-swagger v1.3.4
@@ -0,0 +1,177 @@
+package boundary
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/geofile"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/spatial"
+	"strconv"
+	"strings"
+)
+
+// Уровни административного деления.
+const (
+	LevelCountry        = "country"
+	LevelFederalSubject = "federal_subject"
+	LevelMunicipality   = "municipality"
+	LevelDistrict       = "district"
+)
+
+// Levels — уровни от крупного к мелкому.
+var Levels = []string{LevelCountry, LevelFederalSubject, LevelMunicipality, LevelDistrict}
+
+// osmLevels сопоставляет admin_level OpenStreetMap уровням деления России:
+// 4 — субъект федерации, 6 — муниципальный район или городской округ,
+// 8 и 9 — поселения и районы городов. Федеральные округа (3) не учитываются.
+var osmLevels = map[int]string{
+	2: LevelCountry,
+	4: LevelFederalSubject,
+	5: LevelMunicipality,
+	6: LevelMunicipality,
+	7: LevelDistrict,
+	8: LevelDistrict,
+	9: LevelDistrict,
+}
+
+// Свойства с названием, кодом и уровнем, по порядку предпочтения.
+var (
+	nameKeys  = []string{"name", "name:ru", "NAME", "NAME_RU"}
+	codeKeys  = []string{"code", "ISO3166-2", "ISO3166-1", "oktmo", "OKTMO", "ref"}
+	levelKeys = []string{"level", "LEVEL"}
+	osmKeys   = []string{"admin_level", "ADMIN_LVL", "ADMIN_LEVEL"}
+)
+
+// Объекты без известного уровня или без полигонов, например федеральные
+// округа или точки административных центров, при загрузке пропускаются.
+var (
+	ErrUnknownLevel = errors.New("unknown administrative level")
+	ErrNoPolygons   = errors.New("boundary has no polygons")
+)
+
+// Boundary — административная единица с полигонами в долготе и широте.
+type Boundary struct {
+	Level      string
+	Name       string
+	Code       string
+	Properties map[string]string
+	Geometry   *geometry.Geometry
+
+	area float64
+}
+
+// NewBoundary определяет уровень, название и код по свойствам объекта:
+// level со значением country, federal_subject, municipality или district,
+// либо admin_level OpenStreetMap.
+func NewBoundary(properties map[string]string, g *geometry.Geometry) (*Boundary, error) {
+	b := &Boundary{
+		Name:       firstValue(properties, nameKeys),
+		Code:       firstValue(properties, codeKeys),
+		Properties: properties,
+		Geometry:   g,
+		area:       geometry.Area(g),
+	}
+	if b.Level = levelOf(properties); b.Level == "" {
+		return nil, fmt.Errorf("%w: boundary %q", ErrUnknownLevel, b.Name)
+	}
+	if b.area == 0 {
+		return nil, fmt.Errorf("%w: boundary %q", ErrNoPolygons, b.Name)
+	}
+	return b, nil
+}
+
+func levelOf(properties map[string]string) string {
+	if level := firstValue(properties, levelKeys); level != "" {
+		for _, known := range Levels {
+			if strings.EqualFold(level, known) {
+				return known
+			}
+		}
+		return ""
+	}
+	adminLevel, err := strconv.Atoi(firstValue(properties, osmKeys))
+	if err != nil {
+		return ""
+	}
+	return osmLevels[adminLevel]
+}
+
+func firstValue(properties map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(properties[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Index находит административные единицы по точке. Полигоны хранятся
+// в R-дереве по охватывающим прямоугольникам, точное попадание проверяется
+// только для кандидатов. После построения индекс только читается
+// и безопасен для параллельного использования.
+type Index struct {
+	tree *spatial.RTree[*part]
+	size int
+}
+
+// part — отдельный полигон единицы: у мультиполигона их несколько.
+type part struct {
+	boundary *Boundary
+	polygon  [][]geometry.Point
+}
+
+func NewIndex(boundaries []*Boundary) *Index {
+	index := &Index{tree: spatial.NewRTree[*part]()}
+	for _, b := range boundaries {
+		for _, polygon := range b.Geometry.PolygonParts() {
+			index.tree.Insert(geometry.RingRect(polygon[0]), &part{boundary: b, polygon: polygon})
+		}
+		index.size++
+	}
+	return index
+}
+
+// Load читает границы из файлов GeoJSON (.geojson, .json) и шейп-файлов (.shp).
+func Load(paths ...string) (*Index, error) {
+	var boundaries []*Boundary
+	for _, path := range paths {
+		features, err := geofile.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for i, f := range features {
+			b, err := NewBoundary(f.Properties, f.Geometry)
+			if errors.Is(err, ErrUnknownLevel) || errors.Is(err, ErrNoPolygons) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: feature %d: %w", path, i, err)
+			}
+			boundaries = append(boundaries, b)
+		}
+	}
+	return NewIndex(boundaries), nil
+}
+
+// Len возвращает число административных единиц в индексе.
+func (index *Index) Len() int {
+	return index.size
+}
+
+// Lookup возвращает единицы каждого уровня, содержащие точку. Если на одном
+// уровне точка попала в несколько единиц, выбирается наименьшая по площади.
+func (index *Index) Lookup(lat, lon float64) map[string]*Boundary {
+	point := geometry.Point{lon, lat}
+	found := map[string]*Boundary{}
+	index.tree.Search(spatial.PointRect(lon, lat), func(p *part) bool {
+		current := found[p.boundary.Level]
+		if current != nil && current.area <= p.boundary.area {
+			return true
+		}
+		if geometry.PolygonContains(p.polygon, point) {
+			found[p.boundary.Level] = p.boundary
+		}
+		return true
+	})
+	return found
+}
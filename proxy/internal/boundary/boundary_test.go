@@ -0,0 +1,101 @@
+package boundary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testBoundaries: страна 0..10, два субъекта, в первом — муниципалитет
+// и вложенный в него район; точки административных центров пропускаются.
+const testBoundaries = `{"type":"FeatureCollection","features":[
+	{"type":"Feature","properties":{"name":"Страна","admin_level":2,"ISO3166-1":"RU"},
+	 "geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}},
+	{"type":"Feature","properties":{"name":"Федеральный округ","admin_level":3},
+	 "geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}},
+	{"type":"Feature","properties":{"name":"Западная область","admin_level":"4","ISO3166-2":"RU-ZAP"},
+	 "geometry":{"type":"Polygon","coordinates":[[[0,0],[5,0],[5,10],[0,10],[0,0]]]}},
+	{"type":"Feature","properties":{"name":"Восточная область","level":"federal_subject"},
+	 "geometry":{"type":"MultiPolygon","coordinates":[[[[5,0],[10,0],[10,5],[5,5],[5,0]]],[[[5,5],[10,5],[10,10],[5,10],[5,5]]]]}},
+	{"type":"Feature","properties":{"name":"Городской округ","admin_level":6,"oktmo":"01701000"},
+	 "geometry":{"type":"Polygon","coordinates":[[[1,1],[4,1],[4,4],[1,4],[1,1]],[[2,2],[2,3],[3,3],[3,2],[2,2]]]}},
+	{"type":"Feature","properties":{"name":"Центральный район","admin_level":9},
+	 "geometry":{"type":"Polygon","coordinates":[[[1,1],[2,1],[2,2],[1,2],[1,1]]]}},
+	{"type":"Feature","properties":{"name":"Северный район","admin_level":8},
+	 "geometry":{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]]]}},
+	{"type":"Feature","properties":{"name":"Административный центр","admin_level":6},
+	 "geometry":{"type":"Point","coordinates":[2,2]}}
+]}`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestIndex_Lookup(t *testing.T) {
+	index, err := Load(writeFile(t, "boundaries.geojson", testBoundaries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Len() != 6 {
+		t.Errorf("expected 6 boundaries, got %d", index.Len())
+	}
+
+	testCases := []struct {
+		lat, lon float64
+		expected map[string]string
+	}{
+		{1.5, 1.5, map[string]string{
+			LevelCountry: "Страна", LevelFederalSubject: "Западная область",
+			LevelMunicipality: "Городской округ", LevelDistrict: "Центральный район",
+		}},
+		// дыра городского округа
+		{2.5, 2.5, map[string]string{
+			LevelCountry: "Страна", LevelFederalSubject: "Западная область", LevelDistrict: "Северный район",
+		}},
+		{7, 8, map[string]string{LevelCountry: "Страна", LevelFederalSubject: "Восточная область"}},
+		{20, 20, map[string]string{}},
+	}
+
+	for _, tc := range testCases {
+		found := index.Lookup(tc.lat, tc.lon)
+		if len(found) != len(tc.expected) {
+			t.Errorf("%g, %g: expected %v, got %d levels", tc.lat, tc.lon, tc.expected, len(found))
+			continue
+		}
+		for level, name := range tc.expected {
+			if b := found[level]; b == nil || b.Name != name {
+				t.Errorf("%g, %g: expected %s %q, got %+v", tc.lat, tc.lon, level, name, b)
+			}
+		}
+	}
+
+	found := index.Lookup(1.5, 1.5)
+	if found[LevelCountry].Code != "RU" || found[LevelMunicipality].Code != "01701000" {
+		t.Errorf("unexpected codes: %q, %q", found[LevelCountry].Code, found[LevelMunicipality].Code)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	testCases := []struct {
+		name, content string
+	}{
+		{"boundaries.geojson", `{"type":"Feature"}`},
+		{"boundaries.geojson", `{"type":"FeatureCollection","features":[{"properties":{"admin_level":2},
+			"geometry":{"type":"Polygon","coordinates":[[[0,0],[200,0],[0,1],[0,0]]]}}]}`},
+		{"boundaries.kml", `<kml/>`},
+	}
+
+	for _, tc := range testCases {
+		if _, err := Load(writeFile(t, tc.name, tc.content)); err == nil {
+			t.Errorf("%s: expected error", tc.content)
+		}
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.geojson")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_GetSet(t *testing.T) {
+	c := New[int](time.Minute, 0)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Expected miss for unknown key")
+	}
+
+	c.Set("a", 1)
+	c.Set("a", 2)
+	if value, ok := c.Get("a"); !ok || value != 2 {
+		t.Errorf("expected 2, got %v (found %v)", value, ok)
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected 1 entry, got %d", c.Len())
+	}
+}
+
+func TestCache_Expiration(t *testing.T) {
+	c := New[string](time.Minute, 0)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Set("key", "value")
+	now = now.Add(30 * time.Second)
+	if _, ok := c.Get("key"); !ok {
+		t.Error("Expected entry to be alive before ttl")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("key"); ok {
+		t.Error("Expected entry to expire after ttl")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+func TestCache_Eviction(t *testing.T) {
+	c := New[int](0, 2)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("expected %s to stay in cache", key)
+		}
+	}
+}
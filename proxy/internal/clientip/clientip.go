@@ -0,0 +1,99 @@
+package clientip
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type contextKey struct{}
+
+// Resolver определяет IP-адрес клиента с учетом доверенных прокси.
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// NewResolver создает Resolver. Заголовок X-Forwarded-For учитывается
+// только если запрос пришел от адреса из списка доверенных подсетей.
+func NewResolver(trustedCIDRs []string) (*Resolver, error) {
+	resolver := &Resolver{}
+	for _, cidr := range trustedCIDRs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+// ClientIP возвращает IP-адрес клиента или nil, если его не удалось определить.
+// Цепочка X-Forwarded-For просматривается справа налево, первый адрес
+// не из доверенных подсетей считается адресом клиента.
+func (res *Resolver) ClientIP(r *http.Request) net.IP {
+	remote := parseHostIP(r.RemoteAddr)
+	if remote == nil || !res.isTrusted(remote) {
+		return remote
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !res.isTrusted(ip) {
+			return ip
+		}
+		remote = ip
+	}
+	return remote
+}
+
+// Middleware сохраняет IP-адрес клиента в контексте запроса.
+func (res *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := res.ClientIP(r); ip != nil {
+			r = r.WithContext(context.WithValue(r.Context(), contextKey{}, ip))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FromRequest возвращает IP-адрес клиента, сохраненный Middleware.
+// Если Middleware не использовался, берется RemoteAddr.
+func FromRequest(r *http.Request) net.IP {
+	if ip, ok := r.Context().Value(contextKey{}).(net.IP); ok {
+		return ip
+	}
+	return parseHostIP(r.RemoteAddr)
+}
+
+func (res *Resolver) isTrusted(ip net.IP) bool {
+	for _, network := range res.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseHostIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.ParseIP(strings.Trim(host, "[]"))
+}
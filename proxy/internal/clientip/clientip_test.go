@@ -0,0 +1,67 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolver_ClientIP(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{name: "no proxy", remoteAddr: "203.0.113.7:5000", expected: "203.0.113.7"},
+		{name: "untrusted proxy header ignored", remoteAddr: "203.0.113.7:5000", forwarded: "198.51.100.1", expected: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "127.0.0.1:5000", forwarded: "198.51.100.1", expected: "198.51.100.1"},
+		{name: "chain of trusted proxies", remoteAddr: "10.1.1.1:5000", forwarded: "198.51.100.1, 192.0.2.4, 10.2.2.2", expected: "192.0.2.4"},
+		{name: "only trusted hops", remoteAddr: "10.1.1.1:5000", forwarded: "10.3.3.3", expected: "10.3.3.3"},
+		{name: "garbage in header", remoteAddr: "10.1.1.1:5000", forwarded: "unknown", expected: "10.1.1.1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+
+			ip := resolver.ClientIP(req)
+			if ip == nil || ip.String() != tc.expected {
+				t.Errorf("expected client ip %s, got %v", tc.expected, ip)
+			}
+		})
+	}
+}
+
+func TestResolver_Middleware(t *testing.T) {
+	resolver, _ := NewResolver([]string{"127.0.0.1/32"})
+
+	var got string
+	handler := resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromRequest(r).String()
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.9")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "198.51.100.9" {
+		t.Errorf("expected 198.51.100.9 in context, got %s", got)
+	}
+}
+
+func TestNewResolver_InvalidCIDR(t *testing.T) {
+	if _, err := NewResolver([]string{"not-a-network"}); err == nil {
+		t.Error("Expected error for invalid CIDR, but got nil")
+	}
+}
@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"sort"
+	"sync"
+)
+
+// MaxZoom — наибольший масштаб, на котором точки объединяются. На более
+// крупных масштабах каждая точка отдается отдельным кластером.
+const MaxZoom = 16
+
+const (
+	// Radius — сторона ячейки сетки в пикселях карты: точки, которые
+	// на экране ближе примерно Radius, попадают в один кластер.
+	Radius = 60
+	// tileSize — сторона тайла в пикселях, как в Leaflet.
+	tileSize = 256
+	// maxLat — предел широты тайловых карт; точки ближе к полюсам
+	// прижимаются к нему.
+	maxLat = 85.051128779806592
+)
+
+// Point — точка для кластеризации с произвольным значением.
+type Point[T any] struct {
+	Lat, Lon float64
+	Value    T
+}
+
+// Cluster — группа точек одной ячейки сетки. Lat и Lon — центр масс точек,
+// Bounds — охватывающий их прямоугольник. Value заполнено, только если
+// точка в кластере одна.
+type Cluster[T any] struct {
+	Count    int
+	Lat, Lon float64
+	Bounds   spatial.Rect
+	Value    T
+}
+
+// Index кластеризует точки по сетке в проекции Меркатора, как это делают
+// маркеры на тайловой карте. Сторона ячейки на масштабе zoom вдвое меньше,
+// чем на zoom-1, и сетки выровнены по началу координат, поэтому каждая
+// ячейка делится ровно на четыре ячейки следующего масштаба: кластеры
+// образуют иерархию, и при приближении кластер распадается только на свои
+// же точки. Уровни строятся при первом запросе масштаба и кешируются;
+// набор точек не меняется — при изменении точек нужно построить новый
+// Index. Безопасен для параллельного использования.
+type Index[T any] struct {
+	points []Point[T]
+
+	mu     sync.Mutex
+	levels map[int]*spatial.RTree[*Cluster[T]]
+}
+
+func New[T any](points []Point[T]) *Index[T] {
+	return &Index[T]{points: points, levels: map[int]*spatial.RTree[*Cluster[T]]{}}
+}
+
+// Clusters возвращает кластеры масштаба zoom, пересекающиеся хотя бы
+// с одной из областей rects, от больших к меньшим. Масштабы больше MaxZoom
+// дают отдельные точки.
+func (ix *Index[T]) Clusters(zoom int, rects []spatial.Rect) []Cluster[T] {
+	level := ix.level(zoom)
+
+	result := []Cluster[T]{}
+	seen := map[*Cluster[T]]bool{}
+	for _, rect := range rects {
+		level.Search(rect, func(c *Cluster[T]) bool {
+			if !seen[c] {
+				seen[c] = true
+				result = append(result, *c)
+			}
+			return true
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Lat != b.Lat {
+			return a.Lat > b.Lat
+		}
+		return a.Lon < b.Lon
+	})
+	return result
+}
+
+// level возвращает R-дерево кластеров масштаба, строя его при первом
+// обращении.
+func (ix *Index[T]) level(zoom int) *spatial.RTree[*Cluster[T]] {
+	if zoom < 0 {
+		zoom = 0
+	}
+	if zoom > MaxZoom {
+		zoom = MaxZoom + 1
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if level, ok := ix.levels[zoom]; ok {
+		return level
+	}
+
+	level := spatial.NewRTree[*Cluster[T]]()
+	if zoom > MaxZoom {
+		for _, p := range ix.points {
+			level.Insert(spatial.PointRect(p.Lon, p.Lat), &Cluster[T]{Count: 1, Lat: p.Lat, Lon: p.Lon, Bounds: spatial.PointRect(p.Lon, p.Lat), Value: p.Value})
+		}
+		ix.levels[zoom] = level
+		return level
+	}
+
+	// cell — сторона ячейки в долях мира: мир на масштабе zoom занимает
+	// tileSize·2^zoom пикселей.
+	cell := Radius / (tileSize * math.Exp2(float64(zoom)))
+	type sum struct {
+		cluster  *Cluster[T]
+		lat, lon float64
+	}
+	cells := map[[2]int]*sum{}
+	for _, p := range ix.points {
+		x, y := project(p.Lat, p.Lon)
+		key := [2]int{int(x / cell), int(y / cell)}
+		rect := spatial.PointRect(p.Lon, p.Lat)
+		s, ok := cells[key]
+		if !ok {
+			cells[key] = &sum{cluster: &Cluster[T]{Count: 1, Bounds: rect, Value: p.Value}, lat: p.Lat, lon: p.Lon}
+			continue
+		}
+		s.cluster.Count++
+		s.cluster.Bounds = s.cluster.Bounds.Union(rect)
+		s.lat += p.Lat
+		s.lon += p.Lon
+	}
+	var zero T
+	for _, s := range cells {
+		c := s.cluster
+		// среднее с ошибкой округления не должно выходить за границы
+		c.Lat = math.Max(c.Bounds.MinY, math.Min(c.Bounds.MaxY, s.lat/float64(c.Count)))
+		c.Lon = math.Max(c.Bounds.MinX, math.Min(c.Bounds.MaxX, s.lon/float64(c.Count)))
+		if c.Count > 1 {
+			c.Value = zero
+		}
+		level.Insert(c.Bounds, c)
+	}
+	ix.levels[zoom] = level
+	return level
+}
+
+// project переводит точку в проекцию Меркатора с началом в северо-западном
+// углу мира и стороной 1, как координаты тайлов на масштабе 0.
+func project(lat, lon float64) (x, y float64) {
+	lat = math.Max(-maxLat, math.Min(maxLat, lat))
+	sin := math.Sin(lat * math.Pi / 180)
+	x = lon/360 + 0.5
+	y = 0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)
+	return math.Max(0, math.Min(x, 1-1e-12)), math.Max(0, math.Min(y, 1-1e-12))
+}
@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"fmt"
+	"geo-controller/proxy/internal/spatial"
+	"testing"
+)
+
+var world = []spatial.Rect{{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}}
+
+func testPoints() []Point[string] {
+	var points []Point[string]
+	for i := 0; i < 50; i++ {
+		points = append(points, Point[string]{Lat: 55.75 + float64(i%10)*0.001, Lon: 37.62 + float64(i/10)*0.001, Value: fmt.Sprintf("msk%d", i)})
+	}
+	for i := 0; i < 20; i++ {
+		points = append(points, Point[string]{Lat: 59.93 + float64(i)*0.001, Lon: 30.36, Value: fmt.Sprintf("spb%d", i)})
+	}
+	points = append(points, Point[string]{Lat: -33.87, Lon: 151.21, Value: "sydney"})
+	return points
+}
+
+func TestIndex(t *testing.T) {
+	points := testPoints()
+	index := New(points)
+
+	previous := 0
+	for zoom := 0; zoom <= MaxZoom+1; zoom++ {
+		clusters := index.Clusters(zoom, world)
+		total := 0
+		for _, c := range clusters {
+			total += c.Count
+			if c.Count == 1 && c.Value == "" {
+				t.Errorf("zoom %d: single point cluster without value", zoom)
+			}
+			if c.Count > 1 && c.Value != "" {
+				t.Errorf("zoom %d: cluster of %d points with value %q", zoom, c.Count, c.Value)
+			}
+			if c.Lon < c.Bounds.MinX || c.Lon > c.Bounds.MaxX || c.Lat < c.Bounds.MinY || c.Lat > c.Bounds.MaxY {
+				t.Errorf("zoom %d: centroid %g,%g outside bounds %+v", zoom, c.Lat, c.Lon, c.Bounds)
+			}
+		}
+		if total != len(points) {
+			t.Errorf("zoom %d: clusters hold %d points, expected %d", zoom, total, len(points))
+		}
+		// ячейки вложены: при приближении кластеров не становится меньше
+		if len(clusters) < previous {
+			t.Errorf("zoom %d: %d clusters, fewer than %d on previous zoom", zoom, len(clusters), previous)
+		}
+		previous = len(clusters)
+	}
+
+	clusters := index.Clusters(0, world)
+	if len(clusters) != 2 || clusters[0].Count != 70 || clusters[1].Value != "sydney" {
+		t.Errorf("unexpected clusters on zoom 0: %+v", clusters)
+	}
+	if len(index.Clusters(MaxZoom+5, world)) != len(points) {
+		t.Error("expected separate points beyond MaxZoom")
+	}
+}
+
+func TestIndexBBox(t *testing.T) {
+	index := New(testPoints())
+
+	moscow := []spatial.Rect{{MinX: 37, MinY: 55, MaxX: 38, MaxY: 56}}
+	clusters := index.Clusters(10, moscow)
+	total := 0
+	for _, c := range clusters {
+		total += c.Count
+	}
+	if total != 50 {
+		t.Errorf("expected 50 points in Moscow, got %d in %+v", total, clusters)
+	}
+
+	// область через антимеридиан приходит двумя прямоугольниками
+	pacific := []spatial.Rect{{MinX: 150, MinY: -40, MaxX: 180, MaxY: 0}, {MinX: -180, MinY: -40, MaxX: -170, MaxY: 0}}
+	if clusters := index.Clusters(5, pacific); len(clusters) != 1 || clusters[0].Value != "sydney" {
+		t.Errorf("unexpected clusters across antimeridian: %+v", clusters)
+	}
+	if clusters := index.Clusters(5, []spatial.Rect{{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}}); len(clusters) != 0 {
+		t.Errorf("expected no clusters, got %+v", clusters)
+	}
+}
@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"geo-controller/proxy/internal/clientip"
 	"geo-controller/proxy/internal/models"
 	"geo-controller/proxy/internal/responder"
 	"geo-controller/proxy/internal/service"
@@ -27,7 +29,16 @@ func (c *AddressController) AddressSearchHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	searchResp, err := c.addressService.SearchAddress(searchReq.Query)
+	if ip := clientip.FromRequest(r); ip != nil {
+		searchReq.ClientIP = ip.String()
+	}
+	searchReq.Language = requestLanguage(r, searchReq.Language)
+
+	searchResp, err := c.addressService.SearchAddress(searchReq)
+	if errors.Is(err, service.ErrTimezonesUnavailable) {
+		c.responder.ErrorServiceUnavailable(w, err)
+		return
+	}
 	if err != nil {
 		c.responder.ErrorBadRequest(w, err)
 		return
@@ -43,6 +54,8 @@ func (c *AddressController) GeocodeHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	geocodeReq.Language = requestLanguage(r, geocodeReq.Language)
+
 	geocodeResp, err := c.addressService.Geocode(geocodeReq)
 	if err != nil {
 		c.responder.ErrorBadRequest(w, err)
@@ -51,3 +64,77 @@ func (c *AddressController) GeocodeHandler(w http.ResponseWriter, r *http.Reques
 
 	c.responder.OutputJSON(w, geocodeResp)
 }
+
+func (c *AddressController) ParseHandler(w http.ResponseWriter, r *http.Request) {
+	var parseReq models.ParseRequest
+	if err := json.NewDecoder(r.Body).Decode(&parseReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	parsed, err := c.addressService.ParseAddress(parseReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, parsed)
+}
+
+func (c *AddressController) FormatHandler(w http.ResponseWriter, r *http.Request) {
+	var formatReq models.FormatRequest
+	if err := json.NewDecoder(r.Body).Decode(&formatReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	formatted, err := c.addressService.FormatAddress(formatReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, formatted)
+}
+
+func (c *AddressController) CompareHandler(w http.ResponseWriter, r *http.Request) {
+	var compareReq models.CompareRequest
+	if err := json.NewDecoder(r.Body).Decode(&compareReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, c.addressService.CompareAddresses(compareReq))
+}
+
+func (c *AddressController) DedupeHandler(w http.ResponseWriter, r *http.Request) {
+	var dedupeReq models.DedupeRequest
+	if err := json.NewDecoder(r.Body).Decode(&dedupeReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	dedupeResp, err := c.addressService.DedupeAddresses(dedupeReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, dedupeResp)
+}
+
+func (c *AddressController) ValidateHandler(w http.ResponseWriter, r *http.Request) {
+	var validateReq models.ValidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&validateReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	validateResp, err := c.addressService.ValidateAddress(validateReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, validateResp)
+}
@@ -131,3 +131,228 @@ func TestAddressController_GeocodeHandler_BadRequest(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 }
+
+// stubAddressProvider отдает фиксированный список адресов без обращения к DaData.
+type stubAddressProvider struct {
+	addresses []*models.Address
+}
+
+func (p *stubAddressProvider) SearchAddress(request models.SearchRequest) ([]*models.Address, error) {
+	return p.addresses, nil
+}
+
+func (p *stubAddressProvider) Geocode(request models.GeocodeRequest) (*models.GeocodeResponse, error) {
+	return &models.GeocodeResponse{}, nil
+}
+
+func TestAddressController_AddressSearchHandler_Viewport(t *testing.T) {
+	provider := &stubAddressProvider{addresses: []*models.Address{
+		{Result: "г Москва, ул Ленина", GeoLat: "55.75", GeoLon: "37.61"},
+		{Result: "г Санкт-Петербург, ул Ленина", GeoLat: "59.96", GeoLon: "30.31"},
+	}}
+	addressController := NewAddressController(service.NewAddressService("", "", service.WithProvider(provider)))
+
+	reqBody := []byte(`{"query":"Ленина","viewport":{"south":59.8,"west":30.1,"north":60.1,"east":30.6}}`)
+	req, err := http.NewRequest("POST", "/api/address/search", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(addressController.AddressSearchHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.SearchResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Addresses[0].Result != "г Санкт-Петербург, ул Ленина" || response.Addresses[0].Distance == nil {
+		t.Errorf("expected nearest address with distance first, got %+v", response.Addresses[0])
+	}
+}
+
+func TestAddressController_ParseHandler(t *testing.T) {
+	addressController := NewAddressController(service.NewAddressService("", "", service.WithProvider(&stubAddressProvider{})))
+
+	reqBody := []byte(`{"query":"125009, г Москва, ул Тверская, д 7к2"}`)
+	req, err := http.NewRequest("POST", "/api/address/parse", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(addressController.ParseHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var parsed models.ParsedAddress
+	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+		t.Fatal(err)
+	}
+	expected := models.ParsedAddress{
+		PostalCode: "125009", Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+		Street: "Тверская", StreetType: "улица", House: "7", Building: "2", BuildingType: "корпус",
+	}
+	if parsed != expected {
+		t.Errorf("unexpected parse result: got %+v want %+v", parsed, expected)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/address/parse", bytes.NewBufferString(`{"query":""}`))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(addressController.ParseHandler).ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestAddressController_FormatHandler(t *testing.T) {
+	addressController := NewAddressController(service.NewAddressService("", "", service.WithProvider(&stubAddressProvider{})))
+
+	reqBody := []byte(`{"style":"international","address":{"postal_code":"420111","city":"Казань","city_type":"г","street":"Баумана","street_type":"ул","house":"1"}}`)
+	req, err := http.NewRequest("POST", "/api/address/format", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(addressController.FormatHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.FormatResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	expected := "ul. Baumana, d. 1\ng. Kazan\n420111\nRUSSIAN FEDERATION"
+	if response.Text != expected {
+		t.Errorf("unexpected formatted address: got %q want %q", response.Text, expected)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/address/format", bytes.NewBufferString(`{"style":"envelope","address":{"city":"Казань"}}`))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(addressController.FormatHandler).ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestAddressController_AddressSearchHandler_AcceptLanguage(t *testing.T) {
+	provider := &stubAddressProvider{addresses: []*models.Address{{Result: "г Казань, ул Баумана"}}}
+	addressController := NewAddressController(service.NewAddressService("", "", service.WithProvider(provider)))
+
+	req, err := http.NewRequest("POST", "/api/address/search", bytes.NewBufferString(`{"query":"Баумана"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Language", "en-GB,en;q=0.9,ru;q=0.5")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(addressController.AddressSearchHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.SearchResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Addresses[0].Result != "g Kazan, ul Baumana" {
+		t.Errorf("expected transliterated address, got %q", response.Addresses[0].Result)
+	}
+}
+
+func TestAddressController_CompareHandler(t *testing.T) {
+	addressController := NewAddressController(service.NewAddressService("", "", service.WithProvider(&stubAddressProvider{})))
+
+	reqBody := []byte(`{"first":{"result":"г Москва, ул Тверская, д 7"},"second":{"result":"Moscow, Tverskaya st, 7"}}`)
+	req, err := http.NewRequest("POST", "/api/address/compare", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(addressController.CompareHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.CompareResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Verdict != models.MatchSame || response.Score != 1 {
+		t.Errorf("expected same address, got %+v", response)
+	}
+}
+
+func TestAddressController_DedupeHandler(t *testing.T) {
+	addressController := NewAddressController(service.NewAddressService("", "", service.WithProvider(&stubAddressProvider{})))
+
+	reqBody := []byte(`{"addresses":[{"result":"г Казань, ул Баумана, д 1"},{"result":"г Пермь, ул Ленина, д 1"},{"result":"г. Казань, улица Баумана, дом 1"}]}`)
+	req, err := http.NewRequest("POST", "/api/address/dedupe", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(addressController.DedupeHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.DedupeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Groups) != 1 || response.Groups[0].Indices[0] != 0 || response.Groups[0].Indices[1] != 2 {
+		t.Errorf("unexpected groups: %+v", response)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/address/dedupe", bytes.NewBufferString(`{"addresses":[]}`))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(addressController.DedupeHandler).ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestAddressController_ValidateHandler(t *testing.T) {
+	provider := &stubAddressProvider{addresses: []*models.Address{
+		{Result: "г Казань, ул Баумана", City: "Казань", CityType: "г", Street: "Баумана", StreetType: "ул"},
+	}}
+	addressController := NewAddressController(service.NewAddressService("", "", service.WithProvider(provider)))
+
+	reqBody := []byte(`{"address":{"result":"г Казань, ул Баумана, д 500"}}`)
+	req, err := http.NewRequest("POST", "/api/address/validate", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(addressController.ValidateHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.ValidateResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != models.ValidationInvalid || len(response.Reasons) != 1 || response.Reasons[0] != models.ReasonHouseNotFound {
+		t.Errorf("expected house_not_found, got %+v", response)
+	}
+	if response.Suggestion == nil || response.Suggestion.Result != "г Казань, ул Баумана" {
+		t.Errorf("expected suggestion, got %+v", response.Suggestion)
+	}
+}
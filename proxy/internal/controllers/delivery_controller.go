@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/responder"
+	"geo-controller/proxy/internal/service"
+	"net/http"
+)
+
+type DeliveryController struct {
+	deliveryService *service.DeliveryService
+	responder       *responder.Responder
+}
+
+func NewDeliveryController(deliveryService *service.DeliveryService) *DeliveryController {
+	return &DeliveryController{
+		deliveryService: deliveryService,
+		responder:       responder.NewResponder(),
+	}
+}
+
+func (c *DeliveryController) CheckHandler(w http.ResponseWriter, r *http.Request) {
+	var checkReq models.DeliveryCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&checkReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	checkResp, err := c.deliveryService.Check(checkReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, checkResp)
+}
+
+// outputError отвечает 404, если адрес не найден, и 503, если зоны
+// обслуживания не загружены.
+func (c *DeliveryController) outputError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAddressNotFound):
+		c.responder.ErrorNotFound(w, err)
+	case errors.Is(err, service.ErrDeliveryUnavailable):
+		c.responder.ErrorServiceUnavailable(w, err)
+	default:
+		c.responder.ErrorBadRequest(w, err)
+	}
+}
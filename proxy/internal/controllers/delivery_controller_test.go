@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"geo-controller/proxy/internal/delivery"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeliveryController_CheckHandler(t *testing.T) {
+	zones, err := delivery.New(delivery.Config{
+		Depots: []models.Depot{{ID: "msk", Point: models.GeoPoint{Lat: 55.75, Lon: 37.62}}},
+		Zones:  []delivery.ZoneConfig{{DeliveryZone: models.DeliveryZone{ID: "ring", DepotID: "msk", Fee: 300}, Radius: 30000}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addressService := service.NewAddressService("", "", service.WithProvider(&stubAddressProvider{}))
+	deliveryController := NewDeliveryController(service.NewDeliveryService(zones, addressService))
+	unavailable := NewDeliveryController(service.NewDeliveryService(nil, addressService))
+
+	testCases := []struct {
+		name       string
+		controller *DeliveryController
+		body       string
+		expected   int
+	}{
+		{"inside", deliveryController, `{"point":{"lat":55.8,"lon":37.6}}`, http.StatusOK},
+		{"outside", deliveryController, `{"point":{"lat":59.93,"lon":30.36}}`, http.StatusOK},
+		{"invalid point", deliveryController, `{"point":{"lat":95,"lon":30}}`, http.StatusBadRequest},
+		{"empty", deliveryController, `{}`, http.StatusBadRequest},
+		{"invalid json", deliveryController, `{`, http.StatusBadRequest},
+		{"unavailable", unavailable, `{"point":{"lat":55.8,"lon":37.6}}`, http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest("POST", "/api/delivery/check", bytes.NewBufferString(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(tc.controller.CheckHandler).ServeHTTP(rr, req)
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", tc.name, status, tc.expected)
+			continue
+		}
+		if tc.name != "inside" {
+			continue
+		}
+		var resp models.DeliveryCheckResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Deliverable || resp.Depot == nil || resp.Depot.ID != "msk" {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/responder"
+	"geo-controller/proxy/internal/service"
+	"net/http"
+)
+
+type GeoController struct {
+	geoService *service.GeoService
+	responder  *responder.Responder
+}
+
+func NewGeoController(geoService *service.GeoService) *GeoController {
+	return &GeoController{
+		geoService: geoService,
+		responder:  responder.NewResponder(),
+	}
+}
+
+func (c *GeoController) DistanceHandler(w http.ResponseWriter, r *http.Request) {
+	var distanceReq models.DistanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&distanceReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	distanceResp, err := c.geoService.Distance(distanceReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, distanceResp)
+}
+
+func (c *GeoController) MatrixHandler(w http.ResponseWriter, r *http.Request) {
+	var matrixReq models.MatrixRequest
+	if err := json.NewDecoder(r.Body).Decode(&matrixReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	matrixResp, err := c.geoService.Matrix(matrixReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, matrixResp)
+}
+
+func (c *GeoController) MeasureHandler(w http.ResponseWriter, r *http.Request) {
+	var measureReq models.GeometryRequest
+	if err := json.NewDecoder(r.Body).Decode(&measureReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	measureResp, err := c.geoService.Measure(measureReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, measureResp)
+}
+
+func (c *GeoController) ConvexHullHandler(w http.ResponseWriter, r *http.Request) {
+	var hullReq models.GeometryRequest
+	if err := json.NewDecoder(r.Body).Decode(&hullReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	hullResp, err := c.geoService.ConvexHull(hullReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, hullResp)
+}
+
+func (c *GeoController) SimplifyHandler(w http.ResponseWriter, r *http.Request) {
+	var simplifyReq models.SimplifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&simplifyReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	simplifyResp, err := c.geoService.Simplify(simplifyReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, simplifyResp)
+}
+
+func (c *GeoController) BufferHandler(w http.ResponseWriter, r *http.Request) {
+	var bufferReq models.BufferRequest
+	if err := json.NewDecoder(r.Body).Decode(&bufferReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	bufferResp, err := c.geoService.Buffer(bufferReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, bufferResp)
+}
+
+func (c *GeoController) TransformHandler(w http.ResponseWriter, r *http.Request) {
+	var transformReq models.TransformRequest
+	if err := json.NewDecoder(r.Body).Decode(&transformReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	transformResp, err := c.geoService.Transform(transformReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, transformResp)
+}
+
+// outputError отвечает 404, если адрес точки не удалось геокодировать
+// или для точки нет данных, и 503, если справочник или тайлы не загружены.
+func (c *GeoController) outputError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAddressNotFound), errors.Is(err, service.ErrBoundaryNotFound),
+		errors.Is(err, service.ErrNoElevationCoverage):
+		c.responder.ErrorNotFound(w, err)
+	case errors.Is(err, service.ErrBoundariesUnavailable), errors.Is(err, service.ErrTimezonesUnavailable),
+		errors.Is(err, service.ErrElevationUnavailable):
+		c.responder.ErrorServiceUnavailable(w, err)
+	default:
+		c.responder.ErrorBadRequest(w, err)
+	}
+}
+
+func (c *GeoController) EncodeCellHandler(w http.ResponseWriter, r *http.Request) {
+	var cellReq models.CellEncodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&cellReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	encodeResp, err := c.geoService.EncodeCell(cellReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, encodeResp)
+}
+
+func (c *GeoController) DecodeCellHandler(w http.ResponseWriter, r *http.Request) {
+	var cellReq models.CellRequest
+	if err := json.NewDecoder(r.Body).Decode(&cellReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	decodeResp, err := c.geoService.DecodeCell(cellReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, decodeResp)
+}
+
+func (c *GeoController) CellNeighboursHandler(w http.ResponseWriter, r *http.Request) {
+	var cellReq models.CellRequest
+	if err := json.NewDecoder(r.Body).Decode(&cellReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	neighboursResp, err := c.geoService.CellNeighbours(cellReq)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, neighboursResp)
+}
+
+func (c *GeoController) BoundariesHandler(w http.ResponseWriter, r *http.Request) {
+	var boundaryReq models.BoundaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&boundaryReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	boundaryResp, err := c.geoService.Boundaries(boundaryReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, boundaryResp)
+}
+
+func (c *GeoController) TimezoneHandler(w http.ResponseWriter, r *http.Request) {
+	var timezoneReq models.TimezoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&timezoneReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	timezoneResp, err := c.geoService.Timezone(timezoneReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, timezoneResp)
+}
+
+func (c *GeoController) ElevationHandler(w http.ResponseWriter, r *http.Request) {
+	var elevationReq models.ElevationRequest
+	if err := json.NewDecoder(r.Body).Decode(&elevationReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	elevationResp, err := c.geoService.Elevation(elevationReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, elevationResp)
+}
+
+func (c *GeoController) ElevationProfileHandler(w http.ResponseWriter, r *http.Request) {
+	var profileReq models.ElevationProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&profileReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	profileResp, err := c.geoService.ElevationProfile(profileReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, profileResp)
+}
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestGeoController() *GeoController {
+	provider := &stubAddressProvider{}
+	return NewGeoController(service.NewGeoService(service.NewAddressService("", "", service.WithProvider(provider))))
+}
+
+func TestGeoController_DistanceHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"from":{"point":{"lat":0,"lon":0}},"to":{"point":{"lat":0,"lon":1}}}`)
+	req, err := http.NewRequest("POST", "/api/geo/distance", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.DistanceHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.DistanceResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Vincenty == nil || int(*response.Vincenty) != 111319 || response.InitialBearing != 90 {
+		t.Errorf("unexpected distance: %+v", response.Distance)
+	}
+}
+
+func TestGeoController_DistanceHandler_AddressNotFound(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"from":{"address":"Атлантида"},"to":{"point":{"lat":0,"lon":1}}}`)
+	req, err := http.NewRequest("POST", "/api/geo/distance", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.DistanceHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+}
+
+func TestGeoController_MatrixHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"origins":[{"point":{"lat":0,"lon":0}}],"destinations":[{"point":{"lat":0,"lon":1}},{"point":{"lat":1,"lon":0}}]}`)
+	req, err := http.NewRequest("POST", "/api/geo/matrix", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.MatrixHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.MatrixResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Rows) != 1 || len(response.Rows[0]) != 2 || response.Rows[0][1].InitialBearing != 0 {
+		t.Errorf("unexpected matrix: %+v", response)
+	}
+}
+
+func TestGeoController_MeasureHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"geometry":{"type":"LineString","coordinates":[[0,0],[1,0]]}}`)
+	req, err := http.NewRequest("POST", "/api/geo/measure", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.MeasureHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.MeasureResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if int(response.Length) != 111319 || response.Area != 0 || response.Centroid.Lon != 0.5 {
+		t.Errorf("unexpected measurement: %+v", response)
+	}
+}
+
+func TestGeoController_BufferHandler_InvalidGeometry(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1]]]},"distance":100}`)
+	req, err := http.NewRequest("POST", "/api/geo/buffer", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.BufferHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestGeoController_TransformHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"from":"EPSG:4326","to":"EPSG:3857","points":[[0,0]],"geometry":{"type":"Point","coordinates":[180,0]}}`)
+	req, err := http.NewRequest("POST", "/api/geo/transform", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.TransformHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.TransformResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Points) != 1 || response.Points[0] != [2]float64{0, 0} ||
+		response.Geometry == nil || string(response.Geometry.Coordinates) != "[20037508.342789244,0]" {
+		t.Errorf("unexpected transform: %+v", response)
+	}
+}
+
+func TestGeoController_EncodeCellHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"encoding":"geohash","point":{"lat":57.64911,"lon":10.40744},"precision":11}`)
+	req, err := http.NewRequest("POST", "/api/geo/cell/encode", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.EncodeCellHandler).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.Cell
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Token != "u4pruydqqvj" || response.Precision != 11 {
+		t.Errorf("unexpected cell: %+v", response)
+	}
+}
+
+func TestGeoController_CellNeighboursHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	testCases := []struct {
+		body     string
+		expected int
+	}{
+		{`{"encoding":"s2","token":"89c25a3"}`, http.StatusOK},
+		{`{"encoding":"s2","token":"89c25a"}`, http.StatusBadRequest},
+		{`{"encoding":"h3","token":"89c25a3"}`, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest("POST", "/api/geo/cell/neighbours", bytes.NewBufferString(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(geoController.CellNeighboursHandler).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", tc.body, status, tc.expected)
+		}
+	}
+}
+
+func TestGeoController_BoundariesHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"point":{"lat":55.7558,"lon":37.6176}}`)
+	req, err := http.NewRequest("POST", "/api/geo/boundaries", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.BoundariesHandler).ServeHTTP(rr, req)
+
+	// границы не загружены
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusServiceUnavailable)
+	}
+}
+
+func TestGeoController_TimezoneHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	reqBody := []byte(`{"point":{"lat":55.7558,"lon":37.6176}}`)
+	req, err := http.NewRequest("POST", "/api/geo/timezone", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geoController.TimezoneHandler).ServeHTTP(rr, req)
+
+	// границы поясов не загружены
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusServiceUnavailable)
+	}
+}
+
+func TestGeoController_ElevationHandler(t *testing.T) {
+	geoController := newTestGeoController()
+
+	for path, handler := range map[string]http.HandlerFunc{
+		"/api/geo/elevation":         geoController.ElevationHandler,
+		"/api/geo/elevation/profile": geoController.ElevationProfileHandler,
+	} {
+		req, err := http.NewRequest("POST", path, bytes.NewBufferString("{"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", path, status, http.StatusBadRequest)
+		}
+
+		reqBody := `{"points":[{"point":{"lat":55.7558,"lon":37.6176}}],"path":[{"point":{"lat":55.7558,"lon":37.6176}},{"point":{"lat":55.76,"lon":37.62}}]}`
+		req, err = http.NewRequest("POST", path, bytes.NewBufferString(reqBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		// тайлы высот не загружены
+		if status := rr.Code; status != http.StatusServiceUnavailable {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", path, status, http.StatusServiceUnavailable)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/responder"
+	"geo-controller/proxy/internal/service"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+type GeofenceController struct {
+	geofenceService *service.GeofenceService
+	responder       *responder.Responder
+}
+
+func NewGeofenceController(geofenceService *service.GeofenceService) *GeofenceController {
+	return &GeofenceController{
+		geofenceService: geofenceService,
+		responder:       responder.NewResponder(),
+	}
+}
+
+func (c *GeofenceController) ListHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, c.geofenceService.List(user))
+}
+
+func (c *GeofenceController) GetHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	at, past, err := atParam(r)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	var geofence *models.Geofence
+	if past {
+		geofence, err = c.geofenceService.At(user, chi.URLParam(r, "id"), at)
+	} else {
+		geofence, err = c.geofenceService.Get(user, chi.URLParam(r, "id"))
+	}
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, geofence)
+}
+
+func (c *GeofenceController) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var geofenceReq models.GeofenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&geofenceReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	geofence, err := c.geofenceService.Create(user, geofenceReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, geofence)
+}
+
+func (c *GeofenceController) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var geofenceReq models.GeofenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&geofenceReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	geofence, err := c.geofenceService.Update(user, chi.URLParam(r, "id"), geofenceReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, geofence)
+}
+
+func (c *GeofenceController) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	if err := c.geofenceService.Delete(user, chi.URLParam(r, "id")); err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *GeofenceController) CheckHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var checkReq models.GeofenceCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&checkReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	checkResp, err := c.geofenceService.Check(user, checkReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, checkResp)
+}
+
+func (c *GeofenceController) RevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	revisions, err := c.geofenceService.Revisions(user, chi.URLParam(r, "id"))
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, revisions)
+}
+
+func (c *GeofenceController) RevisionHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	number, err := revisionParam(r)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	revision, err := c.geofenceService.Revision(user, chi.URLParam(r, "id"), number)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, revision)
+}
+
+// RevertHandler возвращает геозону к ревизии. Если ревизия — удаление,
+// геозона удаляется и ответ пустой.
+func (c *GeofenceController) RevertHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var revertReq models.RevertRequest
+	if err := json.NewDecoder(r.Body).Decode(&revertReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	geofence, err := c.geofenceService.Revert(user, chi.URLParam(r, "id"), revertReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+	if geofence == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	c.responder.OutputJSON(w, geofence)
+}
+
+// outputError отвечает 404, если геозоны нет или адрес не найден,
+// и 500, если изменение не удалось сохранить.
+func (c *GeofenceController) outputError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrGeofenceNotFound), errors.Is(err, service.ErrAddressNotFound):
+		c.responder.ErrorNotFound(w, err)
+	case errors.Is(err, service.ErrGeofenceStorage):
+		c.responder.ErrorInternal(w, err)
+	default:
+		c.responder.ErrorBadRequest(w, err)
+	}
+}
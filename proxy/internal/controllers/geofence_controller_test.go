@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"geo-controller/proxy/internal/geofence"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/jwtauth"
+)
+
+func newTestGeofenceController(t *testing.T) *GeofenceController {
+	t.Helper()
+	store, err := geofence.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addressService := service.NewAddressService("", "", service.WithProvider(&stubAddressProvider{}))
+	return NewGeofenceController(service.NewGeofenceService(store, addressService))
+}
+
+// userRequest создает запрос с токеном пользователя в контексте, как после
+// AuthMiddleware, и параметром маршрута id.
+func userRequest(t *testing.T, method, path, username, id string, body []byte) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, path, bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	token, _, err := tokenAuth.Encode(map[string]interface{}{"username": username})
+	if err != nil {
+		t.Fatal(err)
+	}
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("id", id)
+	ctx := context.WithValue(jwtauth.NewContext(req.Context(), token, nil), chi.RouteCtxKey, routeContext)
+	return req.WithContext(ctx)
+}
+
+func TestGeofenceController(t *testing.T) {
+	geofenceController := newTestGeofenceController(t)
+
+	reqBody := []byte(`{"name":"Москва","geometry":{"type":"Polygon","coordinates":[[[37,55],[38,55],[38,56],[37,56],[37,55]]]}}`)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(geofenceController.CreateHandler).ServeHTTP(rr, userRequest(t, "POST", "/api/geofences", "alice", "", reqBody))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var created models.Geofence
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		method   string
+		username string
+		id       string
+		body     string
+		expected int
+	}{
+		{"list", geofenceController.ListHandler, "GET", "alice", "", "", http.StatusOK},
+		{"get", geofenceController.GetHandler, "GET", "alice", created.ID, "", http.StatusOK},
+		{"get other user", geofenceController.GetHandler, "GET", "bob", created.ID, "", http.StatusNotFound},
+		{"check", geofenceController.CheckHandler, "POST", "alice", "", `{"point":{"lat":55.75,"lon":37.62}}`, http.StatusOK},
+		{"update invalid", geofenceController.UpdateHandler, "PUT", "alice", created.ID, `{"name":"Москва","geometry":{"type":"Point","coordinates":[37,55]}}`, http.StatusBadRequest},
+		{"update", geofenceController.UpdateHandler, "PUT", "alice", created.ID, `{"name":"Столица","geometry":{"type":"Polygon","coordinates":[[[37,55],[38,55],[38,56],[37,55]]]}}`, http.StatusOK},
+		{"create without name", geofenceController.CreateHandler, "POST", "alice", "", `{"geometry":{"type":"Polygon","coordinates":[[[37,55],[38,55],[38,56],[37,55]]]}}`, http.StatusBadRequest},
+		{"delete", geofenceController.DeleteHandler, "DELETE", "alice", created.ID, "", http.StatusNoContent},
+		{"delete again", geofenceController.DeleteHandler, "DELETE", "alice", created.ID, "", http.StatusNotFound},
+		{"no username", geofenceController.ListHandler, "GET", "", "", "", http.StatusUnauthorized},
+	}
+	for _, tc := range testCases {
+		rr := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rr, userRequest(t, tc.method, "/api/geofences", tc.username, tc.id, []byte(tc.body)))
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", tc.name, status, tc.expected)
+		}
+	}
+}
+
+// revisionRequest дополняет userRequest параметром маршрута rev.
+func revisionRequest(t *testing.T, method, path, username, id, rev, body string) *http.Request {
+	t.Helper()
+	req := userRequest(t, method, path, username, id, []byte(body))
+	chi.RouteContext(req.Context()).URLParams.Add("rev", rev)
+	return req
+}
+
+func TestGeofenceController_History(t *testing.T) {
+	geofenceController := newTestGeofenceController(t)
+
+	rr := httptest.NewRecorder()
+	geofenceController.CreateHandler(rr, userRequest(t, "POST", "/api/geofences", "alice", "",
+		[]byte(`{"name":"Москва","geometry":{"type":"Polygon","coordinates":[[[37,55],[38,55],[38,56],[37,55]]]}}`)))
+	var created models.Geofence
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		method   string
+		path     string
+		username string
+		rev      string
+		body     string
+		expected int
+	}{
+		{"revisions", geofenceController.RevisionsHandler, "GET", "/api/geofences/x/revisions", "alice", "", "", http.StatusOK},
+		{"revisions of other user", geofenceController.RevisionsHandler, "GET", "/api/geofences/x/revisions", "bob", "", "", http.StatusNotFound},
+		{"revision", geofenceController.RevisionHandler, "GET", "/api/geofences/x/revisions/1", "alice", "1", "", http.StatusOK},
+		{"revision invalid", geofenceController.RevisionHandler, "GET", "/api/geofences/x/revisions/x", "alice", "x", "", http.StatusBadRequest},
+		{"revision missing", geofenceController.RevisionHandler, "GET", "/api/geofences/x/revisions/9", "alice", "9", "", http.StatusNotFound},
+		{"get at", geofenceController.GetHandler, "GET", "/api/geofences/x?at=2999-01-01T00:00:00Z", "alice", "", "", http.StatusOK},
+		{"get before creation", geofenceController.GetHandler, "GET", "/api/geofences/x?at=2000-01-01T00:00:00Z", "alice", "", "", http.StatusNotFound},
+		{"get at invalid", geofenceController.GetHandler, "GET", "/api/geofences/x?at=yesterday", "alice", "", "", http.StatusBadRequest},
+		{"delete", geofenceController.DeleteHandler, "DELETE", "/api/geofences/x", "alice", "", "", http.StatusNoContent},
+		{"revert to created", geofenceController.RevertHandler, "POST", "/api/geofences/x/revert", "alice", "", `{"revision":1}`, http.StatusOK},
+		{"revert to deleted", geofenceController.RevertHandler, "POST", "/api/geofences/x/revert", "alice", "", `{"revision":2}`, http.StatusNoContent},
+		{"revert to deleted again", geofenceController.RevertHandler, "POST", "/api/geofences/x/revert", "alice", "", `{"revision":2}`, http.StatusBadRequest},
+		{"revert missing", geofenceController.RevertHandler, "POST", "/api/geofences/x/revert", "alice", "", `{"revision":9}`, http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		rr := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rr, revisionRequest(t, tc.method, tc.path, tc.username, created.ID, tc.rev, tc.body))
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v (%s)", tc.name, status, tc.expected, rr.Body)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"geo-controller/proxy/internal/clientip"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/responder"
+	"geo-controller/proxy/internal/service"
+	"io"
+	"net/http"
+)
+
+type IPController struct {
+	geoIPService *service.GeoIPService
+	responder    *responder.Responder
+}
+
+func NewIPController(geoIPService *service.GeoIPService) *IPController {
+	return &IPController{
+		geoIPService: geoIPService,
+		responder:    responder.NewResponder(),
+	}
+}
+
+func (c *IPController) LocateHandler(w http.ResponseWriter, r *http.Request) {
+	var locateReq models.IPLocateRequest
+	if err := json.NewDecoder(r.Body).Decode(&locateReq); err != nil && !errors.Is(err, io.EOF) {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	if locateReq.IP == "" {
+		ip := clientip.FromRequest(r)
+		if ip == nil {
+			c.responder.ErrorBadRequest(w, errors.New("unable to determine client ip"))
+			return
+		}
+		locateReq.IP = ip.String()
+	}
+
+	location, err := c.geoIPService.Locate(locateReq.IP)
+	if err != nil {
+		if errors.Is(err, service.ErrIPNotFound) {
+			c.responder.ErrorNotFound(w, err)
+			return
+		}
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, location)
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/service"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type staticIPLocator struct{}
+
+func (staticIPLocator) LocateIP(ip net.IP) (*models.IPLocation, error) {
+	if ip.String() != "203.0.113.7" {
+		return nil, service.ErrIPNotFound
+	}
+	return &models.IPLocation{City: "Казань", GeoLat: "55.79", GeoLon: "49.12", Source: "test"}, nil
+}
+
+func TestIPController_LocateHandler(t *testing.T) {
+	ipController := NewIPController(service.NewGeoIPService(staticIPLocator{}))
+
+	testCases := []struct {
+		name           string
+		body           string
+		remoteAddr     string
+		expectedStatus int
+		expectedCity   string
+	}{
+		{name: "explicit ip", body: `{"ip":"203.0.113.7"}`, remoteAddr: "198.51.100.1:1000", expectedStatus: http.StatusOK, expectedCity: "Казань"},
+		{name: "caller ip", body: `{}`, remoteAddr: "203.0.113.7:1000", expectedStatus: http.StatusOK, expectedCity: "Казань"},
+		{name: "empty body", body: ``, remoteAddr: "203.0.113.7:1000", expectedStatus: http.StatusOK, expectedCity: "Казань"},
+		{name: "unknown ip", body: `{"ip":"198.51.100.1"}`, remoteAddr: "203.0.113.7:1000", expectedStatus: http.StatusNotFound},
+		{name: "invalid ip", body: `{"ip":"localhost"}`, remoteAddr: "203.0.113.7:1000", expectedStatus: http.StatusBadRequest},
+		{name: "invalid json", body: `{invalid json`, remoteAddr: "203.0.113.7:1000", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/api/ip/locate", bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.RemoteAddr = tc.remoteAddr
+
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(ipController.LocateHandler).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tc.expectedStatus)
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var location models.IPLocation
+			if err := json.NewDecoder(rr.Body).Decode(&location); err != nil {
+				t.Fatal(err)
+			}
+			if location.City != tc.expectedCity {
+				t.Errorf("expected city %s, got %s", tc.expectedCity, location.City)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"geo-controller/proxy/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// supportedLanguages — языки, на которых можно получить результаты.
+var supportedLanguages = []string{models.LanguageRussian, models.LanguageEnglish}
+
+// requestLanguage возвращает язык результатов: явно заданный в теле запроса
+// или наиболее предпочтительный из поддерживаемых по заголовку Accept-Language.
+// Пустая строка означает язык по умолчанию.
+func requestLanguage(r *http.Request, language string) string {
+	if language != "" {
+		return language
+	}
+
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+
+		quality := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > bestQuality && isSupportedLanguage(primary) {
+			best, bestQuality = primary, quality
+		}
+	}
+	return best
+}
+
+func isSupportedLanguage(language string) bool {
+	for _, supported := range supportedLanguages {
+		if language == supported {
+			return true
+		}
+	}
+	return false
+}
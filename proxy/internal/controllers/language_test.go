@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRequestLanguage(t *testing.T) {
+	testCases := []struct {
+		header   string
+		explicit string
+		expected string
+	}{
+		{"", "", ""},
+		{"en-US,en;q=0.9", "", "en"},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7", "", "ru"},
+		{"de-DE,de;q=0.9,en;q=0.5", "", "en"},
+		{"en;q=0.3, ru;q=0.7", "", "ru"},
+		{"fr, de", "", ""},
+		{"en;q=abc, ru;q=0.1", "", "ru"},
+		{"en", "ru", "ru"},
+	}
+
+	for _, tc := range testCases {
+		req, _ := http.NewRequest("POST", "/api/address/search", nil)
+		if tc.header != "" {
+			req.Header.Set("Accept-Language", tc.header)
+		}
+		if got := requestLanguage(req, tc.explicit); got != tc.expected {
+			t.Errorf("requestLanguage(%q, %q): expected %q, got %q", tc.header, tc.explicit, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/responder"
+	"geo-controller/proxy/internal/service"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+type LayerController struct {
+	layerService *service.LayerService
+	responder    *responder.Responder
+}
+
+func NewLayerController(layerService *service.LayerService) *LayerController {
+	return &LayerController{
+		layerService: layerService,
+		responder:    responder.NewResponder(),
+	}
+}
+
+func (c *LayerController) ListHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, c.layerService.Layers(user))
+}
+
+func (c *LayerController) GetHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	layer, err := c.layerService.Layer(user, chi.URLParam(r, "id"))
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, layer)
+}
+
+func (c *LayerController) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var layerReq models.LayerRequest
+	if err := json.NewDecoder(r.Body).Decode(&layerReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	layer, err := c.layerService.CreateLayer(user, layerReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, layer)
+}
+
+func (c *LayerController) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var layerReq models.LayerRequest
+	if err := json.NewDecoder(r.Body).Decode(&layerReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	layer, err := c.layerService.UpdateLayer(user, chi.URLParam(r, "id"), layerReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, layer)
+}
+
+func (c *LayerController) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	if err := c.layerService.DeleteLayer(user, chi.URLParam(r, "id")); err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FeaturesHandler отдает объекты слоя как FeatureCollection, чтобы карта
+// могла загрузить слой одним GET. Параметры запроса: bbox — область
+// "запад,юг,восток,север", limit — наибольшее число объектов.
+func (c *LayerController) FeaturesHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	queryReq, err := featuresQuery(r)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	features, err := c.layerService.Query(user, chi.URLParam(r, "id"), queryReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, features)
+}
+
+func featuresQuery(r *http.Request) (models.LayerQueryRequest, error) {
+	var queryReq models.LayerQueryRequest
+	params := r.URL.Query()
+	if value := params.Get("bbox"); value != "" {
+		box, err := parseBBox(value)
+		if err != nil {
+			return queryReq, err
+		}
+		queryReq.BBox = box
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return queryReq, fmt.Errorf("limit must be an integer")
+		}
+		queryReq.Limit = limit
+	}
+	return queryReq, nil
+}
+
+// parseBBox разбирает область "запад,юг,восток,север" — в том же порядке,
+// что возвращает toBBoxString() в Leaflet.
+func parseBBox(value string) (*models.BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("bbox must be west,south,east,north")
+	}
+	var coordinates [4]float64
+	for i, part := range parts {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bbox must be west,south,east,north")
+		}
+		coordinates[i] = parsed
+	}
+	return &models.BoundingBox{West: coordinates[0], South: coordinates[1], East: coordinates[2], North: coordinates[3]}, nil
+}
+
+// PutFeaturesHandler добавляет в слой Feature или FeatureCollection.
+func (c *LayerController) PutFeaturesHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	features, err := c.layerService.PutFeatures(user, chi.URLParam(r, "id"), data)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, features)
+}
+
+func (c *LayerController) GetFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	at, past, err := atParam(r)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	var feature *models.Feature
+	if past {
+		feature, err = c.layerService.FeatureAt(user, chi.URLParam(r, "id"), chi.URLParam(r, "fid"), at)
+	} else {
+		feature, err = c.layerService.Feature(user, chi.URLParam(r, "id"), chi.URLParam(r, "fid"))
+	}
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, feature)
+}
+
+func (c *LayerController) UpdateFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	feature, err := c.layerService.UpdateFeature(user, chi.URLParam(r, "id"), chi.URLParam(r, "fid"), data)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, feature)
+}
+
+func (c *LayerController) DeleteFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	if err := c.layerService.DeleteFeature(user, chi.URLParam(r, "id"), chi.URLParam(r, "fid")); err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *LayerController) FeatureRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	revisions, err := c.layerService.FeatureRevisions(user, chi.URLParam(r, "id"), chi.URLParam(r, "fid"))
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, revisions)
+}
+
+func (c *LayerController) FeatureRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	number, err := revisionParam(r)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	revision, err := c.layerService.FeatureRevision(user, chi.URLParam(r, "id"), chi.URLParam(r, "fid"), number)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, revision)
+}
+
+// RevertFeatureHandler возвращает объект слоя к ревизии. Если ревизия —
+// удаление, объект удаляется и ответ пустой.
+func (c *LayerController) RevertFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var revertReq models.RevertRequest
+	if err := json.NewDecoder(r.Body).Decode(&revertReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	feature, err := c.layerService.RevertFeature(user, chi.URLParam(r, "id"), chi.URLParam(r, "fid"), revertReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+	if feature == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	c.responder.OutputJSON(w, feature)
+}
+
+func (c *LayerController) QueryHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var queryReq models.LayerQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&queryReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	features, err := c.layerService.Query(user, chi.URLParam(r, "id"), queryReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, features)
+}
+
+// outputError отвечает 404, если слоя или объекта нет либо адрес
+// не найден, 403, если слой виден, но менять его нельзя, и 500, если
+// изменение не удалось сохранить.
+func (c *LayerController) outputError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrLayerNotFound), errors.Is(err, service.ErrAddressNotFound):
+		c.responder.ErrorNotFound(w, err)
+	case errors.Is(err, service.ErrLayerForbidden):
+		c.responder.ErrorForbidden(w, err)
+	case errors.Is(err, service.ErrLayerStorage):
+		c.responder.ErrorInternal(w, err)
+	default:
+		c.responder.ErrorBadRequest(w, err)
+	}
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"encoding/json"
+	"geo-controller/proxy/internal/layer"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+)
+
+// featureRequest дополняет userRequest параметром маршрута fid.
+func featureRequest(t *testing.T, method, path, username, id, fid, body string) *http.Request {
+	t.Helper()
+	req := userRequest(t, method, path, username, id, []byte(body))
+	chi.RouteContext(req.Context()).URLParams.Add("fid", fid)
+	return req
+}
+
+func TestLayerController(t *testing.T) {
+	store, err := layer.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addressService := service.NewAddressService("", "", service.WithProvider(&stubAddressProvider{}))
+	layerController := NewLayerController(service.NewLayerService(store, addressService))
+
+	rr := httptest.NewRecorder()
+	layerController.CreateHandler(rr, userRequest(t, "POST", "/api/layers", "alice", "", []byte(`{"name":"Парковки","viewers":["bob"]}`)))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var created models.Layer
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	feature := `{"type":"Feature","id":"p1","geometry":{"type":"Point","coordinates":[37.62,55.75]},"properties":{"name":"Тверская"}}`
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		method   string
+		path     string
+		username string
+		fid      string
+		body     string
+		expected int
+	}{
+		{"put features", layerController.PutFeaturesHandler, "POST", "/api/layers/x/features", "alice", "", feature, http.StatusOK},
+		{"put by viewer", layerController.PutFeaturesHandler, "POST", "/api/layers/x/features", "bob", "", feature, http.StatusForbidden},
+		{"put invalid", layerController.PutFeaturesHandler, "POST", "/api/layers/x/features", "alice", "", `{"type":"Point"}`, http.StatusBadRequest},
+		{"features in bbox", layerController.FeaturesHandler, "GET", "/api/layers/x/features?bbox=37,55,38,56&limit=10", "bob", "", "", http.StatusOK},
+		{"features bad bbox", layerController.FeaturesHandler, "GET", "/api/layers/x/features?bbox=37,55", "bob", "", "", http.StatusBadRequest},
+		{"features hidden", layerController.FeaturesHandler, "GET", "/api/layers/x/features", "carol", "", "", http.StatusNotFound},
+		{"get feature", layerController.GetFeatureHandler, "GET", "/api/layers/x/features/p1", "bob", "p1", "", http.StatusOK},
+		{"get missing feature", layerController.GetFeatureHandler, "GET", "/api/layers/x/features/p2", "bob", "p2", "", http.StatusNotFound},
+		{"update feature", layerController.UpdateFeatureHandler, "PUT", "/api/layers/x/features/p1", "alice", "p1", feature, http.StatusOK},
+		{"query nearest", layerController.QueryHandler, "POST", "/api/layers/x/query", "bob", "", `{"nearest":{"point":{"lat":55.7,"lon":37.6}},"limit":1}`, http.StatusOK},
+		{"query invalid", layerController.QueryHandler, "POST", "/api/layers/x/query", "bob", "", `{"within":{"type":"Polygon"}}`, http.StatusBadRequest},
+		{"update layer by viewer", layerController.UpdateHandler, "PUT", "/api/layers/x", "bob", "", `{"name":"Мое"}`, http.StatusForbidden},
+		{"list", layerController.ListHandler, "GET", "/api/layers", "bob", "", "", http.StatusOK},
+		{"get", layerController.GetHandler, "GET", "/api/layers/x", "bob", "", "", http.StatusOK},
+		{"delete feature", layerController.DeleteFeatureHandler, "DELETE", "/api/layers/x/features/p1", "alice", "p1", "", http.StatusNoContent},
+		{"delete layer", layerController.DeleteHandler, "DELETE", "/api/layers/x", "alice", "", "", http.StatusNoContent},
+		{"get deleted", layerController.GetHandler, "GET", "/api/layers/x", "alice", "", "", http.StatusNotFound},
+		{"no username", layerController.ListHandler, "GET", "/api/layers", "", "", "", http.StatusUnauthorized},
+	}
+	for _, tc := range testCases {
+		rr := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rr, featureRequest(t, tc.method, tc.path, tc.username, created.ID, tc.fid, tc.body))
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v (%s)", tc.name, status, tc.expected, rr.Body)
+		}
+	}
+}
+
+func TestLayerController_FeaturesHandler(t *testing.T) {
+	store, err := layer.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layerService := service.NewLayerService(store, nil)
+	created, _ := layerService.CreateLayer("alice", models.LayerRequest{Name: "Общий", Public: true})
+	_, err = layerService.PutFeatures("alice", created.ID, []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","id":"in","geometry":{"type":"Point","coordinates":[37.62,55.75]},"properties":null},
+		{"type":"Feature","id":"out","geometry":{"type":"Point","coordinates":[30.36,59.93]},"properties":null}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	NewLayerController(layerService).FeaturesHandler(rr,
+		userRequest(t, "GET", "/api/layers/x/features?bbox=37,55,38,56", "bob", created.ID, nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var collection models.FeatureCollection
+	if err := json.NewDecoder(rr.Body).Decode(&collection); err != nil {
+		t.Fatal(err)
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 1 || collection.Features[0].ID != "in" {
+		t.Errorf("unexpected collection: %+v", collection)
+	}
+}
+
+func TestLayerController_History(t *testing.T) {
+	store, err := layer.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layerService := service.NewLayerService(store, nil)
+	created, _ := layerService.CreateLayer("alice", models.LayerRequest{Name: "Парковки", Viewers: []string{"bob"}})
+	_, err = layerService.PutFeatures("alice", created.ID, []byte(`{"type":"Feature","id":"p1","geometry":{"type":"Point","coordinates":[37.62,55.75]},"properties":null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layerController := NewLayerController(layerService)
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		method   string
+		path     string
+		username string
+		rev      string
+		body     string
+		expected int
+	}{
+		{"revisions", layerController.FeatureRevisionsHandler, "GET", "/api/layers/x/features/p1/revisions", "bob", "", "", http.StatusOK},
+		{"revisions hidden", layerController.FeatureRevisionsHandler, "GET", "/api/layers/x/features/p1/revisions", "carol", "", "", http.StatusNotFound},
+		{"revision", layerController.FeatureRevisionHandler, "GET", "/api/layers/x/features/p1/revisions/1", "bob", "1", "", http.StatusOK},
+		{"revision invalid", layerController.FeatureRevisionHandler, "GET", "/api/layers/x/features/p1/revisions/0", "bob", "0", "", http.StatusBadRequest},
+		{"get at", layerController.GetFeatureHandler, "GET", "/api/layers/x/features/p1?at=2999-01-01T00:00:00Z", "bob", "", "", http.StatusOK},
+		{"get before creation", layerController.GetFeatureHandler, "GET", "/api/layers/x/features/p1?at=2000-01-01T00:00:00Z", "bob", "", "", http.StatusNotFound},
+		{"get at invalid", layerController.GetFeatureHandler, "GET", "/api/layers/x/features/p1?at=now", "bob", "", "", http.StatusBadRequest},
+		{"delete", layerController.DeleteFeatureHandler, "DELETE", "/api/layers/x/features/p1", "alice", "", "", http.StatusNoContent},
+		{"revert by viewer", layerController.RevertFeatureHandler, "POST", "/api/layers/x/features/p1/revert", "bob", "", `{"revision":1}`, http.StatusForbidden},
+		{"revert to created", layerController.RevertFeatureHandler, "POST", "/api/layers/x/features/p1/revert", "alice", "", `{"revision":1}`, http.StatusOK},
+		{"revert to deleted", layerController.RevertFeatureHandler, "POST", "/api/layers/x/features/p1/revert", "alice", "", `{"revision":2}`, http.StatusNoContent},
+	}
+	for _, tc := range testCases {
+		rr := httptest.NewRecorder()
+		req := featureRequest(t, tc.method, tc.path, tc.username, created.ID, "p1", tc.body)
+		chi.RouteContext(req.Context()).URLParams.Add("rev", tc.rev)
+		tc.handler.ServeHTTP(rr, req)
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v (%s)", tc.name, status, tc.expected, rr.Body)
+		}
+	}
+}
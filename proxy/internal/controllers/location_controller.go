@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/responder"
+	"geo-controller/proxy/internal/service"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+)
+
+// maxImportSize ограничивает размер загружаемого файла точек.
+const maxImportSize = 32 << 20
+
+type LocationController struct {
+	locationService *service.LocationService
+	responder       *responder.Responder
+}
+
+func NewLocationController(locationService *service.LocationService) *LocationController {
+	return &LocationController{
+		locationService: locationService,
+		responder:       responder.NewResponder(),
+	}
+}
+
+func (c *LocationController) GetHandler(w http.ResponseWriter, r *http.Request) {
+	at, past, err := atParam(r)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	var location *models.Location
+	if past {
+		location, err = c.locationService.At(chi.URLParam(r, "id"), at)
+	} else {
+		location, err = c.locationService.Get(chi.URLParam(r, "id"))
+	}
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, location)
+}
+
+func (c *LocationController) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var locationReq models.LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&locationReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	location, err := c.locationService.Create(user, locationReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, location)
+}
+
+func (c *LocationController) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var locationReq models.LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&locationReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	location, err := c.locationService.Update(user, chi.URLParam(r, "id"), locationReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, location)
+}
+
+func (c *LocationController) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+
+	if err := c.locationService.Delete(user, chi.URLParam(r, "id")); err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportHandler загружает файл точек из тела запроса. Формат определяется
+// по Content-Type: text/csv — CSV, application/json и application/geo+json —
+// GeoJSON.
+func (c *LocationController) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	format, err := importFormat(r.Header.Get("Content-Type"))
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	importResp, err := c.locationService.ImportLocations(user, format, data)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, importResp)
+}
+
+func (c *LocationController) RevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	revisions, err := c.locationService.Revisions(chi.URLParam(r, "id"))
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, revisions)
+}
+
+func (c *LocationController) RevisionHandler(w http.ResponseWriter, r *http.Request) {
+	number, err := revisionParam(r)
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	revision, err := c.locationService.Revision(chi.URLParam(r, "id"), number)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, revision)
+}
+
+// RevertHandler возвращает точку к ревизии. Если ревизия — удаление,
+// точка удаляется и ответ пустой.
+func (c *LocationController) RevertHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := requestUser(r)
+	if err != nil {
+		c.responder.ErrorUnauthorized(w, err)
+		return
+	}
+	var revertReq models.RevertRequest
+	if err := json.NewDecoder(r.Body).Decode(&revertReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	location, err := c.locationService.Revert(user, chi.URLParam(r, "id"), revertReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+	if location == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	c.responder.OutputJSON(w, location)
+}
+
+func importFormat(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("invalid Content-Type: %v", err)
+	}
+	switch mediaType {
+	case "text/csv":
+		return service.LocationFormatCSV, nil
+	case "application/json", "application/geo+json":
+		return service.LocationFormatGeoJSON, nil
+	}
+	return "", fmt.Errorf("unsupported Content-Type %q: use text/csv or application/geo+json", mediaType)
+}
+
+func (c *LocationController) NearestHandler(w http.ResponseWriter, r *http.Request) {
+	var nearestReq models.NearestLocationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&nearestReq); err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	nearestResp, err := c.locationService.Nearest(nearestReq)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, nearestResp)
+}
+
+// ClustersHandler отдает кластеры точек видимой области карты. Параметры
+// запроса: bbox — область "запад,юг,восток,север", zoom — масштаб карты.
+func (c *LocationController) ClustersHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	box, err := parseBBox(params.Get("bbox"))
+	if err != nil {
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+	zoom, err := strconv.Atoi(params.Get("zoom"))
+	if err != nil {
+		c.responder.ErrorBadRequest(w, fmt.Errorf("zoom must be an integer"))
+		return
+	}
+
+	clustersResp, err := c.locationService.Clusters(*box, zoom)
+	if err != nil {
+		c.outputError(w, err)
+		return
+	}
+
+	c.responder.OutputJSON(w, clustersResp)
+}
+
+// outputError отвечает 404, если точки нет или адрес не найден,
+// и 500, если изменение не удалось сохранить.
+func (c *LocationController) outputError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrLocationNotFound), errors.Is(err, service.ErrAddressNotFound):
+		c.responder.ErrorNotFound(w, err)
+	case errors.Is(err, service.ErrLocationForbidden):
+		c.responder.ErrorForbidden(w, err)
+	case errors.Is(err, service.ErrLocationStorage):
+		c.responder.ErrorInternal(w, err)
+	default:
+		c.responder.ErrorBadRequest(w, err)
+	}
+}
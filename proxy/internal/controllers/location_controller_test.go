@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"geo-controller/proxy/internal/locator"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func locationRequest(t *testing.T, method, id, contentType, body string) *http.Request {
+	t.Helper()
+	req := userRequest(t, method, "/api/locations", "admin", id, []byte(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func TestLocationController(t *testing.T) {
+	registry, err := locator.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addressService := service.NewAddressService("", "", service.WithProvider(&stubAddressProvider{}))
+	locationController := NewLocationController(service.NewLocationService(registry, addressService))
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(locationController.CreateHandler).ServeHTTP(rr,
+		locationRequest(t, "POST", "", "application/json", `{"name":"Тверская","point":{"lat":55.76,"lon":37.61},"tags":["pickup"]}`))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var created models.Location
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name        string
+		handler     http.HandlerFunc
+		method, id  string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"get", locationController.GetHandler, "GET", created.ID, "", "", http.StatusOK},
+		{"get missing", locationController.GetHandler, "GET", "missing", "", "", http.StatusNotFound},
+		{"update", locationController.UpdateHandler, "PUT", created.ID, "application/json", `{"name":"Тверская, 7","point":{"lat":55.76,"lon":37.61}}`, http.StatusOK},
+		{"update invalid hours", locationController.UpdateHandler, "PUT", created.ID, "application/json", `{"name":"a","point":{"lat":55.76,"lon":37.61},"hours":"всегда"}`, http.StatusBadRequest},
+		{"create without point", locationController.CreateHandler, "POST", "", "application/json", `{"name":"a"}`, http.StatusBadRequest},
+		{"import csv", locationController.ImportHandler, "POST", "", "text/csv; charset=utf-8", "name,lat,lon\nНевский,59.93,30.36\n", http.StatusOK},
+		{"import geojson", locationController.ImportHandler, "POST", "", "application/geo+json", `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"name":"Тверь"},"geometry":{"type":"Point","coordinates":[35.9,56.86]}}]}`, http.StatusOK},
+		{"import invalid csv", locationController.ImportHandler, "POST", "", "text/csv", "name,lat\nA,55\n", http.StatusBadRequest},
+		{"import unsupported", locationController.ImportHandler, "POST", "", "application/xml", "<locations/>", http.StatusBadRequest},
+		{"nearest", locationController.NearestHandler, "POST", "", "application/json", `{"point":{"lat":55.75,"lon":37.62},"limit":2}`, http.StatusOK},
+		{"nearest invalid limit", locationController.NearestHandler, "POST", "", "application/json", `{"point":{"lat":55.75,"lon":37.62},"limit":1000}`, http.StatusBadRequest},
+		{"delete", locationController.DeleteHandler, "DELETE", created.ID, "", "", http.StatusNoContent},
+		{"delete again", locationController.DeleteHandler, "DELETE", created.ID, "", "", http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		rr := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rr, locationRequest(t, tc.method, tc.id, tc.contentType, tc.body))
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", tc.name, status, tc.expected)
+		}
+	}
+
+	if registry.Len() != 2 {
+		t.Errorf("expected 2 imported locations, got %d", registry.Len())
+	}
+
+	rr = httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/api/locations", bytes.NewBufferString(`{"name":"a","point":{"lat":55,"lon":37}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locationController.CreateHandler(rr, req)
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("create without user: handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+	}
+	owned, err := registry.Create("admin", models.Location{Name: "Арбат", Point: models.GeoPoint{Lat: 55.75, Lon: 37.59}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		handler http.HandlerFunc
+		method  string
+		body    string
+	}{
+		"update": {locationController.UpdateHandler, "PUT", `{"name":"Арбат, 1","point":{"lat":55.75,"lon":37.59}}`},
+		"delete": {locationController.DeleteHandler, "DELETE", ""},
+	} {
+		rr := httptest.NewRecorder()
+		req := userRequest(t, tc.method, "/api/locations", "bob", owned.ID, []byte(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		tc.handler.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("%s by another user: handler returned wrong status code: got %v want %v", name, status, http.StatusForbidden)
+		}
+	}
+}
+
+func TestLocationController_History(t *testing.T) {
+	registry, err := locator.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	locationController := NewLocationController(service.NewLocationService(registry, nil))
+
+	rr := httptest.NewRecorder()
+	locationController.CreateHandler(rr, locationRequest(t, "POST", "", "application/json", `{"name":"Тверская","point":{"lat":55.76,"lon":37.61}}`))
+	var created models.Location
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		method   string
+		path     string
+		rev      string
+		body     string
+		expected int
+	}{
+		{"revisions", locationController.RevisionsHandler, "GET", "/api/locations/x/revisions", "", "", http.StatusOK},
+		{"revision", locationController.RevisionHandler, "GET", "/api/locations/x/revisions/1", "1", "", http.StatusOK},
+		{"revision invalid", locationController.RevisionHandler, "GET", "/api/locations/x/revisions/x", "x", "", http.StatusBadRequest},
+		{"revision missing", locationController.RevisionHandler, "GET", "/api/locations/x/revisions/9", "9", "", http.StatusNotFound},
+		{"get at", locationController.GetHandler, "GET", "/api/locations/x?at=2999-01-01T00:00:00Z", "", "", http.StatusOK},
+		{"get before creation", locationController.GetHandler, "GET", "/api/locations/x?at=2000-01-01T00:00:00Z", "", "", http.StatusNotFound},
+		{"get at invalid", locationController.GetHandler, "GET", "/api/locations/x?at=yesterday", "", "", http.StatusBadRequest},
+		{"delete", locationController.DeleteHandler, "DELETE", "/api/locations/x", "", "", http.StatusNoContent},
+		{"revert to created", locationController.RevertHandler, "POST", "/api/locations/x/revert", "", `{"revision":1}`, http.StatusOK},
+		{"revert to deleted", locationController.RevertHandler, "POST", "/api/locations/x/revert", "", `{"revision":2}`, http.StatusNoContent},
+		{"revert to deleted again", locationController.RevertHandler, "POST", "/api/locations/x/revert", "", `{"revision":2}`, http.StatusBadRequest},
+		{"revert missing", locationController.RevertHandler, "POST", "/api/locations/x/revert", "", `{"revision":9}`, http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		rr := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rr, revisionRequest(t, tc.method, tc.path, "admin", created.ID, tc.rev, tc.body))
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v (%s)", tc.name, status, tc.expected, rr.Body)
+		}
+	}
+}
+
+func TestLocationController_ClustersHandler(t *testing.T) {
+	registry, err := locator.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	locationService := service.NewLocationService(registry, nil)
+	if _, err := locationService.ImportLocations("admin", service.LocationFormatCSV, []byte("name,lat,lon\nТверская,55.76,37.61\nАрбат,55.75,37.59\n")); err != nil {
+		t.Fatal(err)
+	}
+	locationController := NewLocationController(locationService)
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{"clusters", "bbox=37,55,38,56&zoom=5", http.StatusOK},
+		{"bbox wider than world", "bbox=-250,-80,250,80&zoom=1", http.StatusOK},
+		{"no bbox", "zoom=5", http.StatusBadRequest},
+		{"no zoom", "bbox=37,55,38,56", http.StatusBadRequest},
+		{"zoom out of range", "bbox=37,55,38,56&zoom=30", http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		rr := httptest.NewRecorder()
+		locationController.ClustersHandler(rr, httptest.NewRequest("GET", "/api/locations/clusters?"+tc.query, nil))
+		if status := rr.Code; status != tc.expected {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", tc.name, status, tc.expected)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	locationController.ClustersHandler(rr, httptest.NewRequest("GET", "/api/locations/clusters?bbox=37,55,38,56&zoom=5", nil))
+	var clustersResp models.LocationClustersResponse
+	if err := json.NewDecoder(rr.Body).Decode(&clustersResp); err != nil {
+		t.Fatal(err)
+	}
+	if len(clustersResp.Clusters) != 1 || clustersResp.Clusters[0].Count != 2 {
+		t.Errorf("expected one cluster of two locations, got %+v", clustersResp)
+	}
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// atParam разбирает параметр запроса at — момент времени в RFC 3339,
+// на который нужно показать объект. ok ложно, если параметр не задан.
+func atParam(r *http.Request) (at time.Time, ok bool, err error) {
+	value := r.URL.Query().Get("at")
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	at, err = time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("at must be an RFC 3339 timestamp")
+	}
+	return at, true, nil
+}
+
+// revisionParam разбирает номер ревизии из параметра маршрута rev.
+func revisionParam(r *http.Request) (int, error) {
+	number, err := strconv.Atoi(chi.URLParam(r, "rev"))
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("revision must be a positive integer")
+	}
+	return number, nil
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/jwtauth"
+)
+
+// errNoUser возвращается, если в токене нет имени пользователя.
+var errNoUser = errors.New("token has no username")
+
+// requestUser возвращает имя пользователя из проверенного токена, который
+// AuthMiddleware кладет в контекст запроса.
+func requestUser(r *http.Request) (string, error) {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return "", err
+	}
+	username, _ := claims["username"].(string)
+	if username == "" {
+		return "", errNoUser
+	}
+	return username, nil
+}
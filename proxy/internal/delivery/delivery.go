@@ -0,0 +1,233 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"os"
+	"sort"
+)
+
+// ErrInvalidConfig возвращается для конфигурации с ошибками: неизвестным
+// складом, повторяющимся идентификатором или зоной без формы.
+var ErrInvalidConfig = errors.New("invalid delivery config")
+
+// Config — файл конфигурации зон обслуживания. Каждая зона задается либо
+// геометрией Polygon/MultiPolygon, либо радиусом в метрах вокруг своего склада:
+//
+//	{
+//	  "depots": [{"id": "north", "name": "Склад Север", "point": {"lat": 55.85, "lon": 37.6}}],
+//	  "zones": [
+//	    {"id": "center", "depot_id": "north", "fee": 0, "eta_tier": "express", "geometry": {...}},
+//	    {"id": "ring", "depot_id": "north", "fee": 300, "eta_tier": "next_day", "radius": 30000}
+//	  ]
+//	}
+type Config struct {
+	Depots []models.Depot `json:"depots"`
+	Zones  []ZoneConfig   `json:"zones"`
+}
+
+// ZoneConfig — зона в конфигурации: атрибуты и форма.
+type ZoneConfig struct {
+	models.DeliveryZone
+	Radius   float64          `json:"radius,omitempty"`
+	Geometry *models.Geometry `json:"geometry,omitempty"`
+}
+
+// Zone — зона обслуживания с разобранной формой: полигонами либо кругом
+// радиуса radius метров вокруг склада.
+type Zone struct {
+	models.DeliveryZone
+	Depot *models.Depot
+
+	geometry *geometry.Geometry
+	radius   float64
+}
+
+// Contains сообщает, лежит ли точка в зоне. Круг проверяется по большому
+// кругу, полигоны — на плоскости долготы и широты.
+func (z *Zone) Contains(lat, lon float64) bool {
+	if z.geometry == nil {
+		return geo.Haversine(z.Depot.Point.Lat, z.Depot.Point.Lon, lat, lon) <= z.radius
+	}
+	return geometry.Contains(z.geometry, geometry.Point{lon, lat})
+}
+
+// Distance возвращает расстояние в метрах от точки до границы зоны или 0,
+// если точка внутри.
+func (z *Zone) Distance(lat, lon float64) float64 {
+	if z.Contains(lat, lon) {
+		return 0
+	}
+	if z.geometry == nil {
+		return geo.Haversine(z.Depot.Point.Lat, z.Depot.Point.Lon, lat, lon) - z.radius
+	}
+	return geometry.BoundaryDistance(z.geometry, geometry.Point{lon, lat})
+}
+
+// rect возвращает охватывающий прямоугольник круга. Долготный размах
+// круга с угловым радиусом r на широте φ — asin(sin r / cos φ); если круг
+// накрывает полюс, он занимает все долготы.
+func (z *Zone) rect() spatial.Rect {
+	center := z.Depot.Point
+	angular := z.radius / geo.EarthRadius
+	dLat := angular * 180 / math.Pi
+	rect := spatial.Rect{MinX: -180, MinY: center.Lat - dLat, MaxX: 180, MaxY: center.Lat + dLat}
+	if ratio := math.Sin(angular) / math.Cos(center.Lat*math.Pi/180); angular < math.Pi/2 && ratio < 1 {
+		dLon := math.Asin(ratio) * 180 / math.Pi
+		rect.MinX, rect.MaxX = center.Lon-dLon, center.Lon+dLon
+	}
+	return rect
+}
+
+// Index находит зоны обслуживания по точке. Полигоны и круги хранятся
+// в R-дереве; после построения индекс только читается.
+type Index struct {
+	depots map[string]*models.Depot
+	zones  []*Zone
+	tree   *spatial.RTree[*Zone]
+}
+
+// Load читает конфигурацию зон из файла JSON.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	index, err := New(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return index, nil
+}
+
+// New проверяет конфигурацию и строит индекс.
+func New(config Config) (*Index, error) {
+	index := &Index{depots: map[string]*models.Depot{}, tree: spatial.NewRTree[*Zone]()}
+	for i := range config.Depots {
+		depot := config.Depots[i]
+		if depot.ID == "" {
+			return nil, fmt.Errorf("%w: depot %d has no id", ErrInvalidConfig, i)
+		}
+		if index.depots[depot.ID] != nil {
+			return nil, fmt.Errorf("%w: duplicate depot %q", ErrInvalidConfig, depot.ID)
+		}
+		if math.Abs(depot.Point.Lat) > 90 || math.Abs(depot.Point.Lon) > 180 {
+			return nil, fmt.Errorf("%w: depot %q: coordinates out of range", ErrInvalidConfig, depot.ID)
+		}
+		index.depots[depot.ID] = &depot
+	}
+
+	ids := map[string]bool{}
+	for i, zc := range config.Zones {
+		if zc.ID == "" {
+			return nil, fmt.Errorf("%w: zone %d has no id", ErrInvalidConfig, i)
+		}
+		if ids[zc.ID] {
+			return nil, fmt.Errorf("%w: duplicate zone %q", ErrInvalidConfig, zc.ID)
+		}
+		ids[zc.ID] = true
+		zone, err := index.newZone(zc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: zone %q: %v", ErrInvalidConfig, zc.ID, err)
+		}
+		index.add(zone)
+	}
+	return index, nil
+}
+
+func (index *Index) newZone(zc ZoneConfig) (*Zone, error) {
+	depot := index.depots[zc.DepotID]
+	if depot == nil {
+		return nil, fmt.Errorf("unknown depot %q", zc.DepotID)
+	}
+	if zc.Fee < 0 {
+		return nil, errors.New("fee cannot be negative")
+	}
+	zone := &Zone{DeliveryZone: zc.DeliveryZone, Depot: depot}
+	switch {
+	case zc.Geometry != nil && zc.Radius != 0:
+		return nil, errors.New("zone must have either geometry or radius")
+	case zc.Geometry != nil:
+		g, err := geometry.DecodeDataset(*zc.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		if geometry.Area(g) == 0 {
+			return nil, errors.New("geometry must be a polygon with non-zero area")
+		}
+		zone.geometry = g
+	case zc.Radius > 0:
+		zone.radius = zc.Radius
+	default:
+		return nil, errors.New("zone must have geometry or positive radius")
+	}
+	return zone, nil
+}
+
+func (index *Index) add(zone *Zone) {
+	index.zones = append(index.zones, zone)
+	if zone.geometry == nil {
+		index.tree.Insert(zone.rect(), zone)
+		return
+	}
+	polygons := zone.geometry.PolygonParts()
+	rect := geometry.RingRect(polygons[0][0])
+	for _, polygon := range polygons[1:] {
+		rect = rect.Union(geometry.RingRect(polygon[0]))
+	}
+	index.tree.Insert(rect, zone)
+}
+
+// Len возвращает число зон в индексе.
+func (index *Index) Len() int {
+	return len(index.zones)
+}
+
+// Containing возвращает зоны, содержащие точку, от меньшей стоимости
+// доставки к большей; при равной стоимости первой идет зона с ближайшим
+// к точке складом.
+func (index *Index) Containing(lat, lon float64) []*Zone {
+	var found []*Zone
+	index.tree.Search(spatial.PointRect(lon, lat), func(zone *Zone) bool {
+		if zone.Contains(lat, lon) {
+			found = append(found, zone)
+		}
+		return true
+	})
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.Fee != b.Fee {
+			return a.Fee < b.Fee
+		}
+		da := geo.Haversine(a.Depot.Point.Lat, a.Depot.Point.Lon, lat, lon)
+		db := geo.Haversine(b.Depot.Point.Lat, b.Depot.Point.Lon, lat, lon)
+		if da != db {
+			return da < db
+		}
+		return a.ID < b.ID
+	})
+	return found
+}
+
+// Nearest возвращает зону с ближайшей к точке границей и расстояние до нее
+// в метрах. Зон в конфигурации немного, поэтому они перебираются все.
+func (index *Index) Nearest(lat, lon float64) (*Zone, float64, bool) {
+	var nearest *Zone
+	best := math.Inf(1)
+	for _, zone := range index.zones {
+		if d := zone.Distance(lat, lon); d < best {
+			nearest, best = zone, d
+		}
+	}
+	return nearest, best, nearest != nil
+}
@@ -0,0 +1,180 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/models"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testConfig — склад в центре Москвы с бесплатной зоной внутри Садового
+// кольца (упрощенный квадрат) и платным кругом 30 км, и склад в Твери
+// с кругом 10 км.
+const testConfig = `{
+	"depots": [
+		{"id": "msk", "name": "Москва", "point": {"lat": 55.75, "lon": 37.62}},
+		{"id": "tver", "name": "Тверь", "point": {"lat": 56.86, "lon": 35.9}}
+	],
+	"zones": [
+		{"id": "center", "name": "Центр", "depot_id": "msk", "fee": 0, "eta_tier": "express",
+		 "geometry": {"type": "Polygon", "coordinates": [[[37.58,55.73],[37.66,55.73],[37.66,55.77],[37.58,55.77],[37.58,55.73]]]}},
+		{"id": "ring", "name": "До 30 км", "depot_id": "msk", "fee": 300, "eta_tier": "next_day", "radius": 30000},
+		{"id": "tver-city", "depot_id": "tver", "fee": 200, "radius": 10000}
+	]
+}`
+
+func testIndex(t *testing.T) *Index {
+	t.Helper()
+	var config Config
+	if err := json.Unmarshal([]byte(testConfig), &config); err != nil {
+		t.Fatal(err)
+	}
+	index, err := New(config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return index
+}
+
+func zoneIDs(zones []*Zone) []string {
+	ids := []string{}
+	for _, zone := range zones {
+		ids = append(ids, zone.ID)
+	}
+	return ids
+}
+
+func TestIndexContaining(t *testing.T) {
+	index := testIndex(t)
+	if index.Len() != 3 {
+		t.Fatalf("expected 3 zones, got %d", index.Len())
+	}
+
+	testCases := []struct {
+		name     string
+		lat, lon float64
+		expected []string
+	}{
+		{"center", 55.75, 37.62, []string{"center", "ring"}},
+		{"ring only", 55.85, 37.62, []string{"ring"}},
+		{"tver", 56.86, 35.95, []string{"tver-city"}},
+		{"outside", 56.3, 36.8, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := zoneIDs(index.Containing(tc.lat, tc.lon))
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Fatalf("expected %v, got %v", tc.expected, got)
+				}
+			}
+		})
+	}
+
+	if zones := index.Containing(55.75, 37.62); zones[0].Depot.ID != "msk" {
+		t.Errorf("expected depot msk, got %s", zones[0].Depot.ID)
+	}
+}
+
+func TestIndexNearest(t *testing.T) {
+	index := testIndex(t)
+
+	// 40 км к северу от московского склада: до круга 30 км — 10 км
+	lat, _ := geo.Destination(55.75, 37.62, 0, 40000)
+	zone, distance, ok := index.Nearest(lat, 37.62)
+	if !ok || zone.ID != "ring" {
+		t.Fatalf("expected ring, got %v", zone)
+	}
+	if math.Abs(distance-10000) > 1 {
+		t.Errorf("expected 10000 m, got %.1f", distance)
+	}
+
+	// внутри зоны расстояние нулевое
+	if zone, distance, _ := index.Nearest(55.75, 37.62); distance != 0 || zone.ID == "tver-city" {
+		t.Errorf("expected zero distance to a Moscow zone, got %s %.1f", zone.ID, distance)
+	}
+
+	empty, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := empty.Nearest(lat, 37.62); ok {
+		t.Error("expected no nearest zone in an empty index")
+	}
+}
+
+func TestZoneRectCoversCircle(t *testing.T) {
+	index := testIndex(t)
+	for _, zone := range index.zones {
+		if zone.geometry != nil {
+			continue
+		}
+		rect := zone.rect()
+		for bearing := 0.0; bearing < 360; bearing += 15 {
+			// точка чуть внутри окружности должна попасть в прямоугольник
+			y, x := geo.Destination(zone.Depot.Point.Lat, zone.Depot.Point.Lon, bearing, zone.radius*0.999)
+			if x < rect.MinX || x > rect.MaxX || y < rect.MinY || y > rect.MaxY {
+				t.Errorf("zone %s: point %.5f,%.5f outside rect %+v", zone.ID, y, x, rect)
+			}
+		}
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	depots := []models.Depot{{ID: "a", Point: models.GeoPoint{Lat: 55, Lon: 37}}}
+	polygon := &models.Geometry{Type: "Polygon", Coordinates: json.RawMessage(`[[[37,55],[38,55],[38,56],[37,55]]]`)}
+	line := &models.Geometry{Type: "LineString", Coordinates: json.RawMessage(`[[37,55],[38,56]]`)}
+	zone := func(id, depot string, fee, radius float64, g *models.Geometry) ZoneConfig {
+		return ZoneConfig{DeliveryZone: models.DeliveryZone{ID: id, DepotID: depot, Fee: fee}, Radius: radius, Geometry: g}
+	}
+
+	testCases := []struct {
+		name   string
+		config Config
+	}{
+		{"depot without id", Config{Depots: []models.Depot{{}}}},
+		{"duplicate depot", Config{Depots: append(depots, depots[0])}},
+		{"depot out of range", Config{Depots: []models.Depot{{ID: "a", Point: models.GeoPoint{Lat: 95}}}}},
+		{"zone without id", Config{Depots: depots, Zones: []ZoneConfig{zone("", "a", 0, 100, nil)}}},
+		{"duplicate zone", Config{Depots: depots, Zones: []ZoneConfig{zone("z", "a", 0, 100, nil), zone("z", "a", 0, 100, nil)}}},
+		{"unknown depot", Config{Depots: depots, Zones: []ZoneConfig{zone("z", "b", 0, 100, nil)}}},
+		{"negative fee", Config{Depots: depots, Zones: []ZoneConfig{zone("z", "a", -1, 100, nil)}}},
+		{"no shape", Config{Depots: depots, Zones: []ZoneConfig{zone("z", "a", 0, 0, nil)}}},
+		{"both shapes", Config{Depots: depots, Zones: []ZoneConfig{zone("z", "a", 0, 100, polygon)}}},
+		{"line geometry", Config{Depots: depots, Zones: []ZoneConfig{zone("z", "a", 0, 0, line)}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := New(tc.config); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zones.json")
+	if err := os.WriteFile(path, []byte(testConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	index, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if index.Len() != 3 {
+		t.Errorf("expected 3 zones, got %d", index.Len())
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
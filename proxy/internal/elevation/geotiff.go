@@ -0,0 +1,414 @@
+package elevation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/spatial"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Теги TIFF 6.0 и GeoTIFF 1.0, нужные для одноканальных растров высот.
+const (
+	tagImageWidth      = 256
+	tagImageLength     = 257
+	tagBitsPerSample   = 258
+	tagCompression     = 259
+	tagStripOffsets    = 273
+	tagSamplesPerPixel = 277
+	tagRowsPerStrip    = 278
+	tagStripByteCounts = 279
+	tagPredictor       = 317
+	tagTileWidth       = 322
+	tagTileLength      = 323
+	tagTileOffsets     = 324
+	tagTileByteCounts  = 325
+	tagSampleFormat    = 339
+	tagModelPixelScale = 33550
+	tagModelTiepoint   = 33922
+	tagGeoKeyDirectory = 34735
+	tagGDALNoData      = 42113
+)
+
+const (
+	compressionNone         = 1
+	compressionLZW          = 5
+	compressionDeflate      = 8
+	compressionAdobeDeflate = 32946
+)
+
+const (
+	predictorNone       = 1
+	predictorHorizontal = 2
+)
+
+const (
+	sampleUint  = 1
+	sampleInt   = 2
+	sampleFloat = 3
+)
+
+// Ключи GeoKeyDirectory и их значения.
+const (
+	keyModelType       = 1024
+	keyRasterType      = 1025
+	modelGeographic    = 2
+	rasterPixelIsPoint = 2
+)
+
+// maxTagLength ограничивает размер значения тега, чтобы поврежденный файл
+// не приводил к огромным выделениям памяти.
+const maxTagLength = 64 << 20
+
+// typeSizes — размеры значений типов полей TIFF: BYTE, ASCII, SHORT, LONG,
+// RATIONAL, SBYTE, UNDEFINED, SSHORT, SLONG, SRATIONAL, FLOAT, DOUBLE.
+var typeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+const typeASCII = 2
+
+// ifd — первый каталог изображения: числовые и текстовые теги.
+type ifd struct {
+	order  binary.ByteOrder
+	fields map[uint16][]float64
+	text   map[uint16]string
+}
+
+func readIFD(r io.ReaderAt) (*ifd, error) {
+	header := make([]byte, 8)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("read TIFF header: %w", err)
+	}
+	var order binary.ByteOrder
+	switch string(header[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("not a TIFF file")
+	}
+	switch order.Uint16(header[2:]) {
+	case 42:
+	case 43:
+		return nil, errors.New("BigTIFF is not supported")
+	default:
+		return nil, errors.New("not a TIFF file")
+	}
+
+	offset := int64(order.Uint32(header[4:]))
+	count := make([]byte, 2)
+	if _, err := r.ReadAt(count, offset); err != nil {
+		return nil, fmt.Errorf("read TIFF directory: %w", err)
+	}
+	entries := make([]byte, 12*int(order.Uint16(count)))
+	if _, err := r.ReadAt(entries, offset+2); err != nil {
+		return nil, fmt.Errorf("read TIFF directory: %w", err)
+	}
+
+	d := &ifd{order: order, fields: map[uint16][]float64{}, text: map[uint16]string{}}
+	for e := entries; len(e) >= 12; e = e[12:] {
+		tag, typ, n := order.Uint16(e), order.Uint16(e[2:]), int64(order.Uint32(e[4:]))
+		size, ok := typeSizes[typ]
+		if !ok {
+			continue
+		}
+		length := int64(size) * n
+		if length > maxTagLength {
+			return nil, fmt.Errorf("TIFF tag %d is too large", tag)
+		}
+		// значения до четырех байт хранятся в самой записи
+		var raw []byte
+		if length <= 4 {
+			raw = e[8 : 8+length]
+		} else {
+			raw = make([]byte, length)
+			if _, err := r.ReadAt(raw, int64(order.Uint32(e[8:]))); err != nil {
+				return nil, fmt.Errorf("read TIFF tag %d: %w", tag, err)
+			}
+		}
+		if typ == typeASCII {
+			d.text[tag] = strings.TrimRight(string(raw), "\x00")
+			continue
+		}
+		d.fields[tag] = decodeValues(order, typ, raw, size)
+	}
+	return d, nil
+}
+
+func decodeValues(order binary.ByteOrder, typ uint16, raw []byte, size int) []float64 {
+	values := make([]float64, len(raw)/size)
+	for i := range values {
+		b := raw[i*size:]
+		switch typ {
+		case 1, 7:
+			values[i] = float64(b[0])
+		case 6:
+			values[i] = float64(int8(b[0]))
+		case 3:
+			values[i] = float64(order.Uint16(b))
+		case 8:
+			values[i] = float64(int16(order.Uint16(b)))
+		case 4:
+			values[i] = float64(order.Uint32(b))
+		case 9:
+			values[i] = float64(int32(order.Uint32(b)))
+		case 5:
+			values[i] = float64(order.Uint32(b)) / float64(order.Uint32(b[4:]))
+		case 10:
+			values[i] = float64(int32(order.Uint32(b))) / float64(int32(order.Uint32(b[4:])))
+		case 11:
+			values[i] = float64(math.Float32frombits(order.Uint32(b)))
+		case 12:
+			values[i] = math.Float64frombits(order.Uint64(b))
+		}
+	}
+	return values
+}
+
+// int возвращает первое значение тега или fallback, если тега нет.
+func (d *ifd) int(tag uint16, fallback int) int {
+	if values := d.fields[tag]; len(values) > 0 {
+		return int(values[0])
+	}
+	return fallback
+}
+
+// geoKeys разбирает GeoKeyDirectory: заголовок из четырех чисел, затем
+// записи по четыре числа — ключ, место хранения, число значений, значение.
+// Значения, хранящиеся в других тегах, не нужны и пропускаются.
+func (d *ifd) geoKeys() map[int]int {
+	directory := d.fields[tagGeoKeyDirectory]
+	keys := map[int]int{}
+	if len(directory) < 4 {
+		return keys
+	}
+	for entry := directory[4:]; len(entry) >= 4; entry = entry[4:] {
+		if entry[1] == 0 {
+			keys[int(entry[0])] = int(entry[3])
+		}
+	}
+	return keys
+}
+
+// ReadGeoTIFF читает одноканальный GeoTIFF в географических координатах
+// (например, EPSG:4326): тайлы или полосы, без сжатия, LZW или Deflate,
+// с горизонтальным предиктором или без, целые и вещественные значения.
+// Проецированные растры нужно предварительно перепроецировать.
+func ReadGeoTIFF(path string) (*Grid, error) {
+	return readGeoTIFF(path, true)
+}
+
+// readGeoTIFF при withData == false читает только заголовок: размеры
+// и привязку, без значений.
+func readGeoTIFF(path string, withData bool) (*Grid, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d, err := readIFD(f)
+	if err != nil {
+		return nil, err
+	}
+	width, height := d.int(tagImageWidth, 0), d.int(tagImageLength, 0)
+	if width < 1 || height < 1 {
+		return nil, errors.New("invalid raster size")
+	}
+	if bands := d.int(tagSamplesPerPixel, 1); bands != 1 {
+		return nil, fmt.Errorf("expected single-band raster, got %d bands", bands)
+	}
+	sample, err := sampleDecoder(d.order, d.int(tagSampleFormat, sampleUint), d.int(tagBitsPerSample, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	grid, err := georeference(d, width, height)
+	if err != nil {
+		return nil, err
+	}
+	if !withData {
+		return grid, nil
+	}
+
+	nodata := math.NaN()
+	if text := strings.TrimSpace(d.text[tagGDALNoData]); text != "" {
+		if nodata, err = strconv.ParseFloat(text, 64); err != nil {
+			return nil, fmt.Errorf("invalid GDAL_NODATA %q", text)
+		}
+	}
+	grid.Values = make([]float32, width*height)
+	if err := readChunks(f, d, grid, sample, nodata); err != nil {
+		return nil, err
+	}
+	return grid, nil
+}
+
+// georeference вычисляет привязку по ModelTiepoint и ModelPixelScale.
+// Для растров PixelIsArea опорная точка — угол пикселя, для PixelIsPoint — центр.
+func georeference(d *ifd, width, height int) (*Grid, error) {
+	scale, tiepoint := d.fields[tagModelPixelScale], d.fields[tagModelTiepoint]
+	if len(scale) < 2 || len(tiepoint) < 6 || scale[0] <= 0 || scale[1] <= 0 {
+		return nil, errors.New("missing georeferencing: ModelPixelScale and ModelTiepoint are required")
+	}
+	keys := d.geoKeys()
+	if keys[keyModelType] != modelGeographic {
+		return nil, errors.New("raster must be in geographic coordinates, reproject it to EPSG:4326")
+	}
+
+	grid := &Grid{
+		LatStep: scale[1],
+		LonStep: scale[0],
+		Rows:    height,
+		Cols:    width,
+		West:    tiepoint[3] - tiepoint[0]*scale[0],
+		North:   tiepoint[4] + tiepoint[1]*scale[1],
+	}
+	south := grid.North - float64(height-1)*grid.LatStep
+	east := grid.West + float64(width-1)*grid.LonStep
+	if keys[keyRasterType] == rasterPixelIsPoint {
+		grid.Bounds = spatial.Rect{MinX: grid.West, MinY: south, MaxX: east, MaxY: grid.North}
+		return grid, nil
+	}
+	grid.Bounds = spatial.Rect{
+		MinX: grid.West,
+		MinY: south - grid.LatStep,
+		MaxX: east + grid.LonStep,
+		MaxY: grid.North,
+	}
+	grid.West += grid.LonStep / 2
+	grid.North -= grid.LatStep / 2
+	return grid, nil
+}
+
+// sampleFunc читает одно значение растра размером size байт.
+type sampleFunc struct {
+	size int
+	read func([]byte) float64
+}
+
+// sampleDecoder выбирает чтение значений по SampleFormat и BitsPerSample.
+func sampleDecoder(order binary.ByteOrder, format, bits int) (sampleFunc, error) {
+	switch {
+	case format == sampleUint && bits == 8:
+		return sampleFunc{1, func(b []byte) float64 { return float64(b[0]) }}, nil
+	case format == sampleInt && bits == 8:
+		return sampleFunc{1, func(b []byte) float64 { return float64(int8(b[0])) }}, nil
+	case format == sampleUint && bits == 16:
+		return sampleFunc{2, func(b []byte) float64 { return float64(order.Uint16(b)) }}, nil
+	case format == sampleInt && bits == 16:
+		return sampleFunc{2, func(b []byte) float64 { return float64(int16(order.Uint16(b))) }}, nil
+	case format == sampleUint && bits == 32:
+		return sampleFunc{4, func(b []byte) float64 { return float64(order.Uint32(b)) }}, nil
+	case format == sampleInt && bits == 32:
+		return sampleFunc{4, func(b []byte) float64 { return float64(int32(order.Uint32(b))) }}, nil
+	case format == sampleFloat && bits == 32:
+		return sampleFunc{4, func(b []byte) float64 { return float64(math.Float32frombits(order.Uint32(b))) }}, nil
+	case format == sampleFloat && bits == 64:
+		return sampleFunc{8, func(b []byte) float64 { return math.Float64frombits(order.Uint64(b)) }}, nil
+	}
+	return sampleFunc{}, fmt.Errorf("unsupported sample format %d with %d bits", format, bits)
+}
+
+// readChunks читает тайлы или полосы растра в grid.Values.
+func readChunks(r io.ReaderAt, d *ifd, grid *Grid, sample sampleFunc, nodata float64) error {
+	compression := d.int(tagCompression, compressionNone)
+	predictor := d.int(tagPredictor, predictorNone)
+	if predictor != predictorNone && (predictor != predictorHorizontal || sample.size > 4 || d.int(tagSampleFormat, sampleUint) == sampleFloat) {
+		return fmt.Errorf("unsupported predictor %d", predictor)
+	}
+
+	chunkWidth, chunkHeight := grid.Cols, d.int(tagRowsPerStrip, grid.Rows)
+	offsets, counts := d.fields[tagStripOffsets], d.fields[tagStripByteCounts]
+	if _, tiled := d.fields[tagTileWidth]; tiled {
+		chunkWidth, chunkHeight = d.int(tagTileWidth, 0), d.int(tagTileLength, 0)
+		offsets, counts = d.fields[tagTileOffsets], d.fields[tagTileByteCounts]
+	}
+	if chunkWidth < 1 || chunkHeight < 1 {
+		return errors.New("invalid tile or strip size")
+	}
+	if chunkHeight > grid.Rows {
+		chunkHeight = grid.Rows
+	}
+	across := (grid.Cols + chunkWidth - 1) / chunkWidth
+	down := (grid.Rows + chunkHeight - 1) / chunkHeight
+	if len(offsets) < across*down || len(counts) < across*down {
+		return errors.New("missing tile or strip offsets")
+	}
+
+	for i := 0; i < across*down; i++ {
+		if counts[i] > maxTagLength {
+			return fmt.Errorf("chunk %d is too large", i)
+		}
+		raw := make([]byte, int(counts[i]))
+		if _, err := r.ReadAt(raw, int64(offsets[i])); err != nil {
+			return fmt.Errorf("read chunk %d: %w", i, err)
+		}
+		data, err := decompress(compression, raw)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+
+		top, left := i/across*chunkHeight, i%across*chunkWidth
+		rows := chunkHeight
+		if top+rows > grid.Rows {
+			rows = grid.Rows - top
+		}
+		rowLength := chunkWidth * sample.size
+		if len(data) < rows*rowLength {
+			return fmt.Errorf("chunk %d is truncated", i)
+		}
+		for row := 0; row < rows; row++ {
+			line := data[row*rowLength : (row+1)*rowLength]
+			if predictor == predictorHorizontal {
+				undoHorizontalPredictor(line, d.order, sample.size)
+			}
+			for col := 0; col < chunkWidth && left+col < grid.Cols; col++ {
+				value := sample.read(line[col*sample.size:])
+				if value == nodata || math.IsNaN(value) {
+					value = math.NaN()
+				}
+				grid.Values[(top+row)*grid.Cols+left+col] = float32(value)
+			}
+		}
+	}
+	return nil
+}
+
+func decompress(compression int, raw []byte) ([]byte, error) {
+	switch compression {
+	case compressionNone:
+		return raw, nil
+	case compressionLZW:
+		return decodeLZW(raw)
+	case compressionDeflate, compressionAdobeDeflate:
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	}
+	return nil, fmt.Errorf("unsupported compression %d", compression)
+}
+
+// undoHorizontalPredictor восстанавливает значения строки, записанные
+// разностями с предыдущим значением (предиктор 2 TIFF).
+func undoHorizontalPredictor(line []byte, order binary.ByteOrder, size int) {
+	for i := size; i+size <= len(line); i += size {
+		switch size {
+		case 1:
+			line[i] += line[i-1]
+		case 2:
+			order.PutUint16(line[i:], order.Uint16(line[i:])+order.Uint16(line[i-2:]))
+		case 4:
+			order.PutUint32(line[i:], order.Uint32(line[i:])+order.Uint32(line[i-4:]))
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package elevation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// tiffOptions — параметры тестового GeoTIFF.
+type tiffOptions struct {
+	order        binary.ByteOrder
+	format, bits int
+	compression  int
+	predictor    int
+	tileSize     int // 0 — полосы по rowsPerStrip строк
+	rowsPerStrip int
+	pixelIsPoint bool
+	projected    bool
+	nodata       string
+}
+
+type tiffEntry struct {
+	tag, typ uint16
+	values   []float64
+	text     string
+}
+
+// writeGeoTIFF пишет одноканальный растр width×height с опорной точкой
+// в левом верхнем углу (west, north) и квадратными пикселями step.
+func writeGeoTIFF(t *testing.T, path string, width, height int, values []float64, west, north, step float64, opts tiffOptions) {
+	t.Helper()
+	order := opts.order
+	size := opts.bits / 8
+	encode := func(b []byte, value float64) {
+		switch {
+		case opts.format == sampleFloat && size == 4:
+			order.PutUint32(b, math.Float32bits(float32(value)))
+		case opts.format == sampleFloat && size == 8:
+			order.PutUint64(b, math.Float64bits(value))
+		case size == 1:
+			b[0] = byte(int64(value))
+		case size == 2:
+			order.PutUint16(b, uint16(int64(value)))
+		case size == 4:
+			order.PutUint32(b, uint32(int64(value)))
+		}
+	}
+
+	chunkWidth, chunkHeight := width, opts.rowsPerStrip
+	if opts.tileSize > 0 {
+		chunkWidth, chunkHeight = opts.tileSize, opts.tileSize
+	}
+	if chunkHeight == 0 {
+		chunkHeight = height
+	}
+	across := (width + chunkWidth - 1) / chunkWidth
+	down := (height + chunkHeight - 1) / chunkHeight
+
+	file := bytes.NewBuffer(make([]byte, 8))
+	var offsets, counts []float64
+	for i := 0; i < across*down; i++ {
+		top, left := i/across*chunkHeight, i%across*chunkWidth
+		rows := chunkHeight
+		if opts.tileSize == 0 && top+rows > height {
+			rows = height - top
+		}
+		chunk := make([]byte, rows*chunkWidth*size)
+		for row := 0; row < rows; row++ {
+			for col := 0; col < chunkWidth; col++ {
+				if top+row < height && left+col < width {
+					encode(chunk[(row*chunkWidth+col)*size:], values[(top+row)*width+left+col])
+				}
+			}
+			if opts.predictor == predictorHorizontal {
+				line := chunk[row*chunkWidth*size : (row+1)*chunkWidth*size]
+				for col := chunkWidth - 1; col > 0; col-- {
+					switch size {
+					case 1:
+						line[col] -= line[col-1]
+					case 2:
+						order.PutUint16(line[col*2:], order.Uint16(line[col*2:])-order.Uint16(line[col*2-2:]))
+					case 4:
+						order.PutUint32(line[col*4:], order.Uint32(line[col*4:])-order.Uint32(line[col*4-4:]))
+					}
+				}
+			}
+		}
+		switch opts.compression {
+		case compressionLZW:
+			chunk = encodeLZW(chunk)
+		case compressionDeflate:
+			var compressed bytes.Buffer
+			zw := zlib.NewWriter(&compressed)
+			zw.Write(chunk)
+			zw.Close()
+			chunk = compressed.Bytes()
+		}
+		offsets = append(offsets, float64(file.Len()))
+		counts = append(counts, float64(len(chunk)))
+		file.Write(chunk)
+	}
+
+	modelType, rasterType := 2.0, 1.0
+	if opts.projected {
+		modelType = 1
+	}
+	if opts.pixelIsPoint {
+		rasterType = 2
+	}
+	compression := opts.compression
+	if compression == 0 {
+		compression = compressionNone
+	}
+	entries := []tiffEntry{
+		{tag: tagImageWidth, typ: 3, values: []float64{float64(width)}},
+		{tag: tagImageLength, typ: 3, values: []float64{float64(height)}},
+		{tag: tagBitsPerSample, typ: 3, values: []float64{float64(opts.bits)}},
+		{tag: tagCompression, typ: 3, values: []float64{float64(compression)}},
+		{tag: tagSamplesPerPixel, typ: 3, values: []float64{1}},
+		{tag: tagSampleFormat, typ: 3, values: []float64{float64(opts.format)}},
+		{tag: tagModelPixelScale, typ: 12, values: []float64{step, step, 0}},
+		{tag: tagModelTiepoint, typ: 12, values: []float64{0, 0, 0, west, north, 0}},
+		{tag: tagGeoKeyDirectory, typ: 3, values: []float64{1, 1, 0, 2, keyModelType, 0, 1, modelType, keyRasterType, 0, 1, rasterType}},
+	}
+	if opts.predictor != 0 {
+		entries = append(entries, tiffEntry{tag: tagPredictor, typ: 3, values: []float64{float64(opts.predictor)}})
+	}
+	if opts.nodata != "" {
+		entries = append(entries, tiffEntry{tag: tagGDALNoData, typ: typeASCII, text: opts.nodata + "\x00"})
+	}
+	if opts.tileSize > 0 {
+		entries = append(entries,
+			tiffEntry{tag: tagTileWidth, typ: 3, values: []float64{float64(chunkWidth)}},
+			tiffEntry{tag: tagTileLength, typ: 3, values: []float64{float64(chunkHeight)}},
+			tiffEntry{tag: tagTileOffsets, typ: 4, values: offsets},
+			tiffEntry{tag: tagTileByteCounts, typ: 4, values: counts})
+	} else {
+		entries = append(entries,
+			tiffEntry{tag: tagRowsPerStrip, typ: 3, values: []float64{float64(chunkHeight)}},
+			tiffEntry{tag: tagStripOffsets, typ: 4, values: offsets},
+			tiffEntry{tag: tagStripByteCounts, typ: 4, values: counts})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+	// каталог пишется после данных, длинные значения — сразу за ним
+	ifdOffset := file.Len()
+	extraOffset := ifdOffset + 2 + 12*len(entries) + 4
+	var directory, extra bytes.Buffer
+	binary.Write(&directory, order, uint16(len(entries)))
+	for _, e := range entries {
+		var raw bytes.Buffer
+		if e.typ == typeASCII {
+			raw.WriteString(e.text)
+		}
+		for _, v := range e.values {
+			switch e.typ {
+			case 3:
+				binary.Write(&raw, order, uint16(v))
+			case 4:
+				binary.Write(&raw, order, uint32(v))
+			case 12:
+				binary.Write(&raw, order, v)
+			}
+		}
+		binary.Write(&directory, order, e.tag)
+		binary.Write(&directory, order, e.typ)
+		binary.Write(&directory, order, uint32(raw.Len()/typeSizes[e.typ]))
+		if raw.Len() <= 4 {
+			value := make([]byte, 4)
+			copy(value, raw.Bytes())
+			directory.Write(value)
+		} else {
+			binary.Write(&directory, order, uint32(extraOffset+extra.Len()))
+			extra.Write(raw.Bytes())
+		}
+	}
+	binary.Write(&directory, order, uint32(0))
+	file.Write(directory.Bytes())
+	file.Write(extra.Bytes())
+
+	data := file.Bytes()
+	if order == binary.LittleEndian {
+		copy(data, "II")
+	} else {
+		copy(data, "MM")
+	}
+	order.PutUint16(data[2:], 42)
+	order.PutUint32(data[4:], uint32(ifdOffset))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// encodeLZW сжимает данные LZW в варианте TIFF, см. decodeLZW.
+func encodeLZW(data []byte) []byte {
+	var (
+		out   []byte
+		acc   uint32
+		count int
+		width int
+		next  int
+		dict  map[string]int
+	)
+	write := func(code int) {
+		acc = acc<<width | uint32(code)
+		count += width
+		for count >= 8 {
+			out = append(out, byte(acc>>(count-8)))
+			count -= 8
+		}
+	}
+	// grow повторяет рост ширины кода у декодера
+	grow := func() {
+		next++
+		if next == 1<<width && width < lzwMaxWidth {
+			width++
+		}
+	}
+	reset := func() {
+		dict = map[string]int{}
+		for i := 0; i < 256; i++ {
+			dict[string([]byte{byte(i)})] = i
+		}
+		width, next = 9, lzwFirst
+	}
+
+	reset()
+	write(lzwClear)
+	word := ""
+	for _, c := range data {
+		candidate := word + string([]byte{c})
+		if _, ok := dict[candidate]; ok {
+			word = candidate
+			continue
+		}
+		write(dict[word])
+		dict[candidate] = next
+		grow()
+		if next == 1<<lzwMaxWidth-2 {
+			write(lzwClear)
+			reset()
+		}
+		word = string([]byte{c})
+	}
+	if word != "" {
+		write(dict[word])
+		grow()
+	}
+	write(lzwEnd)
+	if count > 0 {
+		out = append(out, byte(acc<<(8-count)))
+	}
+	return out
+}
+
+func TestDecodeLZW(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	data := make([]byte, 50000)
+	for i := range data {
+		// ограниченный алфавит дает и длинные совпадения, и переполнение таблицы
+		data[i] = byte(random.Intn(6))
+	}
+	decoded, err := decodeLZW(encodeLZW(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("round trip mismatch: %d bytes decoded from %d", len(decoded), len(data))
+	}
+
+	// код очистки, затем код 300 при следующей свободной записи 258
+	if _, err := decodeLZW([]byte{0x80, 0x4B, 0x00}); err == nil {
+		t.Error("expected error for invalid code")
+	}
+}
+
+func TestReadGeoTIFF(t *testing.T) {
+	const width, height = 23, 17
+	values := make([]float64, width*height)
+	for i := range values {
+		values[i] = float64(100 + 3*(i/width) + i%width)
+	}
+	values[5*width+7] = -9999
+
+	testCases := map[string]tiffOptions{
+		"strips int16":               {order: binary.LittleEndian, format: sampleInt, bits: 16, rowsPerStrip: 4, pixelIsPoint: true, nodata: "-9999"},
+		"tiles float32 lzw":          {order: binary.BigEndian, format: sampleFloat, bits: 32, compression: compressionLZW, tileSize: 16, pixelIsPoint: true, nodata: "-9999"},
+		"tiles int32 deflate":        {order: binary.LittleEndian, format: sampleInt, bits: 32, compression: compressionDeflate, predictor: predictorHorizontal, tileSize: 8, pixelIsPoint: true, nodata: "-9999"},
+		"strips int16 lzw predictor": {order: binary.BigEndian, format: sampleInt, bits: 16, compression: compressionLZW, predictor: predictorHorizontal, rowsPerStrip: 5, pixelIsPoint: true, nodata: "-9999"},
+		"strips float64":             {order: binary.LittleEndian, format: sampleFloat, bits: 64, pixelIsPoint: true, nodata: "-9999"},
+	}
+	for name, opts := range testCases {
+		path := filepath.Join(t.TempDir(), "dem.tif")
+		writeGeoTIFF(t, path, width, height, values, 37, 56, 0.01, opts)
+		grid, err := ReadGeoTIFF(path)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		for i, expected := range values {
+			got := float64(grid.Values[i])
+			if expected == -9999 {
+				if !math.IsNaN(got) {
+					t.Errorf("%s: expected NoData at %d, got %g", name, i, got)
+				}
+			} else if got != expected {
+				t.Errorf("%s: pixel %d: expected %g, got %g", name, i, expected, got)
+				break
+			}
+		}
+		// центр пикселя (2, 3) и середина между пикселями (2, 3) и (2, 4)
+		if value, ok := grid.Elevation(55.98, 37.03); !ok || math.Abs(value-109) > 1e-6 {
+			t.Errorf("%s: expected 109, got %g (%v)", name, value, ok)
+		}
+		if value, ok := grid.Elevation(55.98, 37.035); !ok || math.Abs(value-109.5) > 1e-6 {
+			t.Errorf("%s: expected 109.5, got %g (%v)", name, value, ok)
+		}
+	}
+}
+
+func TestReadGeoTIFF_PixelIsArea(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dem.tif")
+	writeGeoTIFF(t, path, 2, 2, []float64{10, 20, 30, 40}, 37, 56, 1, tiffOptions{order: binary.LittleEndian, format: sampleUint, bits: 8})
+	grid, err := ReadGeoTIFF(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Grid{North: 55.5, West: 37.5, LatStep: 1, LonStep: 1, Rows: 2, Cols: 2}
+	if grid.North != expected.North || grid.West != expected.West || grid.Bounds.MinX != 37 || grid.Bounds.MaxX != 39 || grid.Bounds.MinY != 54 || grid.Bounds.MaxY != 56 {
+		t.Errorf("unexpected georeferencing: %+v", grid)
+	}
+	// между центрами всех четырех пикселей и у края растра
+	if value, ok := grid.Elevation(55, 38); !ok || value != 25 {
+		t.Errorf("expected 25, got %g (%v)", value, ok)
+	}
+	if value, ok := grid.Elevation(55.9, 37.1); !ok || value != 10 {
+		t.Errorf("expected 10 at the edge, got %g (%v)", value, ok)
+	}
+}
+
+func TestReadGeoTIFF_Unsupported(t *testing.T) {
+	dir := t.TempDir()
+	projected := filepath.Join(dir, "projected.tif")
+	writeGeoTIFF(t, projected, 2, 2, []float64{1, 2, 3, 4}, 400000, 6200000, 30,
+		tiffOptions{order: binary.LittleEndian, format: sampleInt, bits: 16, projected: true})
+	if _, err := ReadGeoTIFF(projected); err == nil {
+		t.Error("expected error for projected raster")
+	}
+
+	notTIFF := filepath.Join(dir, "dem.tif")
+	if err := os.WriteFile(notTIFF, []byte("GIF89a..."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadGeoTIFF(notTIFF); err == nil {
+		t.Error("expected error for non-TIFF file")
+	}
+}
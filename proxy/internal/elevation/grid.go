@@ -0,0 +1,71 @@
+package elevation
+
+import (
+	"geo-controller/proxy/internal/spatial"
+	"math"
+)
+
+// Grid — растр высот в долготе и широте. Значения хранятся по строкам
+// с севера на юг, NaN означает отсутствие данных (пустоты SRTM, NoData GeoTIFF).
+type Grid struct {
+	// North и West — координаты центра пикселя (0, 0).
+	North, West float64
+	// LatStep и LonStep — размер пикселя в градусах.
+	LatStep, LonStep float64
+	Rows, Cols       int
+	Values           []float32
+	// Bounds — покрытие растра: область центров пикселей, для растров
+	// PixelIsArea расширенная на половину пикселя.
+	Bounds spatial.Rect
+}
+
+// resolution — размер пикселя, по которому выбирается более подробный растр.
+func (g *Grid) resolution() float64 {
+	return math.Max(g.LatStep, g.LonStep)
+}
+
+// Elevation возвращает высоту в точке билинейной интерполяцией по четырем
+// соседним пикселям. Пиксели без данных исключаются, а веса остальных
+// нормируются; если данных нет ни в одном, ok равно false.
+func (g *Grid) Elevation(lat, lon float64) (float64, bool) {
+	if !g.Bounds.Intersects(spatial.PointRect(lon, lat)) {
+		return 0, false
+	}
+	y := clamp((g.North-lat)/g.LatStep, 0, float64(g.Rows-1))
+	x := clamp((lon-g.West)/g.LonStep, 0, float64(g.Cols-1))
+	row, col := int(y), int(x)
+	fy, fx := y-float64(row), x-float64(col)
+	nextRow, nextCol := row+1, col+1
+	if nextRow == g.Rows {
+		nextRow = row
+	}
+	if nextCol == g.Cols {
+		nextCol = col
+	}
+
+	var sum, weight float64
+	for _, corner := range [4]struct {
+		row, col int
+		weight   float64
+	}{
+		{row, col, (1 - fy) * (1 - fx)},
+		{row, nextCol, (1 - fy) * fx},
+		{nextRow, col, fy * (1 - fx)},
+		{nextRow, nextCol, fy * fx},
+	} {
+		value := float64(g.Values[corner.row*g.Cols+corner.col])
+		if corner.weight == 0 || math.IsNaN(value) {
+			continue
+		}
+		sum += corner.weight * value
+		weight += corner.weight
+	}
+	if weight == 0 {
+		return 0, false
+	}
+	return sum / weight, true
+}
+
+func clamp(value, low, high float64) float64 {
+	return math.Max(low, math.Min(high, value))
+}
@@ -0,0 +1,81 @@
+package elevation
+
+import (
+	"encoding/binary"
+	"fmt"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hgtVoid — значение пустоты в тайлах SRTM.
+const hgtVoid = -32768
+
+// hgtName — имя тайла SRTM по юго-западному углу, например N55E037.hgt.
+var hgtName = regexp.MustCompile(`^([NS])(\d{2})([EW])(\d{3})$`)
+
+// hgtCorner возвращает юго-западный угол тайла по имени файла.
+func hgtCorner(path string) (lat, lon int, err error) {
+	name := strings.ToUpper(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	match := hgtName.FindStringSubmatch(name)
+	if match == nil {
+		return 0, 0, fmt.Errorf("invalid SRTM tile name %q", filepath.Base(path))
+	}
+	lat, _ = strconv.Atoi(match[2])
+	lon, _ = strconv.Atoi(match[4])
+	if match[1] == "S" {
+		lat = -lat
+	}
+	if match[3] == "W" {
+		lon = -lon
+	}
+	return lat, lon, nil
+}
+
+// hgtBounds — покрытие тайла: градус по широте и долготе от угла.
+func hgtBounds(lat, lon int) spatial.Rect {
+	return spatial.Rect{MinX: float64(lon), MinY: float64(lat), MaxX: float64(lon + 1), MaxY: float64(lat + 1)}
+}
+
+// ReadHGT читает тайл SRTM: квадратную сетку 16-битных высот в метрах
+// (big-endian) размером 1201 (SRTM3, 3″) или 3601 (SRTM1, 1″) пикселей.
+// Крайние строки и столбцы лежат на границах градуса и повторяют соседние тайлы.
+func ReadHGT(path string) (*Grid, error) {
+	lat, lon, err := hgtCorner(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	size := int(math.Sqrt(float64(len(data) / 2)))
+	if size < 2 || size*size*2 != len(data) {
+		return nil, fmt.Errorf("%s: unexpected SRTM tile size %d bytes", filepath.Base(path), len(data))
+	}
+
+	values := make([]float32, size*size)
+	for i := range values {
+		value := int16(binary.BigEndian.Uint16(data[2*i:]))
+		if value == hgtVoid {
+			values[i] = float32(math.NaN())
+		} else {
+			values[i] = float32(value)
+		}
+	}
+	step := 1 / float64(size-1)
+	return &Grid{
+		North:   float64(lat + 1),
+		West:    float64(lon),
+		LatStep: step,
+		LonStep: step,
+		Rows:    size,
+		Cols:    size,
+		Values:  values,
+		Bounds:  hgtBounds(lat, lon),
+	}, nil
+}
@@ -0,0 +1,82 @@
+package elevation
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeHGT пишет квадратный тайл SRTM; значения — по строкам с севера.
+func writeHGT(t *testing.T, dir, name string, values []int16) string {
+	t.Helper()
+	data := make([]byte, 2*len(values))
+	for i, value := range values {
+		binary.BigEndian.PutUint16(data[2*i:], uint16(value))
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadHGT(t *testing.T) {
+	// сетка 3×3 с шагом полградуса, в центре пустота
+	path := writeHGT(t, t.TempDir(), "N55E037.hgt", []int16{
+		100, 110, 120,
+		130, hgtVoid, 150,
+		160, 170, 180,
+	})
+	grid, err := ReadHGT(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		lat, lon float64
+		expected float64
+		ok       bool
+	}{
+		{56, 37, 100, true},
+		{55, 38, 180, true},
+		{56, 37.25, 105, true},
+		// соседняя с пустотой ячейка: вес пустоты распределяется между остальными
+		{55.75, 37.25, 340.0 / 3, true},
+		{55.25, 37.75, 500.0 / 3, true},
+		{55.5, 37.5, 0, false},
+		{54.9, 37.5, 0, false},
+		{55.5, 38.1, 0, false},
+	}
+	for _, tc := range testCases {
+		value, ok := grid.Elevation(tc.lat, tc.lon)
+		if ok != tc.ok || math.Abs(value-tc.expected) > 1e-6 {
+			t.Errorf("%g, %g: expected %g (%v), got %g (%v)", tc.lat, tc.lon, tc.expected, tc.ok, value, ok)
+		}
+	}
+}
+
+func TestReadHGT_InvalidTile(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ReadHGT(writeHGT(t, dir, "N55E037.hgt", []int16{1, 2, 3})); err == nil {
+		t.Error("expected error for non-square tile")
+	}
+	if _, err := ReadHGT(writeHGT(t, dir, "moscow.hgt", []int16{1, 2, 3, 4})); err == nil {
+		t.Error("expected error for invalid tile name")
+	}
+}
+
+func TestHGTCorner(t *testing.T) {
+	testCases := map[string][2]int{
+		"N55E037.hgt":       {55, 37},
+		"s12w077.HGT":       {-12, -77},
+		"/srtm/N00W180.hgt": {0, -180},
+	}
+	for path, expected := range testCases {
+		lat, lon, err := hgtCorner(path)
+		if err != nil || lat != expected[0] || lon != expected[1] {
+			t.Errorf("%s: expected %v, got %d, %d (%v)", path, expected, lat, lon, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package elevation
+
+import "fmt"
+
+// Коды LZW в TIFF.
+const (
+	lzwClear    = 256
+	lzwEnd      = 257
+	lzwFirst    = 258
+	lzwMaxWidth = 12
+)
+
+// decodeLZW распаковывает LZW в варианте TIFF. От GIF и compress/lzw он
+// отличается порядком бит (старшим вперед) и тем, что ширина кода растет
+// на одну запись раньше ("early change").
+func decodeLZW(data []byte) ([]byte, error) {
+	table := make([][]byte, 1<<lzwMaxWidth)
+	for i := 0; i < 256; i++ {
+		table[i] = []byte{byte(i)}
+	}
+	var (
+		out      []byte
+		prev     []byte
+		next     = lzwFirst
+		width    = 9
+		bits     uint32
+		bitCount int
+	)
+	for pos := 0; ; {
+		for bitCount < width {
+			if pos == len(data) {
+				// часть кодировщиков не пишет код конца данных
+				return out, nil
+			}
+			bits = bits<<8 | uint32(data[pos])
+			pos++
+			bitCount += 8
+		}
+		bitCount -= width
+		code := int(bits>>bitCount) & (1<<width - 1)
+
+		switch code {
+		case lzwClear:
+			next, width, prev = lzwFirst, 9, nil
+			continue
+		case lzwEnd:
+			return out, nil
+		}
+
+		var entry []byte
+		switch {
+		case code < next && (code < lzwClear || code >= lzwFirst):
+			entry = table[code]
+		case code == next && prev != nil:
+			entry = append(append(make([]byte, 0, len(prev)+1), prev...), prev[0])
+		default:
+			return nil, fmt.Errorf("invalid LZW code %d", code)
+		}
+		out = append(out, entry...)
+
+		if prev != nil && next < len(table) {
+			table[next] = append(append(make([]byte, 0, len(prev)+1), prev...), entry[0])
+			next++
+			if next == 1<<width-1 && width < lzwMaxWidth {
+				width++
+			}
+		}
+		prev = entry
+	}
+}
@@ -0,0 +1,136 @@
+package elevation
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/cache"
+	"geo-controller/proxy/internal/spatial"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNoCoverage возвращается для точки вне всех тайлов или в пустоте данных.
+var ErrNoCoverage = errors.New("no elevation coverage")
+
+// tile — файл растра в каталоге: покрытие известно заранее, значения
+// читаются при первом обращении.
+type tile struct {
+	path       string
+	bounds     spatial.Rect
+	resolution float64
+	read       func(path string) (*Grid, error)
+}
+
+// Store находит высоты по тайлам SRTM (.hgt) и GeoTIFF (.tif, .tiff)
+// в каталоге. Покрытие тайлов индексируется при открытии, сами растры
+// загружаются по требованию и держатся в памяти, пока не будут вытеснены
+// давно не использованными. Безопасен для параллельного использования.
+type Store struct {
+	tree  *spatial.RTree[*tile]
+	size  int
+	grids *cache.Cache[*Grid]
+	// loading не дает параллельным запросам читать один тайл дважды.
+	loading sync.Mutex
+}
+
+// Open индексирует тайлы в каталоге dir и его подкаталогах. maxTiles —
+// наибольшее число растров в памяти, 0 — без ограничения. Тайл SRTM1
+// занимает около 50 МБ.
+func Open(dir string, maxTiles int) (*Store, error) {
+	s := &Store{tree: spatial.NewRTree[*tile](), grids: cache.New[*Grid](0, maxTiles)}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		var t *tile
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".hgt":
+			lat, lon, err := hgtCorner(path)
+			if err != nil {
+				return err
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			// разрешение (SRTM1 или SRTM3) определяется по размеру файла
+			size := math.Sqrt(float64(info.Size() / 2))
+			if size < 2 {
+				return fmt.Errorf("%s: unexpected SRTM tile size %d bytes", path, info.Size())
+			}
+			t = &tile{path: path, bounds: hgtBounds(lat, lon), resolution: 1 / (size - 1), read: ReadHGT}
+		case ".tif", ".tiff":
+			grid, err := readGeoTIFF(path, false)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			t = &tile{path: path, bounds: grid.Bounds, resolution: grid.resolution(), read: ReadGeoTIFF}
+		default:
+			return nil
+		}
+		s.tree.Insert(t.bounds, t)
+		s.size++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.size == 0 {
+		return nil, fmt.Errorf("no elevation tiles in %s", dir)
+	}
+	return s, nil
+}
+
+// Len возвращает число тайлов в каталоге.
+func (s *Store) Len() int {
+	return s.size
+}
+
+// Elevation возвращает высоту точки в метрах. Если точку покрывают
+// несколько тайлов, берется самый подробный, в котором есть данные.
+func (s *Store) Elevation(lat, lon float64) (float64, error) {
+	var candidates []*tile
+	s.tree.Search(spatial.PointRect(lon, lat), func(t *tile) bool {
+		candidates = append(candidates, t)
+		return true
+	})
+	if len(candidates) == 0 {
+		return 0, fmt.Errorf("%w at %g, %g", ErrNoCoverage, lat, lon)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].resolution < candidates[j].resolution
+	})
+
+	for _, t := range candidates {
+		grid, err := s.grid(t)
+		if err != nil {
+			return 0, err
+		}
+		if value, ok := grid.Elevation(lat, lon); ok {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("%w at %g, %g: no data in tiles", ErrNoCoverage, lat, lon)
+}
+
+// grid возвращает растр тайла из кэша, при необходимости читая файл.
+func (s *Store) grid(t *tile) (*Grid, error) {
+	if grid, ok := s.grids.Get(t.path); ok {
+		return grid, nil
+	}
+	s.loading.Lock()
+	defer s.loading.Unlock()
+	if grid, ok := s.grids.Get(t.path); ok {
+		return grid, nil
+	}
+	grid, err := t.read(t.path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.path, err)
+	}
+	s.grids.Set(t.path, grid)
+	return grid, nil
+}
@@ -0,0 +1,76 @@
+package elevation
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_Elevation(t *testing.T) {
+	dir := t.TempDir()
+	writeHGT(t, dir, "N55E037.hgt", []int16{
+		100, 110, 120,
+		130, 140, 150,
+		160, 170, 180,
+	})
+	writeHGT(t, dir, "N55E038.hgt", []int16{
+		120, hgtVoid, hgtVoid,
+		150, hgtVoid, hgtVoid,
+		180, hgtVoid, hgtVoid,
+	})
+	// подробный растр на северо-западную четверть первого тайла,
+	// с NoData в углу
+	if err := os.Mkdir(filepath.Join(dir, "lidar"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeGeoTIFF(t, filepath.Join(dir, "lidar", "moscow.tif"), 2, 2, []float64{500, 501, 502, -1}, 37, 56, 0.25,
+		tiffOptions{order: binary.LittleEndian, format: sampleInt, bits: 16, nodata: "-1"})
+	if err := os.WriteFile(filepath.Join(dir, "README.txt"), []byte("SRTM"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Open(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Len() != 3 {
+		t.Errorf("expected 3 tiles, got %d", store.Len())
+	}
+
+	testCases := []struct {
+		lat, lon float64
+		expected float64
+	}{
+		{55.875, 37.125, 500},
+		// NoData подробного растра: значение из тайла SRTM
+		{55.625, 37.375, 130},
+		{55, 38, 180},
+		{55.25, 37.5, 155},
+		// повторное чтение вытесненного тайла
+		{55.875, 37.125, 500},
+	}
+	for _, tc := range testCases {
+		value, err := store.Elevation(tc.lat, tc.lon)
+		if err != nil || math.Abs(value-tc.expected) > 1e-6 {
+			t.Errorf("%g, %g: expected %g, got %g (%v)", tc.lat, tc.lon, tc.expected, value, err)
+		}
+	}
+
+	for _, point := range [][2]float64{{54.5, 37.5}, {55.5, 38.75}} {
+		if _, err := store.Elevation(point[0], point[1]); !errors.Is(err, ErrNoCoverage) {
+			t.Errorf("%v: expected ErrNoCoverage, got %v", point, err)
+		}
+	}
+}
+
+func TestOpen_Empty(t *testing.T) {
+	if _, err := Open(t.TempDir(), 0); err == nil {
+		t.Error("expected error for directory without tiles")
+	}
+	if _, err := Open(filepath.Join(t.TempDir(), "missing"), 0); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
@@ -0,0 +1,314 @@
+package formatter
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/normalize"
+	"geo-controller/proxy/internal/parser"
+	"geo-controller/proxy/internal/translit"
+	"strings"
+)
+
+var (
+	// ErrUnknownStyle возвращается для неподдерживаемого стиля форматирования.
+	ErrUnknownStyle = errors.New("unknown format style")
+	// ErrEmptyAddress возвращается, если в адресе нечего форматировать.
+	ErrEmptyAddress = errors.New("address is empty")
+)
+
+// internationalCountry — название страны на международных отправлениях в Россию.
+const internationalCountry = "RUSSIAN FEDERATION"
+
+// shortTypes сопоставляет полную форму типа (см. normalize.Word) с сокращением,
+// принятым в DaData и на почтовых отправлениях.
+var shortTypes = map[string]string{
+	"область":          "обл",
+	"край":             "край",
+	"республика":       "Респ",
+	"автономный округ": "АО",
+	"район":            "р-н",
+	"городской округ":  "г.о.",
+	"город":            "г",
+	"поселок":          "п",
+	"пгт":              "пгт",
+	"село":             "с",
+	"деревня":          "д",
+	"станица":          "ст-ца",
+	"хутор":            "х",
+	"улица":            "ул",
+	"проспект":         "пр-кт",
+	"переулок":         "пер",
+	"бульвар":          "б-р",
+	"шоссе":            "ш",
+	"набережная":       "наб",
+	"площадь":          "пл",
+	"тупик":            "туп",
+	"проезд":           "проезд",
+	"микрорайон":       "мкр",
+	"аллея":            "аллея",
+	"линия":            "линия",
+	"тракт":            "тракт",
+	"дом":              "д",
+	"владение":         "влд",
+	"корпус":           "к",
+	"строение":         "стр",
+	"квартира":         "кв",
+	"офис":             "офис",
+	"помещение":        "помещ",
+}
+
+// postfixTypes всегда пишутся после названия: "Московская обл".
+var postfixTypes = map[string]bool{
+	"область":          true,
+	"край":             true,
+	"автономный округ": true,
+	"район":            true,
+}
+
+// prefixTypes всегда пишутся перед названием: "г Москва", "ул Тверская".
+// Остальные типы ставятся после прилагательных: "Невский пр-кт", "Удмуртская Респ".
+var prefixTypes = map[string]bool{
+	"город": true, "поселок": true, "пгт": true, "село": true, "деревня": true,
+	"станица": true, "хутор": true, "улица": true, "микрорайон": true,
+}
+
+// adjectiveEndings — окончания прилагательных в названиях.
+var adjectiveEndings = []string{"ий", "ый", "ой", "ая", "яя", "ое", "ее"}
+
+// element — название с типом.
+type element struct {
+	name string
+	typ  string // полная форма типа
+}
+
+// components — компоненты адреса, общие для всех стилей.
+type components struct {
+	postalCode string
+	country    string
+	region     element
+	area       element
+	city       element
+	settlement element
+	street     element
+	house      element
+	block      element
+	flat       element
+}
+
+// Format возвращает строки адреса в стиле style (см. models.Format*).
+// Пустой стиль означает models.FormatCompact.
+func Format(addr *models.Address, style string) ([]string, error) {
+	c := newComponents(addr)
+
+	var lines []string
+	switch style {
+	case models.FormatCompact, "":
+		lines = []string{join(", ", c.domestic(false)...)}
+	case models.FormatPost:
+		lines = c.post()
+	case models.FormatLabel:
+		lines = c.label()
+	case models.FormatInternational:
+		lines = c.international()
+	default:
+		return nil, ErrUnknownStyle
+	}
+
+	lines = nonEmpty(lines)
+	if len(lines) == 0 {
+		return nil, ErrEmptyAddress
+	}
+	return lines, nil
+}
+
+// newComponents собирает компоненты из структурных полей адреса. Если их нет,
+// компоненты выделяются из Result локальным разборщиком.
+func newComponents(addr *models.Address) components {
+	c := components{
+		postalCode: addr.PostalCode,
+		country:    addr.Country,
+		region:     newElement(addr.Region, addr.RegionType),
+		area:       newElement(addr.Area, addr.AreaType),
+		city:       newElement(addr.City, addr.CityType),
+		settlement: newElement(addr.Settlement, addr.SettlementType),
+		street:     newElement(addr.Street, addr.StreetType),
+		house:      newElement(addr.House, defaultType(addr.HouseType, "дом")),
+		block:      newElement(addr.Block, defaultType(addr.BlockType, "корпус")),
+		flat:       newElement(addr.Flat, defaultType(addr.FlatType, "квартира")),
+	}
+	if addr.City != "" || addr.Settlement != "" || addr.House != "" || addr.Result == "" {
+		return c
+	}
+
+	parsed := parser.Parse(addr.Result)
+	c.postalCode = firstNonEmpty(c.postalCode, parsed.PostalCode)
+	c.country = firstNonEmpty(c.country, parsed.Country)
+	if c.region.name == "" {
+		c.region = newElement(parsed.Region, parsed.RegionType)
+	}
+	c.area = newElement(parsed.Area, parsed.AreaType)
+	c.city = newElement(parsed.City, parsed.CityType)
+	c.settlement = newElement(parsed.Settlement, parsed.SettlementType)
+	if parsed.Street != "" {
+		c.street = newElement(parsed.Street, parsed.StreetType)
+	}
+	c.house = newElement(parsed.House, "дом")
+	c.block = newElement(parsed.Building, defaultType(parsed.BuildingType, "корпус"))
+	c.flat = newElement(parsed.Flat, defaultType(parsed.FlatType, "квартира"))
+	return c
+}
+
+func newElement(name, typ string) element {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return element{}
+	}
+	if typ != "" {
+		typ = normalize.Word(typ)
+	}
+	return element{name: name, typ: typ}
+}
+
+// domestic возвращает части адреса от региона до квартиры, как их пишет DaData:
+// "г Москва, ул Тверская, д 7 к 2, кв 12". С dots сокращения пишутся с точками.
+func (c components) domestic(dots bool) []string {
+	parts := []string{c.regionPart(dots), c.area.format(dots)}
+	parts = append(parts, c.locality(dots)...)
+	return append(parts, c.streetLine(dots)...)
+}
+
+// post возвращает адрес по правилам Почты России: от улицы к индексу.
+// Страна указывается только для зарубежных адресов.
+func (c components) post() []string {
+	lines := c.postBody()
+	if c.country != "" && !isRussia(c.country) {
+		lines = append(lines, c.country)
+	}
+	return append(lines, c.postalCode)
+}
+
+// label возвращает адрес для наклейки: от индекса к квартире.
+func (c components) label() []string {
+	lines := []string{c.postalCode, c.regionPart(false), c.area.format(false)}
+	lines = append(lines, c.locality(false)...)
+	return append(lines, join(", ", c.streetLine(false)...))
+}
+
+// international возвращает адрес латиницей в порядке, рекомендованном
+// Всемирным почтовым союзом: страна заглавными буквами в последней строке.
+func (c components) international() []string {
+	country := internationalCountry
+	if c.country != "" && !isRussia(c.country) {
+		country = strings.ToUpper(c.country)
+	}
+	lines := append(c.postBody(), c.postalCode, country)
+	for i, line := range lines {
+		lines[i] = translit.ToLatin(line)
+	}
+	return lines
+}
+
+// postBody возвращает строки почтового адреса от улицы до региона.
+func (c components) postBody() []string {
+	lines := []string{join(", ", c.streetLine(true)...)}
+	lines = append(lines, c.locality(true)...)
+	return append(lines, c.area.format(true), c.regionPart(true))
+}
+
+// regionPart возвращает регион, если он не совпадает с городом федерального значения.
+func (c components) regionPart(dots bool) string {
+	if c.region.name == c.city.name {
+		return ""
+	}
+	return c.region.format(dots)
+}
+
+func (c components) locality(dots bool) []string {
+	return []string{c.city.format(dots), c.settlement.format(dots)}
+}
+
+// streetLine возвращает улицу, дом с корпусом и квартиру.
+func (c components) streetLine(dots bool) []string {
+	house := join(" ", c.house.format(dots), c.block.format(dots))
+	return []string{c.street.format(dots), house, c.flat.format(dots)}
+}
+
+// format возвращает название с сокращенным типом до или после него.
+func (e element) format(dots bool) string {
+	if e.name == "" {
+		return ""
+	}
+	if e.typ == "" {
+		return e.name
+	}
+
+	short := abbreviate(e.typ, dots)
+	if postfixTypes[e.typ] || (!prefixTypes[e.typ] && isAdjective(e.name)) {
+		return e.name + " " + short
+	}
+	return short + " " + e.name
+}
+
+// abbreviate сокращает тип. С dots после сокращения ставится точка,
+// кроме сокращений через дефис и аббревиатур: "ул.", "пр-кт", "АО".
+func abbreviate(typ string, dots bool) string {
+	short, ok := shortTypes[typ]
+	if !ok {
+		return typ
+	}
+	if dots && short != typ && !strings.ContainsAny(short, "-.") && strings.ToUpper(short) != short {
+		return short + "."
+	}
+	return short
+}
+
+// isAdjective сообщает, оканчивается ли последнее слово названия как прилагательное.
+func isAdjective(name string) bool {
+	words := strings.Fields(strings.ToLower(name))
+	last := words[len(words)-1]
+	for _, ending := range adjectiveEndings {
+		if strings.HasSuffix(last, ending) && len([]rune(last)) > 3 {
+			return true
+		}
+	}
+	return false
+}
+
+func isRussia(country string) bool {
+	switch strings.ToLower(country) {
+	case "россия", "рф", "российская федерация", "russia", "russian federation":
+		return true
+	}
+	return false
+}
+
+func defaultType(typ, fallback string) string {
+	if typ == "" {
+		return fallback
+	}
+	return typ
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// join соединяет непустые части.
+func join(sep string, parts ...string) string {
+	return strings.Join(nonEmpty(parts), sep)
+}
+
+func nonEmpty(parts []string) []string {
+	var result []string
+	for _, p := range parts {
+		if p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package formatter
+
+import (
+	"geo-controller/proxy/internal/models"
+	"reflect"
+	"testing"
+)
+
+// moscow — адрес в том виде, в каком его возвращает DaData.
+var moscow = models.Address{
+	Result:     "г Москва, ул Тверская, д 7 к 2, кв 12",
+	PostalCode: "125009",
+	Country:    "Россия",
+	Region:     "Москва",
+	RegionType: "г",
+	City:       "Москва",
+	CityType:   "г",
+	Street:     "Тверская",
+	StreetType: "ул",
+	House:      "7",
+	HouseType:  "д",
+	Block:      "2",
+	BlockType:  "к",
+	Flat:       "12",
+	FlatType:   "кв",
+}
+
+var khimki = models.Address{
+	PostalCode: "141400",
+	Country:    "Россия",
+	Region:     "Московская",
+	RegionType: "обл",
+	City:       "Химки",
+	CityType:   "г",
+	Street:     "Ленинградское",
+	StreetType: "ш",
+	House:      "1",
+	HouseType:  "д",
+}
+
+var village = models.Address{
+	Region:         "Московская",
+	RegionType:     "обл",
+	Area:           "Одинцовский",
+	AreaType:       "р-н",
+	Settlement:     "Бор",
+	SettlementType: "д",
+	House:          "5",
+}
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		address  models.Address
+		style    string
+		expected []string
+	}{
+		{"compact", moscow, models.FormatCompact, []string{
+			"г Москва, ул Тверская, д 7 к 2, кв 12",
+		}},
+		{"compact by default", khimki, "", []string{
+			"Московская обл, г Химки, Ленинградское ш, д 1",
+		}},
+		{"post", moscow, models.FormatPost, []string{
+			"ул. Тверская, д. 7 к. 2, кв. 12",
+			"г. Москва",
+			"125009",
+		}},
+		{"post with region", khimki, models.FormatPost, []string{
+			"Ленинградское ш., д. 1",
+			"г. Химки",
+			"Московская обл.",
+			"141400",
+		}},
+		{"post with area", village, models.FormatPost, []string{
+			"д. 5",
+			"д. Бор",
+			"Одинцовский р-н",
+			"Московская обл.",
+		}},
+		{"label", khimki, models.FormatLabel, []string{
+			"141400",
+			"Московская обл",
+			"г Химки",
+			"Ленинградское ш, д 1",
+		}},
+		{"international", moscow, models.FormatInternational, []string{
+			"ul. Tverskaia, d. 7 k. 2, kv. 12",
+			"g. Moskva",
+			"125009",
+			"RUSSIAN FEDERATION",
+		}},
+		{"international with region", khimki, models.FormatInternational, []string{
+			"Leningradskoe sh., d. 1",
+			"g. Khimki",
+			"Moskovskaia obl.",
+			"141400",
+			"RUSSIAN FEDERATION",
+		}},
+		{"foreign country", models.Address{Country: "Беларусь", City: "Минск", CityType: "г", Street: "Ленина", StreetType: "ул", House: "3"},
+			models.FormatPost, []string{
+				"ул. Ленина, д. 3",
+				"г. Минск",
+				"Беларусь",
+			}},
+		{"full types", models.Address{Region: "Удмуртская", RegionType: "республика", City: "Ижевск", CityType: "город",
+			Street: "Мира", StreetType: "проспект", House: "1"},
+			models.FormatCompact, []string{
+				"Удмуртская Респ, г Ижевск, пр-кт Мира, д 1",
+			}},
+		{"prefix republic", models.Address{Region: "Татарстан", RegionType: "Респ", City: "Казань", CityType: "г"},
+			models.FormatCompact, []string{
+				"Респ Татарстан, г Казань",
+			}},
+		{"result only", models.Address{Result: "г Санкт-Петербург, Невский пр-кт, д 28"},
+			models.FormatPost, []string{
+				"Невский пр-кт, д. 28",
+				"г. Санкт-Петербург",
+			}},
+		{"result only international", models.Address{Result: "Московская обл, г Химки, ул Ленина, д 5 кв 1"},
+			models.FormatInternational, []string{
+				"ul. Lenina, d. 5, kv. 1",
+				"g. Khimki",
+				"Moskovskaia obl.",
+				"RUSSIAN FEDERATION",
+			}},
+		{"result only with microdistrict", models.Address{Result: "Московская обл, г Химки, мкр Сходня, ул Горная, д 1"},
+			models.FormatCompact, []string{
+				"Московская обл, г Химки, мкр Сходня, ул Горная, д 1",
+			}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := tc.address
+			got, err := Format(&addr, tc.style)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("\n got: %q\nwant: %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFormat_Errors(t *testing.T) {
+	if _, err := Format(&moscow, "envelope"); err != ErrUnknownStyle {
+		t.Errorf("expected ErrUnknownStyle, got %v", err)
+	}
+	if _, err := Format(&models.Address{}, models.FormatPost); err != ErrEmptyAddress {
+		t.Errorf("expected ErrEmptyAddress, got %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package fuzzy
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/normalize"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Document — индексируемая запись: текст и, при наличии, координаты.
+type Document struct {
+	ID     int
+	Text   string
+	Lat    float64
+	Lon    float64
+	HasGeo bool
+}
+
+// Span — совпавший фрагмент текста документа, смещение и длина в символах.
+type Span struct {
+	Offset int
+	Length int
+}
+
+// Result — найденный документ с итоговой оценкой и подсветкой совпадений.
+type Result struct {
+	Document   Document
+	Score      float64
+	TextScore  float64
+	Distance   float64
+	Highlights []Span
+}
+
+// SearchOptions задает лимит выдачи и точку, близость к которой повышает оценку.
+type SearchOptions struct {
+	Limit     int
+	HasBias   bool
+	BiasLat   float64
+	BiasLon   float64
+	BiasScale float64
+}
+
+// textWeight — доля текстовой оценки в итоговой, остальное — близость к точке.
+const textWeight = 0.7
+
+type docToken struct {
+	term int
+	span Span
+}
+
+type indexedDocument struct {
+	doc    Document
+	tokens []docToken
+}
+
+// Index — нечеткий полнотекстовый индекс: словарь нормализованных слов,
+// триграммный индекс по словарю для поиска слов с опечатками
+// и обратный индекс слово → документы.
+type Index struct {
+	mu       sync.RWMutex
+	docs     []indexedDocument
+	terms    []string
+	termIDs  map[string]int
+	grams    map[string][]int
+	postings map[int][]int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		termIDs:  make(map[string]int),
+		grams:    make(map[string][]int),
+		postings: make(map[int][]int),
+	}
+}
+
+// Add добавляет документ в индекс.
+func (idx *Index) Add(doc Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	position := len(idx.docs)
+	indexed := indexedDocument{doc: doc}
+	seen := make(map[int]bool)
+	for _, token := range tokenize(doc.Text) {
+		term := idx.termID(normalize.Word(token.text))
+		indexed.tokens = append(indexed.tokens, docToken{term: term, span: token.span})
+		if !seen[term] {
+			seen[term] = true
+			idx.postings[term] = append(idx.postings[term], position)
+		}
+	}
+	idx.docs = append(idx.docs, indexed)
+}
+
+// Len возвращает число документов.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Terms возвращает все слова словаря индекса.
+func (idx *Index) Terms() []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return append([]string(nil), idx.terms...)
+}
+
+// Documents возвращает все документы индекса.
+func (idx *Index) Documents() []Document {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	docs := make([]Document, len(idx.docs))
+	for i, indexed := range idx.docs {
+		docs[i] = indexed.doc
+	}
+	return docs
+}
+
+func (idx *Index) termID(term string) int {
+	if id, ok := idx.termIDs[term]; ok {
+		return id
+	}
+	id := len(idx.terms)
+	idx.terms = append(idx.terms, term)
+	idx.termIDs[term] = id
+	for _, gram := range Trigrams(term) {
+		idx.grams[gram] = append(idx.grams[gram], id)
+	}
+	return id
+}
+
+// Search находит документы, в которых есть все слова запроса с учетом опечаток.
+// Последнее слово запроса может быть префиксом (режим автодополнения).
+func (idx *Index) Search(query string, opts SearchOptions) []Result {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var queryTerms []string
+	for _, token := range tokenize(query) {
+		queryTerms = append(queryTerms, strings.Fields(normalize.Word(token.text))...)
+	}
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	// для каждого слова запроса — похожие слова словаря и их сходство
+	matches := make([]map[int]float64, len(queryTerms))
+	for i, term := range queryTerms {
+		matches[i] = idx.similarTerms(term, i == len(queryTerms)-1)
+		if len(matches[i]) == 0 {
+			return nil
+		}
+	}
+
+	var results []Result
+	for _, position := range idx.candidates(matches) {
+		if result, ok := idx.score(idx.docs[position], matches, opts); ok {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Document.ID < results[j].Document.ID
+	})
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
+
+// similarTerms подбирает слова словаря в пределах допустимого числа опечаток.
+// Числа (номера домов) сравниваются только точно или по префиксу.
+func (idx *Index) similarTerms(term string, prefix bool) map[int]float64 {
+	similar := make(map[int]float64)
+	if id, ok := idx.termIDs[term]; ok {
+		similar[id] = 1
+	}
+
+	numeric := hasDigit(term)
+	maxEdits := MaxEdits(term)
+	if numeric {
+		maxEdits = 0
+	}
+	termLen := len([]rune(term))
+
+	candidates := make(map[int]bool)
+	for _, gram := range Trigrams(term) {
+		for _, id := range idx.grams[gram] {
+			candidates[id] = true
+		}
+	}
+
+	for id := range candidates {
+		if _, ok := similar[id]; ok {
+			continue
+		}
+		word := idx.terms[id]
+		if prefix && strings.HasPrefix(word, term) {
+			similar[id] = 0.9
+			continue
+		}
+		if maxEdits == 0 {
+			continue
+		}
+		compared := word
+		if prefix && len([]rune(word)) > termLen {
+			compared = string([]rune(word)[:termLen])
+		}
+		distance := Levenshtein(term, compared)
+		if distance <= maxEdits {
+			similar[id] = 1 - float64(distance)/float64(termLen+1)
+		}
+	}
+	return similar
+}
+
+// candidates возвращает документы, содержащие совпадение для каждого слова запроса.
+func (idx *Index) candidates(matches []map[int]float64) []int {
+	var result map[int]bool
+	for _, similar := range matches {
+		docs := make(map[int]bool)
+		for term := range similar {
+			for _, position := range idx.postings[term] {
+				if result == nil || result[position] {
+					docs[position] = true
+				}
+			}
+		}
+		result = docs
+		if len(result) == 0 {
+			return nil
+		}
+	}
+
+	positions := make([]int, 0, len(result))
+	for position := range result {
+		positions = append(positions, position)
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+func (idx *Index) score(indexed indexedDocument, matches []map[int]float64, opts SearchOptions) (Result, bool) {
+	used := make(map[int]bool)
+	total := 0.0
+	var highlights []Span
+	for _, similar := range matches {
+		best, bestToken := 0.0, -1
+		for i, token := range indexed.tokens {
+			if sim, ok := similar[token.term]; ok && !used[i] && sim > best {
+				best, bestToken = sim, i
+			}
+		}
+		if bestToken < 0 {
+			return Result{}, false
+		}
+		used[bestToken] = true
+		total += best
+		highlights = append(highlights, indexed.tokens[bestToken].span)
+	}
+
+	textScore := total / float64(len(matches))
+	coverage := float64(len(matches)) / float64(len(indexed.tokens))
+	textScore = 0.85*textScore + 0.15*coverage
+
+	result := Result{
+		Document:   indexed.doc,
+		TextScore:  textScore,
+		Score:      textScore,
+		Distance:   -1,
+		Highlights: highlights,
+	}
+	sort.Slice(result.Highlights, func(i, j int) bool {
+		return result.Highlights[i].Offset < result.Highlights[j].Offset
+	})
+
+	if opts.HasBias {
+		proximity := 0.0
+		if indexed.doc.HasGeo {
+			scale := opts.BiasScale
+			if scale <= 0 {
+				scale = 50000
+			}
+			result.Distance = geo.Haversine(opts.BiasLat, opts.BiasLon, indexed.doc.Lat, indexed.doc.Lon)
+			proximity = math.Exp(-result.Distance / scale)
+		}
+		result.Score = textWeight*textScore + (1-textWeight)*proximity
+	}
+	return result, true
+}
+
+type token struct {
+	text string
+	span Span
+}
+
+// tokenize делит текст на слова, запоминая их положение в символах.
+func tokenize(text string) []token {
+	var tokens []token
+	var current []rune
+	start := 0
+	flush := func(end int) {
+		word := strings.Trim(string(current), "-/")
+		if word != "" {
+			tokens = append(tokens, token{text: word, span: Span{Offset: start, Length: end - start}})
+		}
+		current = current[:0]
+	}
+
+	position := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '/' {
+			if len(current) == 0 {
+				start = position
+			}
+			current = append(current, r)
+		} else if len(current) > 0 {
+			flush(position)
+		}
+		position++
+	}
+	if len(current) > 0 {
+		flush(position)
+	}
+	return tokens
+}
+
+func hasDigit(term string) bool {
+	for _, r := range term {
+		if unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package fuzzy
+
+import "testing"
+
+func testIndex() *Index {
+	idx := NewIndex()
+	docs := []Document{
+		{ID: 1, Text: "г Москва, ул Тверская, д 7", Lat: 55.7575, Lon: 37.6133, HasGeo: true},
+		{ID: 2, Text: "г Москва, ул Ленина, д 1", Lat: 55.75, Lon: 37.61, HasGeo: true},
+		{ID: 3, Text: "г Санкт-Петербург, ул Ленина, д 1", Lat: 59.96, Lon: 30.31, HasGeo: true},
+		{ID: 4, Text: "г Казань, ул Ленина, д 12"},
+		{ID: 5, Text: "г Санкт-Петербург, Невский пр-кт, д 28", Lat: 59.93, Lon: 30.33, HasGeo: true},
+	}
+	for _, doc := range docs {
+		idx.Add(doc)
+	}
+	return idx
+}
+
+func resultIDs(results []Result) []int {
+	var ids []int
+	for _, result := range results {
+		ids = append(ids, result.Document.ID)
+	}
+	return ids
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := testIndex()
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []int
+	}{
+		{name: "exact", query: "Тверская", expected: []int{1}},
+		{name: "typo", query: "Тверкая", expected: []int{1}},
+		{name: "transposition", query: "Леинна", expected: []int{2, 3, 4}},
+		{name: "prefix of last word", query: "москва лен", expected: []int{2}},
+		{name: "abbreviation matches expanded word", query: "улица Тверская", expected: []int{1}},
+		{name: "latin input", query: "Tverskaya", expected: []int{1}},
+		{name: "latin with typo", query: "Lenin Kazan", expected: []int{4}},
+		{name: "house number must match exactly", query: "Ленина 12", expected: []int{4}},
+		{name: "street type variants", query: "невский проспект", expected: []int{5}},
+		{name: "nothing", query: "Гагарина", expected: nil},
+		{name: "empty", query: "  ", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := resultIDs(idx.Search(tc.query, SearchOptions{}))
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Fatalf("expected %v, got %v", tc.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_Search_LocalityRanking(t *testing.T) {
+	idx := testIndex()
+
+	results := idx.Search("Ленина", SearchOptions{HasBias: true, BiasLat: 59.94, BiasLon: 30.3, Limit: 2})
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Document.ID != 3 {
+		t.Errorf("expected Saint Petersburg first, got %d", results[0].Document.ID)
+	}
+	if results[0].Distance < 0 || results[0].Distance > 5000 {
+		t.Errorf("expected distance under 5 km, got %f", results[0].Distance)
+	}
+}
+
+func TestIndex_Search_Highlights(t *testing.T) {
+	idx := testIndex()
+
+	results := idx.Search("москва тверкая", SearchOptions{})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	text := []rune(results[0].Document.Text)
+	var highlighted []string
+	for _, span := range results[0].Highlights {
+		highlighted = append(highlighted, string(text[span.Offset:span.Offset+span.Length]))
+	}
+	if len(highlighted) != 2 || highlighted[0] != "Москва" || highlighted[1] != "Тверская" {
+		t.Errorf("unexpected highlights %q", highlighted)
+	}
+}
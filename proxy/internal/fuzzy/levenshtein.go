@@ -0,0 +1,78 @@
+package fuzzy
+
+// Levenshtein возвращает редакционное расстояние между строками с учетом
+// перестановки соседних символов (вариант optimal string alignment).
+// Строки сравниваются по рунам, поэтому кириллица и латиница обрабатываются одинаково.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && prevPrev[j-2]+1 < curr[j] {
+				curr[j] = prevPrev[j-2] + 1
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(rb)]
+}
+
+// MaxEdits — допустимое число опечаток для слова заданной длины.
+func MaxEdits(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Trigrams разбивает слово на триграммы с граничными пробелами,
+// чтобы начало и конец слова имели собственные n-граммы.
+func Trigrams(word string) []string {
+	runes := []rune(" " + word + " ")
+	if len(runes) < 3 {
+		return nil
+	}
+	seen := make(map[string]bool, len(runes))
+	var grams []string
+	for i := 0; i+3 <= len(runes); i++ {
+		gram := string(runes[i : i+3])
+		if !seen[gram] {
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,45 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "абв", 3},
+		{"ленина", "ленина", 0},
+		{"ленина", "ленена", 1},
+		{"ленина", "лениан", 1},
+		{"тверская", "тверскя", 1},
+		{"тверская", "тверсакя", 1},
+		{"lenina", "lenin", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tc := range testCases {
+		if got := Levenshtein(tc.a, tc.b); got != tc.expected {
+			t.Errorf("Levenshtein(%q, %q): expected %d, got %d", tc.a, tc.b, tc.expected, got)
+		}
+	}
+}
+
+func TestTrigrams(t *testing.T) {
+	expected := []string{" ле", "лен", "ен "}
+	if got := Trigrams("лен"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := Trigrams("а"); len(got) != 1 {
+		t.Errorf("expected single trigram for one-letter word, got %q", got)
+	}
+}
+
+func TestMaxEdits(t *testing.T) {
+	if MaxEdits("мира") != 1 || MaxEdits("ул") != 0 || MaxEdits("тверская") != 2 {
+		t.Error("unexpected typo allowance")
+	}
+}
@@ -0,0 +1,88 @@
+package geo
+
+import "math"
+
+// EarthRadius — средний радиус Земли в метрах.
+const EarthRadius = 6371008.8
+
+// Haversine возвращает расстояние по большому кругу между двумя точками в метрах.
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dPhi := toRadians(lat2 - lat1)
+	dLambda := toRadians(lon2 - lon1)
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// InitialBearing возвращает начальный азимут по большому кругу из первой
+// точки во вторую в градусах от 0 до 360, отсчитываемых от севера по часовой стрелке.
+func InitialBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dLambda := toRadians(lon2 - lon1)
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return normalizeBearing(toDegrees(math.Atan2(y, x)))
+}
+
+// FinalBearing возвращает азимут движения по большому кругу в конечной точке.
+func FinalBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	return normalizeBearing(InitialBearing(lat2, lon2, lat1, lon1) + 180)
+}
+
+func toDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// normalizeBearing приводит угол к диапазону [0, 360).
+func normalizeBearing(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// Destination возвращает точку, в которую приводит движение по большому кругу
+// из заданной точки с азимутом bearing (градусы) на расстояние distance (метры).
+func Destination(lat, lon, bearing, distance float64) (float64, float64) {
+	phi1 := toRadians(lat)
+	lambda1 := toRadians(lon)
+	theta := toRadians(bearing)
+	delta := distance / EarthRadius
+
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) + math.Cos(phi1)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(phi1),
+		math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2))
+	return toDegrees(phi2), math.Mod(toDegrees(lambda2)+540, 360) - 180
+}
+
+// SegmentDistance возвращает расстояние в метрах от точки до отрезка
+// большого круга между точками 1 и 2. Точка проецируется на большой круг
+// отрезка (расстояние поперек и вдоль пути, cross-track и along-track);
+// если проекция за концом отрезка, берется расстояние до ближайшего конца.
+func SegmentDistance(lat, lon, lat1, lon1, lat2, lon2 float64) float64 {
+	d13 := Haversine(lat1, lon1, lat, lon)
+	if lat1 == lat2 && lon1 == lon2 {
+		return d13
+	}
+	delta13 := d13 / EarthRadius
+	theta := toRadians(InitialBearing(lat1, lon1, lat, lon) - InitialBearing(lat1, lon1, lat2, lon2))
+	crossTrack := math.Asin(math.Sin(delta13) * math.Sin(theta))
+	alongTrack := math.Atan2(math.Sin(delta13)*math.Cos(theta), math.Cos(delta13))
+	switch {
+	case alongTrack <= 0:
+		return d13
+	case alongTrack*EarthRadius >= Haversine(lat1, lon1, lat2, lon2):
+		return Haversine(lat2, lon2, lat, lon)
+	}
+	return math.Abs(crossTrack) * EarthRadius
+}
@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversine(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+		tolerance              float64
+	}{
+		{name: "same point", lat1: 55.7558, lon1: 37.6176, lat2: 55.7558, lon2: 37.6176, expected: 0, tolerance: 0.001},
+		{name: "Moscow - Saint Petersburg", lat1: 55.7558, lon1: 37.6176, lat2: 59.9311, lon2: 30.3609, expected: 634000, tolerance: 3000},
+		{name: "one degree of longitude on equator", lat1: 0, lon1: 0, lat2: 0, lon2: 1, expected: 111195, tolerance: 10},
+		{name: "antimeridian", lat1: 0, lon1: 179.5, lat2: 0, lon2: -179.5, expected: 111195, tolerance: 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Haversine(tc.lat1, tc.lon1, tc.lat2, tc.lon2)
+			if math.Abs(got-tc.expected) > tc.tolerance {
+				t.Errorf("expected %.0f ± %.0f m, got %.0f m", tc.expected, tc.tolerance, got)
+			}
+		})
+	}
+}
+
+func TestDestination(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		lat, lon                 float64
+		bearing, distance        float64
+		expectedLat, expectedLon float64
+	}{
+		{"east along equator", 0, 0, 90, 111195.08, 0, 1},
+		{"north along meridian", 0, 0, 0, 111195.08, 1, 0},
+		{"across antimeridian", 0, 179.5, 90, 111195.08, 0, -179.5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lat, lon := Destination(tc.lat, tc.lon, tc.bearing, tc.distance)
+			if math.Abs(lat-tc.expectedLat) > 1e-6 || math.Abs(lon-tc.expectedLon) > 1e-6 {
+				t.Errorf("expected %.6f, %.6f, got %.6f, %.6f", tc.expectedLat, tc.expectedLon, lat, lon)
+			}
+		})
+	}
+
+	// туда и обратно по одной линии
+	lat, lon := Destination(55.7558, 37.6176, 45, 10000)
+	if d := Haversine(55.7558, 37.6176, lat, lon); math.Abs(d-10000) > 1e-6 {
+		t.Errorf("expected 10000 m, got %.6f m", d)
+	}
+}
+
+func TestSegmentDistance(t *testing.T) {
+	degree := EarthRadius * math.Pi / 180
+	// основание перпендикуляра из (60°, 10°) на меридиан 0°: tg φ = tg 60° / cos 10°
+	foot := toDegrees(math.Atan(math.Tan(toRadians(60)) / math.Cos(toRadians(10))))
+	testCases := []struct {
+		name                   string
+		lat, lon               float64
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		{"beside the equator", 1, 0.5, 0, 0, 0, 1, degree},
+		{"before start", 0, -1, 0, 0, 0, 1, degree},
+		{"after end", 0, 3, 0, 0, 0, 1, 2 * degree},
+		{"on the segment", 0, 0.5, 0, 0, 0, 1, 0},
+		{"degenerate segment", 0, 1, 0, 0, 0, 0, degree},
+		{"across antimeridian", 0, -179.5, -1, 179.5, 1, 179.5, degree},
+		{"far from meridian", 60, 10, 50, 0, 70, 0, Haversine(60, 10, foot, 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := SegmentDistance(tc.lat, tc.lon, tc.lat1, tc.lon1, tc.lat2, tc.lon2)
+			if math.Abs(got-tc.expected) > 1e-6*tc.expected+1e-6 {
+				t.Errorf("expected %.3f m, got %.3f m", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func dms(d, m, s float64) float64 {
+	sign := 1.0
+	if d < 0 {
+		sign, d = -1, -d
+	}
+	return sign * (d + m/60 + s/3600)
+}
+
+func TestVincenty(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		distance               float64
+		tolerance              float64
+		initial, final         float64
+	}{
+		// контрольный пример Geoscience Australia: Flinders Peak — Buninyong
+		{"Flinders Peak - Buninyong", dms(-37, 57, 3.72030), dms(144, 25, 29.52440), dms(-37, 39, 10.15610), dms(143, 55, 35.38390),
+			54972.271, 0.001, dms(306, 52, 5.37), dms(307, 10, 25.07)},
+		{"one degree of longitude on equator", 0, 0, 0, 1, 111319.491, 0.001, 90, 90},
+		{"meridian quadrant", 0, 0, 90, 0, 10001965.729, 0.001, 0, 0},
+		{"same point", 55.7558, 37.6176, 55.7558, 37.6176, 0, 0, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Vincenty(tc.lat1, tc.lon1, tc.lat2, tc.lon2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got.Distance-tc.distance) > tc.tolerance {
+				t.Errorf("expected %.3f m, got %.3f m", tc.distance, got.Distance)
+			}
+			if math.Abs(got.InitialBearing-tc.initial) > 1e-4 || math.Abs(got.FinalBearing-tc.final) > 1e-4 {
+				t.Errorf("expected bearings %.5f/%.5f, got %.5f/%.5f", tc.initial, tc.final, got.InitialBearing, got.FinalBearing)
+			}
+		})
+	}
+}
+
+func TestVincenty_NearlyAntipodal(t *testing.T) {
+	if _, err := Vincenty(0, 0, 0.5, 179.7); err != ErrNoConvergence {
+		t.Errorf("expected ErrNoConvergence, got %v", err)
+	}
+}
+
+func TestInitialBearing(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		{"east", 0, 0, 0, 1, 90},
+		{"north", 0, 0, 1, 0, 0},
+		{"west", 0, 0, 0, -1, 270},
+		{"south", 1, 0, 0, 0, 180},
+		{"Moscow - Saint Petersburg", 55.7558, 37.6176, 59.9311, 30.3609, 320.3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := InitialBearing(tc.lat1, tc.lon1, tc.lat2, tc.lon2); math.Abs(got-tc.expected) > 0.1 {
+				t.Errorf("expected %.1f°, got %.1f°", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package geocell
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"math"
+	"strings"
+)
+
+// Кодировки ячеек.
+const (
+	EncodingGeohash  = "geohash"
+	EncodingPlusCode = "plus_code"
+	EncodingS2       = "s2"
+)
+
+// Точность по умолчанию: ячейки размером в несколько метров.
+const (
+	DefaultGeohashPrecision  = 9
+	DefaultPlusCodePrecision = 10
+	DefaultS2Level           = 20
+)
+
+var ErrUnknownEncoding = errors.New("unknown cell encoding")
+
+// Encode возвращает ячейку точки в кодировке encoding. Precision 0 выбирает
+// точность по умолчанию.
+func Encode(encoding string, lat, lon float64, precision int) (models.Cell, error) {
+	var (
+		token string
+		err   error
+	)
+	switch encoding {
+	case EncodingGeohash:
+		if precision == 0 {
+			precision = DefaultGeohashPrecision
+		}
+		token, err = EncodeGeohash(lat, lon, precision)
+	case EncodingPlusCode:
+		if precision == 0 {
+			precision = DefaultPlusCodePrecision
+		}
+		token, err = EncodePlusCode(lat, lon, precision)
+	case EncodingS2:
+		if precision == 0 {
+			precision = DefaultS2Level
+		}
+		token, err = EncodeS2(lat, lon, precision)
+	default:
+		return models.Cell{}, fmt.Errorf("%w: %q", ErrUnknownEncoding, encoding)
+	}
+	if err != nil {
+		return models.Cell{}, err
+	}
+	return Decode(encoding, token)
+}
+
+// Decode возвращает ячейку по токену: точность, центр и границы.
+func Decode(encoding, token string) (models.Cell, error) {
+	cell := models.Cell{Encoding: encoding}
+	switch encoding {
+	case EncodingGeohash:
+		box, err := DecodeGeohash(token)
+		if err != nil {
+			return models.Cell{}, err
+		}
+		cell.Token, cell.Precision, cell.BBox, cell.Center = strings.ToLower(token), len(token), box, Center(box)
+	case EncodingPlusCode:
+		digits, err := validatePlusCode(token)
+		if err != nil {
+			return models.Cell{}, err
+		}
+		box, err := DecodePlusCode(token)
+		if err != nil {
+			return models.Cell{}, err
+		}
+		cell.Token, cell.Precision, cell.BBox, cell.Center = strings.ToUpper(token), len(digits), box, Center(box)
+	case EncodingS2:
+		face, i, j, level, err := parseS2Token(token)
+		if err != nil {
+			return models.Cell{}, err
+		}
+		cell.Token, cell.Precision = strings.ToLower(token), level
+		cell.BBox, cell.Center = s2CellBounds(face, i, j, level), s2CellCenter(face, i, j, level)
+	default:
+		return models.Cell{}, fmt.Errorf("%w: %q", ErrUnknownEncoding, encoding)
+	}
+	return cell, nil
+}
+
+// Neighbours возвращает соседние ячейки той же точности: до восьми, у полюсов
+// меньше. Соседи за антимеридианом учитываются.
+func Neighbours(encoding, token string) ([]models.Cell, error) {
+	cell, err := Decode(encoding, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []string
+	if encoding == EncodingS2 {
+		if tokens, err = NeighboursS2(cell.Token); err != nil {
+			return nil, err
+		}
+	} else {
+		tokens = gridNeighbours(cell)
+	}
+
+	neighbours := make([]models.Cell, 0, len(tokens))
+	for _, t := range tokens {
+		neighbour, err := Decode(encoding, t)
+		if err != nil {
+			return nil, err
+		}
+		neighbours = append(neighbours, neighbour)
+	}
+	return neighbours, nil
+}
+
+// gridNeighbours находит соседей прямоугольной ячейки, кодируя точки,
+// смещенные от центра на ширину и высоту ячейки: с севера по часовой стрелке.
+func gridNeighbours(cell models.Cell) []string {
+	height := cell.BBox.North - cell.BBox.South
+	width := cell.BBox.East - cell.BBox.West
+	seen := map[string]bool{cell.Token: true}
+	var tokens []string
+	for _, d := range [8][2]float64{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}} {
+		lat := cell.Center.Lat + d[0]*height
+		if lat < -90 || lat > 90 {
+			continue
+		}
+		lon := math.Mod(cell.Center.Lon+d[1]*width+540, 360) - 180
+		neighbour, err := Encode(cell.Encoding, lat, lon, cell.Precision)
+		if err != nil || seen[neighbour.Token] {
+			continue
+		}
+		seen[neighbour.Token] = true
+		tokens = append(tokens, neighbour.Token)
+	}
+	return tokens
+}
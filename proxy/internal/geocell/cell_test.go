@@ -0,0 +1,103 @@
+package geocell
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	testCases := []struct {
+		encoding  string
+		lat, lon  float64
+		precision int
+		token     string
+		expected  int
+	}{
+		{EncodingGeohash, 57.64911, 10.40744, 0, "u4pruydqq", DefaultGeohashPrecision},
+		{EncodingGeohash, 57.64911, 10.40744, 5, "u4pru", 5},
+		{EncodingPlusCode, 47.365590, 8.524997, 0, "8FVC9G8F+6X", DefaultPlusCodePrecision},
+		{EncodingPlusCode, 47.365590, 8.524997, 4, "8FVC0000+", 4},
+		{EncodingS2, 40.7128, -74.0060, 0, "89c25a220cf", DefaultS2Level},
+	}
+
+	for _, tc := range testCases {
+		cell, err := Encode(tc.encoding, tc.lat, tc.lon, tc.precision)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.encoding, err)
+		}
+		if cell.Token != tc.token {
+			t.Errorf("%s: expected token %s, got %s", tc.encoding, tc.token, cell.Token)
+		}
+		if cell.Precision != tc.expected {
+			t.Errorf("%s: expected precision %d, got %d", tc.encoding, tc.expected, cell.Precision)
+		}
+		if cell.BBox.South > tc.lat || cell.BBox.North < tc.lat || cell.BBox.West > tc.lon || cell.BBox.East < tc.lon {
+			t.Errorf("%s: cell %+v does not contain the point", tc.encoding, cell.BBox)
+		}
+	}
+
+	if _, err := Encode("h3", 0, 0, 0); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("expected ErrUnknownEncoding, got %v", err)
+	}
+	if _, err := Encode(EncodingPlusCode, 0, 0, 9); err == nil {
+		t.Error("expected error for plus code length 9")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	cell, err := Decode(EncodingPlusCode, "8fvc9g8f+6x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cell.Token != "8FVC9G8F+6X" || cell.Precision != 10 {
+		t.Errorf("unexpected cell %+v", cell)
+	}
+
+	cell, err = Decode(EncodingS2, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cell.Precision != 0 || math.Abs(cell.Center.Lat) > 1e-9 || math.Abs(cell.Center.Lon) > 1e-9 {
+		t.Errorf("unexpected cell %+v", cell)
+	}
+
+	if _, err := Decode(EncodingGeohash, "ucfa"); err == nil {
+		t.Error("expected error for invalid geohash")
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	testCases := []struct {
+		encoding, token string
+		expected        []string
+	}{
+		{EncodingGeohash, "ezs42", []string{"ezs48", "ezs49", "ezs43", "ezs41", "ezs40", "ezefp", "ezefr", "ezefx"}},
+		// у полюса соседей с севера нет
+		{EncodingGeohash, "zz", []string{"bp", "bn", "zy", "zw", "zx"}},
+		// за антимеридианом
+		{EncodingGeohash, "xcz", []string{"xfp", "840", "81b", "818", "xcx", "xcw", "xcy", "xfn"}},
+		{EncodingPlusCode, "8FVC9G8F+6X", []string{"8FVC9G8F+7X", "8FVC9G8G+72", "8FVC9G8G+62", "8FVC9G8G+52", "8FVC9G8F+5X", "8FVC9G8F+5W", "8FVC9G8F+6W", "8FVC9G8F+7W"}},
+	}
+
+	for _, tc := range testCases {
+		neighbours, err := Neighbours(tc.encoding, tc.token)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.token, err)
+		}
+		var tokens []string
+		for _, n := range neighbours {
+			tokens = append(tokens, n.Token)
+		}
+		if len(tokens) != len(tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.token, tc.expected, tokens)
+			continue
+		}
+		for i := range tokens {
+			if tokens[i] != tc.expected[i] {
+				t.Errorf("%s: expected %v, got %v", tc.token, tc.expected, tokens)
+				break
+			}
+		}
+	}
+}
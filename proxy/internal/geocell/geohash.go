@@ -0,0 +1,105 @@
+package geocell
+
+import (
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"strings"
+)
+
+// MaxGeohashPrecision — наибольшая длина геохеша: 12 символов дают ячейку
+// размером в несколько сантиметров.
+const MaxGeohashPrecision = 12
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// EncodeGeohash возвращает геохеш точки длиной precision символов (1–12).
+func EncodeGeohash(lat, lon float64, precision int) (string, error) {
+	if precision < 1 || precision > MaxGeohashPrecision {
+		return "", fmt.Errorf("geohash precision must be between 1 and %d", MaxGeohashPrecision)
+	}
+	if err := checkPoint(lat, lon); err != nil {
+		return "", err
+	}
+
+	latRange, lonRange := [2]float64{-90, 90}, [2]float64{-180, 180}
+	hash := make([]byte, 0, precision)
+	even := true
+	for len(hash) < precision {
+		index := 0
+		for bit := 0; bit < 5; bit++ {
+			index <<= 1
+			// биты чередуются, начиная с долготы
+			if even {
+				index |= bisect(&lonRange, lon)
+			} else {
+				index |= bisect(&latRange, lat)
+			}
+			even = !even
+		}
+		hash = append(hash, geohashAlphabet[index])
+	}
+	return string(hash), nil
+}
+
+// bisect делит диапазон пополам и оставляет половину со значением:
+// 1 — верхнюю, 0 — нижнюю.
+func bisect(r *[2]float64, value float64) int {
+	mid := (r[0] + r[1]) / 2
+	if value >= mid {
+		r[0] = mid
+		return 1
+	}
+	r[1] = mid
+	return 0
+}
+
+// DecodeGeohash возвращает ячейку геохеша. Регистр не важен.
+func DecodeGeohash(hash string) (models.BoundingBox, error) {
+	if hash == "" {
+		return models.BoundingBox{}, fmt.Errorf("geohash is empty")
+	}
+	if len(hash) > MaxGeohashPrecision {
+		return models.BoundingBox{}, fmt.Errorf("geohash is longer than %d characters", MaxGeohashPrecision)
+	}
+
+	box := models.BoundingBox{South: -90, West: -180, North: 90, East: 180}
+	even := true
+	for i, c := range strings.ToLower(hash) {
+		index := strings.IndexRune(geohashAlphabet, c)
+		if index < 0 {
+			return models.BoundingBox{}, fmt.Errorf("invalid geohash character %q at position %d", c, i+1)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			upper := index>>bit&1 == 1
+			if even {
+				mid := (box.West + box.East) / 2
+				if upper {
+					box.West = mid
+				} else {
+					box.East = mid
+				}
+			} else {
+				mid := (box.South + box.North) / 2
+				if upper {
+					box.South = mid
+				} else {
+					box.North = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return box, nil
+}
+
+func checkPoint(lat, lon float64) error {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return fmt.Errorf("coordinates out of range: %g, %g", lat, lon)
+	}
+	return nil
+}
+
+// Center возвращает центр ячейки.
+func Center(box models.BoundingBox) models.GeoPoint {
+	return models.GeoPoint{Lat: (box.South + box.North) / 2, Lon: (box.West + box.East) / 2}
+}
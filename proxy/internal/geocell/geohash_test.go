@@ -0,0 +1,57 @@
+package geocell
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	testCases := []struct {
+		lat, lon  float64
+		precision int
+		expected  string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{42.605, -5.603, 5, "ezs42"},
+		{0, 0, 1, "s"},
+		{-90, -180, 4, "0000"},
+		{90, 180, 4, "zzzz"},
+	}
+
+	for _, tc := range testCases {
+		got, err := EncodeGeohash(tc.lat, tc.lon, tc.precision)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.expected {
+			t.Errorf("EncodeGeohash(%g, %g, %d): expected %s, got %s", tc.lat, tc.lon, tc.precision, tc.expected, got)
+		}
+	}
+
+	if _, err := EncodeGeohash(0, 0, 13); err == nil {
+		t.Error("expected error for precision 13")
+	}
+	if _, err := EncodeGeohash(91, 0, 5); err == nil {
+		t.Error("expected error for latitude out of range")
+	}
+}
+
+func TestDecodeGeohash(t *testing.T) {
+	box, err := DecodeGeohash("EZS42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if box.South != 42.5830078125 || box.North != 42.626953125 || box.West != -5.625 || box.East != -5.5810546875 {
+		t.Errorf("unexpected cell %+v", box)
+	}
+	center := Center(box)
+	if math.Abs(center.Lat-42.605) > 0.001 || math.Abs(center.Lon+5.603) > 0.001 {
+		t.Errorf("unexpected center %+v", center)
+	}
+
+	for _, hash := range []string{"", "ezs4a", "u4pruydqqvjkk"} {
+		if _, err := DecodeGeohash(hash); err == nil {
+			t.Errorf("%q: expected error", hash)
+		}
+	}
+}
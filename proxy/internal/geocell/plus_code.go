@@ -0,0 +1,164 @@
+package geocell
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"math"
+	"strings"
+)
+
+// Параметры Open Location Code (plus codes), см.
+// https://github.com/google/open-location-code/blob/main/docs/specification.md
+const (
+	plusCodeAlphabet  = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator = '+'
+	plusCodePadding   = '0'
+	separatorPosition = 8
+	pairCodeLength    = 10
+	gridCodeLength    = 5
+	gridRows          = 5
+	gridColumns       = 4
+	// MaxPlusCodeLength — наибольшее число значащих символов кода.
+	MaxPlusCodeLength = pairCodeLength + gridCodeLength
+)
+
+// Точность целочисленного кодирования: шаг последнего символа сетки.
+const (
+	finalLatPrecision = 8000 * 3125 // 8000 · 5⁵
+	finalLonPrecision = 8000 * 1024 // 8000 · 4⁵
+)
+
+// ErrShortPlusCode возвращается для сокращенного кода вроде "9G8F+6X":
+// его нельзя раскрыть без опорной точки.
+var ErrShortPlusCode = errors.New("short plus code requires a reference location")
+
+// EncodePlusCode возвращает полный plus code точки длиной length значащих
+// символов: 2, 4, 6, 8 или от 10 до 15. Коды короче 8 символов дополняются
+// нулями до разделителя.
+func EncodePlusCode(lat, lon float64, length int) (string, error) {
+	if length < 2 || length > MaxPlusCodeLength || (length < pairCodeLength && length%2 == 1) {
+		return "", fmt.Errorf("plus code length must be 2, 4, 6, 8 or 10 to %d", MaxPlusCodeLength)
+	}
+	if err := checkPoint(lat, lon); err != nil {
+		return "", err
+	}
+
+	latVal := scaled(lat+90, finalLatPrecision)
+	lonVal := scaled(lon+180, finalLonPrecision)
+	// северный полюс и антимеридиан принадлежат последним ячейкам
+	latVal = minInt64(latVal, 180*finalLatPrecision-1)
+	lonVal %= 360 * finalLonPrecision
+
+	code := make([]byte, MaxPlusCodeLength)
+	for i := MaxPlusCodeLength - 1; i >= pairCodeLength; i-- {
+		code[i] = plusCodeAlphabet[(latVal%gridRows)*gridColumns+lonVal%gridColumns]
+		latVal /= gridRows
+		lonVal /= gridColumns
+	}
+	for i := pairCodeLength - 2; i >= 0; i -= 2 {
+		code[i] = plusCodeAlphabet[latVal%20]
+		code[i+1] = plusCodeAlphabet[lonVal%20]
+		latVal /= 20
+		lonVal /= 20
+	}
+
+	significant := string(code[:length])
+	if length < separatorPosition {
+		return significant + strings.Repeat(string(plusCodePadding), separatorPosition-length) + string(plusCodeSeparator), nil
+	}
+	return significant[:separatorPosition] + string(plusCodeSeparator) + significant[separatorPosition:], nil
+}
+
+// DecodePlusCode возвращает область полного plus code. Регистр не важен.
+func DecodePlusCode(code string) (models.BoundingBox, error) {
+	digits, err := validatePlusCode(code)
+	if err != nil {
+		return models.BoundingBox{}, err
+	}
+
+	var latVal, lonVal int64
+	latStep, lonStep := int64(20*finalLatPrecision), int64(20*finalLonPrecision)
+	for i := 0; i < len(digits) && i < pairCodeLength; i += 2 {
+		latVal += int64(strings.IndexByte(plusCodeAlphabet, digits[i])) * latStep
+		lonVal += int64(strings.IndexByte(plusCodeAlphabet, digits[i+1])) * lonStep
+		if i+2 < len(digits) && i+2 < pairCodeLength {
+			latStep /= 20
+			lonStep /= 20
+		}
+	}
+	for i := pairCodeLength; i < len(digits); i++ {
+		latStep /= gridRows
+		lonStep /= gridColumns
+		index := int64(strings.IndexByte(plusCodeAlphabet, digits[i]))
+		latVal += index / gridColumns * latStep
+		lonVal += index % gridColumns * lonStep
+	}
+
+	return models.BoundingBox{
+		South: float64(latVal)/finalLatPrecision - 90,
+		West:  float64(lonVal)/finalLonPrecision - 180,
+		North: math.Min(float64(latVal+latStep)/finalLatPrecision-90, 90),
+		East:  float64(lonVal+lonStep)/finalLonPrecision - 180,
+	}, nil
+}
+
+// validatePlusCode проверяет полный код и возвращает его значащие символы
+// в верхнем регистре.
+func validatePlusCode(code string) (string, error) {
+	code = strings.ToUpper(code)
+	separator := strings.IndexByte(code, plusCodeSeparator)
+	switch {
+	case code == "":
+		return "", errors.New("plus code is empty")
+	case separator < 0:
+		return "", errors.New("plus code must contain '+'")
+	case strings.Count(code, string(plusCodeSeparator)) > 1:
+		return "", errors.New("plus code must contain exactly one '+'")
+	case separator < separatorPosition && separator%2 == 0:
+		return "", ErrShortPlusCode
+	case separator != separatorPosition:
+		return "", fmt.Errorf("'+' must follow %d characters", separatorPosition)
+	case len(code)-separator-1 == 1:
+		return "", errors.New("plus code cannot have a single character after '+'")
+	}
+
+	for i, c := range code {
+		if c != plusCodeSeparator && c != plusCodePadding && !strings.ContainsRune(plusCodeAlphabet, c) {
+			return "", fmt.Errorf("invalid plus code character %q at position %d", c, i+1)
+		}
+	}
+
+	digits := strings.Replace(code, string(plusCodeSeparator), "", 1)
+	if padding := strings.IndexByte(digits, plusCodePadding); padding >= 0 {
+		if padding == 0 || padding%2 == 1 || strings.Trim(digits[padding:], string(plusCodePadding)) != "" || separator+1 != len(code) {
+			return "", errors.New("plus code padding must be an even number of '0' right before '+'")
+		}
+		digits = digits[:padding]
+	}
+	if len(digits) > MaxPlusCodeLength {
+		return "", fmt.Errorf("plus code has more than %d digits", MaxPlusCodeLength)
+	}
+
+	if strings.IndexByte(plusCodeAlphabet, digits[0])*20 >= 180 {
+		return "", errors.New("plus code latitude is out of range")
+	}
+	if strings.IndexByte(plusCodeAlphabet, digits[1])*20 >= 360 {
+		return "", errors.New("plus code longitude is out of range")
+	}
+	return digits, nil
+}
+
+// scaled переводит градусы в целые шаги сетки. Округление до 10⁻⁶ шага
+// перед отбрасыванием дробной части убирает ошибки представления вроде
+// 0.1·25000000 = 2499999.9999.
+func scaled(deg float64, precision int64) int64 {
+	return int64(math.Floor(math.Round(deg*float64(precision)*1e6) / 1e6))
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
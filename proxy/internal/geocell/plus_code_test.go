@@ -0,0 +1,96 @@
+package geocell
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEncodePlusCode(t *testing.T) {
+	testCases := []struct {
+		lat, lon float64
+		length   int
+		expected string
+	}{
+		{47.365590, 8.524997, 10, "8FVC9G8F+6X"},
+		{47.365590, 8.524997, 11, "8FVC9G8F+6XQ"},
+		{20.375, 2.775, 6, "7FG49Q00+"},
+		{0, 0, 10, "6FG22222+22"},
+		{55.7558, 37.6176, 4, "9G7V0000+"},
+		// северный полюс попадает в последнюю ячейку, 180° — в -180°
+		{90, 180, 10, "C2X2X2X2+X2"},
+	}
+
+	for _, tc := range testCases {
+		got, err := EncodePlusCode(tc.lat, tc.lon, tc.length)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.expected {
+			t.Errorf("EncodePlusCode(%g, %g, %d): expected %s, got %s", tc.lat, tc.lon, tc.length, tc.expected, got)
+		}
+	}
+
+	for _, length := range []int{0, 1, 3, 9, 16} {
+		if _, err := EncodePlusCode(0, 0, length); err == nil {
+			t.Errorf("length %d: expected error", length)
+		}
+	}
+}
+
+func TestDecodePlusCode(t *testing.T) {
+	box, err := DecodePlusCode("8fvc9g8f+6x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(box.South-47.3655) > 1e-9 || math.Abs(box.North-47.365625) > 1e-9 ||
+		math.Abs(box.West-8.524875) > 1e-9 || math.Abs(box.East-8.525) > 1e-9 {
+		t.Errorf("unexpected area %+v", box)
+	}
+
+	box, err = DecodePlusCode("7FG49Q00+")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(box.South-20.35) > 1e-9 || math.Abs(box.West-2.75) > 1e-9 || math.Abs(box.North-20.4) > 1e-9 {
+		t.Errorf("unexpected padded area %+v", box)
+	}
+
+	testCases := map[string]string{
+		"empty":                 "",
+		"no separator":          "8FVC9G8F6X",
+		"two separators":        "8FVC9G8F+6X+",
+		"misplaced separator":   "8FVC9G8+F6X",
+		"single digit after":    "8FVC9G8F+6",
+		"invalid character":     "8FVC9G8A+6X",
+		"odd padding":           "8FVC9G80+",
+		"digits after padding":  "8FVC0000+6X",
+		"latitude out of range": "XFVC9G8F+6X",
+		"too long":              "8FVC9G8F+6XQQQQQQ",
+	}
+	for name, code := range testCases {
+		if _, err := DecodePlusCode(code); err == nil {
+			t.Errorf("%s: expected error for %q", name, code)
+		}
+	}
+
+	if _, err := DecodePlusCode("9G8F+6X"); !errors.Is(err, ErrShortPlusCode) {
+		t.Errorf("expected ErrShortPlusCode, got %v", err)
+	}
+}
+
+func TestPlusCode_RoundTrip(t *testing.T) {
+	for _, p := range [][2]float64{{55.7558, 37.6176}, {-33.9249, 18.4241}, {-89.9, -179.9}, {12.3456789, -98.7654321}} {
+		code, err := EncodePlusCode(p[0], p[1], MaxPlusCodeLength)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		box, err := DecodePlusCode(code)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", code, err)
+		}
+		if p[0] < box.South || p[0] >= box.North || p[1] < box.West || p[1] >= box.East {
+			t.Errorf("%v is outside of %s area %+v", p, code, box)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package geocell
+
+import (
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// MaxS2Level — уровень листовых ячеек S2, около сантиметра.
+const MaxS2Level = 30
+
+// Параметры кривой Гильберта S2: для каждой ориентации — номер
+// подъячейки по координатам (i, j) и обратно, и смена ориентации.
+const (
+	s2SwapMask   = 1
+	s2InvertMask = 2
+)
+
+var (
+	s2IJToPos          = [4][4]int{{0, 1, 3, 2}, {0, 3, 1, 2}, {2, 3, 1, 0}, {2, 1, 3, 0}}
+	s2PosToIJ          = [4][4]int{{0, 1, 3, 2}, {0, 2, 3, 1}, {3, 2, 0, 1}, {3, 1, 0, 2}}
+	s2PosToOrientation = [4]int{s2SwapMask, 0, 0, s2InvertMask | s2SwapMask}
+	s2MaxSize          = 1 << MaxS2Level
+	s2EdgeSamples      = 16
+)
+
+// EncodeS2 возвращает токен ячейки S2 уровня level (0–30), содержащей точку.
+// Токен — шестнадцатеричный идентификатор ячейки без хвостовых нулей,
+// как в библиотеках S2.
+func EncodeS2(lat, lon float64, level int) (string, error) {
+	if level < 0 || level > MaxS2Level {
+		return "", fmt.Errorf("s2 level must be between 0 and %d", MaxS2Level)
+	}
+	if err := checkPoint(lat, lon); err != nil {
+		return "", err
+	}
+	face, i, j := xyzToFaceIJ(latLonToXYZ(lat, lon))
+	return s2Token(s2CellID(face, i, j, level)), nil
+}
+
+// DecodeS2 возвращает прямоугольник, охватывающий ячейку S2. Ребра ячейки —
+// дуги больших кругов, поэтому границы считаются по точкам вдоль ребер.
+// Для ячеек, пересекающих антимеридиан, West больше East.
+func DecodeS2(token string) (models.BoundingBox, error) {
+	face, i, j, level, err := parseS2Token(token)
+	if err != nil {
+		return models.BoundingBox{}, err
+	}
+	return s2CellBounds(face, i, j, level), nil
+}
+
+// S2Level возвращает уровень ячейки по токену.
+func S2Level(token string) (int, error) {
+	_, _, _, level, err := parseS2Token(token)
+	return level, err
+}
+
+// NeighboursS2 возвращает до восьми ячеек того же уровня вокруг заданной,
+// включая соседей на соседних гранях куба.
+func NeighboursS2(token string) ([]string, error) {
+	face, i, j, level, err := parseS2Token(token)
+	if err != nil {
+		return nil, err
+	}
+	size := s2MaxSize >> level
+	seen := map[string]bool{s2Token(s2CellID(face, i, j, level)): true}
+	var neighbours []string
+	for _, d := range [8][2]int{{-1, 1}, {0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}} {
+		// центр соседней ячейки может лежать за гранью: точка на продолжении
+		// грани задает направление, по которому находится ячейка на другой грани
+		s := (float64(i) + float64(size)*(float64(d[0])+0.5)) / float64(s2MaxSize)
+		t := (float64(j) + float64(size)*(float64(d[1])+0.5)) / float64(s2MaxSize)
+		nf, ni, nj := xyzToFaceIJ(faceUVToXYZ(face, stToUV(s), stToUV(t)))
+		neighbour := s2Token(s2CellID(nf, ni, nj, level))
+		if !seen[neighbour] {
+			seen[neighbour] = true
+			neighbours = append(neighbours, neighbour)
+		}
+	}
+	return neighbours, nil
+}
+
+func latLonToXYZ(lat, lon float64) [3]float64 {
+	phi, lambda := lat*math.Pi/180, lon*math.Pi/180
+	return [3]float64{math.Cos(phi) * math.Cos(lambda), math.Cos(phi) * math.Sin(lambda), math.Sin(phi)}
+}
+
+func xyzToLatLon(p [3]float64) (float64, float64) {
+	lat := math.Atan2(p[2], math.Hypot(p[0], p[1])) * 180 / math.Pi
+	lon := math.Atan2(p[1], p[0]) * 180 / math.Pi
+	return lat, lon
+}
+
+// xyzToFaceIJ проецирует направление на грань куба и возвращает
+// координаты листовой ячейки.
+func xyzToFaceIJ(p [3]float64) (int, int, int) {
+	face := 0
+	if math.Abs(p[1]) > math.Abs(p[face]) {
+		face = 1
+	}
+	if math.Abs(p[2]) > math.Abs(p[face]) {
+		face = 2
+	}
+	if p[face] < 0 {
+		face += 3
+	}
+	u, v := xyzToFaceUV(face, p)
+	return face, stToIJ(uvToST(u)), stToIJ(uvToST(v))
+}
+
+func xyzToFaceUV(face int, p [3]float64) (float64, float64) {
+	x, y, z := p[0], p[1], p[2]
+	switch face {
+	case 0:
+		return y / x, z / x
+	case 1:
+		return -x / y, z / y
+	case 2:
+		return -x / z, -y / z
+	case 3:
+		return z / x, y / x
+	case 4:
+		return z / y, -x / y
+	}
+	return -y / z, -x / z
+}
+
+func faceUVToXYZ(face int, u, v float64) [3]float64 {
+	switch face {
+	case 0:
+		return [3]float64{1, u, v}
+	case 1:
+		return [3]float64{-u, 1, v}
+	case 2:
+		return [3]float64{-u, -v, 1}
+	case 3:
+		return [3]float64{-1, -v, -u}
+	case 4:
+		return [3]float64{v, -1, -u}
+	}
+	return [3]float64{v, u, -1}
+}
+
+// uvToST и stToUV — квадратичное преобразование S2, выравнивающее
+// площади ячеек.
+func uvToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+func stToUV(s float64) float64 {
+	if s >= 0.5 {
+		return (4*s*s - 1) / 3
+	}
+	return (1 - 4*(1-s)*(1-s)) / 3
+}
+
+func stToIJ(s float64) int {
+	i := int(math.Floor(s * float64(s2MaxSize)))
+	if i < 0 {
+		return 0
+	}
+	if i >= s2MaxSize {
+		return s2MaxSize - 1
+	}
+	return i
+}
+
+// s2CellID обходит кривую Гильберта от грани до уровня level.
+func s2CellID(face, i, j, level int) uint64 {
+	id := uint64(face)
+	orientation := face & s2SwapMask
+	for k := MaxS2Level - 1; k >= MaxS2Level-level; k-- {
+		ij := (i>>k&1)<<1 | j>>k&1
+		pos := s2IJToPos[orientation][ij]
+		id = id<<2 | uint64(pos)
+		orientation ^= s2PosToOrientation[pos]
+	}
+	// младший единичный бит отмечает уровень
+	id = id<<1 | 1
+	return id << (2 * (MaxS2Level - level))
+}
+
+func s2Token(id uint64) string {
+	if id == 0 {
+		return "X"
+	}
+	return strings.TrimRight(fmt.Sprintf("%016x", id), "0")
+}
+
+// parseS2Token разбирает токен в грань, координаты угла ячейки и уровень.
+func parseS2Token(token string) (face, i, j, level int, err error) {
+	if token == "" || len(token) > 16 {
+		return 0, 0, 0, 0, fmt.Errorf("s2 token must have 1 to 16 hex digits")
+	}
+	id, parseErr := strconv.ParseUint(token+strings.Repeat("0", 16-len(token)), 16, 64)
+	if parseErr != nil {
+		return 0, 0, 0, 0, fmt.Errorf("s2 token %q is not hexadecimal", token)
+	}
+	zeros := bits.TrailingZeros64(id)
+	face = int(id >> 61)
+	if id == 0 || zeros%2 == 1 || face > 5 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid s2 cell token %q", token)
+	}
+	level = MaxS2Level - zeros/2
+
+	orientation := face & s2SwapMask
+	for k := 0; k < level; k++ {
+		pos := int(id >> (59 - 2*k) & 3)
+		ij := s2PosToIJ[orientation][pos]
+		i |= (ij >> 1) << (MaxS2Level - 1 - k)
+		j |= (ij & 1) << (MaxS2Level - 1 - k)
+		orientation ^= s2PosToOrientation[pos]
+	}
+	return face, i, j, level, nil
+}
+
+// s2CellCenter возвращает центр ячейки на сфере.
+func s2CellCenter(face, i, j, level int) models.GeoPoint {
+	half := float64(s2MaxSize>>level) / 2
+	s := (float64(i) + half) / float64(s2MaxSize)
+	t := (float64(j) + half) / float64(s2MaxSize)
+	lat, lon := xyzToLatLon(faceUVToXYZ(face, stToUV(s), stToUV(t)))
+	return models.GeoPoint{Lat: lat, Lon: lon}
+}
+
+// s2CellBounds считает границы ячейки по точкам на ее ребрах. Полюс внутри
+// ячейки расширяет границы до всех долгот.
+func s2CellBounds(face, i, j, level int) models.BoundingBox {
+	size := s2MaxSize >> level
+	uLow, uHigh := stToUV(float64(i)/float64(s2MaxSize)), stToUV(float64(i+size)/float64(s2MaxSize))
+	vLow, vHigh := stToUV(float64(j)/float64(s2MaxSize)), stToUV(float64(j+size)/float64(s2MaxSize))
+
+	var lats, lons []float64
+	for k := 0; k <= s2EdgeSamples; k++ {
+		f := float64(k) / float64(s2EdgeSamples)
+		u := uLow + (uHigh-uLow)*f
+		v := vLow + (vHigh-vLow)*f
+		for _, uv := range [4][2]float64{{u, vLow}, {u, vHigh}, {uLow, v}, {uHigh, v}} {
+			lat, lon := xyzToLatLon(faceUVToXYZ(face, uv[0], uv[1]))
+			lats = append(lats, lat)
+			lons = append(lons, lon)
+		}
+	}
+
+	box := models.BoundingBox{South: 90, North: -90}
+	for _, lat := range lats {
+		box.South = math.Min(box.South, lat)
+		box.North = math.Max(box.North, lat)
+	}
+	// полюс — центр грани 2 или 5, точка u = v = 0
+	if (face == 2 || face == 5) && uLow <= 0 && uHigh >= 0 && vLow <= 0 && vHigh >= 0 {
+		if face == 2 {
+			box.North = 90
+		} else {
+			box.South = -90
+		}
+		box.West, box.East = -180, 180
+		return box
+	}
+	box.West, box.East = longitudeRange(lons)
+	return box
+}
+
+// longitudeRange возвращает наименьший интервал долгот, содержащий все
+// значения; если он проходит через антимеридиан, west > east.
+func longitudeRange(lons []float64) (float64, float64) {
+	west, east := 180.0, -180.0
+	for _, lon := range lons {
+		west = math.Min(west, lon)
+		east = math.Max(east, lon)
+	}
+	if east-west <= 180 {
+		return west, east
+	}
+	// через антимеридиан: отсчитываем долготы от 0 до 360
+	west, east = 360, 0
+	for _, lon := range lons {
+		if lon < 0 {
+			lon += 360
+		}
+		west = math.Min(west, lon)
+		east = math.Max(east, lon)
+	}
+	if east > 180 {
+		east -= 360
+	}
+	return west, east
+}
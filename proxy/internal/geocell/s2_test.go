@@ -0,0 +1,109 @@
+package geocell
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEncodeS2(t *testing.T) {
+	testCases := []struct {
+		lat, lon float64
+		level    int
+		expected string
+	}{
+		{0, 0, 0, "1"},
+		{0, 90, 0, "3"},
+		{90, 0, 0, "5"},
+		{0, 180, 0, "7"},
+		{0, -90, 0, "9"},
+		{-90, 0, 0, "b"},
+		{0, 0, 30, "1000000000000001"},
+	}
+
+	for _, tc := range testCases {
+		got, err := EncodeS2(tc.lat, tc.lon, tc.level)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.expected {
+			t.Errorf("EncodeS2(%g, %g, %d): expected %s, got %s", tc.lat, tc.lon, tc.level, tc.expected, got)
+		}
+	}
+
+	// ячейки Нью-Йорка начинаются с 89c25
+	token, err := EncodeS2(40.7128, -74.0060, 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(token, "89c25") {
+		t.Errorf("unexpected token %s", token)
+	}
+
+	if _, err := EncodeS2(0, 0, 31); err == nil {
+		t.Error("expected error for level 31")
+	}
+	if _, err := EncodeS2(0, 181, 10); err == nil {
+		t.Error("expected error for longitude out of range")
+	}
+}
+
+func TestDecodeS2(t *testing.T) {
+	for level := 0; level <= MaxS2Level; level++ {
+		token, err := EncodeS2(55.7558, 37.6176, level)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		box, err := DecodeS2(token)
+		if err != nil {
+			t.Fatalf("level %d: unexpected error: %v", level, err)
+		}
+		if box.South > 55.7558 || box.North < 55.7558 || box.West > 37.6176 || box.East < 37.6176 {
+			t.Errorf("level %d: cell %+v does not contain the point", level, box)
+		}
+		if got, _ := S2Level(token); got != level {
+			t.Errorf("expected level %d, got %d", level, got)
+		}
+	}
+
+	box, err := DecodeS2("5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if box.North != 90 || box.West != -180 || box.East != 180 || math.Abs(box.South-35.26) > 0.01 {
+		t.Errorf("unexpected north face bounds %+v", box)
+	}
+
+	for _, token := range []string{"", "X", "zz", "c", "3000000000000002", "10000000000000001"} {
+		if _, err := DecodeS2(token); err == nil {
+			t.Errorf("%q: expected error", token)
+		}
+	}
+}
+
+func TestNeighboursS2(t *testing.T) {
+	testCases := []struct {
+		token    string
+		expected int
+	}{
+		{"1", 4},  // грань граничит с четырьмя гранями
+		{"1c", 7}, // ячейка в углу куба
+		{"4b59", 8},
+	}
+
+	for _, tc := range testCases {
+		neighbours, err := NeighboursS2(tc.token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(neighbours) != tc.expected {
+			t.Errorf("%s: expected %d neighbours, got %v", tc.token, tc.expected, neighbours)
+		}
+		level, _ := S2Level(tc.token)
+		for _, n := range neighbours {
+			if l, _ := S2Level(n); l != level {
+				t.Errorf("%s: neighbour %s has level %d", tc.token, n, l)
+			}
+		}
+	}
+}
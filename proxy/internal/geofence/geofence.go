@@ -0,0 +1,429 @@
+package geofence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/history"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/spatial"
+	"geo-controller/proxy/internal/storage"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Ограничения на геозоны одного пользователя.
+const (
+	maxGeofences  = 1000
+	maxNameLength = 200
+)
+
+var (
+	ErrNotFound = errors.New("geofence not found")
+	ErrInvalid  = errors.New("invalid geofence")
+	ErrStorage  = errors.New("geofence storage failed")
+)
+
+// fence — геозона с разобранной геометрией.
+type fence struct {
+	models.Geofence
+	geometry *geometry.Geometry
+	area     float64
+}
+
+type part struct {
+	fence   *fence
+	polygon [][]geometry.Point
+}
+
+// storeFile — формат файла хранилища: геозоны по именам пользователей.
+type storeFile struct {
+	Users map[string][]models.Geofence `json:"users"`
+}
+
+// Store хранит геозоны пользователей и находит содержащие точку. Для каждого
+// пользователя строится R-дерево полигонов. Каждое изменение записывается
+// в файл целиком до того, как становится видно в памяти, поэтому при ошибке
+// записи хранилище остается прежним. Каждое изменение геозоны записывается
+// в журнал изменений. Безопасен для параллельного использования.
+type Store struct {
+	mu      sync.RWMutex
+	path    string
+	fences  map[string]map[string]*fence
+	trees   map[string]*spatial.RTree[*part]
+	history *history.Log
+	now     func() time.Time
+}
+
+type Option func(*Store)
+
+// WithHistory задает журнал изменений. По умолчанию журнал хранится только
+// в памяти.
+func WithHistory(log *history.Log) Option {
+	return func(s *Store) {
+		s.history = log
+	}
+}
+
+// Open загружает хранилище из файла JSON; отсутствующий файл будет создан
+// при первом изменении. Пустой path — хранилище только в памяти.
+func Open(path string, options ...Option) (*Store, error) {
+	s := &Store{
+		path:   path,
+		fences: map[string]map[string]*fence{},
+		trees:  map[string]*spatial.RTree[*part]{},
+		now:    time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.history == nil {
+		s.history, _ = history.Open("")
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	var file storeFile
+	if _, err := storage.ReadJSON(path, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for owner, geofences := range file.Users {
+		fences := make(map[string]*fence, len(geofences))
+		for _, geofence := range geofences {
+			f, err := newFence(geofence)
+			if err != nil {
+				return nil, fmt.Errorf("%s: user %q, geofence %s: %w", path, owner, geofence.ID, err)
+			}
+			fences[f.ID] = f
+		}
+		s.set(owner, fences)
+	}
+	return s, nil
+}
+
+func newFence(geofence models.Geofence) (*fence, error) {
+	if strings.TrimSpace(geofence.Name) == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalid)
+	}
+	if utf8.RuneCountInString(geofence.Name) > maxNameLength {
+		return nil, fmt.Errorf("%w: name is longer than %d characters", ErrInvalid, maxNameLength)
+	}
+	if geofence.Geometry.Type != geometry.TypePolygon && geofence.Geometry.Type != geometry.TypeMultiPolygon {
+		return nil, fmt.Errorf("%w: geometry must be Polygon or MultiPolygon, got %q", ErrInvalid, geofence.Geometry.Type)
+	}
+	g, err := geometry.Decode(geofence.Geometry)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	f := &fence{Geofence: geofence, geometry: g, area: geometry.Area(g)}
+	if f.area == 0 {
+		return nil, fmt.Errorf("%w: geometry has zero area", ErrInvalid)
+	}
+	f.Geometry = g.Encode()
+	if geofence.Properties != nil {
+		f.Properties = make(map[string]string, len(geofence.Properties))
+		for key, value := range geofence.Properties {
+			f.Properties[key] = value
+		}
+	}
+	return f, nil
+}
+
+// List возвращает геозоны пользователя по названию.
+func (s *Store) List(owner string) []models.Geofence {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Geofence, 0, len(s.fences[owner]))
+	for _, f := range s.fences[owner] {
+		result = append(result, f.Geofence)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+func (s *Store) Get(owner, id string) (*models.Geofence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.fences[owner][id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	geofence := f.Geofence
+	return &geofence, nil
+}
+
+func (s *Store) Create(owner string, request models.GeofenceRequest) (*models.Geofence, error) {
+	now := s.now().UTC()
+	f, err := newFence(models.Geofence{
+		ID:         storage.NewID(),
+		Name:       request.Name,
+		Properties: request.Properties,
+		Geometry:   request.Geometry,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.fences[owner]) >= maxGeofences {
+		return nil, fmt.Errorf("%w: at most %d geofences per user", ErrInvalid, maxGeofences)
+	}
+	if err := s.commit(owner, 0, func(fences map[string]*fence) { fences[f.ID] = f }); err != nil {
+		return nil, err
+	}
+	geofence := f.Geofence
+	return &geofence, nil
+}
+
+// Update заменяет название, атрибуты и геометрию геозоны.
+func (s *Store) Update(owner, id string, request models.GeofenceRequest) (*models.Geofence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.fences[owner][id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	f, err := newFence(models.Geofence{
+		ID:         id,
+		Name:       request.Name,
+		Properties: request.Properties,
+		Geometry:   request.Geometry,
+		CreatedAt:  current.CreatedAt,
+		UpdatedAt:  s.now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.commit(owner, 0, func(fences map[string]*fence) { fences[id] = f }); err != nil {
+		return nil, err
+	}
+	geofence := f.Geofence
+	return &geofence, nil
+}
+
+func (s *Store) Delete(owner, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.fences[owner][id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return s.commit(owner, 0, func(fences map[string]*fence) { delete(fences, id) })
+}
+
+// Revisions возвращает ревизии геозоны, в том числе удаленной.
+func (s *Store) Revisions(owner, id string) ([]models.Revision, error) {
+	revisions, err := s.history.Revisions(historyKey(owner, id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return revisions, nil
+}
+
+// Revision возвращает ревизию геозоны с ее состоянием.
+func (s *Store) Revision(owner, id string, number int) (*models.Revision, error) {
+	revision, err := s.history.Revision(historyKey(owner, id), number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s, revision %d", ErrNotFound, id, number)
+	}
+	return revision, nil
+}
+
+// At возвращает геозону в том виде, какой она была в момент at.
+func (s *Store) At(owner, id string, at time.Time) (*models.Geofence, error) {
+	revision, err := s.history.At(historyKey(owner, id), at)
+	if err != nil || revision.Data == nil {
+		return nil, fmt.Errorf("%w: %s at %s", ErrNotFound, id, at.Format(time.RFC3339))
+	}
+	var geofence models.Geofence
+	if err := json.Unmarshal(revision.Data, &geofence); err != nil {
+		return nil, fmt.Errorf("%w: revision %d: %v", ErrStorage, revision.Revision, err)
+	}
+	return &geofence, nil
+}
+
+// Revert возвращает геозону к состоянию ревизии number: восстанавливает
+// удаленную, заменяет текущую или удаляет, если ревизия — удаление.
+// Восстановленная геозона сохраняет время создания из ревизии. Возврат
+// записывается в журнал новой ревизией; для удаления результат nil.
+func (s *Store) Revert(owner, id string, number int) (*models.Geofence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revision, err := s.history.Revision(historyKey(owner, id), number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s, revision %d", ErrNotFound, id, number)
+	}
+	current, exists := s.fences[owner][id]
+	if revision.Data == nil {
+		if !exists {
+			return nil, fmt.Errorf("%w: geofence %s is already deleted", ErrInvalid, id)
+		}
+		return nil, s.commit(owner, number, func(fences map[string]*fence) { delete(fences, id) })
+	}
+
+	var geofence models.Geofence
+	if err := json.Unmarshal(revision.Data, &geofence); err != nil {
+		return nil, fmt.Errorf("%w: revision %d: %v", ErrStorage, number, err)
+	}
+	geofence.ID, geofence.UpdatedAt = id, s.now().UTC()
+	if exists {
+		geofence.CreatedAt = current.CreatedAt
+	} else if len(s.fences[owner]) >= maxGeofences {
+		return nil, fmt.Errorf("%w: at most %d geofences per user", ErrInvalid, maxGeofences)
+	}
+	f, err := newFence(geofence)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.commit(owner, number, func(fences map[string]*fence) { fences[id] = f }); err != nil {
+		return nil, err
+	}
+	result := f.Geofence
+	return &result, nil
+}
+
+// Containing возвращает геозоны пользователя, содержащие точку, от меньшей
+// по площади к большей.
+func (s *Store) Containing(owner string, lat, lon float64) []models.Geofence {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tree := s.trees[owner]
+	if tree == nil {
+		return []models.Geofence{}
+	}
+	point := geometry.Point{lon, lat}
+	found := map[string]*fence{}
+	tree.Search(spatial.PointRect(lon, lat), func(p *part) bool {
+		if found[p.fence.ID] == nil && geometry.PolygonContains(p.polygon, point) {
+			found[p.fence.ID] = p.fence
+		}
+		return true
+	})
+
+	fences := make([]*fence, 0, len(found))
+	for _, f := range found {
+		fences = append(fences, f)
+	}
+	sort.Slice(fences, func(i, j int) bool {
+		if fences[i].area != fences[j].area {
+			return fences[i].area < fences[j].area
+		}
+		return fences[i].ID < fences[j].ID
+	})
+	result := make([]models.Geofence, len(fences))
+	for i, f := range fences {
+		result[i] = f.Geofence
+	}
+	return result
+}
+
+// commit применяет изменение к копии геозон пользователя, записывает файл
+// и журнал и только после успешной записи заменяет геозоны и индекс
+// в памяти. Если журнал записать не удалось, файл возвращается к прежнему
+// состоянию. revertedTo — ревизия, к которой возвращается геозона.
+// Вызывается под s.mu.
+func (s *Store) commit(owner string, revertedTo int, change func(map[string]*fence)) error {
+	fences := make(map[string]*fence, len(s.fences[owner])+1)
+	for id, f := range s.fences[owner] {
+		fences[id] = f
+	}
+	change(fences)
+
+	if err := s.write(owner, fences); err != nil {
+		return fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	if err := s.history.Record(owner, s.now().UTC(), s.changes(owner, fences, revertedTo)); err != nil {
+		s.write(owner, s.fences[owner])
+		return fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	s.set(owner, fences)
+	return nil
+}
+
+// write записывает файл хранилища с геозонами пользователя owner.
+func (s *Store) write(owner string, fences map[string]*fence) error {
+	if s.path == "" {
+		return nil
+	}
+	file := storeFile{Users: map[string][]models.Geofence{}}
+	for user, current := range s.fences {
+		if user != owner {
+			file.Users[user] = geofences(current)
+		}
+	}
+	if len(fences) > 0 {
+		file.Users[owner] = geofences(fences)
+	}
+	return storage.WriteJSON(s.path, file)
+}
+
+// changes сравнивает новые геозоны пользователя с текущими и возвращает
+// изменения для журнала.
+func (s *Store) changes(owner string, fences map[string]*fence, revertedTo int) []history.Change {
+	var changes []history.Change
+	current := s.fences[owner]
+	for id, f := range fences {
+		old, exists := current[id]
+		switch {
+		case !exists:
+			changes = append(changes, history.Change{Key: historyKey(owner, id), Action: models.RevisionCreate, Data: f.Geofence, RevertedTo: revertedTo})
+		case old != f:
+			changes = append(changes, history.Change{Key: historyKey(owner, id), Action: models.RevisionUpdate, Data: f.Geofence, RevertedTo: revertedTo})
+		}
+	}
+	for id := range current {
+		if _, exists := fences[id]; !exists {
+			changes = append(changes, history.Change{Key: historyKey(owner, id), Action: models.RevisionDelete, RevertedTo: revertedTo})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+func historyKey(owner, id string) string {
+	return "geofences/" + owner + "/" + id
+}
+
+// set заменяет геозоны пользователя и перестраивает его индекс.
+func (s *Store) set(owner string, fences map[string]*fence) {
+	if len(fences) == 0 {
+		delete(s.fences, owner)
+		delete(s.trees, owner)
+		return
+	}
+	tree := spatial.NewRTree[*part]()
+	for _, f := range fences {
+		for _, polygon := range f.geometry.PolygonParts() {
+			tree.Insert(geometry.RingRect(polygon[0]), &part{fence: f, polygon: polygon})
+		}
+	}
+	s.fences[owner] = fences
+	s.trees[owner] = tree
+}
+
+func geofences(fences map[string]*fence) []models.Geofence {
+	result := make([]models.Geofence, 0, len(fences))
+	for _, f := range fences {
+		result = append(result, f.Geofence)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
@@ -0,0 +1,256 @@
+package geofence
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/history"
+	"geo-controller/proxy/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func polygon(coordinates string) models.Geometry {
+	return models.Geometry{Type: "Polygon", Coordinates: []byte(coordinates)}
+}
+
+// Москва целиком и Садовое кольцо внутри нее, грубо.
+var (
+	moscow      = polygon(`[[[37.3,55.5],[37.9,55.5],[37.9,56],[37.3,56],[37.3,55.5]]]`)
+	gardenRing  = polygon(`[[[37.57,55.73],[37.66,55.73],[37.66,55.78],[37.57,55.78],[37.57,55.73]]]`)
+	petersburg  = polygon(`[[[30.1,59.8],[30.6,59.8],[30.6,60.1],[30.1,60.1],[30.1,59.8]]]`)
+	kremlinHole = polygon(`[[[37.3,55.5],[37.9,55.5],[37.9,56],[37.3,56],[37.3,55.5]],[[37.61,55.74],[37.63,55.74],[37.63,55.76],[37.61,55.76],[37.61,55.74]]]`)
+)
+
+func TestStore_CRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geofences.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	city, err := store.Create("alice", models.GeofenceRequest{Name: "Москва", Properties: map[string]string{"fee": "300"}, Geometry: moscow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	center, err := store.Create("alice", models.GeofenceRequest{Name: "Центр", Geometry: gardenRing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create("bob", models.GeofenceRequest{Name: "Петербург", Geometry: petersburg}); err != nil {
+		t.Fatal(err)
+	}
+	if city.ID == "" || city.ID == center.ID || city.CreatedAt.IsZero() || city.Properties["fee"] != "300" {
+		t.Errorf("unexpected geofence %+v", city)
+	}
+
+	list := store.List("alice")
+	if len(list) != 2 || list[0].Name != "Москва" || list[1].Name != "Центр" {
+		t.Errorf("unexpected list %+v", list)
+	}
+	if _, err := store.Get("bob", city.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("other user's geofence must not be visible, got %v", err)
+	}
+
+	updated, err := store.Update("alice", city.ID, models.GeofenceRequest{Name: "Москва без центра", Geometry: kremlinHole})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.CreatedAt != city.CreatedAt || updated.Name != "Москва без центра" || updated.Properties != nil {
+		t.Errorf("unexpected update %+v", updated)
+	}
+
+	// хранилище переживает перезапуск
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := reopened.Get("alice", city.ID); err != nil || got.Name != "Москва без центра" {
+		t.Errorf("expected persisted update, got %+v (%v)", got, err)
+	}
+	if len(reopened.List("bob")) != 1 {
+		t.Errorf("expected bob's geofence after reopen")
+	}
+
+	if err := reopened.Delete("alice", center.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Delete("alice", center.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reopened.Update("bob", city.ID, models.GeofenceRequest{Name: "x", Geometry: moscow}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if list := reopened.List("alice"); len(list) != 1 {
+		t.Errorf("expected 1 geofence after delete, got %d", len(list))
+	}
+}
+
+func TestStore_Containing(t *testing.T) {
+	store, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, request := range []models.GeofenceRequest{
+		{Name: "Москва", Geometry: moscow},
+		{Name: "Центр", Geometry: gardenRing},
+		{Name: "Москва без Кремля", Geometry: kremlinHole},
+	} {
+		if _, err := store.Create("alice", request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.Create("bob", models.GeofenceRequest{Name: "Москва", Geometry: moscow}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		lat, lon float64
+		expected []string
+	}{
+		// Кремль — в дыре третьей зоны
+		{55.75, 37.62, []string{"Центр", "Москва"}},
+		{55.76, 37.58, []string{"Центр", "Москва без Кремля", "Москва"}},
+		{55.6, 37.4, []string{"Москва без Кремля", "Москва"}},
+		{59.93, 30.36, nil},
+	}
+	for _, tc := range testCases {
+		found := store.Containing("alice", tc.lat, tc.lon)
+		if len(found) != len(tc.expected) {
+			t.Errorf("%g, %g: expected %v, got %d geofences", tc.lat, tc.lon, tc.expected, len(found))
+			continue
+		}
+		for i, name := range tc.expected {
+			if found[i].Name != name {
+				t.Errorf("%g, %g: expected %v, got %q at %d", tc.lat, tc.lon, tc.expected, found[i].Name, i)
+			}
+		}
+	}
+	if found := store.Containing("carol", 55.75, 37.62); len(found) != 0 {
+		t.Errorf("expected no geofences for another user, got %d", len(found))
+	}
+}
+
+func TestStore_Invalid(t *testing.T) {
+	store, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, request := range []models.GeofenceRequest{
+		{Name: "", Geometry: moscow},
+		{Name: "Точка", Geometry: models.Geometry{Type: "Point", Coordinates: []byte(`[37.6,55.7]`)}},
+		{Name: "Линия", Geometry: polygon(`[[[37,55],[38,55],[37,55],[37,55]]]`)},
+		{Name: "Вне диапазона", Geometry: polygon(`[[[37,95],[38,95],[38,96],[37,95]]]`)},
+	} {
+		if _, err := store.Create("alice", request); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", request.Name, err)
+		}
+	}
+}
+
+func TestStore_History(t *testing.T) {
+	dir := t.TempDir()
+	log, err := history.Open(filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := Open(filepath.Join(dir, "geofences.json"), WithHistory(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return clock }
+
+	created, err := store.Create("alice", models.GeofenceRequest{Name: "Москва", Geometry: moscow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(time.Hour)
+	if _, err := store.Update("alice", created.ID, models.GeofenceRequest{Name: "Центр", Geometry: gardenRing}); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(time.Hour)
+	if err := store.Delete("alice", created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := store.Revisions("alice", created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 || revisions[0].Action != models.RevisionCreate || revisions[2].Action != models.RevisionDelete || revisions[1].Author != "alice" {
+		t.Errorf("unexpected revisions: %+v", revisions)
+	}
+	if _, err := store.Revisions("bob", created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("other user's history must not be visible, got %v", err)
+	}
+
+	past, err := store.At("alice", created.ID, clock.Add(-90*time.Minute))
+	if err != nil || past.Name != "Москва" {
+		t.Errorf("expected first version, got %+v (%v)", past, err)
+	}
+	if _, err := store.At("alice", created.ID, clock); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+
+	clock = clock.Add(time.Hour)
+	restored, err := store.Revert("alice", created.ID, 2)
+	if err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if restored.Name != "Центр" || !restored.CreatedAt.Equal(created.CreatedAt) || !restored.UpdatedAt.Equal(clock) {
+		t.Errorf("unexpected restored geofence: %+v", restored)
+	}
+	if got, err := store.Get("alice", created.ID); err != nil || got.Name != "Центр" {
+		t.Errorf("expected restored geofence, got %+v (%v)", got, err)
+	}
+	revision, err := store.Revision("alice", created.ID, 4)
+	if err != nil || revision.Action != models.RevisionCreate || revision.RevertedTo != 2 {
+		t.Errorf("expected revert to be recorded, got %+v (%v)", revision, err)
+	}
+
+	if restored, err := store.Revert("alice", created.ID, 3); err != nil || restored != nil {
+		t.Errorf("expected revert to deletion, got %+v (%v)", restored, err)
+	}
+	if _, err := store.Revert("alice", created.ID, 3); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for already deleted geofence, got %v", err)
+	}
+	if _, err := store.Revert("alice", created.ID, 9); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing revision, got %v", err)
+	}
+}
+
+func TestStore_WriteFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	store, err := Open(filepath.Join(dir, "geofences.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create("alice", models.GeofenceRequest{Name: "Москва", Geometry: moscow}); err != nil {
+		t.Fatal(err)
+	}
+
+	// файл на месте каталога: изменение не должно попасть в память
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create("alice", models.GeofenceRequest{Name: "Центр", Geometry: gardenRing}); !errors.Is(err, ErrStorage) {
+		t.Fatalf("expected ErrStorage, got %v", err)
+	}
+	if list := store.List("alice"); len(list) != 1 {
+		t.Errorf("failed change must not be applied, got %d geofences", len(list))
+	}
+}
+
+func TestOpen_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geofences.json")
+	if err := os.WriteFile(path, []byte(`{"users":{"alice":[{"id":"1","name":"Точка","geometry":{"type":"Point","coordinates":[0,0]}}]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("expected error for invalid stored geofence")
+	}
+}
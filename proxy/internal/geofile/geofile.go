@@ -0,0 +1,36 @@
+package geofile
+
+import (
+	"fmt"
+	"geo-controller/proxy/internal/geometry"
+	"path/filepath"
+	"strings"
+)
+
+// Feature — объект набора данных: геометрия в долготе и широте WGS 84
+// и свойства, приведенные к строкам.
+type Feature struct {
+	Properties map[string]string
+	Geometry   *geometry.Geometry
+}
+
+// Read читает объекты из файла GeoJSON (.geojson, .json) или шейп-файла (.shp).
+func Read(path string) ([]Feature, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".geojson", ".json":
+		return ReadGeoJSON(path)
+	case ".shp":
+		return ReadShapefile(path)
+	}
+	return nil, fmt.Errorf("unsupported file format %q", filepath.Ext(path))
+}
+
+// Property возвращает первое непустое значение из свойств keys.
+func (f Feature) Property(keys ...string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(f.Properties[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
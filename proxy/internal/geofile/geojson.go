@@ -0,0 +1,76 @@
+package geofile
+
+import (
+	"encoding/json"
+	"fmt"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/models"
+	"os"
+	"strconv"
+)
+
+type featureCollection struct {
+	Type     string           `json:"type"`
+	Features []geojsonFeature `json:"features"`
+}
+
+type geojsonFeature struct {
+	Properties map[string]interface{} `json:"properties"`
+	Geometry   *models.Geometry       `json:"geometry"`
+}
+
+// ReadGeoJSON читает объекты FeatureCollection GeoJSON. Объекты без
+// геометрии пропускаются.
+func ReadGeoJSON(path string) ([]Feature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGeoJSON(data)
+}
+
+// ParseGeoJSON разбирает FeatureCollection GeoJSON, например загруженный
+// в запросе.
+func ParseGeoJSON(data []byte) ([]Feature, error) {
+	var collection featureCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, err
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("expected FeatureCollection, got %q", collection.Type)
+	}
+
+	var features []Feature
+	for i, f := range collection.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		g, err := geometry.DecodeDataset(*f.Geometry)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		features = append(features, Feature{Properties: stringProperties(f.Properties), Geometry: g})
+	}
+	return features, nil
+}
+
+// stringProperties приводит значения свойств к строкам; вложенные объекты
+// сохраняются как JSON.
+func stringProperties(properties map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(properties))
+	for key, value := range properties {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			result[key] = v
+		case float64:
+			result[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			result[key] = strconv.FormatBool(v)
+		default:
+			encoded, _ := json.Marshal(v)
+			result[key] = string(encoded)
+		}
+	}
+	return result
+}
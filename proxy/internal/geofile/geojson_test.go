@@ -0,0 +1,39 @@
+package geofile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRead_GeoJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zones.geojson")
+	content := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"tzid":"Europe/Moscow","admin_level":4,"capital":true,"tags":{"a":1},"empty":null},
+		 "geometry":{"type":"Polygon","coordinates":[[[37,55],[38,55],[38,56],[37,55]]]}},
+		{"type":"Feature","properties":{"name":"без геометрии"},"geometry":null}]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	features, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(features) != 1 {
+		t.Fatalf("expected 1 feature, got %d", len(features))
+	}
+	expected := map[string]string{"tzid": "Europe/Moscow", "admin_level": "4", "capital": "true", "tags": `{"a":1}`}
+	for key, value := range expected {
+		if got := features[0].Properties[key]; got != value {
+			t.Errorf("%s: expected %q, got %q", key, value, got)
+		}
+	}
+	if _, ok := features[0].Properties["empty"]; ok {
+		t.Error("null property must be skipped")
+	}
+
+	if _, err := Read(filepath.Join(t.TempDir(), "zones.kml")); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
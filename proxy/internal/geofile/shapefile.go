@@ -0,0 +1,259 @@
+package geofile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/geometry"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Формат ESRI Shapefile, см. "ESRI Shapefile Technical Description", 1998.
+const (
+	shpFileCode     = 9994
+	shpHeaderLength = 100
+	shpNullShape    = 0
+	shpPolygon      = 5
+	shpPolygonZ     = 15
+	shpPolygonM     = 25
+	dbfTerminator   = 0x0D
+	dbfDeleted      = '*'
+	// dbfRussianLDID — код языкового драйвера dBase для Windows-1251.
+	dbfRussianLDID = 0xC9
+)
+
+// ReadShapefile читает полигоны из .shp и атрибуты из .dbf с тем же именем.
+// Координаты должны быть в долготе и широте WGS 84. Кодировка атрибутов
+// берется из .cpg или кода языкового драйвера, иначе определяется
+// по содержимому: UTF-8 или Windows-1251.
+func ReadShapefile(path string) ([]Feature, error) {
+	geometries, err := readShp(path)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	records, err := readDbf(base+".dbf", readCodePage(base+".cpg"))
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != len(geometries) {
+		return nil, fmt.Errorf("shapefile has %d shapes but %d attribute records", len(geometries), len(records))
+	}
+
+	var features []Feature
+	for i, g := range geometries {
+		if g == nil || records[i] == nil {
+			continue
+		}
+		features = append(features, Feature{Properties: records[i], Geometry: g})
+	}
+	return features, nil
+}
+
+// readShp возвращает геометрии записей по порядку; пустым фигурам
+// соответствует nil.
+func readShp(path string) ([]*geometry.Geometry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < shpHeaderLength || binary.BigEndian.Uint32(data[0:]) != shpFileCode {
+		return nil, errors.New("not a shapefile")
+	}
+	switch shapeType := binary.LittleEndian.Uint32(data[32:]); shapeType {
+	case shpPolygon, shpPolygonZ, shpPolygonM:
+	default:
+		return nil, fmt.Errorf("shape type %d is not a polygon", shapeType)
+	}
+
+	var geometries []*geometry.Geometry
+	for offset := shpHeaderLength; offset+8 <= len(data); {
+		// длины в заголовках — в 16-битных словах
+		length := int(binary.BigEndian.Uint32(data[offset+4:])) * 2
+		content := data[offset+8:]
+		if length < 4 || length > len(content) {
+			return nil, fmt.Errorf("record %d is truncated", len(geometries)+1)
+		}
+		g, err := readPolygon(content[:length])
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(geometries)+1, err)
+		}
+		geometries = append(geometries, g)
+		offset += 8 + length
+	}
+	return geometries, nil
+}
+
+func readPolygon(content []byte) (*geometry.Geometry, error) {
+	if binary.LittleEndian.Uint32(content) == shpNullShape {
+		return nil, nil
+	}
+	if len(content) < 44 {
+		return nil, errors.New("polygon record is truncated")
+	}
+	numParts := int(binary.LittleEndian.Uint32(content[36:]))
+	numPoints := int(binary.LittleEndian.Uint32(content[40:]))
+	pointsOffset := 44 + 4*numParts
+	if numParts <= 0 || numPoints <= 0 || pointsOffset+16*numPoints > len(content) {
+		return nil, errors.New("polygon record is truncated")
+	}
+
+	points := make([]geometry.Point, numPoints)
+	for i := range points {
+		x := math.Float64frombits(binary.LittleEndian.Uint64(content[pointsOffset+16*i:]))
+		y := math.Float64frombits(binary.LittleEndian.Uint64(content[pointsOffset+16*i+8:]))
+		if x < -180 || x > 180 || y < -90 || y > 90 {
+			return nil, fmt.Errorf("coordinates %g, %g are not WGS 84 longitude and latitude", x, y)
+		}
+		points[i] = geometry.Point{x, y}
+	}
+
+	var rings [][]geometry.Point
+	for i := 0; i < numParts; i++ {
+		start := int(binary.LittleEndian.Uint32(content[44+4*i:]))
+		end := numPoints
+		if i+1 < numParts {
+			end = int(binary.LittleEndian.Uint32(content[44+4*(i+1):]))
+		}
+		if start < 0 || start > end || end > numPoints {
+			return nil, errors.New("invalid polygon part index")
+		}
+		if ring := points[start:end]; len(ring) >= 4 {
+			rings = append(rings, ring)
+		}
+	}
+	return assemblePolygons(rings), nil
+}
+
+// assemblePolygons собирает полигоны из колец шейп-файла: внешние кольца
+// идут по часовой стрелке, дыры — против, и дыра относится к внешнему
+// кольцу, которое ее содержит.
+func assemblePolygons(rings [][]geometry.Point) *geometry.Geometry {
+	var polygons [][][]geometry.Point
+	var holes [][]geometry.Point
+	for _, ring := range rings {
+		if planarArea(ring) <= 0 {
+			polygons = append(polygons, [][]geometry.Point{ring})
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+	for _, hole := range holes {
+		owner := -1
+		for i, polygon := range polygons {
+			if geometry.PolygonContains(polygon[:1], hole[0]) {
+				owner = i
+				break
+			}
+		}
+		if owner < 0 {
+			// дыра вне внешних колец — кольцо с обратным обходом
+			polygons = append(polygons, [][]geometry.Point{hole})
+			continue
+		}
+		polygons[owner] = append(polygons[owner], hole)
+	}
+
+	if len(polygons) == 1 {
+		return &geometry.Geometry{Type: geometry.TypePolygon, Polygons: polygons}
+	}
+	return &geometry.Geometry{Type: geometry.TypeMultiPolygon, Polygons: polygons}
+}
+
+// planarArea — знаковая площадь кольца на плоскости, положительна против
+// часовой стрелки.
+func planarArea(ring []geometry.Point) float64 {
+	var sum float64
+	for i := 0; i < len(ring)-1; i++ {
+		sum += ring[i].Lon()*ring[i+1].Lat() - ring[i+1].Lon()*ring[i].Lat()
+	}
+	return sum / 2
+}
+
+func readCodePage(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimSpace(string(data)))
+}
+
+// readDbf читает атрибуты dBase; удаленным записям соответствует nil.
+func readDbf(path, codePage string) ([]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 32 {
+		return nil, errors.New("dbf header is truncated")
+	}
+	numRecords := int(binary.LittleEndian.Uint32(data[4:]))
+	headerLength := int(binary.LittleEndian.Uint16(data[8:]))
+	recordLength := int(binary.LittleEndian.Uint16(data[10:]))
+	cp1251 := data[29] == dbfRussianLDID || strings.Contains(codePage, "1251")
+	if strings.Contains(codePage, "UTF") {
+		cp1251 = false
+	}
+
+	type field struct {
+		name           string
+		offset, length int
+	}
+	var fields []field
+	offset := 1 // первый байт записи — отметка об удалении
+	for pos := 32; pos+32 <= headerLength && pos < len(data) && data[pos] != dbfTerminator; pos += 32 {
+		name := strings.TrimRight(string(data[pos:pos+11]), "\x00 ")
+		length := int(data[pos+16])
+		fields = append(fields, field{name: name, offset: offset, length: length})
+		offset += length
+	}
+	if headerLength+numRecords*recordLength > len(data) || offset > recordLength {
+		return nil, errors.New("dbf records are truncated")
+	}
+
+	records := make([]map[string]string, numRecords)
+	for i := range records {
+		record := data[headerLength+i*recordLength : headerLength+(i+1)*recordLength]
+		if record[0] == dbfDeleted {
+			continue
+		}
+		values := make(map[string]string, len(fields))
+		for _, f := range fields {
+			raw := record[f.offset : f.offset+f.length]
+			values[f.name] = strings.TrimSpace(decodeText(raw, cp1251))
+		}
+		records[i] = values
+	}
+	return records, nil
+}
+
+func decodeText(raw []byte, cp1251 bool) string {
+	if !cp1251 && utf8.Valid(raw) {
+		return strings.TrimRight(string(raw), "\x00")
+	}
+	var b strings.Builder
+	for _, c := range raw {
+		switch {
+		case c == 0:
+		case c < 0x80:
+			b.WriteByte(c)
+		case c >= 0xC0:
+			b.WriteRune(rune(c-0xC0) + 'А')
+		default:
+			b.WriteRune(cp1251High[c-0x80])
+		}
+	}
+	return b.String()
+}
+
+// cp1251High — символы Windows-1251 с кодами 0x80–0xBF; с 0xC0 идут А–я.
+var cp1251High = [64]rune{
+	'Ђ', 'Ѓ', '‚', 'ѓ', '„', '…', '†', '‡', '€', '‰', 'Љ', '‹', 'Њ', 'Ќ', 'Ћ', 'Џ',
+	'ђ', '‘', '’', '“', '”', '•', '–', '—', '\uFFFD', '™', 'љ', '›', 'њ', 'ќ', 'ћ', 'џ',
+	'\u00A0', 'Ў', 'ў', 'Ј', '¤', 'Ґ', '¦', '§', 'Ё', '©', 'Є', '«', '¬', '\u00AD', '®', 'Ї',
+	'°', '±', 'І', 'і', 'ґ', 'µ', '¶', '·', 'ё', '№', 'є', '»', 'ј', 'Ѕ', 'ѕ', 'ї',
+}
@@ -0,0 +1,137 @@
+package geofile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"geo-controller/proxy/internal/geometry"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeShapefile пишет полигоны и атрибуты; nil вместо колец — пустая фигура.
+func writeShapefile(t *testing.T, dir string, shapes [][][][2]float64, fields []string, records [][]string, ldid byte) string {
+	t.Helper()
+
+	var body bytes.Buffer
+	for i, rings := range shapes {
+		var content bytes.Buffer
+		if rings == nil {
+			binary.Write(&content, binary.LittleEndian, int32(shpNullShape))
+		} else {
+			numPoints := 0
+			for _, ring := range rings {
+				numPoints += len(ring)
+			}
+			binary.Write(&content, binary.LittleEndian, int32(shpPolygon))
+			binary.Write(&content, binary.LittleEndian, [4]float64{})
+			binary.Write(&content, binary.LittleEndian, int32(len(rings)))
+			binary.Write(&content, binary.LittleEndian, int32(numPoints))
+			start := 0
+			for _, ring := range rings {
+				binary.Write(&content, binary.LittleEndian, int32(start))
+				start += len(ring)
+			}
+			for _, ring := range rings {
+				binary.Write(&content, binary.LittleEndian, ring)
+			}
+		}
+		binary.Write(&body, binary.BigEndian, [2]int32{int32(i + 1), int32(content.Len() / 2)})
+		body.Write(content.Bytes())
+	}
+
+	header := make([]byte, shpHeaderLength)
+	binary.BigEndian.PutUint32(header[0:], shpFileCode)
+	binary.BigEndian.PutUint32(header[24:], uint32((shpHeaderLength+body.Len())/2))
+	binary.LittleEndian.PutUint32(header[28:], 1000)
+	binary.LittleEndian.PutUint32(header[32:], shpPolygon)
+	shp := filepath.Join(dir, "zones.shp")
+	if err := os.WriteFile(shp, append(header, body.Bytes()...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	const fieldLength = 32
+	recordLength := 1 + fieldLength*len(fields)
+	headerLength := 32 + 32*len(fields) + 1
+	dbf := make([]byte, 32, headerLength+recordLength*len(records)+1)
+	dbf[0] = 3
+	binary.LittleEndian.PutUint32(dbf[4:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(dbf[8:], uint16(headerLength))
+	binary.LittleEndian.PutUint16(dbf[10:], uint16(recordLength))
+	dbf[29] = ldid
+	for _, name := range fields {
+		descriptor := make([]byte, 32)
+		copy(descriptor, name)
+		descriptor[11] = 'C'
+		descriptor[16] = fieldLength
+		dbf = append(dbf, descriptor...)
+	}
+	dbf = append(dbf, dbfTerminator)
+	for _, record := range records {
+		dbf = append(dbf, ' ')
+		for _, value := range record {
+			field := bytes.Repeat([]byte{' '}, fieldLength)
+			copy(field, value)
+			dbf = append(dbf, field...)
+		}
+	}
+	dbf = append(dbf, 0x1A)
+	if err := os.WriteFile(filepath.Join(dir, "zones.dbf"), dbf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return shp
+}
+
+func TestReadShapefile(t *testing.T) {
+	// "Москва" в Windows-1251
+	moscow := string([]byte{0xCC, 0xEE, 0xF1, 0xEA, 0xE2, 0xE0})
+	// внешнее кольцо по часовой стрелке, дыра — против
+	outer := [][2]float64{{37, 55}, {37, 56}, {38, 56}, {38, 55}, {37, 55}}
+	hole := [][2]float64{{37.4, 55.4}, {37.6, 55.4}, {37.6, 55.6}, {37.4, 55.6}, {37.4, 55.4}}
+	island := [][2]float64{{39, 55}, {39, 56}, {40, 56}, {40, 55}, {39, 55}}
+
+	shp := writeShapefile(t, t.TempDir(),
+		[][][][2]float64{{outer, hole, island}, nil},
+		[]string{"NAME", "ADMIN_LVL", "OKTMO"},
+		[][]string{{moscow, "4", "45000000"}, {"", "6", ""}},
+		dbfRussianLDID)
+
+	features, err := ReadShapefile(shp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// пустая фигура пропускается
+	if len(features) != 1 {
+		t.Fatalf("expected 1 feature, got %d", len(features))
+	}
+	f := features[0]
+	if f.Property("NAME") != "Москва" || f.Property("ADMIN_LVL") != "4" || f.Property("OKTMO") != "45000000" {
+		t.Errorf("unexpected properties %+v", f.Properties)
+	}
+	if len(f.Geometry.Polygons) != 2 || len(f.Geometry.Polygons[0]) != 2 {
+		t.Fatalf("expected polygon with hole and island, got %+v", f.Geometry.Polygons)
+	}
+
+	for _, tc := range []struct {
+		lat, lon float64
+		expected bool
+	}{
+		{55.2, 37.2, true},
+		{55.5, 37.5, false},
+		{55.5, 39.5, true},
+		{55.5, 38.5, false},
+	} {
+		if got := geometry.Contains(f.Geometry, geometry.Point{tc.lon, tc.lat}); got != tc.expected {
+			t.Errorf("%g, %g: expected %v, got %v", tc.lat, tc.lon, tc.expected, got)
+		}
+	}
+}
+
+func TestReadShapefile_Projected(t *testing.T) {
+	ring := [][2]float64{{400000, 6100000}, {400000, 6200000}, {500000, 6200000}, {400000, 6100000}}
+	shp := writeShapefile(t, t.TempDir(), [][][][2]float64{{ring}}, []string{"NAME"}, [][]string{{"UTM"}}, 0)
+
+	if _, err := ReadShapefile(shp); err == nil {
+		t.Error("expected error for projected coordinates")
+	}
+}
@@ -0,0 +1,466 @@
+package geometry
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"sort"
+)
+
+// Пределы числа отрезков, аппроксимирующих окружность буфера.
+const (
+	DefaultSegments = 32
+	minSegments     = 8
+	maxSegments     = 360
+)
+
+// Buffer строит область в пределах distance метров от геометрии. Вокруг точек
+// строятся окружности по большому кругу. Буфер линии — объединение капсул
+// вокруг ее отрезков: окружностей вокруг концов и четырехугольника со
+// сторонами, смещенными от отрезка на distance. Буфер полигона — объединение
+// самого полигона с капсулами вокруг ребер всех колец, так что дыры сужаются
+// на distance и исчезают, если они уже 2·distance. Вершины буфера строятся
+// на сфере, объединение считается в равнопромежуточной проекции с центром
+// в первой вершине части. Каждая часть составной геометрии обрабатывается
+// отдельно, результат — Polygon или MultiPolygon.
+func Buffer(g *Geometry, distance float64, segments int) (*Geometry, error) {
+	if distance <= 0 {
+		return nil, errors.New("buffer distance must be positive")
+	}
+	if segments == 0 {
+		segments = DefaultSegments
+	}
+	if segments < minSegments || segments > maxSegments {
+		return nil, errors.New("segments must be between 8 and 360")
+	}
+
+	points, lines, polygons := g.parts()
+	var result [][][]Point
+	for _, p := range points {
+		result = append(result, [][]Point{circle(p, distance, segments)})
+	}
+	for _, line := range lines {
+		result = append(result, bufferPart([][]Point{line}, nil, distance, segments)...)
+	}
+	for _, polygon := range polygons {
+		result = append(result, bufferPart(polygon, polygon, distance, segments)...)
+	}
+
+	switch len(result) {
+	case 0:
+		return nil, errors.New("geometry is empty")
+	case 1:
+		return &Geometry{Type: TypePolygon, Polygons: result}, nil
+	}
+	return &Geometry{Type: TypeMultiPolygon, Polygons: result}, nil
+}
+
+// circle возвращает замкнутое кольцо против часовой стрелки: азимуты
+// перебираются по убыванию.
+func circle(center Point, distance float64, segments int) []Point {
+	ring := make([]Point, segments+1)
+	for i := 0; i < segments; i++ {
+		bearing := 360 - float64(i)*360/float64(segments)
+		lat, lon := geo.Destination(center.Lat(), center.Lon(), bearing, distance)
+		ring[i] = Point{lon, lat}
+	}
+	ring[segments] = ring[0]
+	return ring
+}
+
+// capsuleSide возвращает четырехугольник против часовой стрелки между
+// окружностями вокруг концов отрезка ab: его длинные стороны смещены
+// от отрезка на distance по перпендикуляру.
+func capsuleSide(a, b Point, distance float64) []Point {
+	start := geo.InitialBearing(a.Lat(), a.Lon(), b.Lat(), b.Lon())
+	end := geo.FinalBearing(a.Lat(), a.Lon(), b.Lat(), b.Lon())
+	offset := func(p Point, bearing float64) Point {
+		lat, lon := geo.Destination(p.Lat(), p.Lon(), bearing, distance)
+		return Point{lon, lat}
+	}
+	right := offset(a, start+90)
+	return []Point{right, offset(b, end+90), offset(b, end-90), offset(a, start-90), right}
+}
+
+// bufferPart строит буфер одной части: капсулы вокруг отрезков rings,
+// объединенные с полигоном area, если он задан. Возвращает полигоны
+// буфера: первое кольцо внешнее, остальные — дыры.
+func bufferPart(rings [][]Point, area [][]Point, distance float64, segments int) [][][]Point {
+	origin := rings[0][0]
+	plane := newPlane(origin)
+
+	var pieces [][]Point
+	addPiece := func(ring []Point) {
+		projected := make([]Point, len(ring))
+		for i, p := range ring {
+			projected[i] = plane.project(p)
+		}
+		pieces = append(pieces, projected)
+	}
+	// вершины ближе к прямой, чем окружность буфера к своим хордам,
+	// не меняют результат, но добавляют капсулы
+	tolerance := distance * (1 - math.Cos(math.Pi/float64(segments)))
+	vertices := make(map[Point]bool)
+	sides := make(map[[2]Point]bool)
+	for _, ring := range rings {
+		ring = simplifyLine(ring, tolerance)
+		for i, p := range ring {
+			if !vertices[p] {
+				vertices[p] = true
+				addPiece(circle(p, distance, segments))
+			}
+			if i == 0 || ring[i-1] == p || sides[[2]Point{ring[i-1], p}] {
+				continue
+			}
+			sides[[2]Point{ring[i-1], p}], sides[[2]Point{p, ring[i-1]}] = true, true
+			addPiece(capsuleSide(ring[i-1], p, distance))
+		}
+	}
+
+	inside := newRingIndex()
+	for _, ring := range area {
+		for i := 1; i < len(ring); i++ {
+			inside.add(plane.project(ring[i-1]), plane.project(ring[i]))
+		}
+	}
+
+	var result [][][]Point
+	for _, polygon := range union(pieces, inside.contains, distance*1e-6) {
+		rings := make([][]Point, len(polygon))
+		for i, ring := range polygon {
+			rings[i] = make([]Point, len(ring))
+			for j, p := range ring {
+				rings[i][j] = plane.unproject(p)
+			}
+		}
+		result = append(result, rings)
+	}
+	return result
+}
+
+// plane — равнопромежуточная проекция в метрах с центром в origin.
+// Долготы отсчитываются от origin, поэтому часть может пересекать антимеридиан.
+type plane struct {
+	origin Point
+	scale  float64
+}
+
+func newPlane(origin Point) plane {
+	return plane{origin: origin, scale: math.Cos(radians(origin.Lat())) * geo.EarthRadius}
+}
+
+func (p plane) project(q Point) Point {
+	return Point{
+		radians(unwrapLon(q.Lon(), p.origin.Lon())-p.origin.Lon()) * p.scale,
+		radians(q.Lat()-p.origin.Lat()) * geo.EarthRadius,
+	}
+}
+
+func (p plane) unproject(q Point) Point {
+	return Point{
+		normalizeLon(p.origin.Lon() + q.Lon()/p.scale*180/math.Pi),
+		p.origin.Lat() + q.Lat()/geo.EarthRadius*180/math.Pi,
+	}
+}
+
+// bufferEdge — ребро фигуры объединения с точками разреза.
+type bufferEdge struct {
+	a, b Point
+	cuts []cut
+}
+
+// cut — точка разреза ребра; t — ее доля пути от начала ребра.
+type cut struct {
+	t float64
+	p Point
+}
+
+// union объединяет на плоскости выпуклые кольца pieces против часовой
+// стрелки и область, в которой inside возвращает true. Ребра фигур
+// разрезаются в точках пересечения, и остаются только те части, снаружи
+// от которых на расстоянии eps нет ни одной фигуры: они и образуют границу
+// объединения. Оставшиеся ребра сшиваются в кольца: против часовой
+// стрелки — внешние, по часовой — дыры.
+func union(pieces [][]Point, inside func(Point) bool, eps float64) [][][]Point {
+	nodes := newSnapper(eps)
+	index := spatial.NewRTree[int]()
+	edgeIndex := spatial.NewRTree[int]()
+	var edges []*bufferEdge
+	for i, piece := range pieces {
+		for j, p := range piece {
+			piece[j] = nodes.snap(p)
+		}
+		index.Insert(RingRect(piece), i)
+		for j := 1; j < len(piece); j++ {
+			if piece[j-1] != piece[j] {
+				edgeIndex.Insert(RingRect(piece[j-1:j+1]), len(edges))
+				edges = append(edges, &bufferEdge{a: piece[j-1], b: piece[j]})
+			}
+		}
+	}
+
+	for i, e := range edges {
+		rect := RingRect([]Point{e.a, e.b})
+		rect = spatial.Rect{MinX: rect.MinX - eps, MinY: rect.MinY - eps, MaxX: rect.MaxX + eps, MaxY: rect.MaxY + eps}
+		edgeIndex.Search(rect, func(j int) bool {
+			if j > i {
+				nodes.cutEdges(e, edges[j])
+			}
+			return true
+		})
+	}
+
+	covered := func(p Point) bool {
+		found := false
+		index.Search(spatial.PointRect(p.Lon(), p.Lat()), func(j int) bool {
+			found = ringContains(pieces[j], p)
+			return !found
+		})
+		return found || inside(p)
+	}
+
+	next := make(map[Point][]*[2]Point)
+	seen := make(map[[2]Point]bool)
+	for _, e := range edges {
+		sort.Slice(e.cuts, func(i, j int) bool { return e.cuts[i].t < e.cuts[j].t })
+		from := e.a
+		for _, c := range append(e.cuts, cut{t: 1, p: e.b}) {
+			to := c.p
+			if to == from {
+				continue
+			}
+			if !seen[[2]Point{from, to}] && !covered(outward(from, to, eps)) {
+				seen[[2]Point{from, to}] = true
+				next[from] = append(next[from], &[2]Point{from, to})
+			}
+			from = to
+		}
+	}
+
+	var outers, holes [][]Point
+	for _, ring := range traceRings(next) {
+		switch a := planarArea(ring); {
+		case a > eps*eps*1e6:
+			outers = append(outers, ring)
+		case a < -eps*eps*1e6:
+			holes = append(holes, ring)
+		}
+	}
+	sort.Slice(outers, func(i, j int) bool { return planarArea(outers[i]) < planarArea(outers[j]) })
+
+	result := make([][][]Point, len(outers))
+	for i, outer := range outers {
+		result[i] = [][]Point{outer}
+	}
+	for _, hole := range holes {
+		mid := Point{(hole[0].Lon() + hole[1].Lon()) / 2, (hole[0].Lat() + hole[1].Lat()) / 2}
+		// наименьшее внешнее кольцо, содержащее дыру
+		for i, outer := range outers {
+			if ringContains(outer, mid) {
+				result[i] = append(result[i], hole)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// outward возвращает точку на расстоянии eps справа от середины отрезка ab —
+// снаружи фигуры, обходящей границу против часовой стрелки.
+func outward(a, b Point, eps float64) Point {
+	dx, dy := b.Lon()-a.Lon(), b.Lat()-a.Lat()
+	length := math.Hypot(dx, dy)
+	return Point{(a.Lon()+b.Lon())/2 + dy/length*eps, (a.Lat()+b.Lat())/2 - dx/length*eps}
+}
+
+// snapper сводит точки ближе eps друг к другу в одну вершину. Без этого
+// почти совпадающие углы соседних капсул дают ребра короче eps, сторону
+// которых нельзя определить, и граница не сшивается.
+type snapper struct {
+	eps   float64
+	cells map[[2]int64][]Point
+}
+
+func newSnapper(eps float64) *snapper {
+	return &snapper{eps: eps, cells: make(map[[2]int64][]Point)}
+}
+
+// snap возвращает уже известную вершину не дальше eps от p или запоминает p.
+// Ячейки сетки вчетверо больше eps, поэтому соседние ячейки проверяются,
+// только если p лежит у их границы.
+func (s *snapper) snap(p Point) Point {
+	size := 4 * s.eps
+	x, y := p.Lon()/size, p.Lat()/size
+	cx, cy := int64(math.Floor(x)), int64(math.Floor(y))
+	near := func(v float64, c int64) (int64, int64) {
+		from, to := c, c
+		if v-float64(c) < 0.25 {
+			from--
+		}
+		if v-float64(c) > 0.75 {
+			to++
+		}
+		return from, to
+	}
+	fromX, toX := near(x, cx)
+	fromY, toY := near(y, cy)
+	for i := fromX; i <= toX; i++ {
+		for j := fromY; j <= toY; j++ {
+			for _, q := range s.cells[[2]int64{i, j}] {
+				if math.Hypot(q.Lon()-p.Lon(), q.Lat()-p.Lat()) <= s.eps {
+					return q
+				}
+			}
+		}
+	}
+	s.cells[[2]int64{cx, cy}] = append(s.cells[[2]int64{cx, cy}], p)
+	return p
+}
+
+// cutEdges разрезает ребра e и f в точке их пересечения, а также там, где
+// конец одного ребра лежит ближе eps к другому. Общая точка вычисляется
+// один раз, чтобы концы частей ребер совпадали точно.
+func (s *snapper) cutEdges(e, f *bufferEdge) {
+	if math.Max(e.a.Lon(), e.b.Lon())+s.eps < math.Min(f.a.Lon(), f.b.Lon()) ||
+		math.Max(f.a.Lon(), f.b.Lon())+s.eps < math.Min(e.a.Lon(), e.b.Lon()) ||
+		math.Max(e.a.Lat(), e.b.Lat())+s.eps < math.Min(f.a.Lat(), f.b.Lat()) ||
+		math.Max(f.a.Lat(), f.b.Lat())+s.eps < math.Min(e.a.Lat(), e.b.Lat()) {
+		return
+	}
+	s.cutAtEnds(e, f)
+	s.cutAtEnds(f, e)
+
+	rx, ry := e.b.Lon()-e.a.Lon(), e.b.Lat()-e.a.Lat()
+	sx, sy := f.b.Lon()-f.a.Lon(), f.b.Lat()-f.a.Lat()
+	denom := rx*sy - ry*sx
+	if denom == 0 {
+		return
+	}
+	qx, qy := f.a.Lon()-e.a.Lon(), f.a.Lat()-e.a.Lat()
+	t := (qx*sy - qy*sx) / denom
+	u := (qx*ry - qy*rx) / denom
+	if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+		return
+	}
+	p := s.snap(Point{e.a.Lon() + t*rx, e.a.Lat() + t*ry})
+	e.cuts = append(e.cuts, cut{t: t, p: p})
+	f.cuts = append(f.cuts, cut{t: u, p: p})
+}
+
+// cutAtEnds разрезает e в концах f, лежащих ближе eps к его внутренней части.
+func (s *snapper) cutAtEnds(e, f *bufferEdge) {
+	dx, dy := e.b.Lon()-e.a.Lon(), e.b.Lat()-e.a.Lat()
+	for _, p := range []Point{f.a, f.b} {
+		if p == e.a || p == e.b {
+			continue
+		}
+		t := ((p.Lon()-e.a.Lon())*dx + (p.Lat()-e.a.Lat())*dy) / (dx*dx + dy*dy)
+		if t <= 0 || t >= 1 {
+			continue
+		}
+		if math.Hypot(e.a.Lon()+t*dx-p.Lon(), e.a.Lat()+t*dy-p.Lat()) <= s.eps {
+			e.cuts = append(e.cuts, cut{t: t, p: p})
+		}
+	}
+}
+
+// traceRings сшивает ребра границы в замкнутые кольца. Если из вершины
+// выходит несколько ребер, берется самый левый поворот: так фигуры,
+// касающиеся в точке, остаются отдельными кольцами.
+func traceRings(next map[Point][]*[2]Point) [][]Point {
+	used := make(map[*[2]Point]bool)
+	var starts []*[2]Point
+	for _, out := range next {
+		starts = append(starts, out...)
+	}
+	// порядок обхода map случаен, а результат должен быть воспроизводимым
+	sort.Slice(starts, func(i, j int) bool {
+		a, b := starts[i][0], starts[j][0]
+		if a.Lon() != b.Lon() {
+			return a.Lon() < b.Lon()
+		}
+		return a.Lat() < b.Lat()
+	})
+
+	var rings [][]Point
+	for _, start := range starts {
+		if used[start] {
+			continue
+		}
+		ring := []Point{start[0]}
+		edge := start
+		for !used[edge] {
+			used[edge] = true
+			ring = append(ring, edge[1])
+			if edge[1] == start[0] {
+				break
+			}
+			var best *[2]Point
+			bestTurn := math.Inf(-1)
+			for _, candidate := range next[edge[1]] {
+				if used[candidate] {
+					continue
+				}
+				if turn := turnAngle(edge[0], edge[1], candidate[1]); turn > bestTurn {
+					best, bestTurn = candidate, turn
+				}
+			}
+			if best == nil {
+				break
+			}
+			edge = best
+		}
+		if len(ring) >= 4 && ring[len(ring)-1] == ring[0] {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
+
+// ringIndex проверяет точку по правилу четности, как ringContains, но
+// перебирает только ребра, которые может пересечь луч из точки на восток:
+// проверка каждой части буфера не должна обходить весь полигон.
+type ringIndex struct {
+	edges *spatial.RTree[[2]Point]
+	east  float64
+}
+
+func newRingIndex() *ringIndex {
+	return &ringIndex{edges: spatial.NewRTree[[2]Point](), east: math.Inf(-1)}
+}
+
+func (r *ringIndex) add(a, b Point) {
+	r.edges.Insert(RingRect([]Point{a, b}), [2]Point{a, b})
+	r.east = math.Max(r.east, math.Max(a.Lon(), b.Lon()))
+}
+
+func (r *ringIndex) contains(p Point) bool {
+	inside := false
+	ray := spatial.Rect{MinX: p.Lon(), MinY: p.Lat(), MaxX: math.Max(r.east, p.Lon()), MaxY: p.Lat()}
+	r.edges.Search(ray, func(e [2]Point) bool {
+		a, b := e[0], e[1]
+		if (a.Lat() > p.Lat()) != (b.Lat() > p.Lat()) &&
+			p.Lon() < (b.Lon()-a.Lon())*(p.Lat()-a.Lat())/(b.Lat()-a.Lat())+a.Lon() {
+			inside = !inside
+		}
+		return true
+	})
+	return inside
+}
+
+// turnAngle — угол поворота в вершине b по пути a → b → c: положителен
+// при повороте налево.
+func turnAngle(a, b, c Point) float64 {
+	return math.Atan2(cross(a, b, c), (b.Lon()-a.Lon())*(c.Lon()-b.Lon())+(b.Lat()-a.Lat())*(c.Lat()-b.Lat()))
+}
+
+// planarArea — знаковая площадь замкнутого кольца на плоскости,
+// положительна для колец против часовой стрелки.
+func planarArea(ring []Point) float64 {
+	var area float64
+	for i := 1; i < len(ring); i++ {
+		area += ring[i-1].Lon()*ring[i].Lat() - ring[i].Lon()*ring[i-1].Lat()
+	}
+	return area / 2
+}
@@ -0,0 +1,172 @@
+package geometry
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"math"
+	"testing"
+)
+
+func TestBuffer_Point(t *testing.T) {
+	g, err := Buffer(mustDecode(t, `{"type":"Point","coordinates":[37.6176,55.7558]}`), 1000, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Type != TypePolygon {
+		t.Fatalf("expected Polygon, got %s", g.Type)
+	}
+
+	ring := g.Polygons[0][0]
+	if len(ring) != DefaultSegments+1 || ring[0] != ring[len(ring)-1] {
+		t.Fatalf("expected closed ring of %d points, got %d", DefaultSegments+1, len(ring))
+	}
+	for _, p := range ring {
+		if d := geo.Haversine(55.7558, 37.6176, p.Lat(), p.Lon()); math.Abs(d-1000) > 1e-6 {
+			t.Errorf("expected vertex at 1000 m, got %.6f m", d)
+		}
+	}
+	if ringArea(ring) <= 0 {
+		t.Error("expected counter-clockwise ring")
+	}
+	// площадь вписанного 32-угольника близка к площади круга
+	if area := Area(g); math.Abs(area-math.Pi*1000*1000)/(math.Pi*1000*1000) > 0.01 {
+		t.Errorf("expected area close to π·10⁶ m², got %.0f m²", area)
+	}
+}
+
+func TestBuffer_Line(t *testing.T) {
+	g, err := Buffer(mustDecode(t, `{"type":"LineString","coordinates":[[0,0],[0.1,0]]}`), 500, 16)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	box := BBox(g)
+	// 500 м ≈ 0.0045° у экватора
+	if math.Abs(box.North-0.0045) > 0.0001 || math.Abs(box.South+0.0045) > 0.0001 ||
+		math.Abs(box.West+0.0045) > 0.0001 || math.Abs(box.East-0.1045) > 0.0001 {
+		t.Errorf("unexpected buffer extent: %+v", box)
+	}
+}
+
+func TestBuffer_Concave(t *testing.T) {
+	// 0.001° ≈ 111 м у экватора, буфер 100 м
+	testCases := []struct {
+		name     string
+		geometry string
+		inside   []Point
+		outside  []Point
+		holes    int
+	}{
+		{
+			name:     "U-shaped line",
+			geometry: `{"type":"LineString","coordinates":[[0,0.01],[0,0],[0.01,0],[0.01,0.01]]}`,
+			inside:   []Point{{0.005, 0.0005}, {0.0005, 0.005}, {-0.0005, 0.0105}, {0.0105, -0.0005}},
+			outside:  []Point{{0.005, 0.005}, {0.005, 0.002}, {0.002, 0.009}, {-0.002, 0.005}},
+		},
+		{
+			name:     "L-shaped line",
+			geometry: `{"type":"LineString","coordinates":[[0,0.01],[0,0],[0.01,0]]}`,
+			inside:   []Point{{0.0005, 0.0005}, {0.0095, 0.0005}},
+			outside:  []Point{{0.005, 0.005}, {0.009, 0.009}},
+		},
+		{
+			name:     "closed line",
+			geometry: `{"type":"LineString","coordinates":[[0,0],[0.01,0],[0.01,0.01],[0,0.01],[0,0]]}`,
+			inside:   []Point{{0.0005, 0.005}, {0.005, 0.0095}},
+			outside:  []Point{{0.005, 0.005}},
+			holes:    1,
+		},
+		{
+			name:     "concave polygon",
+			geometry: `{"type":"Polygon","coordinates":[[[0,0],[0.02,0],[0.02,0.02],[0.01,0.005],[0,0.02],[0,0]]]}`,
+			inside:   []Point{{0.01, 0.004}, {0.01, 0.0055}, {0.0005, 0.0195}, {-0.0005, 0.01}},
+			outside:  []Point{{0.01, 0.015}, {0.01, 0.01}, {-0.002, 0.01}},
+		},
+		{
+			name: "polygon with hole",
+			geometry: `{"type":"Polygon","coordinates":[[[0,0],[0.02,0],[0.02,0.02],[0,0.02],[0,0]],
+				[[0.005,0.005],[0.015,0.005],[0.015,0.015],[0.005,0.015],[0.005,0.005]]]}`,
+			inside:  []Point{{0.0055, 0.01}, {0.002, 0.01}, {-0.0005, 0.01}},
+			outside: []Point{{0.01, 0.01}, {0.007, 0.01}, {-0.002, 0.01}},
+			holes:   1,
+		},
+		{
+			name:     "hole narrower than buffer",
+			geometry: `{"type":"Polygon","coordinates":[[[0,0],[0.02,0],[0.02,0.02],[0,0.02],[0,0]],[[0.00925,0.00925],[0.01075,0.00925],[0.01075,0.01075],[0.00925,0.01075],[0.00925,0.00925]]]}`,
+			inside:   []Point{{0.01, 0.01}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := Buffer(mustDecode(t, tc.geometry), 100, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if g.Type != TypePolygon {
+				t.Fatalf("expected Polygon, got %s of %d parts", g.Type, len(g.Polygons))
+			}
+			if holes := len(g.Polygons[0]) - 1; holes != tc.holes {
+				t.Errorf("expected %d holes, got %d", tc.holes, holes)
+			}
+			for _, p := range tc.inside {
+				if !Contains(g, p) {
+					t.Errorf("expected %v inside buffer", p)
+				}
+			}
+			for _, p := range tc.outside {
+				if Contains(g, p) {
+					t.Errorf("expected %v outside buffer", p)
+				}
+			}
+		})
+	}
+}
+
+func TestBuffer_Area(t *testing.T) {
+	// капсула вокруг отрезка: прямоугольник 2d×L и круг радиуса d
+	const d = 100.0
+	for name, geojson := range map[string]string{
+		"segment":             `{"type":"LineString","coordinates":[[37.6,55.7],[37.62,55.71]]}`,
+		"collinear vertices":  `{"type":"LineString","coordinates":[[37.6,55.7],[37.61,55.705],[37.62,55.71]]}`,
+		"across antimeridian": `{"type":"LineString","coordinates":[[179.99,0],[-179.99,0]]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			line := mustDecode(t, geojson)
+			g, err := Buffer(line, d, 64)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := 2*d*Length(line) + math.Pi*d*d
+			if area := Area(g); math.Abs(area-expected)/expected > 0.01 {
+				t.Errorf("expected area close to %.0f m², got %.0f m²", expected, area)
+			}
+			for _, p := range g.Polygons[0][0] {
+				if p.Lon() < -180 || p.Lon() > 180 {
+					t.Errorf("longitude out of range: %v", p)
+				}
+			}
+		})
+	}
+}
+
+func TestBuffer_MultiPart(t *testing.T) {
+	g, err := Buffer(mustDecode(t, `{"type":"MultiPoint","coordinates":[[0,0],[10,10]]}`), 100, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Type != TypeMultiPolygon || len(g.Polygons) != 2 {
+		t.Errorf("expected MultiPolygon of 2 parts, got %s of %d", g.Type, len(g.Polygons))
+	}
+}
+
+func TestBuffer_Invalid(t *testing.T) {
+	point := mustDecode(t, `{"type":"Point","coordinates":[0,0]}`)
+	if _, err := Buffer(point, 0, 0); err == nil {
+		t.Error("expected error for zero distance")
+	}
+	if _, err := Buffer(point, 100, 4); err == nil {
+		t.Error("expected error for too few segments")
+	}
+	if _, err := Buffer(mustDecode(t, `{"type":"GeometryCollection","geometries":[]}`), 100, 0); err == nil {
+		t.Error("expected error for empty geometry")
+	}
+}
@@ -0,0 +1,87 @@
+package geometry
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+)
+
+// Contains сообщает, лежит ли точка внутри полигонов геометрии: внутри
+// внешнего кольца и вне дыр. Расчет ведется на плоскости долготы и широты,
+// поэтому полигоны, пересекающие антимеридиан, должны быть разрезаны по нему.
+// Точка на границе может оказаться по любую сторону.
+func Contains(g *Geometry, p Point) bool {
+	_, _, polygons := g.parts()
+	for _, polygon := range polygons {
+		if PolygonContains(polygon, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolygonContains проверяет один полигон: первое кольцо внешнее, остальные — дыры.
+func PolygonContains(polygon [][]Point, p Point) bool {
+	if len(polygon) == 0 || !ringContains(polygon[0], p) {
+		return false
+	}
+	for _, hole := range polygon[1:] {
+		if ringContains(hole, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains — правило четности: луч из точки на восток пересекает
+// границу замкнутого кольца нечетное число раз.
+func ringContains(ring []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat() > p.Lat()) != (b.Lat() > p.Lat()) &&
+			p.Lon() < (b.Lon()-a.Lon())*(p.Lat()-a.Lat())/(b.Lat()-a.Lat())+a.Lon() {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// RingRect возвращает охватывающий прямоугольник кольца для spatial.RTree.
+func RingRect(ring []Point) spatial.Rect {
+	rect := spatial.PointRect(ring[0].Lon(), ring[0].Lat())
+	for _, p := range ring[1:] {
+		rect = rect.Union(spatial.PointRect(p.Lon(), p.Lat()))
+	}
+	return rect
+}
+
+// BoundaryDistance возвращает расстояние в метрах от точки до ближайшей
+// границы полигонов геометрии, включая дыры. Ребра считаются отрезками
+// большого круга, расстояние до них — на сфере, так что оно точно и для
+// далеких точек, и через антимеридиан. Для геометрии без полигонов
+// возвращается +Inf.
+func BoundaryDistance(g *Geometry, p Point) float64 {
+	best := math.Inf(1)
+	for _, polygon := range g.PolygonParts() {
+		for _, ring := range polygon {
+			for i := 1; i < len(ring); i++ {
+				a, b := ring[i-1], ring[i]
+				best = math.Min(best, geo.SegmentDistance(p.Lat(), p.Lon(), a.Lat(), a.Lon(), b.Lat(), b.Lon()))
+			}
+		}
+	}
+	return best
+}
+
+// equirectangular возвращает равнопромежуточную проекцию в метрах
+// с центром в точке.
+func equirectangular(center Point) func(Point) [2]float64 {
+	scale := math.Cos(radians(center.Lat()))
+	return func(q Point) [2]float64 {
+		return [2]float64{
+			radians(q.Lon()-center.Lon()) * scale * geo.EarthRadius,
+			radians(q.Lat()-center.Lat()) * geo.EarthRadius,
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package geometry
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"math"
+	"testing"
+)
+
+func TestContains(t *testing.T) {
+	// квадрат 0..4 с дырой 1..2 и отдельный квадрат 10..11
+	g := mustDecode(t, `{"type":"MultiPolygon","coordinates":[
+		[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,2],[2,2],[2,1],[1,1]]],
+		[[[10,10],[11,10],[11,11],[10,11],[10,10]]]]}`)
+
+	testCases := []struct {
+		point    Point
+		expected bool
+	}{
+		{Point{3, 3}, true},
+		{Point{1.5, 1.5}, false},
+		{Point{10.5, 10.5}, true},
+		{Point{5, 5}, false},
+		{Point{-1, 2}, false},
+	}
+
+	for _, tc := range testCases {
+		if got := Contains(g, tc.point); got != tc.expected {
+			t.Errorf("Contains(%v): expected %v, got %v", tc.point, tc.expected, got)
+		}
+	}
+
+	if Contains(mustDecode(t, `{"type":"LineString","coordinates":[[0,0],[4,4]]}`), Point{2, 2}) {
+		t.Error("line cannot contain a point")
+	}
+}
+
+func TestBoundaryDistance(t *testing.T) {
+	// квадрат 0..1 градус у экватора с дырой 0.4..0.6
+	g := mustDecode(t, `{"type":"Polygon","coordinates":[
+		[[0,0],[1,0],[1,1],[0,1],[0,0]],[[0.4,0.4],[0.6,0.4],[0.6,0.6],[0.4,0.6],[0.4,0.4]]]}`)
+	degree := geo.EarthRadius * math.Pi / 180
+
+	testCases := []struct {
+		point    Point
+		expected float64
+	}{
+		{Point{2, 0.5}, degree},
+		{Point{0.5, -0.5}, 0.5 * degree},
+		{Point{0.2, 0.5}, 0.2 * degree},
+		{Point{0.5, 0.5}, 0.1 * degree},
+		{Point{1, 0.5}, 0},
+	}
+
+	for _, tc := range testCases {
+		if got := BoundaryDistance(g, tc.point); math.Abs(got-tc.expected) > 0.005*tc.expected+1e-6 {
+			t.Errorf("BoundaryDistance(%v): expected %.0f, got %.0f", tc.point, tc.expected, got)
+		}
+	}
+
+	// далекая точка и полигон за антимеридианом
+	far := mustDecode(t, `{"type":"Polygon","coordinates":[[[0,50],[1,50],[1,70],[0,70],[0,50]]]}`)
+	if got, expected := BoundaryDistance(far, Point{10, 60}), geo.SegmentDistance(60, 10, 50, 1, 70, 1); math.Abs(got-expected) > 1e-6 {
+		t.Errorf("expected %.0f m to a distant boundary, got %.0f m", expected, got)
+	}
+	across := mustDecode(t, `{"type":"Polygon","coordinates":[[[179,-1],[179.5,-1],[179.5,1],[179,1],[179,-1]]]}`)
+	if got := BoundaryDistance(across, Point{-179.5, 0}); math.Abs(got-degree) > 1e-6*degree {
+		t.Errorf("expected %.0f m across the antimeridian, got %.0f m", degree, got)
+	}
+
+	if got := BoundaryDistance(mustDecode(t, `{"type":"Point","coordinates":[0,0]}`), Point{1, 1}); !math.IsInf(got, 1) {
+		t.Errorf("expected +Inf without polygons, got %v", got)
+	}
+}
@@ -0,0 +1,335 @@
+package geometry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+)
+
+// Типы геометрий GeoJSON.
+const (
+	TypePoint              = "Point"
+	TypeMultiPoint         = "MultiPoint"
+	TypeLineString         = "LineString"
+	TypeMultiLineString    = "MultiLineString"
+	TypePolygon            = "Polygon"
+	TypeMultiPolygon       = "MultiPolygon"
+	TypeGeometryCollection = "GeometryCollection"
+)
+
+// maxPoints ограничивает число вершин в одной геометрии.
+const maxPoints = 100000
+
+// ErrInvalidGeometry возвращается для геометрии, не соответствующей RFC 7946.
+var ErrInvalidGeometry = errors.New("invalid geometry")
+
+// Point — позиция GeoJSON: долгота, затем широта.
+type Point [2]float64
+
+func (p Point) Lon() float64 { return p[0] }
+func (p Point) Lat() float64 { return p[1] }
+
+// Geometry — разобранная геометрия. Заполнено поле, соответствующее типу:
+// Points для Point и MultiPoint, Lines для линий, Polygons для полигонов
+// (первое кольцо внешнее, остальные — дыры), Geometries для коллекции.
+type Geometry struct {
+	Type       string
+	Points     []Point
+	Lines      [][]Point
+	Polygons   [][][]Point
+	Geometries []*Geometry
+}
+
+// Decode разбирает и проверяет геометрию GeoJSON в долготе и широте.
+func Decode(g models.Geometry) (*Geometry, error) {
+	return decoder{geographic: true}.decodeAll(g)
+}
+
+// DecodeProjected разбирает геометрию в прямоугольных координатах проекции:
+// диапазоны долготы и широты не проверяются.
+func DecodeProjected(g models.Geometry) (*Geometry, error) {
+	return decoder{}.decodeAll(g)
+}
+
+// DecodeDataset разбирает геометрию из локального набора данных, например
+// границы регионов: число вершин не ограничено.
+func DecodeDataset(g models.Geometry) (*Geometry, error) {
+	return decoder{geographic: true, unlimited: true}.decodeAll(g)
+}
+
+// decoder разбирает геометрию; geographic включает проверку диапазонов
+// долготы и широты, unlimited снимает ограничение maxPoints.
+type decoder struct {
+	geographic bool
+	unlimited  bool
+}
+
+func (d decoder) decodeAll(g models.Geometry) (*Geometry, error) {
+	result, err := d.decode(g)
+	if err != nil {
+		return nil, err
+	}
+	if n := result.NumPoints(); !d.unlimited && n > maxPoints {
+		return nil, fmt.Errorf("%w: %d points, at most %d", ErrInvalidGeometry, n, maxPoints)
+	}
+	return result, nil
+}
+
+func (d decoder) decode(g models.Geometry) (*Geometry, error) {
+	result := &Geometry{Type: g.Type}
+	var err error
+	switch g.Type {
+	case TypePoint:
+		var p Point
+		if p, err = d.decodePosition(g.Coordinates); err == nil {
+			result.Points = []Point{p}
+		}
+	case TypeMultiPoint:
+		result.Points, err = d.decodePositions(g.Coordinates, 1)
+	case TypeLineString:
+		var line []Point
+		if line, err = d.decodePositions(g.Coordinates, 2); err == nil {
+			result.Lines = [][]Point{line}
+		}
+	case TypeMultiLineString:
+		result.Lines, err = d.decodeLines(g.Coordinates)
+	case TypePolygon:
+		var polygon [][]Point
+		if polygon, err = d.decodePolygon(g.Coordinates); err == nil {
+			result.Polygons = [][][]Point{polygon}
+		}
+	case TypeMultiPolygon:
+		var raw []json.RawMessage
+		if err = json.Unmarshal(g.Coordinates, &raw); err != nil {
+			break
+		}
+		for _, r := range raw {
+			polygon, err := d.decodePolygon(r)
+			if err != nil {
+				return nil, err
+			}
+			result.Polygons = append(result.Polygons, polygon)
+		}
+	case TypeGeometryCollection:
+		for _, child := range g.Geometries {
+			decoded, err := d.decode(child)
+			if err != nil {
+				return nil, err
+			}
+			result.Geometries = append(result.Geometries, decoded)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %q", ErrInvalidGeometry, g.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidGeometry, g.Type, err)
+	}
+	return result, nil
+}
+
+// decodePosition разбирает позицию. Высота, если есть, отбрасывается.
+func (d decoder) decodePosition(raw json.RawMessage) (Point, error) {
+	var coords []float64
+	if err := json.Unmarshal(raw, &coords); err != nil {
+		return Point{}, err
+	}
+	if len(coords) < 2 {
+		return Point{}, errors.New("position must have longitude and latitude")
+	}
+	p := Point{coords[0], coords[1]}
+	if d.geographic && (p.Lon() < -180 || p.Lon() > 180 || p.Lat() < -90 || p.Lat() > 90) {
+		return Point{}, fmt.Errorf("coordinates out of range: %g, %g", p.Lon(), p.Lat())
+	}
+	return p, nil
+}
+
+func (d decoder) decodePositions(raw json.RawMessage, min int) ([]Point, error) {
+	var positions []json.RawMessage
+	if err := json.Unmarshal(raw, &positions); err != nil {
+		return nil, err
+	}
+	if len(positions) < min {
+		return nil, fmt.Errorf("at least %d positions required", min)
+	}
+	points := make([]Point, len(positions))
+	for i, position := range positions {
+		p, err := d.decodePosition(position)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = p
+	}
+	return points, nil
+}
+
+func (d decoder) decodeLines(raw json.RawMessage) ([][]Point, error) {
+	var lines []json.RawMessage
+	if err := json.Unmarshal(raw, &lines); err != nil {
+		return nil, err
+	}
+	result := make([][]Point, len(lines))
+	for i, line := range lines {
+		points, err := d.decodePositions(line, 2)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = points
+	}
+	return result, nil
+}
+
+// decodePolygon разбирает кольца полигона: каждое замкнуто и содержит
+// не меньше четырех позиций.
+func (d decoder) decodePolygon(raw json.RawMessage) ([][]Point, error) {
+	var rings []json.RawMessage
+	if err := json.Unmarshal(raw, &rings); err != nil {
+		return nil, err
+	}
+	if len(rings) == 0 {
+		return nil, errors.New("polygon must have an exterior ring")
+	}
+	result := make([][]Point, len(rings))
+	for i, ring := range rings {
+		points, err := d.decodePositions(ring, 4)
+		if err != nil {
+			return nil, err
+		}
+		if points[0] != points[len(points)-1] {
+			return nil, errors.New("polygon ring must be closed")
+		}
+		result[i] = points
+	}
+	return result, nil
+}
+
+// Encode возвращает геометрию в виде GeoJSON.
+func (g *Geometry) Encode() models.Geometry {
+	result := models.Geometry{Type: g.Type}
+	var coordinates interface{}
+	switch g.Type {
+	case TypePoint:
+		coordinates = g.Points[0]
+	case TypeMultiPoint:
+		coordinates = g.Points
+	case TypeLineString:
+		coordinates = g.Lines[0]
+	case TypeMultiLineString:
+		coordinates = g.Lines
+		if g.Lines == nil {
+			coordinates = [][]Point{}
+		}
+	case TypePolygon:
+		coordinates = g.Polygons[0]
+	case TypeMultiPolygon:
+		coordinates = g.Polygons
+		if g.Polygons == nil {
+			coordinates = [][][]Point{}
+		}
+	case TypeGeometryCollection:
+		result.Geometries = make([]models.Geometry, len(g.Geometries))
+		for i, child := range g.Geometries {
+			result.Geometries[i] = child.Encode()
+		}
+		return result
+	}
+	// массивы чисел всегда сериализуются без ошибок
+	result.Coordinates, _ = json.Marshal(coordinates)
+	return result
+}
+
+// Transform возвращает копию геометрии, в которой каждая вершина заменена
+// результатом fn, например пересчетом в другую систему координат.
+func (g *Geometry) Transform(fn func(Point) (Point, error)) (*Geometry, error) {
+	result := &Geometry{Type: g.Type}
+	var err error
+	if result.Points, err = transformPoints(g.Points, fn); err != nil {
+		return nil, err
+	}
+	for _, line := range g.Lines {
+		transformed, err := transformPoints(line, fn)
+		if err != nil {
+			return nil, err
+		}
+		result.Lines = append(result.Lines, transformed)
+	}
+	for _, polygon := range g.Polygons {
+		rings := make([][]Point, len(polygon))
+		for i, ring := range polygon {
+			if rings[i], err = transformPoints(ring, fn); err != nil {
+				return nil, err
+			}
+		}
+		result.Polygons = append(result.Polygons, rings)
+	}
+	for _, child := range g.Geometries {
+		transformed, err := child.Transform(fn)
+		if err != nil {
+			return nil, err
+		}
+		result.Geometries = append(result.Geometries, transformed)
+	}
+	return result, nil
+}
+
+func transformPoints(points []Point, fn func(Point) (Point, error)) ([]Point, error) {
+	if points == nil {
+		return nil, nil
+	}
+	result := make([]Point, len(points))
+	for i, p := range points {
+		transformed, err := fn(p)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = transformed
+	}
+	return result, nil
+}
+
+// NumPoints возвращает число вершин геометрии.
+func (g *Geometry) NumPoints() int {
+	n := 0
+	g.eachPoint(func(Point) { n++ })
+	return n
+}
+
+// eachPoint вызывает fn для каждой вершины, включая вершины коллекции.
+func (g *Geometry) eachPoint(fn func(Point)) {
+	for _, p := range g.Points {
+		fn(p)
+	}
+	for _, line := range g.Lines {
+		for _, p := range line {
+			fn(p)
+		}
+	}
+	for _, polygon := range g.Polygons {
+		for _, ring := range polygon {
+			for _, p := range ring {
+				fn(p)
+			}
+		}
+	}
+	for _, child := range g.Geometries {
+		child.eachPoint(fn)
+	}
+}
+
+// PolygonParts возвращает все полигоны геометрии, включая вложенные в коллекцию.
+func (g *Geometry) PolygonParts() [][][]Point {
+	_, _, polygons := g.parts()
+	return polygons
+}
+
+// parts собирает точки, линии и полигоны геометрии и ее коллекций.
+func (g *Geometry) parts() (points []Point, lines [][]Point, polygons [][][]Point) {
+	points = append(points, g.Points...)
+	lines = append(lines, g.Lines...)
+	polygons = append(polygons, g.Polygons...)
+	for _, child := range g.Geometries {
+		p, l, pg := child.parts()
+		points, lines, polygons = append(points, p...), append(lines, l...), append(polygons, pg...)
+	}
+	return points, lines, polygons
+}
@@ -0,0 +1,119 @@
+package geometry
+
+import (
+	"encoding/json"
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"testing"
+)
+
+// mustDecode разбирает геометрию, заданную строкой GeoJSON.
+func mustDecode(t *testing.T, geojson string) *Geometry {
+	t.Helper()
+	var raw models.Geometry
+	if err := json.Unmarshal([]byte(geojson), &raw); err != nil {
+		t.Fatalf("invalid test geometry: %v", err)
+	}
+	g, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	testCases := []string{
+		`{"type":"Point","coordinates":[37.6176,55.7558]}`,
+		`{"type":"MultiPoint","coordinates":[[37.6,55.7],[30.3,59.9]]}`,
+		`{"type":"LineString","coordinates":[[37.6,55.7],[30.3,59.9]]}`,
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[2,2],[3,2],[3,3],[2,2]]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"LineString","coordinates":[[0,0],[1,1]]}]}`,
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			encoded, err := json.Marshal(mustDecode(t, tc).Encode())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(encoded) != tc {
+				t.Errorf("expected %s, got %s", tc, encoded)
+			}
+		})
+	}
+}
+
+func TestDecode_DropsAltitude(t *testing.T) {
+	g := mustDecode(t, `{"type":"Point","coordinates":[37.6,55.7,150]}`)
+	if g.Points[0] != (Point{37.6, 55.7}) {
+		t.Errorf("expected [37.6 55.7], got %v", g.Points[0])
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	testCases := map[string]string{
+		"unknown type":      `{"type":"Circle","coordinates":[0,0]}`,
+		"missing latitude":  `{"type":"Point","coordinates":[0]}`,
+		"latitude range":    `{"type":"Point","coordinates":[0,91]}`,
+		"longitude range":   `{"type":"Point","coordinates":[181,0]}`,
+		"short line":        `{"type":"LineString","coordinates":[[0,0]]}`,
+		"short ring":        `{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,0]]]}`,
+		"open ring":         `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`,
+		"no rings":          `{"type":"Polygon","coordinates":[]}`,
+		"wrong nesting":     `{"type":"LineString","coordinates":[0,0]}`,
+		"invalid in member": `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,100]}]}`,
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var raw models.Geometry
+			if err := json.Unmarshal([]byte(tc), &raw); err != nil {
+				t.Fatalf("invalid test geometry: %v", err)
+			}
+			if _, err := Decode(raw); !errors.Is(err, ErrInvalidGeometry) {
+				t.Errorf("expected ErrInvalidGeometry, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeProjected(t *testing.T) {
+	var raw models.Geometry
+	if err := json.Unmarshal([]byte(`{"type":"Point","coordinates":[7413324.39,6182340.69]}`), &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Decode(raw); !errors.Is(err, ErrInvalidGeometry) {
+		t.Errorf("expected ErrInvalidGeometry for geographic decoding, got %v", err)
+	}
+	g, err := DecodeProjected(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Points[0] != (Point{7413324.39, 6182340.69}) {
+		t.Errorf("unexpected point %v", g.Points[0])
+	}
+}
+
+func TestGeometry_Transform(t *testing.T) {
+	g := mustDecode(t, `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}]}`)
+
+	shifted, err := g.Transform(func(p Point) (Point, error) {
+		return Point{p.Lon() + 10, p.Lat() * 2}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := json.Marshal(shifted.Encode())
+	expected := `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[11,4]},{"type":"Polygon","coordinates":[[[10,0],[11,0],[11,2],[10,0]]]}]}`
+	if string(got) != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	failure := errors.New("out of range")
+	if _, err := g.Transform(func(Point) (Point, error) { return Point{}, failure }); !errors.Is(err, failure) {
+		t.Errorf("expected transform error, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package geometry
+
+import "sort"
+
+// ConvexHull возвращает выпуклую оболочку вершин геометрии: полигон с внешним
+// кольцом против часовой стрелки, а для одной точки или точек на одной
+// прямой — Point или LineString. Долготы отсчитываются от первой вершины,
+// поэтому геометрия может пересекать антимеридиан.
+func ConvexHull(g *Geometry) *Geometry {
+	var points []Point
+	g.eachPoint(func(p Point) {
+		if len(points) > 0 {
+			p = Point{unwrapLon(p.Lon(), points[0].Lon()), p.Lat()}
+		}
+		points = append(points, p)
+	})
+	hull := convexHull(points)
+	for i, p := range hull {
+		hull[i] = Point{normalizeLon(p.Lon()), p.Lat()}
+	}
+
+	switch len(hull) {
+	case 0:
+		return &Geometry{Type: TypeGeometryCollection}
+	case 1:
+		return &Geometry{Type: TypePoint, Points: hull}
+	case 2:
+		return &Geometry{Type: TypeLineString, Lines: [][]Point{hull}}
+	}
+	ring := append(hull, hull[0])
+	return &Geometry{Type: TypePolygon, Polygons: [][][]Point{{ring}}}
+}
+
+// convexHull строит оболочку методом монотонной цепочки (Andrew, 1979)
+// и возвращает вершины против часовой стрелки без повтора первой.
+func convexHull(points []Point) []Point {
+	sorted := make([]Point, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Lon() != sorted[j].Lon() {
+			return sorted[i].Lon() < sorted[j].Lon()
+		}
+		return sorted[i].Lat() < sorted[j].Lat()
+	})
+
+	unique := sorted[:0]
+	for i, p := range sorted {
+		if i == 0 || p != sorted[i-1] {
+			unique = append(unique, p)
+		}
+	}
+	if len(unique) < 3 {
+		return unique
+	}
+
+	hull := make([]Point, 0, 2*len(unique))
+	for _, p := range unique {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(unique) - 2; i >= 0; i-- {
+		p := unique[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
+
+// cross — векторное произведение OA × OB: положительно при повороте
+// против часовой стрелки.
+func cross(o, a, b Point) float64 {
+	return (a.Lon()-o.Lon())*(b.Lat()-o.Lat()) - (a.Lat()-o.Lat())*(b.Lon()-o.Lon())
+}
@@ -0,0 +1,34 @@
+package geometry
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvexHull(t *testing.T) {
+	testCases := []struct {
+		name     string
+		geometry string
+		expected string
+	}{
+		{"single point", `{"type":"MultiPoint","coordinates":[[1,1],[1,1]]}`,
+			`{"type":"Point","coordinates":[1,1]}`},
+		{"collinear points", `{"type":"MultiPoint","coordinates":[[0,0],[2,2],[1,1]]}`,
+			`{"type":"LineString","coordinates":[[0,0],[2,2]]}`},
+		{"inner point dropped", `{"type":"MultiPoint","coordinates":[[0,0],[2,0],[1,1],[2,2],[0,2],[1,2]]}`,
+			`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`},
+		{"concave polygon", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[2,1],[0,4],[0,0]]]}`,
+			`{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]]]}`},
+		{"across antimeridian", `{"type":"MultiPoint","coordinates":[[179,0],[-179,0],[-179,1],[179,1],[-179.5,0.5]]}`,
+			`{"type":"Polygon","coordinates":[[[179,0],[-179,0],[-179,1],[179,1],[179,0]]]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := json.Marshal(ConvexHull(mustDecode(t, tc.geometry)).Encode())
+			if string(got) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package geometry
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/models"
+	"math"
+)
+
+// Area возвращает площадь полигонов в квадратных метрах на сфере радиуса
+// geo.EarthRadius. Площадь дыр вычитается, точки и линии площади не имеют.
+func Area(g *Geometry) float64 {
+	_, _, polygons := g.parts()
+	var area float64
+	for _, polygon := range polygons {
+		area += polygonArea(polygon)
+	}
+	return area
+}
+
+func polygonArea(polygon [][]Point) float64 {
+	area := math.Abs(ringArea(polygon[0]))
+	for _, hole := range polygon[1:] {
+		area -= math.Abs(ringArea(hole))
+	}
+	return math.Max(area, 0)
+}
+
+// ringArea — знаковая площадь замкнутого кольца на сфере (Chamberlain,
+// Duquette, "Some Algorithms for Polygons on a Sphere", 2007).
+// Положительна для колец против часовой стрелки. Долготы отсчитываются
+// от первой вершины, так что кольцо может пересекать антимеридиан.
+func ringArea(ring []Point) float64 {
+	ref := ring[0].Lon()
+	var sum float64
+	for i := 0; i < len(ring)-1; i++ {
+		p1, p2 := ring[i], ring[i+1]
+		dLon := unwrapLon(p2.Lon(), ref) - unwrapLon(p1.Lon(), ref)
+		sum += radians(dLon) * (2 + math.Sin(radians(p1.Lat())) + math.Sin(radians(p2.Lat())))
+	}
+	return -sum * geo.EarthRadius * geo.EarthRadius / 2
+}
+
+// Length возвращает длину линий и периметр полигонов (включая дыры) в метрах
+// по эллипсоиду WGS 84.
+func Length(g *Geometry) float64 {
+	_, lines, polygons := g.parts()
+	var length float64
+	for _, line := range lines {
+		length += lineLength(line)
+	}
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			length += lineLength(ring)
+		}
+	}
+	return length
+}
+
+func lineLength(line []Point) float64 {
+	var length float64
+	for i := 1; i < len(line); i++ {
+		length += segmentLength(line[i-1], line[i])
+	}
+	return length
+}
+
+// segmentLength считает длину отрезка по Винсенти, а для почти
+// антиподальных точек, где формула не сходится, — по сфере.
+func segmentLength(a, b Point) float64 {
+	inverse, err := geo.Vincenty(a.Lat(), a.Lon(), b.Lat(), b.Lon())
+	if err != nil {
+		return geo.Haversine(a.Lat(), a.Lon(), b.Lat(), b.Lon())
+	}
+	return inverse.Distance
+}
+
+// Centroid возвращает центр масс геометрии старшей размерности: полигонов,
+// если они есть, иначе линий, иначе точек. Центр считается на плоскости
+// долгота/широта, что достаточно точно для объектов размером до сотен
+// километров вдали от полюсов. Долготы отсчитываются от первой вершины,
+// поэтому объект может пересекать антимеридиан.
+func Centroid(g *Geometry) models.GeoPoint {
+	points, lines, polygons := g.parts()
+	if c, ok := polygonsCentroid(polygons); ok {
+		return c
+	}
+	if c, ok := linesCentroid(lines); ok {
+		return c
+	}
+
+	if len(points) == 0 {
+		// вырожденные линии и полигоны: среднее вершин
+		g.eachPoint(func(p Point) { points = append(points, p) })
+	}
+	var lat, lon float64
+	for _, p := range points {
+		lat += p.Lat()
+		lon += unwrapLon(p.Lon(), points[0].Lon())
+	}
+	if n := float64(len(points)); n > 0 {
+		lat /= n
+		lon /= n
+	}
+	return models.GeoPoint{Lat: lat, Lon: normalizeLon(lon)}
+}
+
+// polygonsCentroid взвешивает кольца по площади; дыры входят с обратным знаком.
+// Координаты отсчитываются от первой вершины, чтобы не терять точность.
+func polygonsCentroid(polygons [][][]Point) (models.GeoPoint, bool) {
+	if len(polygons) == 0 {
+		return models.GeoPoint{}, false
+	}
+	origin := polygons[0][0][0]
+	var area, cx, cy float64
+	for _, polygon := range polygons {
+		for i, ring := range polygon {
+			a, x, y := ringMoments(ring, origin)
+			if (i == 0) != (a > 0) {
+				a, x, y = -a, -x, -y
+			}
+			area += a
+			cx += x
+			cy += y
+		}
+	}
+	if area <= 0 {
+		return models.GeoPoint{}, false
+	}
+	return models.GeoPoint{Lat: origin.Lat() + cy/(3*area), Lon: normalizeLon(origin.Lon() + cx/(3*area))}, true
+}
+
+// ringMoments возвращает знаковую площадь кольца и его моменты
+// относительно origin; центр кольца — момент/(3·площадь).
+func ringMoments(ring []Point, origin Point) (area, mx, my float64) {
+	for i := 0; i < len(ring)-1; i++ {
+		x1, y1 := unwrapLon(ring[i].Lon(), origin.Lon())-origin.Lon(), ring[i].Lat()-origin.Lat()
+		x2, y2 := unwrapLon(ring[i+1].Lon(), origin.Lon())-origin.Lon(), ring[i+1].Lat()-origin.Lat()
+		cross := x1*y2 - x2*y1
+		area += cross / 2
+		mx += (x1 + x2) * cross / 2
+		my += (y1 + y2) * cross / 2
+	}
+	return area, mx, my
+}
+
+// linesCentroid — середины отрезков, взвешенные по их длине.
+func linesCentroid(lines [][]Point) (models.GeoPoint, bool) {
+	var total, lat, lon float64
+	for _, line := range lines {
+		ref := lines[0][0].Lon()
+		for i := 1; i < len(line); i++ {
+			length := segmentLength(line[i-1], line[i])
+			total += length
+			lat += length * (line[i-1].Lat() + line[i].Lat()) / 2
+			lon += length * (unwrapLon(line[i-1].Lon(), ref) + unwrapLon(line[i].Lon(), ref)) / 2
+		}
+	}
+	if total == 0 {
+		return models.GeoPoint{}, false
+	}
+	return models.GeoPoint{Lat: lat / total, Lon: normalizeLon(lon / total)}, true
+}
+
+// BBox возвращает прямоугольник, охватывающий все вершины геометрии.
+// Долготы отсчитываются от первой вершины: у геометрии, пересекающей
+// антимеридиан, West больше East, как у bbox в запросах.
+func BBox(g *Geometry) models.BoundingBox {
+	box := models.BoundingBox{South: 90, West: 180, North: -90, East: -180}
+	empty, ref := true, 0.0
+	g.eachPoint(func(p Point) {
+		if empty {
+			empty, ref = false, p.Lon()
+		}
+		lon := unwrapLon(p.Lon(), ref)
+		box.South = math.Min(box.South, p.Lat())
+		box.North = math.Max(box.North, p.Lat())
+		box.West = math.Min(box.West, lon)
+		box.East = math.Max(box.East, lon)
+	})
+	if !empty {
+		box.West, box.East = normalizeLon(box.West), normalizeLon(box.East)
+	}
+	return box
+}
+
+// unwrapLon сдвигает долготу на 360°, чтобы она отличалась от ref не больше
+// чем на 180°: вершины объекта, пересекающего антимеридиан, идут подряд.
+func unwrapLon(lon, ref float64) float64 {
+	for lon-ref > 180 {
+		lon -= 360
+	}
+	for lon-ref < -180 {
+		lon += 360
+	}
+	return lon
+}
+
+// normalizeLon возвращает долготу в диапазон [-180, 180].
+func normalizeLon(lon float64) float64 {
+	if lon >= -180 && lon <= 180 {
+		return lon
+	}
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,100 @@
+package geometry
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"math"
+	"testing"
+)
+
+func TestArea(t *testing.T) {
+	// площадь сферической трапеции 1°×1° у экватора: R²·Δλ·(sin φ2 − sin φ1)
+	square := geo.EarthRadius * geo.EarthRadius * radians(1) * math.Sin(radians(1))
+
+	testCases := []struct {
+		name     string
+		geometry string
+		expected float64
+	}{
+		{"point", `{"type":"Point","coordinates":[0,0]}`, 0},
+		{"line", `{"type":"LineString","coordinates":[[0,0],[1,1]]}`, 0},
+		{"counter-clockwise square", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`, square},
+		{"clockwise square", `{"type":"Polygon","coordinates":[[[0,0],[0,1],[1,1],[1,0],[0,0]]]}`, square},
+		{"square with hole", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,1],[0,1],[0,0]],[[1,0],[2,0],[2,1],[1,1],[1,0]]]}`,
+			2*square - geo.EarthRadius*geo.EarthRadius*radians(1)*math.Sin(radians(1))},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,1],[0,0]]],[[[0,-1],[1,-1],[1,0],[0,0],[0,-1]]]]}`, 2 * square},
+		{"across antimeridian", `{"type":"Polygon","coordinates":[[[179,0],[-179,0],[-179,1],[179,1],[179,0]]]}`, 2 * square},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Area(mustDecode(t, tc.geometry))
+			if math.Abs(got-tc.expected) > 1 {
+				t.Errorf("expected %.0f m², got %.0f m²", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLength(t *testing.T) {
+	testCases := []struct {
+		name     string
+		geometry string
+		expected float64
+	}{
+		{"point", `{"type":"Point","coordinates":[0,0]}`, 0},
+		// градус долготы на экваторе эллипсоида WGS 84
+		{"line", `{"type":"LineString","coordinates":[[0,0],[1,0]]}`, 111319.49},
+		{"multiline", `{"type":"MultiLineString","coordinates":[[[0,0],[1,0]],[[1,0],[2,0]]]}`, 2 * 111319.49},
+		{"there-and-back ring", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0],[0,0]]]}`, 2 * 111319.49},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Length(mustDecode(t, tc.geometry))
+			if math.Abs(got-tc.expected) > 0.1 {
+				t.Errorf("expected %.2f m, got %.2f m", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		geometry string
+		lat, lon float64
+	}{
+		{"point", `{"type":"Point","coordinates":[37.6,55.7]}`, 55.7, 37.6},
+		{"multipoint", `{"type":"MultiPoint","coordinates":[[0,0],[2,4]]}`, 2, 1},
+		{"line", `{"type":"LineString","coordinates":[[0,0],[2,0]]}`, 0, 1},
+		{"square", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, 1, 1},
+		{"square with hole", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,1],[0,1],[0,0]],[[1,0],[2,0],[2,1],[1,1],[1,0]]]}`, 0.5, 0.5},
+		{"polygon wins over point", `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[50,50]},{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}]}`, 1, 1},
+		{"degenerate polygon", `{"type":"Polygon","coordinates":[[[1,1],[1,1],[1,1],[1,1]]]}`, 1, 1},
+		{"polygon across antimeridian", `{"type":"Polygon","coordinates":[[[179,0],[-179,0],[-179,2],[179,2],[179,0]]]}`, 1, 180},
+		{"line across antimeridian", `{"type":"LineString","coordinates":[[178,0],[-179,0]]}`, 0, 179.5},
+		{"multipoint across antimeridian", `{"type":"MultiPoint","coordinates":[[-179,0],[177,0]]}`, 0, 179},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Centroid(mustDecode(t, tc.geometry))
+			if math.Abs(got.Lat-tc.lat) > 1e-9 || math.Abs(got.Lon-tc.lon) > 1e-9 {
+				t.Errorf("expected %g, %g, got %g, %g", tc.lat, tc.lon, got.Lat, got.Lon)
+			}
+		})
+	}
+}
+
+func TestBBox(t *testing.T) {
+	g := mustDecode(t, `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[37.6,55.7]},{"type":"LineString","coordinates":[[30.3,59.9],[39.7,47.2]]}]}`)
+	box := BBox(g)
+	if box.South != 47.2 || box.West != 30.3 || box.North != 59.9 || box.East != 39.7 {
+		t.Errorf("unexpected bbox: %+v", box)
+	}
+
+	box = BBox(mustDecode(t, `{"type":"Polygon","coordinates":[[[179,0],[-179,0],[-179,1],[179,1],[179,0]]]}`))
+	if box.South != 0 || box.West != 179 || box.North != 1 || box.East != -179 {
+		t.Errorf("expected bbox across antimeridian, got %+v", box)
+	}
+}
@@ -0,0 +1,223 @@
+package geometry
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+)
+
+// Пространственные отношения считаются, как и Contains, на плоскости
+// долготы и широты: геометрии, пересекающие антимеридиан, должны быть
+// разрезаны по нему. Касание границы может дать любой ответ.
+
+// Rect возвращает охватывающий прямоугольник геометрии для spatial.RTree.
+func Rect(g *Geometry) spatial.Rect {
+	box := BBox(g)
+	return spatial.Rect{MinX: box.West, MinY: box.South, MaxX: box.East, MaxY: box.North}
+}
+
+// Intersects сообщает, есть ли у геометрий общие точки: вершина одной
+// лежит на другой, отрезки пересекаются или одна целиком внутри полигона
+// другой.
+func Intersects(a, b *Geometry) bool {
+	if !Rect(a).Intersects(Rect(b)) {
+		return false
+	}
+	pointsA, linesA, polygonsA := a.parts()
+	pointsB, linesB, polygonsB := b.parts()
+	segmentsA, segmentsB := segments(linesA, polygonsA), segments(linesB, polygonsB)
+
+	for _, p := range pointsA {
+		if touches(p, pointsB, segmentsB, polygonsB) {
+			return true
+		}
+	}
+	for _, p := range pointsB {
+		if touches(p, pointsA, segmentsA, polygonsA) {
+			return true
+		}
+	}
+	for _, s := range segmentsA {
+		for _, t := range segmentsB {
+			if segmentsIntersect(s[0], s[1], t[0], t[1]) {
+				return true
+			}
+		}
+	}
+	// без пересечений границ линия или кольцо либо целиком внутри полигона,
+	// либо целиком снаружи: достаточно проверить первую вершину
+	return firstVertexInside(linesA, polygonsA, polygonsB) || firstVertexInside(linesB, polygonsB, polygonsA)
+}
+
+// Within сообщает, лежит ли a целиком внутри полигонов b: все вершины a
+// внутри b, отрезки a не пересекают границу b, а дыры b не попадают внутрь
+// полигонов a. Геометрия без полигонов ничего не содержит.
+func Within(a, b *Geometry) bool {
+	pointsA, linesA, polygonsA := a.parts()
+	_, _, polygonsB := b.parts()
+	if len(polygonsB) == 0 || !Rect(b).ContainsRect(Rect(a)) {
+		return false
+	}
+
+	inside := true
+	a.eachPoint(func(p Point) {
+		inside = inside && Contains(b, p)
+	})
+	if !inside {
+		return false
+	}
+	if len(pointsA) > 0 && len(linesA) == 0 && len(polygonsA) == 0 {
+		return true
+	}
+
+	segmentsA, segmentsB := segments(linesA, polygonsA), segments(nil, polygonsB)
+	for _, s := range segmentsA {
+		for _, t := range segmentsB {
+			if segmentsCross(s[0], s[1], t[0], t[1]) {
+				return false
+			}
+		}
+	}
+	for _, polygon := range polygonsB {
+		for _, ring := range polygon {
+			for _, p := range ring {
+				for _, container := range polygonsA {
+					if PolygonContains(container, p) {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Distance возвращает расстояние в метрах от точки до геометрии: 0 внутри
+// полигона, иначе до ближайшей вершины или отрезка. Ближайшая точка отрезка
+// ищется в равнопромежуточной проекции с центром в точке, а расстояние
+// до нее считается по большому кругу:
+// результат не меньше точного и отличается от него на малую величину
+// второго порядка.
+func Distance(g *Geometry, p Point) float64 {
+	points, lines, polygons := g.parts()
+	for _, polygon := range polygons {
+		if PolygonContains(polygon, p) {
+			return 0
+		}
+	}
+
+	best := math.Inf(1)
+	for _, q := range points {
+		best = math.Min(best, geo.Haversine(p.Lat(), p.Lon(), q.Lat(), q.Lon()))
+	}
+	project := equirectangular(p)
+	for _, s := range segments(lines, polygons) {
+		a, b := project(s[0]), project(s[1])
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		t := 0.0
+		if dx != 0 || dy != 0 {
+			t = math.Max(0, math.Min(1, -(a[0]*dx+a[1]*dy)/(dx*dx+dy*dy)))
+		}
+		lat, lon := s[0].Lat()+t*(s[1].Lat()-s[0].Lat()), s[0].Lon()+t*(s[1].Lon()-s[0].Lon())
+		best = math.Min(best, geo.Haversine(p.Lat(), p.Lon(), lat, lon))
+	}
+	return best
+}
+
+// segments собирает отрезки линий и колец полигонов.
+func segments(lines [][]Point, polygons [][][]Point) [][2]Point {
+	var result [][2]Point
+	add := func(line []Point) {
+		for i := 1; i < len(line); i++ {
+			result = append(result, [2]Point{line[i-1], line[i]})
+		}
+	}
+	for _, line := range lines {
+		add(line)
+	}
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			add(ring)
+		}
+	}
+	return result
+}
+
+// touches проверяет, совпадает ли точка с точкой, лежит ли на отрезке
+// или внутри полигона.
+func touches(p Point, points []Point, segments [][2]Point, polygons [][][]Point) bool {
+	for _, q := range points {
+		if p == q {
+			return true
+		}
+	}
+	for _, s := range segments {
+		if orientation(s[0], s[1], p) == 0 && onSegment(s[0], s[1], p) {
+			return true
+		}
+	}
+	for _, polygon := range polygons {
+		if PolygonContains(polygon, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstVertexInside проверяет, лежит ли первая вершина какой-либо линии
+// или внешнего кольца внутри полигонов containers.
+func firstVertexInside(lines [][]Point, polygons, containers [][][]Point) bool {
+	var firsts []Point
+	for _, line := range lines {
+		firsts = append(firsts, line[0])
+	}
+	for _, polygon := range polygons {
+		firsts = append(firsts, polygon[0][0])
+	}
+	for _, p := range firsts {
+		for _, container := range containers {
+			if PolygonContains(container, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// orientation — знак векторного произведения (b-a)×(c-a): 1 — поворот
+// против часовой стрелки, -1 — по часовой, 0 — точки на одной прямой.
+func orientation(a, b, c Point) int {
+	cross := (b.Lon()-a.Lon())*(c.Lat()-a.Lat()) - (b.Lat()-a.Lat())*(c.Lon()-a.Lon())
+	switch {
+	case cross > 0:
+		return 1
+	case cross < 0:
+		return -1
+	}
+	return 0
+}
+
+// onSegment проверяет, лежит ли точка на одной прямой с отрезком ab внутри
+// его прямоугольника.
+func onSegment(a, b, p Point) bool {
+	return math.Min(a.Lon(), b.Lon()) <= p.Lon() && p.Lon() <= math.Max(a.Lon(), b.Lon()) &&
+		math.Min(a.Lat(), b.Lat()) <= p.Lat() && p.Lat() <= math.Max(a.Lat(), b.Lat())
+}
+
+// segmentsIntersect сообщает, есть ли у отрезков ab и cd общая точка,
+// включая касание концом и наложение.
+func segmentsIntersect(a, b, c, d Point) bool {
+	o1, o2, o3, o4 := orientation(a, b, c), orientation(a, b, d), orientation(c, d, a), orientation(c, d, b)
+	if o1 != o2 && o3 != o4 {
+		return true
+	}
+	return (o1 == 0 && onSegment(a, b, c)) || (o2 == 0 && onSegment(a, b, d)) ||
+		(o3 == 0 && onSegment(c, d, a)) || (o4 == 0 && onSegment(c, d, b))
+}
+
+// segmentsCross сообщает, пересекаются ли отрезки во внутренней точке
+// обоих: касание концом и наложение не считаются.
+func segmentsCross(a, b, c, d Point) bool {
+	o1, o2, o3, o4 := orientation(a, b, c), orientation(a, b, d), orientation(c, d, a), orientation(c, d, b)
+	return o1*o2 < 0 && o3*o4 < 0
+}
@@ -0,0 +1,92 @@
+package geometry
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"math"
+	"testing"
+)
+
+func TestIntersects(t *testing.T) {
+	// квадрат 0..4 с дырой 1..3
+	square := mustDecode(t, `{"type":"Polygon","coordinates":[
+		[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,3],[3,3],[3,1],[1,1]]]}`)
+
+	testCases := []struct {
+		name     string
+		geometry string
+		expected bool
+	}{
+		{"point inside", `{"type":"Point","coordinates":[0.5,0.5]}`, true},
+		{"point in hole", `{"type":"Point","coordinates":[2,2]}`, false},
+		{"point on edge", `{"type":"Point","coordinates":[4,2]}`, true},
+		{"line crossing", `{"type":"LineString","coordinates":[[-1,2],[0.5,2]]}`, true},
+		{"line in hole", `{"type":"LineString","coordinates":[[1.5,1.5],[2.5,2.5]]}`, false},
+		{"line outside", `{"type":"LineString","coordinates":[[5,5],[6,6]]}`, false},
+		{"polygon covering", `{"type":"Polygon","coordinates":[[[-1,-1],[5,-1],[5,5],[-1,5],[-1,-1]]]}`, true},
+		{"polygon in hole", `{"type":"Polygon","coordinates":[[[1.5,1.5],[2.5,1.5],[2.5,2.5],[1.5,2.5],[1.5,1.5]]]}`, false},
+		{"polygon overlapping", `{"type":"Polygon","coordinates":[[[3.5,3.5],[6,3.5],[6,6],[3.5,6],[3.5,3.5]]]}`, true},
+	}
+
+	for _, tc := range testCases {
+		g := mustDecode(t, tc.geometry)
+		if got := Intersects(square, g); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+		if got := Intersects(g, square); got != tc.expected {
+			t.Errorf("%s (swapped): expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	square := mustDecode(t, `{"type":"Polygon","coordinates":[
+		[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,3],[3,3],[3,1],[1,1]]]}`)
+
+	testCases := []struct {
+		name     string
+		geometry string
+		expected bool
+	}{
+		{"point inside", `{"type":"Point","coordinates":[0.5,0.5]}`, true},
+		{"point in hole", `{"type":"Point","coordinates":[2,2]}`, false},
+		{"line along side", `{"type":"LineString","coordinates":[[0.5,0.5],[0.5,3.5]]}`, true},
+		{"line through hole", `{"type":"LineString","coordinates":[[0.5,2],[3.5,2]]}`, false},
+		{"line leaving", `{"type":"LineString","coordinates":[[0.5,0.5],[5,0.5]]}`, false},
+		{"polygon in corner", `{"type":"Polygon","coordinates":[[[0.2,0.2],[0.8,0.2],[0.8,0.8],[0.2,0.8],[0.2,0.2]]]}`, true},
+		{"polygon covering hole", `{"type":"Polygon","coordinates":[[[0.5,0.5],[3.5,0.5],[3.5,3.5],[0.5,3.5],[0.5,0.5]]]}`, false},
+	}
+
+	for _, tc := range testCases {
+		if got := Within(mustDecode(t, tc.geometry), square); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+
+	point := mustDecode(t, `{"type":"Point","coordinates":[0.5,0.5]}`)
+	if Within(point, mustDecode(t, `{"type":"LineString","coordinates":[[0,0],[1,1]]}`)) {
+		t.Error("nothing can be within a line")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	degree := geo.EarthRadius * math.Pi / 180
+
+	testCases := []struct {
+		geometry string
+		point    Point
+		expected float64
+	}{
+		{`{"type":"Point","coordinates":[1,0]}`, Point{0, 0}, degree},
+		{`{"type":"LineString","coordinates":[[1,-1],[1,1]]}`, Point{0, 0}, degree},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`, Point{0.5, 0.5}, 0},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`, Point{0.5, -0.5}, 0.5 * degree},
+		{`{"type":"GeometryCollection","geometries":[
+			{"type":"Point","coordinates":[3,0]},{"type":"LineString","coordinates":[[0,2],[1,2]]}]}`, Point{0, 0}, 2 * degree},
+	}
+
+	for _, tc := range testCases {
+		if got := Distance(mustDecode(t, tc.geometry), tc.point); math.Abs(got-tc.expected) > 0.005*tc.expected+1e-6 {
+			t.Errorf("Distance(%s, %v): expected %.0f, got %.0f", tc.geometry, tc.point, tc.expected, got)
+		}
+	}
+}
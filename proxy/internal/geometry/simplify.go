@@ -0,0 +1,95 @@
+package geometry
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"math"
+)
+
+// Simplify упрощает линии и кольца полигонов алгоритмом Дугласа — Пекера:
+// удаляются вершины, отстоящие от упрощенной линии не дальше tolerance метров.
+// Кольцо, которое выродилось бы меньше чем в четыре вершины, остается как есть.
+// Точки не меняются.
+func Simplify(g *Geometry, tolerance float64) *Geometry {
+	result := &Geometry{Type: g.Type, Points: g.Points}
+	for _, line := range g.Lines {
+		result.Lines = append(result.Lines, simplifyLine(line, tolerance))
+	}
+	for _, polygon := range g.Polygons {
+		rings := make([][]Point, len(polygon))
+		for i, ring := range polygon {
+			rings[i] = ring
+			if simplified := simplifyLine(ring, tolerance); len(simplified) >= 4 {
+				rings[i] = simplified
+			}
+		}
+		result.Polygons = append(result.Polygons, rings)
+	}
+	for _, child := range g.Geometries {
+		result.Geometries = append(result.Geometries, Simplify(child, tolerance))
+	}
+	return result
+}
+
+// simplifyLine отмечает сохраняемые вершины без рекурсии, чтобы длинные
+// линии не упирались в глубину стека.
+func simplifyLine(line []Point, tolerance float64) []Point {
+	if len(line) <= 2 {
+		return line
+	}
+	projected := project(line)
+	keep := make([]bool, len(line))
+	keep[0], keep[len(line)-1] = true, true
+
+	stack := [][2]int{{0, len(line) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		farthest, maxDistance := -1, tolerance
+		for i := first + 1; i < last; i++ {
+			if d := segmentDistance(projected[i], projected[first], projected[last]); d > maxDistance {
+				farthest, maxDistance = i, d
+			}
+		}
+		if farthest < 0 {
+			continue
+		}
+		keep[farthest] = true
+		stack = append(stack, [2]int{first, farthest}, [2]int{farthest, last})
+	}
+
+	result := make([]Point, 0, len(line))
+	for i, p := range line {
+		if keep[i] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// project переводит вершины в метры равнопромежуточной проекцией с центром
+// в первой вершине. Для допусков в метрах на линиях до сотен километров
+// искажения несущественны.
+func project(line []Point) [][2]float64 {
+	origin := line[0]
+	scale := math.Cos(radians(origin.Lat()))
+	projected := make([][2]float64, len(line))
+	for i, p := range line {
+		projected[i] = [2]float64{
+			radians(p.Lon()-origin.Lon()) * scale * geo.EarthRadius,
+			radians(p.Lat()-origin.Lat()) * geo.EarthRadius,
+		}
+	}
+	return projected
+}
+
+// segmentDistance — расстояние от точки p до отрезка ab на плоскости.
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-a[0]-t*dx, p[1]-a[1]-t*dy)
+}
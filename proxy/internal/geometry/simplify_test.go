@@ -0,0 +1,38 @@
+package geometry
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	// 0.001° широты ≈ 111 м
+	testCases := []struct {
+		name      string
+		geometry  string
+		tolerance float64
+		expected  string
+	}{
+		{"small deviation removed", `{"type":"LineString","coordinates":[[0,0],[0.01,0.0001],[0.02,0]]}`, 50,
+			`{"type":"LineString","coordinates":[[0,0],[0.02,0]]}`},
+		{"large deviation kept", `{"type":"LineString","coordinates":[[0,0],[0.01,0.001],[0.02,0]]}`, 50,
+			`{"type":"LineString","coordinates":[[0,0],[0.01,0.001],[0.02,0]]}`},
+		{"collinear vertices removed", `{"type":"LineString","coordinates":[[0,0],[0,0.01],[0,0.02],[0,0.03]]}`, 1,
+			`{"type":"LineString","coordinates":[[0,0],[0,0.03]]}`},
+		{"ring vertex removed", `{"type":"Polygon","coordinates":[[[0,0],[0.01,0.00001],[0.02,0],[0.02,0.02],[0,0.02],[0,0]]]}`, 10,
+			`{"type":"Polygon","coordinates":[[[0,0],[0.02,0],[0.02,0.02],[0,0.02],[0,0]]]}`},
+		{"collapsing ring kept", `{"type":"Polygon","coordinates":[[[0,0],[0.001,0],[0.001,0.001],[0,0]]]}`, 1000,
+			`{"type":"Polygon","coordinates":[[[0,0],[0.001,0],[0.001,0.001],[0,0]]]}`},
+		{"points unchanged", `{"type":"MultiPoint","coordinates":[[0,0],[0,0.00001]]}`, 1000,
+			`{"type":"MultiPoint","coordinates":[[0,0],[0,0.00001]]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := json.Marshal(Simplify(mustDecode(t, tc.geometry), tc.tolerance).Encode())
+			if string(got) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
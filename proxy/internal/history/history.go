@@ -0,0 +1,210 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("revision not found")
+	ErrStorage  = errors.New("history storage failed")
+)
+
+// Change — изменение одного объекта для записи в журнал. Key — ключ
+// объекта, например "geofences/alice/3f2a…", Data — состояние объекта после
+// изменения (nil для удаления), RevertedTo — ревизия, к которой объект
+// вернули.
+type Change struct {
+	Key        string
+	Action     string
+	Data       interface{}
+	RevertedTo int
+}
+
+// entry — строка файла журнала.
+type entry struct {
+	Key string `json:"key"`
+	models.Revision
+}
+
+// Log — журнал изменений только на дописывание: каждая ревизия — строка
+// JSON в конце файла, записанные строки не меняются. Ревизии всех объектов
+// держатся в памяти для выборки по номеру и времени. Безопасен для
+// параллельного использования.
+type Log struct {
+	mu        sync.RWMutex
+	path      string
+	revisions map[string][]models.Revision
+}
+
+// Open читает журнал из файла JSON Lines; отсутствующий файл будет создан
+// при первой записи. Пустой path — журнал только в памяти. Оборванная
+// последняя строка — след сбоя при дописывании — отрезается, ошибка в любой
+// другой строке не дает открыть журнал.
+func Open(path string) (*Log, error) {
+	l := &Log{path: path, revisions: map[string][]models.Revision{}}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := decoder.InputOffset()
+		var e entry
+		err := decoder.Decode(&e)
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			if err := os.Truncate(path, offset); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: offset %d: %w", path, offset, err)
+		}
+		l.revisions[e.Key] = append(l.revisions[e.Key], e.Revision)
+	}
+	return l, nil
+}
+
+// Record дописывает ревизии изменений одной записью: при ошибке
+// в журнале не остается ни одной.
+func (l *Log) Record(author string, at time.Time, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]entry, len(changes))
+	var buf bytes.Buffer
+	for i, change := range changes {
+		e := entry{Key: change.Key, Revision: models.Revision{
+			Revision:   len(l.revisions[change.Key]) + 1,
+			Action:     change.Action,
+			Author:     author,
+			Timestamp:  at,
+			RevertedTo: change.RevertedTo,
+		}}
+		for _, previous := range entries[:i] {
+			if previous.Key == change.Key {
+				e.Revision.Revision++
+			}
+		}
+		if change.Data != nil {
+			data, err := json.Marshal(change.Data)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrStorage, err)
+			}
+			e.Data = data
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrStorage, err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		entries[i] = e
+	}
+
+	if l.path != "" {
+		if err := appendFile(l.path, buf.Bytes()); err != nil {
+			return fmt.Errorf("%w: %v", ErrStorage, err)
+		}
+	}
+	for _, e := range entries {
+		l.revisions[e.Key] = append(l.revisions[e.Key], e.Revision)
+	}
+	return nil
+}
+
+// appendFile дописывает данные в конец файла и сбрасывает их на диск.
+// Если запись не удалась, файл обрезается до прежнего размера.
+func appendFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		file.Truncate(info.Size())
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Revisions возвращает ревизии объекта от первой к последней без
+// состояний объекта.
+func (l *Log) Revisions(key string) ([]models.Revision, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	revisions, ok := l.revisions[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: no history for %s", ErrNotFound, key)
+	}
+	result := make([]models.Revision, len(revisions))
+	for i, revision := range revisions {
+		result[i] = revision
+		result[i].Data = nil
+	}
+	return result, nil
+}
+
+// Revision возвращает ревизию объекта по номеру.
+func (l *Log) Revision(key string, number int) (*models.Revision, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	revisions := l.revisions[key]
+	if number < 1 || number > len(revisions) {
+		return nil, fmt.Errorf("%w: %s, revision %d", ErrNotFound, key, number)
+	}
+	revision := revisions[number-1]
+	return &revision, nil
+}
+
+// At возвращает ревизию, действовавшую в момент at, — последнюю записанную
+// не позже него.
+func (l *Log) At(key string, at time.Time) (*models.Revision, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	revisions := l.revisions[key]
+	i := sort.Search(len(revisions), func(i int) bool { return revisions[i].Timestamp.After(at) })
+	if i == 0 {
+		return nil, fmt.Errorf("%w: %s did not exist at %s", ErrNotFound, key, at.Format(time.RFC3339))
+	}
+	revision := revisions[i-1]
+	return &revision, nil
+}
@@ -0,0 +1,125 @@
+package history
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := log.Record("alice", start, []Change{
+		{Key: "a", Action: models.RevisionCreate, Data: map[string]string{"name": "v1"}},
+		{Key: "b", Action: models.RevisionCreate, Data: map[string]string{"name": "b"}},
+	}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := log.Record("bob", start.Add(time.Hour), []Change{{Key: "a", Action: models.RevisionUpdate, Data: map[string]string{"name": "v2"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Record("alice", start.Add(2*time.Hour), []Change{{Key: "a", Action: models.RevisionDelete}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	revisions, err := reopened.Revisions("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 || revisions[1].Revision != 2 || revisions[1].Author != "bob" || revisions[2].Action != models.RevisionDelete {
+		t.Errorf("unexpected revisions: %+v", revisions)
+	}
+	if revisions[0].Data != nil {
+		t.Error("expected revision list without data")
+	}
+
+	testCases := []struct {
+		at       time.Time
+		expected int
+	}{
+		{start, 1},
+		{start.Add(90 * time.Minute), 2},
+		{start.Add(3 * time.Hour), 3},
+	}
+	for _, tc := range testCases {
+		revision, err := reopened.At("a", tc.at)
+		if err != nil || revision.Revision != tc.expected {
+			t.Errorf("At(%s): expected revision %d, got %+v (%v)", tc.at, tc.expected, revision, err)
+		}
+	}
+	if revision, _ := reopened.At("a", start.Add(time.Hour)); string(revision.Data) != `{"name":"v2"}` {
+		t.Errorf("expected data of revision 2, got %s", revision.Data)
+	}
+	if _, err := reopened.At("a", start.Add(-time.Second)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound before creation, got %v", err)
+	}
+	if _, err := reopened.Revision("a", 4); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reopened.Revisions("c"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLogTruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	log, _ := Open(path)
+	if err := log.Record("alice", time.Now(), []Change{{Key: "a", Action: models.RevisionCreate, Data: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	// сбой посреди дописывания оставляет неполную строку
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"key":"a","revision":2,"act`)
+	file.Close()
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := reopened.Record("alice", time.Now(), []Change{{Key: "a", Action: models.RevisionDelete}}); err != nil {
+		t.Fatal(err)
+	}
+	revisions, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open after append: %v", err)
+	}
+	if got, _ := revisions.Revisions("a"); len(got) != 2 || got[1].Revision != 2 {
+		t.Errorf("expected two revisions, got %+v", got)
+	}
+
+	if err := os.WriteFile(path, []byte("{\"key\":\"a\"}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("expected error for corrupted line")
+	}
+}
+
+func TestLogStorageFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	log, _ := Open(filepath.Join(dir, "history.jsonl"))
+	// файл на месте каталога не дает записать журнал
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Record("alice", time.Now(), []Change{{Key: "a", Action: models.RevisionCreate, Data: 1}}); !errors.Is(err, ErrStorage) {
+		t.Errorf("expected ErrStorage, got %v", err)
+	}
+	if _, err := log.Revisions("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected no revisions after failure, got %v", err)
+	}
+}
@@ -0,0 +1,862 @@
+package layer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/history"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/spatial"
+	"geo-controller/proxy/internal/storage"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Ограничения хранилища.
+const (
+	maxLayers            = 100
+	maxFeatures          = 50000
+	maxMembers           = 100
+	maxNameLength        = 200
+	maxDescriptionLength = 2000
+	maxFeatureIDLength   = 100
+)
+
+// FeatureType — значение поля type объекта GeoJSON.
+const FeatureType = "Feature"
+
+var (
+	ErrNotFound  = errors.New("layer not found")
+	ErrForbidden = errors.New("layer access denied")
+	ErrInvalid   = errors.New("invalid layer")
+	ErrStorage   = errors.New("layer storage failed")
+)
+
+// Права доступа к слою.
+type access int
+
+const (
+	accessRead access = iota
+	accessEdit
+	accessManage
+)
+
+// feature — объект слоя с разобранной геометрией.
+type feature struct {
+	models.Feature
+	geometry *geometry.Geometry
+	rect     spatial.Rect
+}
+
+// layer — слой с объектами и R-деревом их охватывающих прямоугольников.
+type layer struct {
+	models.Layer
+	features map[string]*feature
+	tree     *spatial.RTree[*feature]
+}
+
+// storedLayer — слой в файле хранилища.
+type storedLayer struct {
+	models.Layer
+	Features []models.Feature `json:"features"`
+}
+
+// storeFile — формат файла хранилища.
+type storeFile struct {
+	Layers []storedLayer `json:"layers"`
+}
+
+// Store хранит слои карты пользователей и ищет объекты слоя по области,
+// пересечению, вложенности и расстоянию. Как и хранилище геозон, записывает
+// файл целиком до изменения в памяти: при ошибке записи хранилище остается
+// прежним. Слой, который пользователю не виден, для него не существует:
+// методы возвращают ErrNotFound, а не ErrForbidden. Каждое изменение
+// объекта записывается в журнал изменений. Безопасен для параллельного
+// использования.
+type Store struct {
+	mu      sync.RWMutex
+	path    string
+	layers  map[string]*layer
+	history *history.Log
+	now     func() time.Time
+}
+
+type Option func(*Store)
+
+// WithHistory задает журнал изменений объектов. По умолчанию журнал
+// хранится только в памяти.
+func WithHistory(log *history.Log) Option {
+	return func(s *Store) {
+		s.history = log
+	}
+}
+
+// Open загружает хранилище из файла JSON; отсутствующий файл будет создан
+// при первом изменении. Пустой path — хранилище только в памяти.
+func Open(path string, options ...Option) (*Store, error) {
+	s := &Store{path: path, layers: map[string]*layer{}, now: time.Now}
+	for _, option := range options {
+		option(s)
+	}
+	if s.history == nil {
+		s.history, _ = history.Open("")
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	var file storeFile
+	if _, err := storage.ReadJSON(path, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, stored := range file.Layers {
+		meta, err := newMeta(stored.Layer)
+		if err != nil {
+			return nil, fmt.Errorf("%s: layer %s: %w", path, stored.ID, err)
+		}
+		l := &layer{Layer: meta, features: map[string]*feature{}, tree: spatial.NewRTree[*feature]()}
+		for _, f := range stored.Features {
+			parsed, err := newFeature(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: layer %s, feature %s: %w", path, stored.ID, f.ID, err)
+			}
+			l.features[parsed.ID] = parsed
+			l.tree.Insert(parsed.rect, parsed)
+		}
+		s.layers[l.ID] = l
+	}
+	return s, nil
+}
+
+// newMeta проверяет свойства слоя и убирает повторы из списков участников.
+func newMeta(meta models.Layer) (models.Layer, error) {
+	if strings.TrimSpace(meta.Name) == "" {
+		return meta, fmt.Errorf("%w: name cannot be empty", ErrInvalid)
+	}
+	if utf8.RuneCountInString(meta.Name) > maxNameLength {
+		return meta, fmt.Errorf("%w: name is longer than %d characters", ErrInvalid, maxNameLength)
+	}
+	if utf8.RuneCountInString(meta.Description) > maxDescriptionLength {
+		return meta, fmt.Errorf("%w: description is longer than %d characters", ErrInvalid, maxDescriptionLength)
+	}
+	if len(meta.Editors)+len(meta.Viewers) > maxMembers {
+		return meta, fmt.Errorf("%w: at most %d editors and viewers", ErrInvalid, maxMembers)
+	}
+	var err error
+	if meta.Editors, err = members(meta.Editors, meta.Owner); err != nil {
+		return meta, err
+	}
+	if meta.Viewers, err = members(meta.Viewers, meta.Owner); err != nil {
+		return meta, err
+	}
+	meta.FeatureCount = 0
+	return meta, nil
+}
+
+// members очищает список пользователей: владелец и повторы пропускаются.
+func members(users []string, owner string) ([]string, error) {
+	var result []string
+	for _, user := range users {
+		user = strings.TrimSpace(user)
+		if user == "" {
+			return nil, fmt.Errorf("%w: user name cannot be empty", ErrInvalid)
+		}
+		if user != owner && !contains(result, user) {
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+
+func contains(users []string, user string) bool {
+	for _, u := range users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+// newFeature проверяет объект и приводит геометрию к каноническому виду.
+// Объекты без геометрии не принимаются: их нельзя найти по области.
+func newFeature(f models.Feature) (*feature, error) {
+	if f.Type != "" && f.Type != FeatureType {
+		return nil, fmt.Errorf("%w: feature type must be %q, got %q", ErrInvalid, FeatureType, f.Type)
+	}
+	if utf8.RuneCountInString(f.ID) > maxFeatureIDLength {
+		return nil, fmt.Errorf("%w: feature id is longer than %d characters", ErrInvalid, maxFeatureIDLength)
+	}
+	g, err := geometry.Decode(f.Geometry)
+	if err != nil {
+		return nil, fmt.Errorf("%w: feature %s: %v", ErrInvalid, f.ID, err)
+	}
+	parsed := &feature{Feature: f, geometry: g, rect: geometry.Rect(g)}
+	parsed.Type = FeatureType
+	parsed.Geometry = g.Encode()
+	parsed.Distance = nil
+	if f.Properties != nil {
+		parsed.Properties = make(map[string]interface{}, len(f.Properties))
+		for key, value := range f.Properties {
+			parsed.Properties[key] = value
+		}
+	}
+	return parsed, nil
+}
+
+func (l *layer) allows(user string, level access) bool {
+	switch level {
+	case accessManage:
+		return l.Owner == user
+	case accessEdit:
+		return l.Owner == user || contains(l.Editors, user)
+	}
+	return l.Public || l.Owner == user || contains(l.Editors, user) || contains(l.Viewers, user)
+}
+
+// info возвращает свойства слоя с числом объектов.
+func (l *layer) info() *models.Layer {
+	meta := l.Layer
+	meta.FeatureCount = len(l.features)
+	return &meta
+}
+
+// lookup находит слой с проверкой прав. Вызывается под s.mu.
+func (s *Store) lookup(user, id string, level access) (*layer, error) {
+	l, ok := s.layers[id]
+	if !ok || !l.allows(user, accessRead) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if !l.allows(user, level) {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, id)
+	}
+	return l, nil
+}
+
+// Layers возвращает слои, которые видит пользователь, по названию.
+func (s *Store) Layers(user string) []models.Layer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []models.Layer{}
+	for _, l := range s.layers {
+		if l.allows(user, accessRead) {
+			result = append(result, *l.info())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+func (s *Store) Layer(user, id string) (*models.Layer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, err := s.lookup(user, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return l.info(), nil
+}
+
+// CreateLayer создает пустой слой, владельцем которого становится user.
+func (s *Store) CreateLayer(user string, request models.LayerRequest) (*models.Layer, error) {
+	now := s.now().UTC()
+	meta, err := newMeta(models.Layer{
+		ID:          storage.NewID(),
+		Name:        request.Name,
+		Description: request.Description,
+		Owner:       user,
+		Public:      request.Public,
+		Editors:     request.Editors,
+		Viewers:     request.Viewers,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owned := 0
+	for _, l := range s.layers {
+		if l.Owner == user {
+			owned++
+		}
+	}
+	if owned >= maxLayers {
+		return nil, fmt.Errorf("%w: at most %d layers per user", ErrInvalid, maxLayers)
+	}
+	if err := s.commit(change{layer: meta.ID, meta: &meta, author: user, at: now}); err != nil {
+		return nil, err
+	}
+	return s.layers[meta.ID].info(), nil
+}
+
+// UpdateLayer заменяет свойства и участников слоя. Доступно только владельцу.
+func (s *Store) UpdateLayer(user, id string, request models.LayerRequest) (*models.Layer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.lookup(user, id, accessManage)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := newMeta(models.Layer{
+		ID:          id,
+		Name:        request.Name,
+		Description: request.Description,
+		Owner:       l.Owner,
+		Public:      request.Public,
+		Editors:     request.Editors,
+		Viewers:     request.Viewers,
+		CreatedAt:   l.CreatedAt,
+		UpdatedAt:   s.now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.commit(change{layer: id, meta: &meta, author: user, at: meta.UpdatedAt}); err != nil {
+		return nil, err
+	}
+	return s.layers[id].info(), nil
+}
+
+// DeleteLayer удаляет слой вместе с объектами. Доступно только владельцу.
+func (s *Store) DeleteLayer(user, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.lookup(user, id, accessManage); err != nil {
+		return err
+	}
+	return s.commit(change{layer: id, drop: true, author: user, at: s.now().UTC()})
+}
+
+func (s *Store) Feature(user, layerID, id string) (*models.Feature, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, err := s.lookup(user, layerID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := l.features[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: feature %s", ErrNotFound, id)
+	}
+	result := f.Feature
+	return &result, nil
+}
+
+// PutFeatures добавляет объекты одной записью: объект с идентификатором
+// существующего заменяет его, без идентификатора получает новый. Ошибка
+// в любом объекте отменяет всю загрузку.
+func (s *Store) PutFeatures(user, layerID string, features []models.Feature) ([]models.Feature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.lookup(user, layerID, accessEdit)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]*feature, len(features))
+	order := make([]string, 0, len(features))
+	created := 0
+	for i, f := range features {
+		if f.ID == "" {
+			f.ID = storage.NewID()
+		}
+		if _, duplicate := changes[f.ID]; duplicate {
+			return nil, fmt.Errorf("%w: feature %d: duplicate id %q", ErrInvalid, i+1, f.ID)
+		}
+		parsed, err := newFeature(f)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i+1, err)
+		}
+		changes[f.ID] = parsed
+		order = append(order, f.ID)
+		if _, exists := l.features[f.ID]; !exists {
+			created++
+		}
+	}
+	if len(l.features)+created > maxFeatures {
+		return nil, fmt.Errorf("%w: at most %d features per layer", ErrInvalid, maxFeatures)
+	}
+	if err := s.commit(s.touch(user, l, changes)); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Feature, len(order))
+	for i, id := range order {
+		result[i] = changes[id].Feature
+	}
+	return result, nil
+}
+
+// UpdateFeature заменяет существующий объект.
+func (s *Store) UpdateFeature(user, layerID, id string, f models.Feature) (*models.Feature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.lookup(user, layerID, accessEdit)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := l.features[id]; !ok {
+		return nil, fmt.Errorf("%w: feature %s", ErrNotFound, id)
+	}
+	f.ID = id
+	parsed, err := newFeature(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.commit(s.touch(user, l, map[string]*feature{id: parsed})); err != nil {
+		return nil, err
+	}
+	result := parsed.Feature
+	return &result, nil
+}
+
+func (s *Store) DeleteFeature(user, layerID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.lookup(user, layerID, accessEdit)
+	if err != nil {
+		return err
+	}
+	if _, ok := l.features[id]; !ok {
+		return fmt.Errorf("%w: feature %s", ErrNotFound, id)
+	}
+	return s.commit(s.touch(user, l, map[string]*feature{id: nil}))
+}
+
+// touch собирает изменение объектов слоя пользователем user с новым
+// временем изменения слоя.
+func (s *Store) touch(user string, l *layer, features map[string]*feature) change {
+	meta := l.Layer
+	meta.UpdatedAt = s.now().UTC()
+	return change{layer: l.ID, meta: &meta, features: features, author: user, at: meta.UpdatedAt}
+}
+
+// historyLookup проверяет, что пользователь может читать историю объектов
+// слоя. Права удаленного слоя берутся из его последнего состояния
+// в журнале, поэтому история остается доступной тем же пользователям.
+// Вызывается под s.mu.
+func (s *Store) historyLookup(user, id string) error {
+	if _, ok := s.layers[id]; ok {
+		_, err := s.lookup(user, id, accessRead)
+		return err
+	}
+	meta, err := s.deletedMeta(id)
+	if err != nil {
+		return err
+	}
+	if !(&layer{Layer: *meta}).allows(user, accessRead) {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return nil
+}
+
+// deletedMeta возвращает свойства удаленного слоя — последнюю ревизию
+// слоя в журнале с состоянием. Вызывается под s.mu.
+func (s *Store) deletedMeta(id string) (*models.Layer, error) {
+	revisions, _ := s.history.Revisions(layerKey(id))
+	for n := len(revisions); n > 0; n-- {
+		revision, err := s.history.Revision(layerKey(id), n)
+		if err != nil || revision.Data == nil {
+			continue
+		}
+		var meta models.Layer
+		if err := json.Unmarshal(revision.Data, &meta); err != nil {
+			return nil, fmt.Errorf("%w: layer %s, revision %d: %v", ErrStorage, id, n, err)
+		}
+		return &meta, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// FeatureRevisions возвращает ревизии объекта слоя, в том числе удаленного
+// или из удаленного слоя.
+func (s *Store) FeatureRevisions(user, layerID, id string) ([]models.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.historyLookup(user, layerID); err != nil {
+		return nil, err
+	}
+	revisions, err := s.history.Revisions(historyKey(layerID, id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: feature %s", ErrNotFound, id)
+	}
+	return revisions, nil
+}
+
+// FeatureRevision возвращает ревизию объекта с его состоянием.
+func (s *Store) FeatureRevision(user, layerID, id string, number int) (*models.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.historyLookup(user, layerID); err != nil {
+		return nil, err
+	}
+	return s.revision(layerID, id, number)
+}
+
+func (s *Store) revision(layerID, id string, number int) (*models.Revision, error) {
+	revision, err := s.history.Revision(historyKey(layerID, id), number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: feature %s, revision %d", ErrNotFound, id, number)
+	}
+	return revision, nil
+}
+
+// FeatureAt возвращает объект в том виде, какой он был в момент at.
+func (s *Store) FeatureAt(user, layerID, id string, at time.Time) (*models.Feature, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.historyLookup(user, layerID); err != nil {
+		return nil, err
+	}
+	revision, err := s.history.At(historyKey(layerID, id), at)
+	if err != nil || revision.Data == nil {
+		return nil, fmt.Errorf("%w: feature %s at %s", ErrNotFound, id, at.Format(time.RFC3339))
+	}
+	var f models.Feature
+	if err := json.Unmarshal(revision.Data, &f); err != nil {
+		return nil, fmt.Errorf("%w: revision %d: %v", ErrStorage, revision.Revision, err)
+	}
+	return &f, nil
+}
+
+// RevertFeature возвращает объект к состоянию ревизии number:
+// восстанавливает удаленный, заменяет текущий или удаляет, если ревизия —
+// удаление. Возврат записывается в журнал новой ревизией; для удаления
+// результат nil. Объект удаленного слоя вернуть нельзя: слоя для него нет.
+func (s *Store) RevertFeature(user, layerID, id string, number int) (*models.Feature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.lookup(user, layerID, accessEdit)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := s.revision(layerID, id, number)
+	if err != nil {
+		return nil, err
+	}
+	_, exists := l.features[id]
+	c := s.touch(user, l, nil)
+	c.revertedTo = number
+	if revision.Data == nil {
+		if !exists {
+			return nil, fmt.Errorf("%w: feature %s is already deleted", ErrInvalid, id)
+		}
+		c.features = map[string]*feature{id: nil}
+		return nil, s.commit(c)
+	}
+
+	var f models.Feature
+	if err := json.Unmarshal(revision.Data, &f); err != nil {
+		return nil, fmt.Errorf("%w: revision %d: %v", ErrStorage, number, err)
+	}
+	if !exists && len(l.features) >= maxFeatures {
+		return nil, fmt.Errorf("%w: at most %d features per layer", ErrInvalid, maxFeatures)
+	}
+	f.ID = id
+	parsed, err := newFeature(f)
+	if err != nil {
+		return nil, err
+	}
+	c.features = map[string]*feature{id: parsed}
+	if err := s.commit(c); err != nil {
+		return nil, err
+	}
+	result := parsed.Feature
+	return &result, nil
+}
+
+// Query — условия поиска объектов слоя. BBoxes — области, с которыми
+// должен пересекаться охватывающий прямоугольник объекта (хотя бы одна),
+// Intersects и Within — геометрии для проверки пересечения и вложенности,
+// Near — точка, по расстоянию до которой упорядочиваются объекты.
+// Limit — наибольшее число объектов, 0 — без ограничения. Пустые условия
+// не проверяются.
+type Query struct {
+	BBoxes     []spatial.Rect
+	Intersects *geometry.Geometry
+	Within     *geometry.Geometry
+	Near       *geometry.Point
+	Limit      int
+}
+
+// Query возвращает объекты слоя, подходящие под условия: без Near —
+// по идентификатору, с Near — от ближнего к дальнему с расстоянием
+// в метрах.
+func (s *Store) Query(user, layerID string, query Query) ([]models.Feature, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, err := s.lookup(user, layerID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	if query.Near != nil {
+		return l.nearest(query), nil
+	}
+
+	var found []*feature
+	collect := func(f *feature) bool {
+		if query.matches(f) {
+			found = append(found, f)
+		}
+		return true
+	}
+	if len(query.BBoxes) == 0 {
+		for _, f := range l.features {
+			collect(f)
+		}
+	} else {
+		// объект может попасть в обе части области через антимеридиан
+		seen := map[*feature]bool{}
+		for _, rect := range query.BBoxes {
+			l.tree.Search(rect, func(f *feature) bool {
+				if seen[f] {
+					return true
+				}
+				seen[f] = true
+				return collect(f)
+			})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	if query.Limit > 0 && len(found) > query.Limit {
+		found = found[:query.Limit]
+	}
+
+	result := make([]models.Feature, len(found))
+	for i, f := range found {
+		result[i] = f.Feature
+	}
+	return result, nil
+}
+
+// nearest обходит дерево в порядке расстояния до прямоугольников. Для линий
+// и полигонов оно лишь нижняя граница, поэтому найденные объекты хранятся
+// отсортированными по точному расстоянию, а обход заканчивается, когда
+// граница следующего превышает последнее из Limit лучших.
+func (l *layer) nearest(query Query) []models.Feature {
+	type candidate struct {
+		feature  *feature
+		distance float64
+	}
+	var best []candidate
+	full := func() bool { return query.Limit > 0 && len(best) >= query.Limit }
+	point := *query.Near
+
+	bound := func(rect spatial.Rect) float64 { return rect.GreatCircleDistance(point.Lat(), point.Lon()) }
+	l.tree.Nearest(bound, func(f *feature, lower float64) bool {
+		if full() && lower > best[len(best)-1].distance {
+			return false
+		}
+		if !query.matches(f) {
+			return true
+		}
+		// точное расстояние не меньше границы прямоугольника
+		d := math.Max(geometry.Distance(f.geometry, point), lower)
+		i := sort.Search(len(best), func(i int) bool { return best[i].distance > d })
+		best = append(best, candidate{})
+		copy(best[i+1:], best[i:])
+		best[i] = candidate{feature: f, distance: d}
+		if query.Limit > 0 && len(best) > query.Limit {
+			best = best[:query.Limit]
+		}
+		return true
+	})
+
+	result := make([]models.Feature, len(best))
+	for i, c := range best {
+		result[i] = c.feature.Feature
+		distance := c.distance
+		result[i].Distance = &distance
+	}
+	return result
+}
+
+func (q Query) matches(f *feature) bool {
+	if len(q.BBoxes) > 0 {
+		inside := false
+		for _, rect := range q.BBoxes {
+			inside = inside || rect.Intersects(f.rect)
+		}
+		if !inside {
+			return false
+		}
+	}
+	if q.Intersects != nil && !geometry.Intersects(f.geometry, q.Intersects) {
+		return false
+	}
+	return q.Within == nil || geometry.Within(f.geometry, q.Within)
+}
+
+// change — изменение одного слоя: новые свойства (nil — прежние), объекты
+// по идентификаторам (nil — удаление) или удаление слоя целиком. author
+// и at попадают в журнал, revertedTo — ревизия, к которой вернули объект.
+type change struct {
+	layer      string
+	meta       *models.Layer
+	features   map[string]*feature
+	drop       bool
+	author     string
+	at         time.Time
+	revertedTo int
+}
+
+// commit записывает хранилище с изменением в файл, изменения объектов —
+// в журнал и применяет изменение к памяти и индексу слоя. Если журнал
+// записать не удалось, файл возвращается к прежнему состоянию.
+// Вызывается под s.mu.
+func (s *Store) commit(c change) error {
+	if err := s.write(&c); err != nil {
+		return fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	if err := s.history.Record(c.author, c.at, s.changes(c)); err != nil {
+		s.write(nil)
+		return fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+
+	if c.drop {
+		delete(s.layers, c.layer)
+		return nil
+	}
+	l, ok := s.layers[c.layer]
+	if !ok {
+		l = &layer{features: map[string]*feature{}, tree: spatial.NewRTree[*feature]()}
+		s.layers[c.layer] = l
+	}
+	if c.meta != nil {
+		l.Layer = *c.meta
+	}
+	for id, f := range c.features {
+		if old, ok := l.features[id]; ok {
+			l.tree.Delete(old.rect, func(v *feature) bool { return v == old })
+			delete(l.features, id)
+		}
+		if f != nil {
+			l.features[id] = f
+			l.tree.Insert(f.rect, f)
+		}
+	}
+	return nil
+}
+
+// write записывает файл хранилища с изменением c, nil — текущее состояние.
+func (s *Store) write(c *change) error {
+	if s.path == "" {
+		return nil
+	}
+	file := storeFile{Layers: make([]storedLayer, 0, len(s.layers)+1)}
+	for id, l := range s.layers {
+		if c == nil || id != c.layer {
+			file.Layers = append(file.Layers, l.stored(nil))
+		}
+	}
+	if c != nil && !c.drop {
+		l, ok := s.layers[c.layer]
+		if !ok {
+			l = &layer{features: map[string]*feature{}}
+		}
+		if c.meta != nil {
+			l = &layer{Layer: *c.meta, features: l.features}
+		}
+		file.Layers = append(file.Layers, l.stored(c.features))
+	}
+	sort.Slice(file.Layers, func(i, j int) bool { return file.Layers[i].ID < file.Layers[j].ID })
+	return storage.WriteJSON(s.path, file)
+}
+
+// changes возвращает изменения объектов слоя для журнала. Удаление слоя —
+// удаление всех его объектов. Создание, изменение и удаление самого слоя
+// записываются под ключом слоя: по ним проверяются права на историю
+// объектов удаленного слоя.
+func (s *Store) changes(c change) []history.Change {
+	current := map[string]*feature{}
+	if l, ok := s.layers[c.layer]; ok {
+		current = l.features
+	}
+	features := c.features
+	if c.drop {
+		features = make(map[string]*feature, len(current))
+		for id := range current {
+			features[id] = nil
+		}
+	}
+
+	var changes []history.Change
+	for id, f := range features {
+		_, exists := current[id]
+		change := history.Change{Key: historyKey(c.layer, id), RevertedTo: c.revertedTo}
+		switch {
+		case f == nil && !exists:
+			continue
+		case f == nil:
+			change.Action = models.RevisionDelete
+		case exists:
+			change.Action, change.Data = models.RevisionUpdate, f.Feature
+		default:
+			change.Action, change.Data = models.RevisionCreate, f.Feature
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	// изменения объектов тоже несут свойства слоя — с новым временем
+	// изменения, но без новых прав
+	_, exists := s.layers[c.layer]
+	switch {
+	case c.drop:
+		changes = append(changes, history.Change{Key: layerKey(c.layer), Action: models.RevisionDelete})
+	case c.meta != nil && c.features == nil && exists:
+		changes = append(changes, history.Change{Key: layerKey(c.layer), Action: models.RevisionUpdate, Data: *c.meta})
+	case c.meta != nil && c.features == nil:
+		changes = append(changes, history.Change{Key: layerKey(c.layer), Action: models.RevisionCreate, Data: *c.meta})
+	}
+	return changes
+}
+
+func layerKey(layerID string) string {
+	return "layers/" + layerID
+}
+
+func historyKey(layerID, id string) string {
+	return layerKey(layerID) + "/features/" + id
+}
+
+// stored возвращает слой для записи в файл с изменениями объектов.
+func (l *layer) stored(changes map[string]*feature) storedLayer {
+	result := storedLayer{Layer: l.Layer, Features: make([]models.Feature, 0, len(l.features)+len(changes))}
+	for id, f := range l.features {
+		if _, changed := changes[id]; !changed {
+			result.Features = append(result.Features, f.Feature)
+		}
+	}
+	for _, f := range changes {
+		if f != nil {
+			result.Features = append(result.Features, f.Feature)
+		}
+	}
+	sort.Slice(result.Features, func(i, j int) bool { return result.Features[i].ID < result.Features[j].ID })
+	return result
+}
@@ -0,0 +1,402 @@
+package layer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/history"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T, path string) *Store {
+	t.Helper()
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return store
+}
+
+func pointFeature(id string, lon, lat float64) models.Feature {
+	return models.Feature{
+		ID:       id,
+		Geometry: models.Geometry{Type: "Point", Coordinates: json.RawMessage(fmt.Sprintf("[%g,%g]", lon, lat))},
+	}
+}
+
+func squareFeature(id string, west, south, size float64) models.Feature {
+	coordinates := fmt.Sprintf("[[[%g,%g],[%g,%g],[%g,%g],[%g,%g],[%g,%g]]]",
+		west, south, west+size, south, west+size, south+size, west, south+size, west, south)
+	return models.Feature{ID: id, Geometry: models.Geometry{Type: "Polygon", Coordinates: json.RawMessage(coordinates)}}
+}
+
+func mustDecode(t *testing.T, raw string) *geometry.Geometry {
+	t.Helper()
+	var g models.Geometry
+	if err := json.Unmarshal([]byte(raw), &g); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := geometry.Decode(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestStoreCRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "features.json")
+	store := openTestStore(t, path)
+
+	created, err := store.CreateLayer("alice", models.LayerRequest{Name: "Парковки", Editors: []string{"bob", "bob", "alice"}})
+	if err != nil {
+		t.Fatalf("CreateLayer: %v", err)
+	}
+	if created.Owner != "alice" || len(created.Editors) != 1 || created.Editors[0] != "bob" {
+		t.Errorf("unexpected layer: %+v", created)
+	}
+
+	features, err := store.PutFeatures("bob", created.ID, []models.Feature{
+		pointFeature("", 37.61, 55.75),
+		squareFeature("zone", 37, 55, 1),
+	})
+	if err != nil {
+		t.Fatalf("PutFeatures: %v", err)
+	}
+	if len(features) != 2 || features[0].ID == "" || features[1].ID != "zone" || features[1].Type != FeatureType {
+		t.Errorf("unexpected features: %+v", features)
+	}
+
+	updated, err := store.UpdateFeature("alice", created.ID, "zone", squareFeature("other", 38, 55, 1))
+	if err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	if updated.ID != "zone" {
+		t.Errorf("expected id from path, got %q", updated.ID)
+	}
+
+	reopened := openTestStore(t, path)
+	if got, err := reopened.Layer("alice", created.ID); err != nil || got.FeatureCount != 2 {
+		t.Errorf("expected layer with 2 features to persist, got %+v (%v)", got, err)
+	}
+	if got, err := reopened.Feature("alice", created.ID, "zone"); err != nil || string(got.Geometry.Coordinates) != string(updated.Geometry.Coordinates) {
+		t.Errorf("expected updated feature to persist, got %+v (%v)", got, err)
+	}
+
+	if err := store.DeleteFeature("bob", created.ID, "zone"); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+	if _, err := store.Feature("alice", created.ID, "zone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := store.DeleteLayer("alice", created.ID); err != nil {
+		t.Fatalf("DeleteLayer: %v", err)
+	}
+	if _, err := store.Layer("alice", created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if got := openTestStore(t, path).Layers("alice"); len(got) != 0 {
+		t.Errorf("expected no layers after delete, got %+v", got)
+	}
+}
+
+func TestStoreAccess(t *testing.T) {
+	store := openTestStore(t, "")
+	private, _ := store.CreateLayer("alice", models.LayerRequest{Name: "Личный", Editors: []string{"bob"}, Viewers: []string{"carol"}})
+	public, _ := store.CreateLayer("alice", models.LayerRequest{Name: "Общий", Public: true})
+
+	testCases := []struct {
+		user    string
+		layer   string
+		read    error
+		edit    error
+		manage  error
+		visible int
+	}{
+		{"alice", private.ID, nil, nil, nil, 2},
+		{"bob", private.ID, nil, nil, ErrForbidden, 2},
+		{"carol", private.ID, nil, ErrForbidden, ErrForbidden, 2},
+		{"dave", private.ID, ErrNotFound, ErrNotFound, ErrNotFound, 1},
+		{"dave", public.ID, nil, ErrForbidden, ErrForbidden, 1},
+	}
+
+	for _, tc := range testCases {
+		check := func(action string, err, expected error) {
+			if expected == nil && err != nil || expected != nil && !errors.Is(err, expected) {
+				t.Errorf("%s %s %s: expected %v, got %v", tc.user, action, tc.layer, expected, err)
+			}
+		}
+		_, err := store.Query(tc.user, tc.layer, Query{})
+		check("read", err, tc.read)
+		_, err = store.PutFeatures(tc.user, tc.layer, []models.Feature{pointFeature("p", 0, 0)})
+		check("edit", err, tc.edit)
+		request := models.LayerRequest{Name: "Личный", Editors: []string{"bob"}, Viewers: []string{"carol"}}
+		if tc.layer == public.ID {
+			request = models.LayerRequest{Name: "Общий", Public: true}
+		}
+		_, err = store.UpdateLayer(tc.user, tc.layer, request)
+		check("manage", err, tc.manage)
+		if got := store.Layers(tc.user); len(got) != tc.visible {
+			t.Errorf("%s: expected %d visible layers, got %d", tc.user, tc.visible, len(got))
+		}
+	}
+}
+
+func TestStoreInvalid(t *testing.T) {
+	store := openTestStore(t, "")
+	for _, request := range []models.LayerRequest{
+		{},
+		{Name: "a", Editors: []string{" "}},
+	} {
+		if _, err := store.CreateLayer("alice", request); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: expected ErrInvalid, got %v", request, err)
+		}
+	}
+
+	l, _ := store.CreateLayer("alice", models.LayerRequest{Name: "a"})
+	for _, features := range [][]models.Feature{
+		{{ID: "a"}},
+		{{Type: "Point", Geometry: pointFeature("", 0, 0).Geometry}},
+		{pointFeature("a", 0, 0), pointFeature("a", 1, 1)},
+		{pointFeature("a", 0, 0), pointFeature("b", 0, 91)},
+	} {
+		if _, err := store.PutFeatures("alice", l.ID, features); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: expected ErrInvalid, got %v", features, err)
+		}
+	}
+	// ошибка в любом объекте отменяет загрузку целиком
+	if got, _ := store.Layer("alice", l.ID); got.FeatureCount != 0 {
+		t.Errorf("expected unchanged layer, got %d features", got.FeatureCount)
+	}
+}
+
+func TestStoreQuery(t *testing.T) {
+	store := openTestStore(t, "")
+	l, _ := store.CreateLayer("alice", models.LayerRequest{Name: "a"})
+	_, err := store.PutFeatures("alice", l.ID, []models.Feature{
+		pointFeature("inside", 0.5, 0.5),
+		pointFeature("outside", 5, 5),
+		pointFeature("east", 179.5, 0),
+		squareFeature("overlap", 1.5, 1.5, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	square := mustDecode(t, `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`)
+
+	testCases := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"all", Query{}, []string{"east", "inside", "outside", "overlap"}},
+		{"limit", Query{Limit: 1}, []string{"east"}},
+		{"bbox", Query{BBoxes: []spatial.Rect{{MinX: 0, MinY: 0, MaxX: 2, MaxY: 2}}}, []string{"inside", "overlap"}},
+		{"antimeridian", Query{BBoxes: []spatial.Rect{{MinX: 179, MinY: -1, MaxX: 180, MaxY: 1}, {MinX: -180, MinY: -1, MaxX: 1, MaxY: 1}}}, []string{"east", "inside"}},
+		{"intersects", Query{Intersects: square}, []string{"inside", "overlap"}},
+		{"within", Query{Within: square}, []string{"inside"}},
+	}
+
+	for _, tc := range testCases {
+		features, err := store.Query("alice", l.ID, tc.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		var ids []string
+		for _, f := range features {
+			ids = append(ids, f.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, ids)
+		}
+	}
+}
+
+func TestStoreQueryNearest(t *testing.T) {
+	store := openTestStore(t, "")
+	l, _ := store.CreateLayer("alice", models.LayerRequest{Name: "a"})
+	random := rand.New(rand.NewSource(1))
+	var features []models.Feature
+	for i := 0; i < 2000; i++ {
+		lon, lat := random.Float64()*40+20, random.Float64()*20+40
+		if i%2 == 0 {
+			features = append(features, pointFeature(fmt.Sprint(i), lon, lat))
+		} else {
+			features = append(features, squareFeature(fmt.Sprint(i), lon, lat, random.Float64()))
+		}
+	}
+	if _, err := store.PutFeatures("alice", l.ID, features); err != nil {
+		t.Fatal(err)
+	}
+	parsed := make([]*geometry.Geometry, len(features))
+	for i, f := range features {
+		g, _ := geometry.Decode(f.Geometry)
+		parsed[i] = g
+	}
+
+	for k := 0; k < 20; k++ {
+		point := geometry.Point{random.Float64()*40 + 20, random.Float64()*20 + 40}
+		var expected []float64
+		for _, g := range parsed {
+			expected = append(expected, geometry.Distance(g, point))
+		}
+		sort.Float64s(expected)
+
+		got, err := store.Query("alice", l.ID, Query{Near: &point, Limit: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 5 {
+			t.Fatalf("expected 5 features, got %d", len(got))
+		}
+		for i, f := range got {
+			if f.Distance == nil || math.Abs(*f.Distance-expected[i]) > 1e-3 {
+				t.Fatalf("point %v: result %d: expected %.3f, got %.3f", point, i, expected[i], *f.Distance)
+			}
+		}
+	}
+
+	// расстояние до точки совпадает с расстоянием по большому кругу
+	origin := geometry.Point{20, 40}
+	got, _ := store.Query("alice", l.ID, Query{Near: &origin, Limit: 1, Within: mustDecode(t,
+		`{"type":"Polygon","coordinates":[[[20,40],[60,40],[60,60],[20,60],[20,40]]]}`)})
+	if len(got) != 1 || got[0].Distance == nil {
+		t.Fatalf("expected one feature, got %+v", got)
+	}
+	if got[0].Geometry.Type == "Point" {
+		var p geometry.Point
+		_ = json.Unmarshal(got[0].Geometry.Coordinates, &p)
+		if d := geo.Haversine(40, 20, p.Lat(), p.Lon()); math.Abs(d-*got[0].Distance) > 1e-3 {
+			t.Errorf("expected %.3f, got %.3f", d, *got[0].Distance)
+		}
+	}
+}
+
+func TestStoreHistory(t *testing.T) {
+	dir := t.TempDir()
+	log, err := history.Open(filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := Open(filepath.Join(dir, "features.json"), WithHistory(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return clock }
+
+	l, _ := store.CreateLayer("alice", models.LayerRequest{Name: "a", Editors: []string{"bob"}, Viewers: []string{"carol"}})
+	if _, err := store.PutFeatures("alice", l.ID, []models.Feature{pointFeature("p", 37, 55)}); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(time.Hour)
+	if _, err := store.UpdateFeature("bob", l.ID, "p", pointFeature("", 38, 56)); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(time.Hour)
+	if err := store.DeleteFeature("alice", l.ID, "p"); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := store.FeatureRevisions("carol", l.ID, "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 || revisions[1].Author != "bob" || revisions[1].Action != models.RevisionUpdate || revisions[2].Action != models.RevisionDelete {
+		t.Errorf("unexpected revisions: %+v", revisions)
+	}
+	if _, err := store.FeatureRevisions("dave", l.ID, "p"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for hidden layer, got %v", err)
+	}
+
+	past, err := store.FeatureAt("carol", l.ID, "p", clock.Add(-90*time.Minute))
+	if err != nil || string(past.Geometry.Coordinates) != "[37,55]" {
+		t.Errorf("expected first version, got %+v (%v)", past, err)
+	}
+	if _, err := store.FeatureAt("carol", l.ID, "p", clock); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+
+	if _, err := store.RevertFeature("carol", l.ID, "p", 1); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for viewer, got %v", err)
+	}
+	restored, err := store.RevertFeature("bob", l.ID, "p", 1)
+	if err != nil || string(restored.Geometry.Coordinates) != "[37,55]" {
+		t.Fatalf("expected restored feature, got %+v (%v)", restored, err)
+	}
+	if got, err := store.Feature("alice", l.ID, "p"); err != nil || got.ID != "p" {
+		t.Errorf("expected feature back in layer, got %+v (%v)", got, err)
+	}
+	revision, err := store.FeatureRevision("alice", l.ID, "p", 4)
+	if err != nil || revision.Action != models.RevisionCreate || revision.RevertedTo != 1 || revision.Author != "bob" {
+		t.Errorf("expected revert to be recorded, got %+v (%v)", revision, err)
+	}
+
+	// удаление слоя записывает удаление каждого объекта
+	if err := store.DeleteLayer("alice", l.ID); err != nil {
+		t.Fatal(err)
+	}
+	if revisions, _ := log.Revisions(historyKey(l.ID, "p")); len(revisions) != 5 || revisions[4].Action != models.RevisionDelete {
+		t.Errorf("expected deletion with layer, got %+v", revisions)
+	}
+
+	// история объектов удаленного слоя доступна его участникам по правам
+	// из журнала, в том числе после перезапуска
+	reopened, err := Open(filepath.Join(dir, "features.json"), WithHistory(mustOpenHistory(t, filepath.Join(dir, "history.jsonl"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*Store{store, reopened} {
+		if revisions, err := s.FeatureRevisions("carol", l.ID, "p"); err != nil || len(revisions) != 5 {
+			t.Errorf("expected history of deleted layer, got %+v (%v)", revisions, err)
+		}
+		if revision, err := s.FeatureRevision("bob", l.ID, "p", 1); err != nil || revision.Data == nil {
+			t.Errorf("expected revision of deleted layer, got %+v (%v)", revision, err)
+		}
+		if past, err := s.FeatureAt("alice", l.ID, "p", clock.Add(-90*time.Minute)); err != nil || past.ID != "p" {
+			t.Errorf("expected feature of deleted layer, got %+v (%v)", past, err)
+		}
+		if _, err := s.FeatureRevisions("dave", l.ID, "p"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for stranger, got %v", err)
+		}
+		if _, err := s.RevertFeature("alice", l.ID, "p", 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for revert in deleted layer, got %v", err)
+		}
+	}
+	if _, err := store.FeatureRevisions("alice", "missing", "p"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown layer, got %v", err)
+	}
+}
+
+func mustOpenHistory(t *testing.T, path string) *history.Log {
+	t.Helper()
+	log, err := history.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func TestStoreStorageFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	store := openTestStore(t, filepath.Join(dir, "features.json"))
+	// файл на месте каталога не дает записать хранилище
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateLayer("alice", models.LayerRequest{Name: "a"}); !errors.Is(err, ErrStorage) {
+		t.Errorf("expected ErrStorage, got %v", err)
+	}
+	if got := store.Layers("alice"); len(got) != 0 {
+		t.Errorf("expected unchanged store, got %d layers", len(got))
+	}
+}
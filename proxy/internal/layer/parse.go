@@ -0,0 +1,75 @@
+package layer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+)
+
+// FeatureCollectionType — значение поля type коллекции объектов GeoJSON.
+const FeatureCollectionType = "FeatureCollection"
+
+// rawFeature — объект GeoJSON, идентификатор которого может быть строкой
+// или числом (RFC 7946, раздел 3.2).
+type rawFeature struct {
+	Type       string                 `json:"type"`
+	ID         json.RawMessage        `json:"id"`
+	Geometry   *models.Geometry       `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// ParseFeatures разбирает Feature или FeatureCollection GeoJSON. Числовые
+// идентификаторы приводятся к строкам, как их записывает источник.
+func ParseFeatures(data []byte) ([]models.Feature, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	var header struct {
+		Type     string       `json:"type"`
+		Features []rawFeature `json:"features"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	raw := header.Features
+	switch header.Type {
+	case FeatureCollectionType:
+	case FeatureType:
+		var f rawFeature
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		raw = []rawFeature{f}
+	default:
+		return nil, fmt.Errorf("%w: expected Feature or FeatureCollection, got %q", ErrInvalid, header.Type)
+	}
+
+	features := make([]models.Feature, len(raw))
+	for i, f := range raw {
+		if f.Geometry == nil {
+			return nil, fmt.Errorf("%w: feature %d: geometry is required", ErrInvalid, i+1)
+		}
+		id, err := featureID(f.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: feature %d: %v", ErrInvalid, i+1, err)
+		}
+		features[i] = models.Feature{Type: f.Type, ID: id, Geometry: *f.Geometry, Properties: f.Properties}
+	}
+	return features, nil
+}
+
+func featureID(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id, nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return "", fmt.Errorf("id must be a string or a number")
+	}
+	return number.String(), nil
+}
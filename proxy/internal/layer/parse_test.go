@@ -0,0 +1,34 @@
+package layer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFeatures(t *testing.T) {
+	features, err := ParseFeatures([]byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","id":17,"geometry":{"type":"Point","coordinates":[37.6,55.7]},"properties":{"name":"a"}},
+		{"type":"Feature","id":"b","geometry":{"type":"Point","coordinates":[30.3,59.9]},"properties":null}]}`))
+	if err != nil {
+		t.Fatalf("ParseFeatures: %v", err)
+	}
+	if len(features) != 2 || features[0].ID != "17" || features[0].Properties["name"] != "a" || features[1].ID != "b" {
+		t.Errorf("unexpected features: %+v", features)
+	}
+
+	features, err = ParseFeatures([]byte("\ufeff" + `{"type":"Feature","geometry":{"type":"Point","coordinates":[37.6,55.7]},"properties":{}}`))
+	if err != nil || len(features) != 1 || features[0].ID != "" {
+		t.Errorf("expected one feature without id, got %+v (%v)", features, err)
+	}
+
+	for _, data := range []string{
+		`[]`,
+		`{"type":"Point","coordinates":[0,0]}`,
+		`{"type":"Feature","geometry":null,"properties":{}}`,
+		`{"type":"Feature","id":[1],"geometry":{"type":"Point","coordinates":[0,0]}}`,
+	} {
+		if _, err := ParseFeatures([]byte(data)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", data, err)
+		}
+	}
+}
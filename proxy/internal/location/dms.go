@@ -0,0 +1,285 @@
+package location
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Единицы компонентов координаты.
+const (
+	unitDegrees = iota + 1
+	unitMinutes
+	unitSeconds
+)
+
+var unitNames = map[int]string{unitDegrees: "degrees", unitMinutes: "minutes", unitSeconds: "seconds"}
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenUnit
+	tokenHemisphere
+	tokenSeparator
+)
+
+type token struct {
+	kind       tokenKind
+	text       string
+	value      float64
+	unit       int
+	hemisphere rune
+}
+
+// unitOf распознает знаки градуса, минуты и секунды, включая их замены
+// с клавиатуры: апостроф и кавычку.
+func unitOf(r rune) int {
+	switch r {
+	case '°', 'º', '˚':
+		return unitDegrees
+	case '′', '\'', '’', '‘':
+		return unitMinutes
+	case '″', '"', '”', '“':
+		return unitSeconds
+	}
+	return 0
+}
+
+// hemisphereOf распознает буквы полушарий, латинские и русские (С, Ю, В, З).
+func hemisphereOf(r rune) rune {
+	switch unicode.ToUpper(r) {
+	case 'N', 'С':
+		return 'N'
+	case 'S', 'Ю':
+		return 'S'
+	case 'E', 'В':
+		return 'E'
+	case 'W', 'З':
+		return 'W'
+	}
+	return 0
+}
+
+// parseDMS разбирает две координаты в градусах, минутах и секундах.
+// Единицы можно опустить: тогда числа читаются по порядку.
+func parseDMS(s string) (models.GeoPoint, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return models.GeoPoint{}, err
+	}
+	groups, err := splitCoordinates(tokens)
+	if err != nil {
+		return models.GeoPoint{}, err
+	}
+
+	var values [2]float64
+	var hemispheres [2]rune
+	for i, group := range groups {
+		if values[i], hemispheres[i], err = parseCoordinate(group); err != nil {
+			return models.GeoPoint{}, fmt.Errorf("%s coordinate: %v", []string{"first", "second"}[i], err)
+		}
+	}
+
+	first, second := axisOf(hemispheres[0]), axisOf(hemispheres[1])
+	switch {
+	case first != "" && first == second:
+		return models.GeoPoint{}, fmt.Errorf("both coordinates are %ss", first)
+	case first == "longitude" || second == "latitude":
+		return checkRange(values[1], values[0])
+	case first == "latitude" || second == "longitude":
+		return checkRange(values[0], values[1])
+	}
+	return orderPair(values[0], values[1])
+}
+
+func tokenize(s string) ([]token, error) {
+	runes := []rune(s)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '-' || r == '−' || r == '+' || r == '.':
+			start := i
+			for i++; i < len(runes) && isNumberRune(runes, i); i++ {
+			}
+			text := string(runes[start:i])
+			value, err := parseNumber(text)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value})
+		case r == ',' || r == ';':
+			tokens = append(tokens, token{kind: tokenSeparator, text: string(r)})
+			i++
+		case unitOf(r) != 0:
+			unit := unitOf(r)
+			// две апострофа подряд — секунды
+			if unit == unitMinutes && i+1 < len(runes) && unitOf(runes[i+1]) == unitMinutes {
+				unit = unitSeconds
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenUnit, text: string(r), unit: unit})
+			i++
+		case hemisphereOf(r) != 0:
+			tokens = append(tokens, token{kind: tokenHemisphere, text: string(r), hemisphere: hemisphereOf(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+// isNumberRune: цифра, точка или десятичная запятая между цифрами.
+func isNumberRune(runes []rune, i int) bool {
+	r := runes[i]
+	if unicode.IsDigit(r) || r == '.' {
+		return true
+	}
+	return r == ',' && unicode.IsDigit(runes[i-1]) && i+1 < len(runes) && unicode.IsDigit(runes[i+1])
+}
+
+// splitCoordinates делит лексемы на две координаты: по запятой, по буквам
+// полушарий или по второму знаку градуса.
+func splitCoordinates(tokens []token) ([2][]token, error) {
+	var groups [2][]token
+	split := -1
+	separators := 0
+	for i, t := range tokens {
+		if t.kind == tokenSeparator {
+			separators++
+			split = i
+		}
+	}
+
+	switch {
+	case separators > 1:
+		return groups, errors.New("expected one separator between coordinates")
+	case separators == 1:
+		groups[0], groups[1] = tokens[:split], tokens[split+1:]
+	case len(tokens) > 0 && tokens[0].kind == tokenHemisphere:
+		// буква перед координатой: вторая начинается со следующей буквы
+		split = indexOf(tokens[1:], tokenHemisphere, 0) + 1
+		if split > 0 {
+			groups[0], groups[1] = tokens[:split], tokens[split:]
+		}
+	case indexOf(tokens, tokenHemisphere, 0) >= 0:
+		// буква после координаты: первая заканчивается на ней
+		split = indexOf(tokens, tokenHemisphere, 0) + 1
+		groups[0], groups[1] = tokens[:split], tokens[split:]
+	default:
+		first := indexOfUnit(tokens, unitDegrees, 0)
+		second := indexOfUnit(tokens, unitDegrees, first+1)
+		if first >= 0 && second > 0 {
+			groups[0], groups[1] = tokens[:second-1], tokens[second-1:]
+		}
+	}
+
+	if len(groups[0]) == 0 || len(groups[1]) == 0 {
+		return groups, errors.New("expected two coordinates; separate them with a comma")
+	}
+	return groups, nil
+}
+
+func indexOf(tokens []token, kind tokenKind, from int) int {
+	for i := from; i < len(tokens); i++ {
+		if tokens[i].kind == kind {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexOfUnit(tokens []token, unit, from int) int {
+	for i := from; i < len(tokens); i++ {
+		if tokens[i].kind == tokenUnit && tokens[i].unit == unit {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseCoordinate разбирает одну координату: необязательная буква полушария
+// до или после чисел и от одного до трех чисел с единицами или без них.
+func parseCoordinate(tokens []token) (float64, rune, error) {
+	var hemisphere rune
+	if tokens[0].kind == tokenHemisphere {
+		hemisphere, tokens = tokens[0].hemisphere, tokens[1:]
+	}
+	if n := len(tokens); n > 0 && tokens[n-1].kind == tokenHemisphere {
+		if hemisphere != 0 {
+			return 0, 0, errors.New("hemisphere is given twice")
+		}
+		hemisphere, tokens = tokens[n-1].hemisphere, tokens[:n-1]
+	}
+
+	var components [3]*token
+	position := 0
+	for i := 0; i < len(tokens); i++ {
+		number := &tokens[i]
+		if number.kind != tokenNumber {
+			return 0, 0, fmt.Errorf("unexpected %q", number.text)
+		}
+		unit := position + 1
+		if i+1 < len(tokens) && tokens[i+1].kind == tokenUnit {
+			unit = tokens[i+1].unit
+			i++
+		}
+		switch {
+		case unit > unitSeconds:
+			return 0, 0, errors.New("too many numbers")
+		case unit <= position:
+			return 0, 0, errors.New("degrees, minutes and seconds must go in this order")
+		}
+		components[unit-1] = number
+		position = unit
+	}
+
+	degrees, minutes, seconds := components[0], components[1], components[2]
+	if degrees == nil {
+		return 0, 0, errors.New("degrees are missing")
+	}
+	negative := strings.HasPrefix(degrees.text, "-") || strings.HasPrefix(degrees.text, "−")
+	if negative && hemisphere != 0 {
+		return 0, 0, errors.New("negative value together with hemisphere letter")
+	}
+
+	value := math.Abs(degrees.value)
+	for i, c := range []*token{minutes, seconds} {
+		if c == nil {
+			continue
+		}
+		unit := unitMinutes + i
+		if c.value < 0 {
+			return 0, 0, fmt.Errorf("%s cannot be negative", unitNames[unit])
+		}
+		if c.value >= 60 {
+			return 0, 0, fmt.Errorf("%s must be less than 60, got %s", unitNames[unit], c.text)
+		}
+		if previous := components[unit-2]; previous != nil && previous.value != math.Trunc(previous.value) {
+			return 0, 0, fmt.Errorf("%s must be whole when %s are given", unitNames[unit-1], unitNames[unit])
+		}
+		value += c.value / math.Pow(60, float64(i+1))
+	}
+
+	if negative || hemisphere == 'S' || hemisphere == 'W' {
+		value = -value
+	}
+	return value, hemisphere, nil
+}
+
+func axisOf(hemisphere rune) string {
+	switch hemisphere {
+	case 'N', 'S':
+		return "latitude"
+	case 'E', 'W':
+		return "longitude"
+	}
+	return ""
+}
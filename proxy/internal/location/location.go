@@ -0,0 +1,170 @@
+package location
+
+import (
+	"fmt"
+	"geo-controller/proxy/internal/geocell"
+	"geo-controller/proxy/internal/models"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Форматы строки местоположения.
+const (
+	FormatDMS      = "dms"
+	FormatDecimal  = "decimal"
+	FormatGeohash  = "geohash"
+	FormatPlusCode = "plus_code"
+)
+
+var formatNames = map[string]string{
+	FormatDMS:      "degrees, minutes and seconds",
+	FormatDecimal:  "decimal degrees",
+	FormatGeohash:  "geohash",
+	FormatPlusCode: "plus code",
+}
+
+// ParseError сообщает, какой формат распознан во входной строке и почему
+// его не удалось разобрать. Format пуст, если формат не распознан.
+type ParseError struct {
+	Input  string
+	Format string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	if e.Format == "" {
+		return fmt.Sprintf("cannot parse location %q: %s", e.Input, e.Reason)
+	}
+	return fmt.Sprintf("cannot parse location %q as %s: %s", e.Input, formatNames[e.Format], e.Reason)
+}
+
+// Parse разбирает местоположение в одном из форматов:
+//   - градусы, минуты, секунды: "55°45′21″N 37°37′04″E", "N 55 45.35, E 37 37.07";
+//   - пара десятичных градусов: "55.7558, 37.6176", "37.6176 55.7558";
+//   - геохеш: "ucftpuzx";
+//   - полный plus code: "9G7VQJ4J+8C".
+//
+// Для геохеша и plus code возвращается центр ячейки. Если порядок координат
+// не задан буквами полушарий, первой считается широта, а если первое число
+// по модулю больше 90 — долгота.
+func Parse(input string) (models.GeoPoint, string, error) {
+	s := strings.TrimSpace(input)
+	format := detect(s)
+	fail := func(reason string) (models.GeoPoint, string, error) {
+		return models.GeoPoint{}, format, &ParseError{Input: input, Format: format, Reason: reason}
+	}
+
+	var (
+		point models.GeoPoint
+		err   error
+	)
+	switch format {
+	case "":
+		return fail("location is empty")
+	case FormatPlusCode:
+		var box models.BoundingBox
+		if box, err = geocell.DecodePlusCode(strings.Fields(s)[0]); err == nil {
+			point = geocell.Center(box)
+		}
+	case FormatGeohash:
+		var box models.BoundingBox
+		if box, err = geocell.DecodeGeohash(s); err == nil {
+			point = geocell.Center(box)
+		}
+	case FormatDMS:
+		point, err = parseDMS(s)
+	case FormatDecimal:
+		point, err = parseDecimal(s)
+	}
+	if err != nil {
+		return fail(err.Error())
+	}
+	return point, format, nil
+}
+
+// detect определяет формат по виду строки: plus code содержит '+' внутри
+// первого слова, геохеш — одно слово из букв и цифр, градусы с минутами —
+// знаки градусов, минут, секунд или буквы полушарий.
+func detect(s string) string {
+	fields := strings.FieldsFunc(s, isSeparator)
+	switch {
+	case len(fields) == 0:
+		return ""
+	case strings.IndexByte(strings.Fields(s)[0], '+') > 0:
+		return FormatPlusCode
+	case len(fields) == 1 && isAlphanumeric(s) && strings.IndexFunc(s, unicode.IsLetter) >= 0:
+		return FormatGeohash
+	case strings.IndexFunc(s, func(r rune) bool { return unitOf(r) != 0 || hemisphereOf(r) != 0 }) >= 0:
+		return FormatDMS
+	}
+	return FormatDecimal
+}
+
+func isSeparator(r rune) bool {
+	return r == ',' || r == ';' || unicode.IsSpace(r)
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDecimal разбирает пару чисел через запятую, точку с запятой или
+// пробел. Десятичная запятая допускается, если числа разделены пробелом
+// или точкой с запятой: "55,7558 37,6176".
+func parseDecimal(s string) (models.GeoPoint, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ';' || unicode.IsSpace(r) })
+	if len(fields) == 1 {
+		fields = strings.Split(fields[0], ",")
+	}
+	var values []float64
+	for _, field := range fields {
+		field = strings.Trim(field, ",")
+		if field == "" {
+			continue
+		}
+		value, err := parseNumber(field)
+		if err != nil {
+			return models.GeoPoint{}, err
+		}
+		values = append(values, value)
+	}
+	if len(values) != 2 {
+		return models.GeoPoint{}, fmt.Errorf("expected two numbers, got %d", len(values))
+	}
+	return orderPair(values[0], values[1])
+}
+
+func parseNumber(s string) (float64, error) {
+	normalized := strings.Replace(strings.Replace(s, "−", "-", 1), ",", ".", 1)
+	value, err := strconv.ParseFloat(normalized, 64)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return value, nil
+}
+
+// orderPair определяет порядок координат без букв полушарий.
+func orderPair(a, b float64) (models.GeoPoint, error) {
+	lat, lon := a, b
+	if math.Abs(a) > 90 && math.Abs(b) <= 90 {
+		lat, lon = b, a
+	}
+	return checkRange(lat, lon)
+}
+
+func checkRange(lat, lon float64) (models.GeoPoint, error) {
+	if math.Abs(lat) > 90 {
+		return models.GeoPoint{}, fmt.Errorf("latitude %g is out of range ±90", lat)
+	}
+	if math.Abs(lon) > 180 {
+		return models.GeoPoint{}, fmt.Errorf("longitude %g is out of range ±180", lon)
+	}
+	return models.GeoPoint{Lat: lat, Lon: lon}, nil
+}
@@ -0,0 +1,99 @@
+package location
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input    string
+		format   string
+		lat, lon float64
+	}{
+		{"55°45′21″N 37°37′04″E", FormatDMS, 55.755833, 37.617778},
+		{"55°45'21\"N, 37°37'04\"E", FormatDMS, 55.755833, 37.617778},
+		{"55°45′21″ 37°37′04″", FormatDMS, 55.755833, 37.617778},
+		{"37°37′04″E 55°45′21″N", FormatDMS, 55.755833, 37.617778},
+		{"N 55 45.35, E 37 37.07", FormatDMS, 55.755833, 37.617833},
+		{"55°45′21″ С, 37°37′04″ В", FormatDMS, 55.755833, 37.617778},
+		{"33°55′29.6″S 18°25′26.8″E", FormatDMS, -33.924889, 18.424111},
+		{"40°42′46″N 74°00′22″W", FormatDMS, 40.712778, -74.006111},
+		{"-33.9249°, 18.4241°", FormatDMS, -33.9249, 18.4241},
+		{"55.7558N 37.6176E", FormatDMS, 55.7558, 37.6176},
+		{"55°45′21″N 37°37′04″", FormatDMS, 55.755833, 37.617778},
+		{"55.7558, 37.6176", FormatDecimal, 55.7558, 37.6176},
+		{"55.7558,37.6176", FormatDecimal, 55.7558, 37.6176},
+		{"55.7558 37.6176", FormatDecimal, 55.7558, 37.6176},
+		{"55,7558 37,6176", FormatDecimal, 55.7558, 37.6176},
+		{"55,7558; 37,6176", FormatDecimal, 55.7558, 37.6176},
+		{"-122.4194, 37.7749", FormatDecimal, 37.7749, -122.4194},
+		{"-74.006, 40.7128", FormatDecimal, -74.006, 40.7128},
+		{"  -33.9249 , 18.4241 ", FormatDecimal, -33.9249, 18.4241},
+		{"ucfv0j2s", FormatGeohash, 55.7523, 37.6180},
+		{"8FVC9G8F+6X", FormatPlusCode, 47.365563, 8.524938},
+		{"8fvc9g8f+6x", FormatPlusCode, 47.365563, 8.524938},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			point, format, err := Parse(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if format != tc.format {
+				t.Errorf("expected format %s, got %s", tc.format, format)
+			}
+			if math.Abs(point.Lat-tc.lat) > 1e-3 || math.Abs(point.Lon-tc.lon) > 1e-3 {
+				t.Errorf("expected %.6f, %.6f, got %.6f, %.6f", tc.lat, tc.lon, point.Lat, point.Lon)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		input  string
+		format string
+		reason string
+	}{
+		{"   ", "", "location is empty"},
+		{"55.7558", FormatDecimal, "expected two numbers, got 1"},
+		{"55.7558, 37.6176, 12", FormatDecimal, "expected two numbers, got 3"},
+		{"55.7.558, 37.6176", FormatDecimal, `"55.7.558" is not a number`},
+		{"95, 200", FormatDecimal, "latitude 95 is out of range"},
+		{"55, 200", FormatDecimal, "longitude 200 is out of range"},
+		{"55°75′21″N 37°37′04″E", FormatDMS, "first coordinate: minutes must be less than 60, got 75"},
+		{"55°45′61″N 37°37′04″E", FormatDMS, "first coordinate: seconds must be less than 60"},
+		{"55°45′21″N 37°37′04″N", FormatDMS, "both coordinates are latitudes"},
+		{"-55°45′21″N 37°37′04″E", FormatDMS, "negative value together with hemisphere letter"},
+		{"55′45°N 37°E", FormatDMS, "must go in this order"},
+		{"55.5°45′N 37°E", FormatDMS, "degrees must be whole when minutes are given"},
+		{"55°45′21″", FormatDMS, "expected two coordinates"},
+		{"55° 37° 12°", FormatDMS, "second coordinate: degrees, minutes and seconds must go in this order"},
+		{"55°45′21″N; 37°37′04″E; 1", FormatDMS, "expected one separator"},
+		{"55°45′21″N 37°37′04″E!", FormatDMS, "unexpected character '!'"},
+		{"N E", FormatDMS, "degrees are missing"},
+		{"ucfv0j2a", FormatGeohash, "invalid geohash character 'a' at position 8"},
+		{"9G8F+6X Zurich", FormatPlusCode, "short plus code requires a reference location"},
+		{"8FVC9G8F+6", FormatPlusCode, "single character after '+'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, format, err := Parse(tc.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected ParseError, got %v", err)
+			}
+			if format != tc.format || parseErr.Format != tc.format {
+				t.Errorf("expected format %q, got %q", tc.format, parseErr.Format)
+			}
+			if !strings.Contains(err.Error(), tc.reason) {
+				t.Errorf("expected reason %q, got %q", tc.reason, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package locator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+// dayNames — дни недели opening_hours по порядку time.Weekday.
+var dayNames = []string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
+
+// Hours — недельное расписание. Разбирается подмножество формата
+// opening_hours OpenStreetMap, которого хватает для магазинов:
+//
+//	24/7
+//	Mo-Fr 09:00-21:00; Sa,Su 10:00-18:00
+//	Mo-Su 08:00-13:00,14:00-20:00; Su off
+//	Fr-Sa 18:00-02:00
+//
+// Правило с днями заменяет расписание этих дней, заданное раньше; правило
+// без дней относится ко всей неделе. Интервал с концом не позже начала
+// продолжается после полуночи. Праздники, месяцы и недели не поддерживаются.
+type Hours struct {
+	// week — интервалы по дням time.Weekday в минутах от начала суток;
+	// конец интервала после полуночи больше minutesPerDay
+	week [7][]interval
+}
+
+type interval struct {
+	start, end int
+}
+
+// ParseHours разбирает расписание. Пустая строка — ошибка: точку без
+// расписания не нужно проверять.
+func ParseHours(value string) (*Hours, error) {
+	hours := &Hours{}
+	rules := 0
+	for _, rule := range strings.Split(value, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if err := hours.addRule(rule); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule, err)
+		}
+		rules++
+	}
+	if rules == 0 {
+		return nil, errors.New("opening hours cannot be empty")
+	}
+	return hours, nil
+}
+
+func (h *Hours) addRule(rule string) error {
+	if rule == "24/7" {
+		for day := range h.week {
+			h.week[day] = []interval{{0, minutesPerDay}}
+		}
+		return nil
+	}
+
+	days := []int{0, 1, 2, 3, 4, 5, 6}
+	fields := strings.Fields(rule)
+	if len(fields) > 0 && !isTimeSpec(fields[0]) {
+		var err error
+		if days, err = parseDays(fields[0]); err != nil {
+			return err
+		}
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return errors.New("expected times or off after days")
+	}
+
+	// пробелы внутри списка интервалов допустимы: "09:00-13:00, 14:00-20:00"
+	intervals, err := parseTimes(strings.Join(fields, ""))
+	if err != nil {
+		return err
+	}
+	for _, day := range days {
+		h.week[day] = intervals
+	}
+	return nil
+}
+
+// isTimeSpec отличает времена и off от списка дней.
+func isTimeSpec(field string) bool {
+	return field == "off" || field == "closed" || (field != "" && field[0] >= '0' && field[0] <= '9')
+}
+
+// parseDays разбирает список дней вида "Mo-Fr,Su". Диапазон может
+// переходить через воскресенье: "Fr-Mo".
+func parseDays(spec string) ([]int, error) {
+	var days []int
+	for _, item := range strings.Split(spec, ",") {
+		bounds := strings.Split(item, "-")
+		if len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid day range %q", item)
+		}
+		first, err := parseDay(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = parseDay(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseDay(name string) (int, error) {
+	for day, dayName := range dayNames {
+		if name == dayName {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q", name)
+}
+
+// parseTimes разбирает интервалы "09:00-13:00,14:00-20:00" или off.
+func parseTimes(spec string) ([]interval, error) {
+	if spec == "off" || spec == "closed" {
+		return nil, nil
+	}
+	var intervals []interval
+	for _, item := range strings.Split(spec, ",") {
+		bounds := strings.Split(item, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid time range %q", item)
+		}
+		start, err := parseTime(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTime(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if start == minutesPerDay {
+			return nil, fmt.Errorf("time range %q starts at 24:00", item)
+		}
+		if end <= start {
+			end += minutesPerDay
+		}
+		intervals = append(intervals, interval{start, end})
+	}
+	return intervals, nil
+}
+
+// parseTime разбирает время hh:mm от 00:00 до 24:00 в минутах.
+func parseTime(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	hour, errHour := strconv.Atoi(parts[0])
+	minute, errMinute := strconv.Atoi(parts[1])
+	if errHour != nil || errMinute != nil || hour > 24 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// OpenAt сообщает, открыто ли в момент t по местному времени t.
+// Учитываются и интервалы предыдущего дня, продолжающиеся после полуночи.
+func (h *Hours) OpenAt(t time.Time) bool {
+	day, minute := int(t.Weekday()), t.Hour()*60+t.Minute()
+	for _, i := range h.week[day] {
+		if i.start <= minute && minute < i.end {
+			return true
+		}
+	}
+	for _, i := range h.week[(day+6)%7] {
+		if minute+minutesPerDay < i.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package locator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHoursOpenAt(t *testing.T) {
+	// 2024-01-01 — понедельник
+	at := func(day int, clock string) time.Time {
+		parsed, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2024, 1, 1+day, parsed.Hour(), parsed.Minute(), 0, 0, time.UTC)
+	}
+	const (
+		monday = iota
+		tuesday
+		wednesday
+		thursday
+		friday
+		saturday
+		sunday
+	)
+
+	testCases := []struct {
+		hours    string
+		at       time.Time
+		expected bool
+	}{
+		{"24/7", at(sunday, "03:00"), true},
+		{"Mo-Fr 09:00-21:00; Sa,Su 10:00-18:00", at(monday, "09:00"), true},
+		{"Mo-Fr 09:00-21:00; Sa,Su 10:00-18:00", at(monday, "21:00"), false},
+		{"Mo-Fr 09:00-21:00; Sa,Su 10:00-18:00", at(saturday, "09:30"), false},
+		{"Mo-Fr 09:00-21:00; Sa,Su 10:00-18:00", at(sunday, "17:59"), true},
+		{"Mo-Su 08:00-13:00, 14:00-20:00; Su off", at(wednesday, "13:30"), false},
+		{"Mo-Su 08:00-13:00, 14:00-20:00; Su off", at(wednesday, "14:00"), true},
+		{"Mo-Su 08:00-13:00, 14:00-20:00; Su off", at(sunday, "10:00"), false},
+		{"Fr-Sa 18:00-02:00", at(saturday, "01:30"), true},
+		{"Fr-Sa 18:00-02:00", at(sunday, "01:30"), true},
+		{"Fr-Sa 18:00-02:00", at(friday, "01:30"), false},
+		{"Fr-Sa 18:00-02:00", at(thursday, "19:00"), false},
+		{"Sa-Mo 10:00-24:00", at(monday, "23:59"), true},
+		{"Sa-Mo 10:00-24:00", at(tuesday, "10:00"), false},
+		{"10:00-22:00", at(tuesday, "12:00"), true},
+	}
+
+	for _, tc := range testCases {
+		hours, err := ParseHours(tc.hours)
+		if err != nil {
+			t.Fatalf("ParseHours(%q): %v", tc.hours, err)
+		}
+		if got := hours.OpenAt(tc.at); got != tc.expected {
+			t.Errorf("%q at %s: expected %v, got %v", tc.hours, tc.at.Format("Mon 15:04"), tc.expected, got)
+		}
+	}
+}
+
+func TestParseHoursInvalid(t *testing.T) {
+	for _, hours := range []string{"", " ; ", "Mo-Fr", "Mn 09:00-18:00", "Mo-Tu-We 09:00-18:00",
+		"Mo 9:00-18:00", "Mo 09:00", "Mo 25:00-26:00", "Mo 24:00-02:00", "Mo 09:60-18:00", "PH off"} {
+		if _, err := ParseHours(hours); err == nil {
+			t.Errorf("ParseHours(%q): expected error", hours)
+		}
+	}
+}
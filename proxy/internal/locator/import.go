@@ -0,0 +1,179 @@
+package locator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/geofile"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/models"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Столбцы CSV и свойства GeoJSON с полями точки. Остальные столбцы
+// и свойства попадают в Properties.
+var (
+	idKeys       = []string{"id", "ref"}
+	nameKeys     = []string{"name", "title"}
+	addressKeys  = []string{"address", "addr"}
+	latKeys      = []string{"lat", "latitude"}
+	lonKeys      = []string{"lon", "lng", "longitude"}
+	tagsKeys     = []string{"tags"}
+	hoursKeys    = []string{"hours", "opening_hours"}
+	timezoneKeys = []string{"timezone", "tz"}
+)
+
+// ParseCSV разбирает точки из CSV с заголовком. Разделитель — запятая
+// или точка с запятой (так сохраняет таблицы русский Excel), метки
+// в столбце tags разделяются запятой, точкой с запятой или чертой.
+func ParseCSV(data []byte) ([]models.Location, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.TrimLeadingSpace = true
+
+	columns, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalid, err)
+	}
+	for i := range columns {
+		columns[i] = strings.ToLower(strings.TrimSpace(columns[i]))
+	}
+	if findKey(columns, latKeys) == "" || findKey(columns, lonKeys) == "" {
+		return nil, fmt.Errorf("%w: header must have lat and lon columns", ErrInvalid)
+	}
+
+	var locations []models.Location
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		fields := make(map[string]string, len(columns))
+		for i, column := range columns {
+			fields[column] = strings.TrimSpace(record[i])
+		}
+		location, err := fieldsLocation(fields, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalid, row, err)
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// ParseGeoJSON разбирает точки из FeatureCollection с геометрией Point.
+// Метки задаются массивом или строкой через запятую.
+func ParseGeoJSON(data []byte) ([]models.Location, error) {
+	features, err := geofile.ParseGeoJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	locations := make([]models.Location, 0, len(features))
+	for i, f := range features {
+		if f.Geometry.Type != geometry.TypePoint {
+			return nil, fmt.Errorf("%w: feature %d: geometry must be Point, got %q", ErrInvalid, i, f.Geometry.Type)
+		}
+		point := f.Geometry.Points[0]
+		location, err := fieldsLocation(f.Properties, &models.GeoPoint{Lat: point.Lat(), Lon: point.Lon()})
+		if err != nil {
+			return nil, fmt.Errorf("%w: feature %d: %v", ErrInvalid, i, err)
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// fieldsLocation собирает точку из строковых полей. Если point не задан,
+// координаты берутся из полей lat и lon.
+func fieldsLocation(fields map[string]string, point *models.GeoPoint) (models.Location, error) {
+	location := models.Location{
+		ID:       fieldValue(fields, idKeys),
+		Name:     fieldValue(fields, nameKeys),
+		Address:  fieldValue(fields, addressKeys),
+		Tags:     splitTags(fieldValue(fields, tagsKeys)),
+		Hours:    fieldValue(fields, hoursKeys),
+		Timezone: fieldValue(fields, timezoneKeys),
+	}
+	if point == nil {
+		lat, errLat := strconv.ParseFloat(strings.Replace(fieldValue(fields, latKeys), ",", ".", 1), 64)
+		lon, errLon := strconv.ParseFloat(strings.Replace(fieldValue(fields, lonKeys), ",", ".", 1), 64)
+		if errLat != nil || errLon != nil {
+			return location, errors.New("lat and lon must be numbers")
+		}
+		point = &models.GeoPoint{Lat: lat, Lon: lon}
+	}
+	location.Point = *point
+
+	known := [][]string{idKeys, nameKeys, addressKeys, latKeys, lonKeys, tagsKeys, hoursKeys, timezoneKeys}
+	for key, value := range fields {
+		if value == "" || isKnown(key, known) {
+			continue
+		}
+		if location.Properties == nil {
+			location.Properties = map[string]string{}
+		}
+		location.Properties[key] = value
+	}
+	return location, nil
+}
+
+func fieldValue(fields map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(fields[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func findKey(columns, keys []string) string {
+	for _, key := range keys {
+		for _, column := range columns {
+			if column == key {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+func isKnown(key string, known [][]string) bool {
+	for _, keys := range known {
+		if findKey([]string{key}, keys) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTags разбирает метки из строки через запятую, точку с запятой или
+// черту либо из массива JSON, в который geofile превращает массив свойств.
+func splitTags(value string) []string {
+	if strings.HasPrefix(value, "[") {
+		var tags []string
+		if json.Unmarshal([]byte(value), &tags) == nil {
+			return tags
+		}
+	}
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
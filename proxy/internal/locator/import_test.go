@@ -0,0 +1,65 @@
+package locator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	data := "\ufeffid;Name;Address;Lat;Lon;Tags;Opening_Hours;Region\n" +
+		"msk-1;Тверская;г Москва, ул Тверская, д 7;55,7612;37,6092;pickup, 24h;24/7;Москва\n" +
+		"spb-1;\"Невский; 28\";;59.9358;30.3256;;;\n"
+	locations, err := ParseCSV([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseCSV: %v", err)
+	}
+	if len(locations) != 2 {
+		t.Fatalf("expected 2 locations, got %d", len(locations))
+	}
+
+	first := locations[0]
+	if first.ID != "msk-1" || first.Name != "Тверская" || first.Point.Lat != 55.7612 || first.Point.Lon != 37.6092 ||
+		first.Hours != "24/7" || len(first.Tags) != 2 || first.Tags[1] != "24h" || first.Properties["region"] != "Москва" {
+		t.Errorf("unexpected first location: %+v", first)
+	}
+	if second := locations[1]; second.Name != "Невский; 28" || second.Tags != nil || second.Properties != nil {
+		t.Errorf("unexpected second location: %+v", second)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"name,address\nA,B\n",
+		"name,lat,lon\nA,north,37\n",
+		"name,lat,lon\nA,55\n",
+	} {
+		if _, err := ParseCSV([]byte(invalid)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: expected ErrInvalid, got %v", invalid, err)
+		}
+	}
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	data := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[37.6092,55.7612]},
+		 "properties":{"id":"msk-1","name":"Тверская","tags":["pickup","24h"],"opening_hours":"24/7","floor":2}}]}`
+	locations, err := ParseGeoJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseGeoJSON: %v", err)
+	}
+	if len(locations) != 1 {
+		t.Fatalf("expected 1 location, got %d", len(locations))
+	}
+	location := locations[0]
+	if location.ID != "msk-1" || location.Point.Lat != 55.7612 || len(location.Tags) != 2 || location.Hours != "24/7" ||
+		location.Properties["floor"] != "2" {
+		t.Errorf("unexpected location: %+v", location)
+	}
+
+	polygon := `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{},
+		"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}]}`
+	for _, invalid := range []string{`{`, `{"type":"Feature"}`, polygon} {
+		if _, err := ParseGeoJSON([]byte(invalid)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", invalid, err)
+		}
+	}
+}
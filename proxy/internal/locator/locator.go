@@ -0,0 +1,564 @@
+package locator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/cluster"
+	"geo-controller/proxy/internal/history"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/spatial"
+	"geo-controller/proxy/internal/storage"
+	"geo-controller/proxy/internal/timezone"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Ограничения реестра.
+const (
+	maxLocations  = 100000
+	maxNameLength = 200
+	maxTags       = 20
+	maxTagLength  = 50
+)
+
+var (
+	ErrNotFound  = errors.New("location not found")
+	ErrInvalid   = errors.New("invalid location")
+	ErrStorage   = errors.New("location storage failed")
+	ErrForbidden = errors.New("location belongs to another user")
+)
+
+// place — точка с разобранным расписанием и часовым поясом.
+type place struct {
+	models.Location
+	hours *Hours
+	zone  *time.Location
+}
+
+func (p *place) rect() spatial.Rect {
+	return spatial.PointRect(p.Point.Lon, p.Point.Lat)
+}
+
+// storeFile — формат файла реестра.
+type storeFile struct {
+	Locations []models.Location `json:"locations"`
+}
+
+// Registry хранит точки сети — магазины, пункты выдачи — в R-дереве
+// и находит ближайшие к запросу. Как и хранилище геозон, записывает файл
+// целиком до изменения в памяти: при ошибке записи реестр остается прежним.
+// Каждое изменение точки записывается в журнал изменений. Безопасен для
+// параллельного использования.
+type Registry struct {
+	mu      sync.RWMutex
+	path    string
+	places  map[string]*place
+	tree    *spatial.RTree[*place]
+	history *history.Log
+	now     func() time.Time
+
+	// clusters — индекс кластеров текущих точек, строится при первом
+	// запросе после изменения. Сбрасывается в commit под mu, читается
+	// под mu.RLock и clustersMu.
+	clustersMu sync.Mutex
+	clusters   *cluster.Index[*models.Location]
+}
+
+type Option func(*Registry)
+
+// WithHistory задает журнал изменений точек. По умолчанию журнал хранится
+// только в памяти.
+func WithHistory(log *history.Log) Option {
+	return func(r *Registry) {
+		r.history = log
+	}
+}
+
+// Open загружает реестр из файла JSON; отсутствующий файл будет создан
+// при первом изменении. Пустой path — реестр только в памяти.
+func Open(path string, options ...Option) (*Registry, error) {
+	r := &Registry{
+		path:   path,
+		places: map[string]*place{},
+		tree:   spatial.NewRTree[*place](),
+		now:    time.Now,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	if r.history == nil {
+		r.history, _ = history.Open("")
+	}
+	if path == "" {
+		return r, nil
+	}
+
+	var file storeFile
+	if _, err := storage.ReadJSON(path, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, location := range file.Locations {
+		p, err := newPlace(location)
+		if err != nil {
+			return nil, fmt.Errorf("%s: location %s: %w", path, location.ID, err)
+		}
+		r.places[p.ID] = p
+		r.tree.Insert(p.rect(), p)
+	}
+	return r, nil
+}
+
+// newPlace проверяет точку и приводит метки к нижнему регистру. Часы
+// работы без пояса считаются по морскому поясу долготы.
+func newPlace(location models.Location) (*place, error) {
+	if strings.TrimSpace(location.Name) == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalid)
+	}
+	if utf8.RuneCountInString(location.Name) > maxNameLength {
+		return nil, fmt.Errorf("%w: name is longer than %d characters", ErrInvalid, maxNameLength)
+	}
+	if math.Abs(location.Point.Lat) > 90 || math.Abs(location.Point.Lon) > 180 {
+		return nil, fmt.Errorf("%w: coordinates out of range: %g, %g", ErrInvalid, location.Point.Lat, location.Point.Lon)
+	}
+	if len(location.Tags) > maxTags {
+		return nil, fmt.Errorf("%w: at most %d tags", ErrInvalid, maxTags)
+	}
+
+	p := &place{Location: location}
+	p.Tags = nil
+	for _, tag := range location.Tags {
+		tag = normalizeTag(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tags must be non-empty and at most %d characters", ErrInvalid, maxTagLength)
+		}
+		if !p.hasTag(tag) {
+			p.Tags = append(p.Tags, tag)
+		}
+	}
+	if location.Hours != "" {
+		hours, err := ParseHours(location.Hours)
+		if err != nil {
+			return nil, fmt.Errorf("%w: hours: %v", ErrInvalid, err)
+		}
+		p.hours = hours
+	}
+	p.zone = timezone.Nautical(location.Point.Lon)
+	if location.Timezone != "" {
+		zone, err := time.LoadLocation(location.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		p.zone = zone
+	}
+	if location.Properties != nil {
+		p.Properties = make(map[string]string, len(location.Properties))
+		for key, value := range location.Properties {
+			p.Properties[key] = value
+		}
+	}
+	return p, nil
+}
+
+// checkOwner разрешает изменение точки владельцу owner. Точки без владельца
+// остались от версий, которые его не записывали: их может изменить любой
+// пользователь и становится владельцем.
+func checkOwner(owner, author, id string) error {
+	if owner != "" && owner != author {
+		return fmt.Errorf("%w: %s", ErrForbidden, id)
+	}
+	return nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func (p *place) hasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Len возвращает число точек в реестре.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.places)
+}
+
+func (r *Registry) Get(id string) (*models.Location, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.places[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	location := p.Location
+	return &location, nil
+}
+
+// Create добавляет точку с новым идентификатором. author — пользователь,
+// от имени которого изменение записывается в журнал; он же становится
+// владельцем точки и единственным, кто может ее изменять.
+func (r *Registry) Create(author string, location models.Location) (*models.Location, error) {
+	now := r.now().UTC()
+	location.ID, location.CreatedAt, location.UpdatedAt = storage.NewID(), now, now
+	location.Owner = author
+	p, err := newPlace(location)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.places) >= maxLocations {
+		return nil, fmt.Errorf("%w: at most %d locations", ErrInvalid, maxLocations)
+	}
+	if err := r.commit(author, 0, map[string]*place{p.ID: p}); err != nil {
+		return nil, err
+	}
+	result := p.Location
+	return &result, nil
+}
+
+// Update заменяет точку целиком, сохраняя время создания. Изменить точку
+// может только ее владелец.
+func (r *Registry) Update(author, id string, location models.Location) (*models.Location, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.places[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err := checkOwner(current.Owner, author, id); err != nil {
+		return nil, err
+	}
+	location.ID, location.CreatedAt, location.UpdatedAt = id, current.CreatedAt, r.now().UTC()
+	location.Owner = author
+	p, err := newPlace(location)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.commit(author, 0, map[string]*place{id: p}); err != nil {
+		return nil, err
+	}
+	result := p.Location
+	return &result, nil
+}
+
+// Delete удаляет точку. Удалить точку может только ее владелец.
+func (r *Registry) Delete(author, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.places[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err := checkOwner(current.Owner, author, id); err != nil {
+		return err
+	}
+	return r.commit(author, 0, map[string]*place{id: nil})
+}
+
+// Import добавляет точки одной записью: точка с идентификатором уже
+// существующей заменяет ее, без идентификатора получает новый. Ошибка
+// в любой точке отменяет весь импорт, в том числе попытка заменить точку
+// другого владельца. Все импортированные точки принадлежат author.
+func (r *Registry) Import(author string, locations []models.Location) (created, updated int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now().UTC()
+	changes := make(map[string]*place, len(locations))
+	for i, location := range locations {
+		if location.ID == "" {
+			location.ID = storage.NewID()
+		}
+		location.CreatedAt, location.UpdatedAt = now, now
+		location.Owner = author
+		current, exists := r.places[location.ID]
+		if exists {
+			if err := checkOwner(current.Owner, author, location.ID); err != nil {
+				return 0, 0, fmt.Errorf("location %d: %w", i+1, err)
+			}
+			location.CreatedAt = current.CreatedAt
+		}
+		if _, duplicate := changes[location.ID]; duplicate {
+			return 0, 0, fmt.Errorf("%w: location %d: duplicate id %q", ErrInvalid, i+1, location.ID)
+		}
+		p, err := newPlace(location)
+		if err != nil {
+			return 0, 0, fmt.Errorf("location %d: %w", i+1, err)
+		}
+		changes[p.ID] = p
+		if exists {
+			updated++
+		} else {
+			created++
+		}
+	}
+	if len(r.places)+created > maxLocations {
+		return 0, 0, fmt.Errorf("%w: at most %d locations", ErrInvalid, maxLocations)
+	}
+	if err := r.commit(author, 0, changes); err != nil {
+		return 0, 0, err
+	}
+	return created, updated, nil
+}
+
+// Revisions возвращает ревизии точки, в том числе удаленной.
+func (r *Registry) Revisions(id string) ([]models.Revision, error) {
+	revisions, err := r.history.Revisions(historyKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return revisions, nil
+}
+
+// Revision возвращает ревизию точки с ее состоянием.
+func (r *Registry) Revision(id string, number int) (*models.Revision, error) {
+	revision, err := r.history.Revision(historyKey(id), number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s, revision %d", ErrNotFound, id, number)
+	}
+	return revision, nil
+}
+
+// At возвращает точку в том виде, какой она была в момент at.
+func (r *Registry) At(id string, at time.Time) (*models.Location, error) {
+	revision, err := r.history.At(historyKey(id), at)
+	if err != nil || revision.Data == nil {
+		return nil, fmt.Errorf("%w: %s at %s", ErrNotFound, id, at.Format(time.RFC3339))
+	}
+	var location models.Location
+	if err := json.Unmarshal(revision.Data, &location); err != nil {
+		return nil, fmt.Errorf("%w: revision %d: %v", ErrStorage, revision.Revision, err)
+	}
+	return &location, nil
+}
+
+// Revert возвращает точку к состоянию ревизии number: восстанавливает
+// удаленную, заменяет текущую или удаляет, если ревизия — удаление.
+// Возврат записывается в журнал новой ревизией; для удаления результат nil.
+// Вернуть существующую точку может только ее владелец, удаленную — владелец
+// из ревизии.
+func (r *Registry) Revert(author, id string, number int) (*models.Location, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revision, err := r.history.Revision(historyKey(id), number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s, revision %d", ErrNotFound, id, number)
+	}
+	current, exists := r.places[id]
+	if exists {
+		if err := checkOwner(current.Owner, author, id); err != nil {
+			return nil, err
+		}
+	}
+	if revision.Data == nil {
+		if !exists {
+			return nil, fmt.Errorf("%w: location %s is already deleted", ErrInvalid, id)
+		}
+		return nil, r.commit(author, number, map[string]*place{id: nil})
+	}
+
+	var location models.Location
+	if err := json.Unmarshal(revision.Data, &location); err != nil {
+		return nil, fmt.Errorf("%w: revision %d: %v", ErrStorage, number, err)
+	}
+	if !exists {
+		if err := checkOwner(location.Owner, author, id); err != nil {
+			return nil, err
+		}
+	}
+	location.ID, location.UpdatedAt, location.Owner = id, r.now().UTC(), author
+	if exists {
+		location.CreatedAt = current.CreatedAt
+	} else if len(r.places) >= maxLocations {
+		return nil, fmt.Errorf("%w: at most %d locations", ErrInvalid, maxLocations)
+	}
+	p, err := newPlace(location)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.commit(author, number, map[string]*place{id: p}); err != nil {
+		return nil, err
+	}
+	result := p.Location
+	return &result, nil
+}
+
+// Query — условия поиска ближайших точек. Limit — сколько вернуть,
+// MaxDistance — радиус в метрах (0 — без ограничения), Tags — метки,
+// которые должны быть у точки все, OpenAt — момент, в который точка
+// должна работать (нулевое время — без проверки).
+type Query struct {
+	Lat, Lon    float64
+	Limit       int
+	MaxDistance float64
+	Tags        []string
+	OpenAt      time.Time
+}
+
+// Nearest возвращает ближайшие к точке запроса точки сети, подходящие
+// под условия, от ближней к дальней. Дерево обходится в порядке расстояния,
+// поэтому проверяются только точки ближе последней найденной.
+func (r *Registry) Nearest(query Query) []models.NearestLocation {
+	tags := make([]string, len(query.Tags))
+	for i, tag := range query.Tags {
+		tags[i] = normalizeTag(tag)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []models.NearestLocation{}
+	if query.Limit <= 0 {
+		return result
+	}
+	distance := func(rect spatial.Rect) float64 { return rect.GreatCircleDistance(query.Lat, query.Lon) }
+	r.tree.Nearest(distance, func(p *place, d float64) bool {
+		if query.MaxDistance > 0 && d > query.MaxDistance {
+			return false
+		}
+		if p.matches(tags, query.OpenAt) {
+			result = append(result, models.NearestLocation{Location: p.Location, Distance: d})
+		}
+		return len(result) < query.Limit
+	})
+	return result
+}
+
+// Clusters возвращает кластеры точек масштаба карты zoom, пересекающиеся
+// хотя бы с одной из областей rects, от больших к меньшим.
+func (r *Registry) Clusters(zoom int, rects []spatial.Rect) []models.LocationCluster {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	r.clustersMu.Lock()
+	if r.clusters == nil {
+		points := make([]cluster.Point[*models.Location], 0, len(r.places))
+		for _, p := range r.places {
+			points = append(points, cluster.Point[*models.Location]{Lat: p.Point.Lat, Lon: p.Point.Lon, Value: &p.Location})
+		}
+		r.clusters = cluster.New(points)
+	}
+	index := r.clusters
+	r.clustersMu.Unlock()
+
+	clusters := index.Clusters(zoom, rects)
+	result := make([]models.LocationCluster, len(clusters))
+	for i, c := range clusters {
+		result[i] = models.LocationCluster{
+			Count:  c.Count,
+			Point:  models.GeoPoint{Lat: c.Lat, Lon: c.Lon},
+			Bounds: models.BoundingBox{South: c.Bounds.MinY, West: c.Bounds.MinX, North: c.Bounds.MaxY, East: c.Bounds.MaxX},
+		}
+		if c.Value != nil {
+			location := *c.Value
+			result[i].Location = &location
+		}
+	}
+	return result
+}
+
+// matches проверяет метки и часы работы. Точка без расписания под фильтр
+// по времени не подходит: неизвестно, открыта ли она.
+func (p *place) matches(tags []string, at time.Time) bool {
+	for _, tag := range tags {
+		if !p.hasTag(tag) {
+			return false
+		}
+	}
+	if at.IsZero() {
+		return true
+	}
+	return p.hours != nil && p.hours.OpenAt(at.In(p.zone))
+}
+
+// commit записывает реестр с изменениями в файл, изменения — в журнал
+// от имени author и применяет их к памяти и индексу. changes — новые точки
+// по идентификаторам, nil — удаление; revertedTo — ревизия, к которой
+// вернули точку. Если журнал записать не удалось, файл возвращается
+// к прежнему состоянию. Вызывается под r.mu.
+func (r *Registry) commit(author string, revertedTo int, changes map[string]*place) error {
+	if err := r.write(changes); err != nil {
+		return fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	if err := r.history.Record(author, r.now().UTC(), r.changes(changes, revertedTo)); err != nil {
+		r.write(nil)
+		return fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+
+	r.clusters = nil
+	for id, p := range changes {
+		if old, ok := r.places[id]; ok {
+			r.tree.Delete(old.rect(), func(v *place) bool { return v == old })
+			delete(r.places, id)
+		}
+		if p != nil {
+			r.places[id] = p
+			r.tree.Insert(p.rect(), p)
+		}
+	}
+	return nil
+}
+
+// write записывает файл реестра с изменениями changes, nil — текущее
+// состояние.
+func (r *Registry) write(changes map[string]*place) error {
+	if r.path == "" {
+		return nil
+	}
+	locations := make([]models.Location, 0, len(r.places)+len(changes))
+	for id, p := range r.places {
+		if _, changed := changes[id]; !changed {
+			locations = append(locations, p.Location)
+		}
+	}
+	for _, p := range changes {
+		if p != nil {
+			locations = append(locations, p.Location)
+		}
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].ID < locations[j].ID })
+	return storage.WriteJSON(r.path, storeFile{Locations: locations})
+}
+
+// changes возвращает изменения точек для журнала.
+func (r *Registry) changes(changes map[string]*place, revertedTo int) []history.Change {
+	var result []history.Change
+	for id, p := range changes {
+		_, exists := r.places[id]
+		change := history.Change{Key: historyKey(id), RevertedTo: revertedTo}
+		switch {
+		case p == nil && !exists:
+			continue
+		case p == nil:
+			change.Action = models.RevisionDelete
+		case exists:
+			change.Action, change.Data = models.RevisionUpdate, p.Location
+		default:
+			change.Action, change.Data = models.RevisionCreate, p.Location
+		}
+		result = append(result, change)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+func historyKey(id string) string {
+	return "locations/" + id
+}
@@ -0,0 +1,383 @@
+package locator
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/history"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func openTestRegistry(t *testing.T, path string) *Registry {
+	t.Helper()
+	registry, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return registry
+}
+
+func TestRegistryCRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locations.json")
+	registry := openTestRegistry(t, path)
+
+	created, err := registry.Create("admin", models.Location{
+		Name:  "Тверская",
+		Point: models.GeoPoint{Lat: 55.76, Lon: 37.61},
+		Tags:  []string{" Pickup ", "pickup", "24h"},
+		Hours: "24/7",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID == "" || len(created.Tags) != 2 || created.Tags[0] != "pickup" {
+		t.Errorf("unexpected location: %+v", created)
+	}
+
+	updated, err := registry.Update("admin", created.ID, models.Location{Name: "Тверская, 7", Point: created.Point})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "Тверская, 7" || !updated.CreatedAt.Equal(created.CreatedAt) || len(updated.Tags) != 0 {
+		t.Errorf("unexpected update: %+v", updated)
+	}
+
+	reopened := openTestRegistry(t, path)
+	if got, err := reopened.Get(created.ID); err != nil || got.Name != "Тверская, 7" {
+		t.Errorf("expected location to persist, got %+v (%v)", got, err)
+	}
+
+	if err := registry.Delete("admin", created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := registry.Get(created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := registry.Delete("admin", created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := registry.Update("admin", created.ID, *created); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if registry.Len() != 0 || len(registry.Nearest(Query{Lat: 55.76, Lon: 37.61, Limit: 10})) != 0 {
+		t.Error("expected an empty registry after delete")
+	}
+}
+
+func TestRegistryInvalid(t *testing.T) {
+	registry := openTestRegistry(t, "")
+	point := models.GeoPoint{Lat: 55, Lon: 37}
+	for _, location := range []models.Location{
+		{Point: point},
+		{Name: "a", Point: models.GeoPoint{Lat: 91}},
+		{Name: "a", Point: point, Tags: []string{" "}},
+		{Name: "a", Point: point, Hours: "always"},
+		{Name: "a", Point: point, Timezone: "Mars/Olympus"},
+	} {
+		if _, err := registry.Create("admin", location); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: expected ErrInvalid, got %v", location, err)
+		}
+	}
+}
+
+func TestRegistryImport(t *testing.T) {
+	registry := openTestRegistry(t, "")
+	created, updated, err := registry.Import("admin", []models.Location{
+		{ID: "a", Name: "A", Point: models.GeoPoint{Lat: 55, Lon: 37}},
+		{Name: "B", Point: models.GeoPoint{Lat: 56, Lon: 38}},
+	})
+	if err != nil || created != 2 || updated != 0 {
+		t.Fatalf("expected 2 created, got %d, %d (%v)", created, updated, err)
+	}
+
+	created, updated, err = registry.Import("admin", []models.Location{{ID: "a", Name: "A2", Point: models.GeoPoint{Lat: 55, Lon: 37}}})
+	if err != nil || created != 0 || updated != 1 {
+		t.Fatalf("expected 1 updated, got %d, %d (%v)", created, updated, err)
+	}
+	if got, _ := registry.Get("a"); got.Name != "A2" {
+		t.Errorf("expected replaced location, got %+v", got)
+	}
+
+	// ошибка в любой точке отменяет импорт целиком
+	_, _, err = registry.Import("admin", []models.Location{
+		{ID: "c", Name: "C", Point: models.GeoPoint{Lat: 57, Lon: 39}},
+		{ID: "d", Point: models.GeoPoint{Lat: 57, Lon: 39}},
+	})
+	if !errors.Is(err, ErrInvalid) || registry.Len() != 2 {
+		t.Errorf("expected ErrInvalid and unchanged registry, got %v, %d locations", err, registry.Len())
+	}
+	_, _, err = registry.Import("admin", []models.Location{{ID: "e", Name: "E"}, {ID: "e", Name: "E"}})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for duplicate ids, got %v", err)
+	}
+}
+
+func TestRegistryNearest(t *testing.T) {
+	registry := openTestRegistry(t, "")
+	random := rand.New(rand.NewSource(1))
+	var locations []models.Location
+	for i := 0; i < 5000; i++ {
+		location := models.Location{
+			Name:  "точка",
+			Point: models.GeoPoint{Lat: random.Float64()*170 - 85, Lon: random.Float64()*360 - 180},
+		}
+		if i%3 == 0 {
+			location.Tags = []string{"pickup"}
+		}
+		locations = append(locations, location)
+	}
+	if _, _, err := registry.Import("admin", locations); err != nil {
+		t.Fatal(err)
+	}
+
+	for k := 0; k < 30; k++ {
+		query := Query{Lat: random.Float64()*180 - 90, Lon: random.Float64()*360 - 180, Limit: 5}
+		if k%2 == 0 {
+			query.Tags = []string{"PICKUP"}
+		}
+
+		var expected []float64
+		for _, location := range locations {
+			if len(query.Tags) > 0 && len(location.Tags) == 0 {
+				continue
+			}
+			expected = append(expected, geo.Haversine(query.Lat, query.Lon, location.Point.Lat, location.Point.Lon))
+		}
+		sort.Float64s(expected)
+
+		got := registry.Nearest(query)
+		if len(got) != query.Limit {
+			t.Fatalf("expected %d locations, got %d", query.Limit, len(got))
+		}
+		for i, result := range got {
+			if math.Abs(result.Distance-expected[i]) > 1e-3 {
+				t.Fatalf("query %+v: result %d: expected %.3f, got %.3f", query, i, expected[i], result.Distance)
+			}
+		}
+	}
+
+	if got := registry.Nearest(Query{Lat: 0, Lon: 0, Limit: 10, MaxDistance: 1}); len(got) != 0 {
+		t.Errorf("expected no locations within 1 m, got %d", len(got))
+	}
+}
+
+func TestRegistryNearestOpenAt(t *testing.T) {
+	registry := openTestRegistry(t, "")
+	_, _, err := registry.Import("admin", []models.Location{
+		{ID: "day", Name: "Днем", Point: models.GeoPoint{Lat: 55.75, Lon: 37.61}, Hours: "Mo-Su 09:00-21:00", Timezone: "Europe/Moscow"},
+		{ID: "night", Name: "Ночью", Point: models.GeoPoint{Lat: 55.76, Lon: 37.61}, Hours: "Mo-Su 21:00-09:00", Timezone: "Europe/Moscow"},
+		{ID: "unknown", Name: "Без расписания", Point: models.GeoPoint{Lat: 55.75, Lon: 37.6}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 07:00 UTC — 10:00 по Москве
+	morning := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+	got := registry.Nearest(Query{Lat: 55.75, Lon: 37.61, Limit: 10, OpenAt: morning})
+	if len(got) != 1 || got[0].Location.ID != "day" {
+		t.Errorf("expected only day location open, got %+v", got)
+	}
+	got = registry.Nearest(Query{Lat: 55.75, Lon: 37.61, Limit: 10, OpenAt: morning.Add(12 * time.Hour)})
+	if len(got) != 1 || got[0].Location.ID != "night" {
+		t.Errorf("expected only night location open, got %+v", got)
+	}
+	if got := registry.Nearest(Query{Lat: 55.75, Lon: 37.61, Limit: 10}); len(got) != 3 {
+		t.Errorf("expected all locations without time filter, got %d", len(got))
+	}
+}
+
+func TestRegistryClusters(t *testing.T) {
+	registry := openTestRegistry(t, "")
+	var locations []models.Location
+	for i := 0; i < 30; i++ {
+		locations = append(locations, models.Location{Name: "Москва", Point: models.GeoPoint{Lat: 55.75 + float64(i)*0.001, Lon: 37.62}})
+	}
+	if _, _, err := registry.Import("admin", locations); err != nil {
+		t.Fatal(err)
+	}
+	world := []spatial.Rect{{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}}
+
+	clusters := registry.Clusters(5, world)
+	if len(clusters) != 1 || clusters[0].Count != 30 || clusters[0].Location != nil {
+		t.Fatalf("expected one cluster of 30 points, got %+v", clusters)
+	}
+	if b := clusters[0].Bounds; b.South != 55.75 || b.West != 37.62 || math.Abs(b.North-55.779) > 1e-9 {
+		t.Errorf("unexpected bounds: %+v", b)
+	}
+
+	// изменение реестра сбрасывает кешированные кластеры
+	created, err := registry.Create("admin", models.Location{Name: "Невский", Point: models.GeoPoint{Lat: 59.93, Lon: 30.36}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters = registry.Clusters(5, world)
+	if len(clusters) != 2 || clusters[1].Location == nil || clusters[1].Location.ID != created.ID {
+		t.Errorf("expected new location as a separate cluster, got %+v", clusters)
+	}
+	if clusters := registry.Clusters(20, world); len(clusters) != 31 {
+		t.Errorf("expected separate points on zoom 20, got %d clusters", len(clusters))
+	}
+}
+
+func TestRegistryHistory(t *testing.T) {
+	dir := t.TempDir()
+	log, err := history.Open(filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := Open(filepath.Join(dir, "locations.json"), WithHistory(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return clock }
+
+	created, err := registry.Create("alice", models.Location{Name: "Тверская", Point: models.GeoPoint{Lat: 55.76, Lon: 37.61}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(time.Hour)
+	if _, err := registry.Update("bob", created.ID, models.Location{Name: "Тверская, 7", Point: created.Point}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another user, got %v", err)
+	}
+	if _, err := registry.Update("alice", created.ID, models.Location{Name: "Тверская, 7", Point: created.Point}); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(time.Hour)
+	if _, _, err := registry.Import("alice", []models.Location{{ID: "other", Name: "Арбат", Point: models.GeoPoint{Lat: 55.75, Lon: 37.59}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Delete("alice", created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := registry.Revisions(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 || revisions[0].Action != models.RevisionCreate || revisions[1].Author != "alice" || revisions[2].Action != models.RevisionDelete {
+		t.Errorf("unexpected revisions: %+v", revisions)
+	}
+	if revisions, err := registry.Revisions("other"); err != nil || len(revisions) != 1 {
+		t.Errorf("expected imported location in history, got %+v (%v)", revisions, err)
+	}
+
+	past, err := registry.At(created.ID, clock.Add(-90*time.Minute))
+	if err != nil || past.Name != "Тверская" {
+		t.Errorf("expected first version, got %+v (%v)", past, err)
+	}
+	if _, err := registry.At(created.ID, clock); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+
+	clock = clock.Add(time.Hour)
+	restored, err := registry.Revert("alice", created.ID, 2)
+	if err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if restored.Name != "Тверская, 7" || !restored.CreatedAt.Equal(created.CreatedAt) || !restored.UpdatedAt.Equal(clock) {
+		t.Errorf("unexpected restored location: %+v", restored)
+	}
+	if nearest := registry.Nearest(Query{Lat: 55.76, Lon: 37.61, Limit: 1}); len(nearest) != 1 || nearest[0].Location.ID != created.ID {
+		t.Errorf("expected restored location in index, got %+v", nearest)
+	}
+	revision, err := registry.Revision(created.ID, 4)
+	if err != nil || revision.Action != models.RevisionCreate || revision.RevertedTo != 2 {
+		t.Errorf("expected revert to be recorded, got %+v (%v)", revision, err)
+	}
+
+	if restored, err := registry.Revert("alice", created.ID, 3); err != nil || restored != nil {
+		t.Errorf("expected revert to deletion, got %+v (%v)", restored, err)
+	}
+	if _, err := registry.Revert("alice", created.ID, 3); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for already deleted location, got %v", err)
+	}
+	if _, err := registry.Revert("alice", created.ID, 9); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing revision, got %v", err)
+	}
+	if _, err := registry.Revert("bob", created.ID, 2); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for restoring another user's location, got %v", err)
+	}
+}
+
+func TestRegistryOwner(t *testing.T) {
+	dir := t.TempDir()
+	log, err := history.Open(filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "locations.json")
+	if err := os.WriteFile(path, []byte(`{"locations":[{"id":"legacy","name":"Арбат","point":{"lat":55.75,"lon":37.59}}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registry, err := Open(path, WithHistory(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := registry.Create("alice", models.Location{Name: "Тверская", Point: models.GeoPoint{Lat: 55.76, Lon: 37.61}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Owner != "alice" {
+		t.Errorf("expected alice to own the location, got %q", created.Owner)
+	}
+	if _, err := registry.Update("bob", created.ID, models.Location{Name: "Тверская, 7", Point: created.Point}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Update: expected ErrForbidden, got %v", err)
+	}
+	if err := registry.Delete("bob", created.ID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Delete: expected ErrForbidden, got %v", err)
+	}
+	if _, err := registry.Revert("bob", created.ID, 1); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Revert: expected ErrForbidden, got %v", err)
+	}
+	_, _, err = registry.Import("bob", []models.Location{
+		{Name: "Новая", Point: models.GeoPoint{Lat: 55, Lon: 37}},
+		{ID: created.ID, Name: "Чужая", Point: created.Point},
+	})
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("Import: expected ErrForbidden, got %v", err)
+	}
+	if registry.Len() != 2 {
+		t.Errorf("expected rejected import to change nothing, got %d locations", registry.Len())
+	}
+	if location, _ := registry.Get(created.ID); location.Name != "Тверская" {
+		t.Errorf("expected location unchanged, got %+v", location)
+	}
+
+	updated, err := registry.Update("alice", created.ID, models.Location{Name: "Тверская, 7", Point: created.Point, Owner: "bob"})
+	if err != nil || updated.Owner != "alice" {
+		t.Errorf("expected owner to stay alice, got %+v (%v)", updated, err)
+	}
+
+	legacy, err := registry.Update("bob", "legacy", models.Location{Name: "Арбат, 1", Point: models.GeoPoint{Lat: 55.75, Lon: 37.59}})
+	if err != nil || legacy.Owner != "bob" {
+		t.Fatalf("expected bob to take over location without owner, got %+v (%v)", legacy, err)
+	}
+	if err := registry.Delete("alice", "legacy"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden after takeover, got %v", err)
+	}
+}
+
+func TestRegistryStorageFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	registry := openTestRegistry(t, filepath.Join(dir, "locations.json"))
+	// файл на месте каталога не дает записать реестр
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Create("admin", models.Location{Name: "a", Point: models.GeoPoint{Lat: 55, Lon: 37}}); !errors.Is(err, ErrStorage) {
+		t.Errorf("expected ErrStorage, got %v", err)
+	}
+	if registry.Len() != 0 {
+		t.Errorf("expected unchanged registry, got %d locations", registry.Len())
+	}
+}
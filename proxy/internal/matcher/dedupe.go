@@ -0,0 +1,66 @@
+package matcher
+
+import (
+	"geo-controller/proxy/internal/models"
+	"sort"
+)
+
+// Dedupe объединяет в группы адреса, которые CompareProfiles считает одним
+// местом. Группы транзитивны: если A совпадает с B, а B с C, все три попадут
+// в одну группу. Сравнение попарное, поэтому размер списка стоит ограничивать.
+func Dedupe(addresses []*models.Address) models.DedupeResponse {
+	profiles := make([]*Profile, len(addresses))
+	for i, addr := range addresses {
+		profiles[i] = NewProfile(addr)
+	}
+
+	parent := make([]int, len(addresses))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range profiles {
+		for j := i + 1; j < len(profiles); j++ {
+			if find(i) == find(j) {
+				continue
+			}
+			if CompareProfiles(profiles[i], profiles[j]).Verdict == models.MatchSame {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	for i := range addresses {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+
+	resp := models.DedupeResponse{Groups: []models.DuplicateGroup{}, Unique: []int{}}
+	for _, indices := range members {
+		if len(indices) == 1 {
+			resp.Unique = append(resp.Unique, indices[0])
+			continue
+		}
+		canonical := indices[0]
+		for _, i := range indices[1:] {
+			if profiles[i].Completeness() > profiles[canonical].Completeness() {
+				canonical = i
+			}
+		}
+		resp.Groups = append(resp.Groups, models.DuplicateGroup{Indices: indices, Canonical: canonical})
+	}
+
+	sort.Ints(resp.Unique)
+	sort.Slice(resp.Groups, func(i, j int) bool {
+		return resp.Groups[i].Indices[0] < resp.Groups[j].Indices[0]
+	})
+	return resp
+}
@@ -0,0 +1,274 @@
+package matcher
+
+import (
+	"geo-controller/proxy/internal/fuzzy"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/normalize"
+	"geo-controller/proxy/internal/parser"
+	"geo-controller/proxy/internal/translit"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Пороги оценки для вердиктов.
+const (
+	sameScore     = 0.85
+	possibleScore = 0.5
+)
+
+// Расстояния в метрах, на которых координаты считаются совпавшими
+// или указывающими на разные места.
+const (
+	sameDistance      = 50
+	differentDistance = 1000
+)
+
+// normalizer приводит названия к единому виду со встроенными синонимами.
+var normalizer = normalize.New(nil)
+
+// Веса компонентов в оценке. Несовпадение ключевых компонентов
+// (город, улица, дом, корпус, квартира) означает разные адреса независимо от оценки.
+var weights = []struct {
+	name string
+	w    float64
+	key  bool
+}{
+	{"postal_code", 0.05, false},
+	{"region", 0.05, false},
+	{"city", 0.15, true},
+	{"street", 0.3, true},
+	{"house", 0.3, true},
+	{"block", 0.1, true},
+	{"flat", 0.1, true},
+	{"coordinates", 0.3, false},
+}
+
+// Profile — адрес, приведенный к виду для сравнения. Строится один раз
+// на адрес, чтобы попарное сравнение списка не разбирало строки повторно.
+type Profile struct {
+	fiasID     string
+	components map[string]string
+	lat, lon   float64
+	hasGeo     bool
+}
+
+// NewProfile нормализует компоненты адреса. Если структурных полей нет,
+// компоненты выделяются из Result локальным разборщиком.
+func NewProfile(addr *models.Address) *Profile {
+	p := &Profile{fiasID: strings.ToLower(addr.FiasID), components: map[string]string{}}
+
+	parsed := &models.ParsedAddress{
+		PostalCode: addr.PostalCode, Region: addr.Region, City: firstNonEmpty(addr.City, addr.Settlement),
+		Street: addr.Street, StreetType: addr.StreetType, House: addr.House, Building: addr.Block, Flat: addr.Flat,
+	}
+	if addr.City == "" && addr.Settlement == "" && addr.House == "" && addr.Result != "" {
+		fromResult := parser.Parse(addr.Result)
+		fromResult.PostalCode = firstNonEmpty(addr.PostalCode, fromResult.PostalCode)
+		fromResult.Region = firstNonEmpty(addr.Region, fromResult.Region)
+		parsed = fromResult
+	}
+
+	p.set("postal_code", parsed.PostalCode)
+	p.set("region", nameKey(parsed.Region))
+	p.set("city", nameKey(parsed.City))
+	if street := nameKey(parsed.Street); street != "" {
+		p.set("street", joinKey(street, typeKey(parsed.StreetType)))
+	}
+	p.set("house", numberKey(parsed.House))
+	p.set("block", numberKey(parsed.Building))
+	p.set("flat", numberKey(parsed.Flat))
+
+	lat, errLat := strconv.ParseFloat(addr.GeoLat, 64)
+	lon, errLon := strconv.ParseFloat(addr.GeoLon, 64)
+	if errLat == nil && errLon == nil {
+		p.lat, p.lon, p.hasGeo = lat, lon, true
+	}
+	return p
+}
+
+// Has сообщает, заполнен ли компонент: "city", "street", "house" и т.д.
+func (p *Profile) Has(component string) bool {
+	return p.components[component] != ""
+}
+
+// Completeness — число заполненных компонентов адреса.
+func (p *Profile) Completeness() int {
+	n := len(p.components)
+	if p.hasGeo {
+		n++
+	}
+	if p.fiasID != "" {
+		n++
+	}
+	return n
+}
+
+// Compare сравнивает два адреса.
+func Compare(a, b *models.Address) models.CompareResponse {
+	return CompareProfiles(NewProfile(a), NewProfile(b))
+}
+
+// CompareProfiles сравнивает два подготовленных адреса. Совпадение кодов ФИАС
+// решает сразу, иначе оценка — доля веса совпавших компонентов среди
+// заполненных в обоих адресах.
+func CompareProfiles(a, b *Profile) models.CompareResponse {
+	resp := models.CompareResponse{Matched: []string{}, Mismatched: []string{}}
+	if a.hasGeo && b.hasGeo {
+		distance := geo.Haversine(a.lat, a.lon, b.lat, b.lon)
+		resp.Distance = &distance
+	}
+
+	if a.fiasID != "" && a.fiasID == b.fiasID {
+		resp.Verdict, resp.Score = models.MatchSame, 1
+		resp.Matched = append(resp.Matched, "fias_id")
+		return resp
+	}
+
+	var matched, compared float64
+	decisive := false
+	for _, c := range weights {
+		var equal, ok bool
+		if c.name == "coordinates" {
+			equal, ok = compareDistance(resp.Distance)
+		} else {
+			equal, ok = compareComponent(c.name, a.components[c.name], b.components[c.name])
+		}
+		if !ok {
+			continue
+		}
+
+		compared += c.w
+		if equal {
+			matched += c.w
+			resp.Matched = append(resp.Matched, c.name)
+			continue
+		}
+		resp.Mismatched = append(resp.Mismatched, c.name)
+		decisive = decisive || c.key
+	}
+	if a.fiasID != "" && b.fiasID != "" {
+		resp.Mismatched = append(resp.Mismatched, "fias_id")
+	}
+
+	if compared > 0 {
+		resp.Score = matched / compared
+	}
+	resp.Verdict = verdict(resp.Score, decisive, resp.Matched)
+	return resp
+}
+
+// verdict выбирает вердикт. Для "same" нужно совпадение дома или точки:
+// одного совпавшего города недостаточно.
+func verdict(score float64, decisive bool, matched []string) string {
+	switch {
+	case decisive || score < possibleScore:
+		return models.MatchDifferent
+	case score >= sameScore && (contains(matched, "house") || contains(matched, "coordinates")):
+		return models.MatchSame
+	}
+	return models.MatchPossible
+}
+
+// compareComponent сравнивает компонент, если он есть в обоих адресах.
+// Названия допускают опечатки в пределах fuzzy.MaxEdits, тип улицы
+// учитывается, только если он известен для обоих адресов.
+func compareComponent(name, a, b string) (equal, ok bool) {
+	if a == "" || b == "" {
+		return false, false
+	}
+	if a == b {
+		return true, true
+	}
+	switch name {
+	case "region", "city":
+		return similarNames(a, b), true
+	case "street":
+		nameA, typeA, _ := strings.Cut(a, "|")
+		nameB, typeB, _ := strings.Cut(b, "|")
+		sameType := typeA == "" || typeB == "" || typeA == typeB
+		return sameType && similarNames(nameA, nameB), true
+	}
+	return false, true
+}
+
+// similarNames сравнивает названия с точностью до опечаток.
+func similarNames(a, b string) bool {
+	return fuzzy.Levenshtein(a, b) <= fuzzy.MaxEdits(a)
+}
+
+// compareDistance считает точки совпавшими ближе sameDistance и разными
+// дальше differentDistance. Промежуточные расстояния не учитываются.
+func compareDistance(distance *float64) (equal, ok bool) {
+	switch {
+	case distance == nil:
+		return false, false
+	case *distance <= sameDistance:
+		return true, true
+	case *distance > differentDistance:
+		return false, true
+	}
+	return false, false
+}
+
+func (p *Profile) set(name, value string) {
+	if value != "" {
+		p.components[name] = value
+	}
+}
+
+// nameKey приводит название к нормализованной форме в латинице без знаков
+// препинания, чтобы "Тверская", "ТВЕРСКАЯ", "Tverskaya" и "Moscow"/"Москва"
+// сравнивались по одному ключу.
+func nameKey(name string) string {
+	fields := strings.FieldsFunc(normalizer.Normalize(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return translit.ToLatin(strings.Join(fields, " "))
+}
+
+// typeKey возвращает каноническую форму типа (см. normalize.Word).
+func typeKey(typ string) string {
+	if typ == "" {
+		return ""
+	}
+	return translit.ToLatin(normalize.Word(typ))
+}
+
+// numberKey приводит номер дома, корпуса или квартиры к виду без пробелов
+// и разделителей: "7 А" и "7а" совпадают.
+func numberKey(number string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(number) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '/' {
+			b.WriteRune(r)
+		}
+	}
+	return translit.ToLatin(b.String())
+}
+
+func joinKey(name, typ string) string {
+	if typ == "" {
+		return name
+	}
+	return name + "|" + typ
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
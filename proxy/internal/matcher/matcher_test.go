@@ -0,0 +1,98 @@
+package matcher
+
+import (
+	"geo-controller/proxy/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tverskaya := models.Address{
+		Result: "г Москва, ул Тверская, д 7, кв 12", PostalCode: "125009", Region: "Москва",
+		City: "Москва", CityType: "г", Street: "Тверская", StreetType: "ул", House: "7", Flat: "12",
+		GeoLat: "55.7579", GeoLon: "37.6119",
+	}
+
+	testCases := []struct {
+		name    string
+		first   models.Address
+		second  models.Address
+		verdict string
+	}{
+		{"same strings with different abbreviations", models.Address{Result: "г. Москва, ул. Тверская, д. 7, кв. 12"},
+			models.Address{Result: "город Москва, улица Тверская, дом 7, квартира 12"}, models.MatchSame},
+		{"structured and free-form", tverskaya, models.Address{Result: "Москва, Тверская ул, 7, кв 12"}, models.MatchSame},
+		{"case, typo and letter ё", models.Address{Result: "г Москва, ул Пролетарская, д 3"},
+			models.Address{Result: "Г МОСКВА, УЛ ПРОЛЕТАРСКЯ, Д 3"}, models.MatchSame},
+		{"transliterated", tverskaya, models.Address{City: "Moscow", Street: "Tverskaya", StreetType: "st", House: "7", Flat: "12"}, models.MatchSame},
+		{"house letter spacing", models.Address{Result: "ул Ленина, д 7 А"}, models.Address{Result: "ул Ленина, д 7а"}, models.MatchSame},
+		{"different house", tverskaya, models.Address{Result: "г Москва, ул Тверская, д 9, кв 12"}, models.MatchDifferent},
+		{"different flat", tverskaya, models.Address{Result: "г Москва, ул Тверская, д 7, кв 13"}, models.MatchDifferent},
+		{"different street type", models.Address{Result: "г Москва, Тверской пер, д 7"},
+			models.Address{Result: "г Москва, Тверской б-р, д 7"}, models.MatchDifferent},
+		{"different city", models.Address{Result: "г Казань, ул Ленина, д 1"}, models.Address{Result: "г Пермь, ул Ленина, д 1"}, models.MatchDifferent},
+		{"missing flat", tverskaya, models.Address{Result: "г Москва, ул Тверская, д 7"}, models.MatchSame},
+		{"street only", models.Address{Result: "ул Тверская"}, models.Address{Result: "г Москва, ул Тверская"}, models.MatchPossible},
+		{"same fias id", models.Address{Result: "Москва", FiasID: "ABC"}, models.Address{Result: "Казань", FiasID: "abc"}, models.MatchSame},
+		{"coordinates only", models.Address{GeoLat: "55.7579", GeoLon: "37.6119"}, models.Address{GeoLat: "55.7581", GeoLon: "37.6121"}, models.MatchSame},
+		{"far coordinates", tverskaya, models.Address{Result: "Москва, Тверская 7, кв 12", GeoLat: "55.80", GeoLon: "37.70"}, models.MatchPossible},
+		{"nothing in common", models.Address{}, models.Address{Result: "г Москва"}, models.MatchDifferent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Compare(&tc.first, &tc.second)
+			if got.Verdict != tc.verdict {
+				t.Errorf("expected %s, got %+v", tc.verdict, got)
+			}
+			reverse := Compare(&tc.second, &tc.first)
+			if reverse.Verdict != got.Verdict || reverse.Score != got.Score {
+				t.Errorf("comparison is not symmetric: %+v vs %+v", got, reverse)
+			}
+		})
+	}
+}
+
+func TestCompare_Components(t *testing.T) {
+	got := Compare(
+		&models.Address{Result: "125009, г Москва, ул Тверская, д 7", GeoLat: "55.7579", GeoLon: "37.6119"},
+		&models.Address{Result: "г Москва, ул Тверская, д 9", GeoLat: "55.7580", GeoLon: "37.6120"},
+	)
+
+	if got.Verdict != models.MatchDifferent {
+		t.Errorf("expected different, got %s", got.Verdict)
+	}
+	if !reflect.DeepEqual(got.Matched, []string{"region", "city", "street", "coordinates"}) {
+		t.Errorf("unexpected matched components %q", got.Matched)
+	}
+	if !reflect.DeepEqual(got.Mismatched, []string{"house"}) {
+		t.Errorf("unexpected mismatched components %q", got.Mismatched)
+	}
+	if got.Distance == nil || *got.Distance > 20 {
+		t.Errorf("expected distance of a few metres, got %v", got.Distance)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	addresses := []*models.Address{
+		{Result: "г Москва, ул Тверская, д 7"},
+		{Result: "г Казань, ул Баумана, д 1"},
+		{Result: "Москва, Тверская ул., 7", PostalCode: "125009", GeoLat: "55.7579", GeoLon: "37.6119"},
+		{Result: "г Москва, ул Тверская, д 9"},
+		{Result: "г. Казань, улица Баумана, дом 1"},
+		{Result: "город Москва, улица Тверская, дом 7"},
+	}
+
+	got := Dedupe(addresses)
+
+	expected := models.DedupeResponse{
+		Groups: []models.DuplicateGroup{
+			{Indices: []int{0, 2, 5}, Canonical: 2},
+			{Indices: []int{1, 4}, Canonical: 1},
+		},
+		Unique: []int{3},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type User struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -12,8 +17,38 @@ type Address struct {
 	Country    string `json:"country"`
 	Region     string `json:"region"`
 	Street     string `json:"street"`
-	GeoLat     string `json:"lat"`
-	GeoLon     string `json:"lon"`
+	// Структурные компоненты адреса. Типы хранятся в сокращенной форме
+	// DaData ("обл", "г", "ул", "д", "кв"), FiasID — код ФИАС адреса.
+	RegionType     string `json:"region_type,omitempty"`
+	Area           string `json:"area,omitempty"`
+	AreaType       string `json:"area_type,omitempty"`
+	City           string `json:"city,omitempty"`
+	CityType       string `json:"city_type,omitempty"`
+	Settlement     string `json:"settlement,omitempty"`
+	SettlementType string `json:"settlement_type,omitempty"`
+	StreetType     string `json:"street_type,omitempty"`
+	House          string `json:"house,omitempty"`
+	HouseType      string `json:"house_type,omitempty"`
+	Block          string `json:"block,omitempty"`
+	BlockType      string `json:"block_type,omitempty"`
+	Flat           string `json:"flat,omitempty"`
+	FlatType       string `json:"flat_type,omitempty"`
+	FiasID         string `json:"fias_id,omitempty"`
+	GeoLat         string `json:"lat"`
+	GeoLon         string `json:"lon"`
+	// Distance — расстояние в метрах от точки смещения поиска.
+	Distance *float64 `json:"distance,omitempty"`
+	// Highlights — совпавшие с запросом фрагменты Result (локальный поиск).
+	Highlights []Highlight `json:"highlights,omitempty"`
+	// Timezone — часовой пояс IANA, заполняется по запросу.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Highlight — фрагмент строки адреса, совпавший с запросом.
+// Offset и Length измеряются в символах.
+type Highlight struct {
+	Offset int `json:"offset"`
+	Length int `json:"length"`
 }
 
 // GeocodeResponse представляет ответ на запрос геокодирования.
@@ -28,18 +63,708 @@ type Suggestion struct {
 	Value  string `json:"value"`
 }
 
+// GeoPoint — точка в координатах WGS84.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// BoundingBox — прямоугольная область, например видимая часть карты.
+type BoundingBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// Варианты сортировки результатов поиска.
+const (
+	SortRelevance    = "relevance"
+	SortDistance     = "distance"
+	SortAlphabetical = "alphabetical"
+)
+
+// Языки результатов.
+const (
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+)
+
+// Схемы транслитерации для результатов на английском.
+const (
+	TransliterationICAO = "icao"
+	TransliterationGOST = "gost"
+)
+
 // SearchRequest представляет запрос на поиск адреса.
+// Location или Viewport смещают выдачу к ближайшим адресам,
+// UseIPLocation разрешает взять точку по IP клиента, если они не заданы.
+// Limit и Cursor задают страницу, Sort — порядок результатов; страницы
+// строятся в окне из первых 20 результатов провайдера, курсор действует
+// только с тем же запросом, сортировкой, языком и точкой смещения.
+// AutoCorrect повторяет пустой поиск с первым вариантом исправления.
+// Language задает язык результатов, Transliteration — схему для полей,
+// которые провайдер не вернул на этом языке. Timezone добавляет
+// к адресам с координатами часовой пояс.
 type SearchRequest struct {
-	Query string `json:"query"`
+	Query           string       `json:"query"`
+	Location        *GeoPoint    `json:"location,omitempty"`
+	Viewport        *BoundingBox `json:"viewport,omitempty"`
+	UseIPLocation   bool         `json:"use_ip_location,omitempty"`
+	Limit           int          `json:"limit,omitempty"`
+	Cursor          string       `json:"cursor,omitempty"`
+	Sort            string       `json:"sort,omitempty"`
+	AutoCorrect     bool         `json:"auto_correct,omitempty"`
+	Language        string       `json:"language,omitempty"`
+	Transliteration string       `json:"transliteration,omitempty"`
+	Timezone        bool         `json:"timezone,omitempty"`
+	ClientIP        string       `json:"-"`
 }
 
-// GeocodeRequest представляет запрос геокодирования.
+// GeocodeRequest представляет запрос геокодирования. Точка задается либо
+// парой Lat и Lng, либо строкой Location в одном из форматов: градусы,
+// минуты и секунды, десятичные градусы, геохеш или plus code.
+// Language и Transliteration работают так же, как в SearchRequest.
 type GeocodeRequest struct {
-	Lat string `json:"lat"`
-	Lng string `json:"lng"`
+	Lat             string `json:"lat"`
+	Lng             string `json:"lng"`
+	Location        string `json:"location,omitempty"`
+	Language        string `json:"language,omitempty"`
+	Transliteration string `json:"transliteration,omitempty"`
 }
 
 // SearchResponse представляет ответ на запрос поиска адреса.
+// NormalizedQuery — запрос после нормализации, TotalEstimate — число
+// найденных адресов в окне выдачи провайдера. DidYouMean заполняется,
+// если ничего не найдено, CorrectedQuery — если поиск был повторен с исправлением.
 type SearchResponse struct {
-	Addresses []*Address `json:"addresses"`
+	Addresses       []*Address `json:"addresses"`
+	NormalizedQuery string     `json:"normalized_query,omitempty"`
+	DidYouMean      []string   `json:"did_you_mean,omitempty"`
+	CorrectedQuery  string     `json:"corrected_query,omitempty"`
+	Bias            *GeoPoint  `json:"bias,omitempty"`
+	NextCursor      string     `json:"next_cursor,omitempty"`
+	TotalEstimate   int        `json:"total_estimate"`
+}
+
+// IPLocateRequest представляет запрос на определение местоположения по IP.
+// Если IP не указан, используется адрес клиента.
+type IPLocateRequest struct {
+	IP string `json:"ip"`
+}
+
+// IPLocation содержит приблизительное местоположение IP-адреса.
+type IPLocation struct {
+	IP       string `json:"ip"`
+	Country  string `json:"country"`
+	Region   string `json:"region"`
+	City     string `json:"city"`
+	GeoLat   string `json:"lat"`
+	GeoLon   string `json:"lon"`
+	Accuracy int    `json:"accuracy_radius,omitempty"`
+	Source   string `json:"source"`
+}
+
+// ParseRequest представляет запрос на разбор адреса в свободной форме.
+type ParseRequest struct {
+	Query string `json:"query"`
+}
+
+// ParsedAddress содержит компоненты адреса, выделенные из строки без обращения
+// к провайдеру. Типы (RegionType, StreetType и т.д.) приводятся к полной форме.
+type ParsedAddress struct {
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+	Region     string `json:"region,omitempty"`
+	RegionType string `json:"region_type,omitempty"`
+	Area       string `json:"area,omitempty"`
+	AreaType   string `json:"area_type,omitempty"`
+	City       string `json:"city,omitempty"`
+	CityType   string `json:"city_type,omitempty"`
+	// Settlement — микрорайон, если в адресе есть и он, и улица.
+	Settlement     string `json:"settlement,omitempty"`
+	SettlementType string `json:"settlement_type,omitempty"`
+	Street         string `json:"street,omitempty"`
+	StreetType     string `json:"street_type,omitempty"`
+	House          string `json:"house,omitempty"`
+	Building       string `json:"building,omitempty"`
+	BuildingType   string `json:"building_type,omitempty"`
+	Flat           string `json:"flat,omitempty"`
+	FlatType       string `json:"flat_type,omitempty"`
+	Unparsed       string `json:"unparsed,omitempty"`
+}
+
+// Стили форматирования адреса.
+const (
+	FormatPost          = "post"
+	FormatCompact       = "compact"
+	FormatInternational = "international"
+	FormatLabel         = "label"
+)
+
+// FormatRequest представляет запрос на форматирование адреса.
+// Style по умолчанию — FormatCompact.
+type FormatRequest struct {
+	Address Address `json:"address"`
+	Style   string  `json:"style,omitempty"`
+}
+
+// FormatResponse содержит адрес, отформатированный в заданном стиле.
+// Text — строки Lines, соединенные переводом строки.
+type FormatResponse struct {
+	Style string   `json:"style"`
+	Text  string   `json:"text"`
+	Lines []string `json:"lines"`
+}
+
+// Вердикты сравнения адресов.
+const (
+	MatchSame      = "same"
+	MatchPossible  = "possible"
+	MatchDifferent = "different"
+)
+
+// CompareRequest представляет запрос на сравнение двух адресов. Адрес может
+// быть задан строкой в Result или структурными полями, FiasID и координаты
+// учитываются, если заполнены.
+type CompareRequest struct {
+	First  Address `json:"first"`
+	Second Address `json:"second"`
+}
+
+// CompareResponse содержит вердикт и оценку от 0 до 1. Matched и Mismatched
+// перечисляют совпавшие и различающиеся компоненты, Distance — расстояние
+// в метрах между точками адресов.
+type CompareResponse struct {
+	Verdict    string   `json:"verdict"`
+	Score      float64  `json:"score"`
+	Distance   *float64 `json:"distance,omitempty"`
+	Matched    []string `json:"matched"`
+	Mismatched []string `json:"mismatched"`
+}
+
+// DedupeRequest представляет список адресов для поиска дубликатов.
+type DedupeRequest struct {
+	Addresses []Address `json:"addresses"`
+}
+
+// DuplicateGroup — группа адресов, обозначающих одно место. Indices — индексы
+// адресов в запросе, Canonical — индекс самого полного из них.
+type DuplicateGroup struct {
+	Indices   []int `json:"indices"`
+	Canonical int   `json:"canonical"`
+}
+
+// DedupeResponse содержит группы дубликатов и индексы адресов без дубликатов.
+type DedupeResponse struct {
+	Groups []DuplicateGroup `json:"groups"`
+	Unique []int            `json:"unique"`
+}
+
+// Результаты проверки адреса.
+const (
+	ValidationValid     = "valid"
+	ValidationInvalid   = "invalid"
+	ValidationUncertain = "uncertain"
+)
+
+// Причины, по которым адрес признан неверным или сомнительным.
+const (
+	ReasonNotFound           = "not_found"
+	ReasonCityNotFound       = "city_not_found"
+	ReasonStreetNotFound     = "street_not_found"
+	ReasonHouseNotFound      = "house_not_found"
+	ReasonHouseMissing       = "house_missing"
+	ReasonPostalCodeMismatch = "postal_code_mismatch"
+	ReasonAmbiguous          = "ambiguous"
+)
+
+// ValidateRequest представляет запрос на проверку существования адреса.
+// Адрес задается строкой в Result или структурными полями.
+type ValidateRequest struct {
+	Address Address `json:"address"`
+}
+
+// ValidateResponse содержит результат проверки, причины (см. Reason*)
+// и ближайший найденный адрес, если исходный неверен или сомнителен.
+type ValidateResponse struct {
+	Status     string   `json:"status"`
+	Reasons    []string `json:"reasons"`
+	Score      float64  `json:"score"`
+	Suggestion *Address `json:"suggestion,omitempty"`
+}
+
+// Waypoint — точка для расчета расстояний: координаты или адрес,
+// который геокодируется поиском.
+type Waypoint struct {
+	Point   *GeoPoint `json:"point,omitempty"`
+	Address string    `json:"address,omitempty"`
+}
+
+// DistanceRequest представляет запрос расстояния между двумя точками.
+type DistanceRequest struct {
+	From Waypoint `json:"from"`
+	To   Waypoint `json:"to"`
+}
+
+// Distance содержит расстояния в метрах по сфере (Haversine) и по эллипсоиду
+// WGS84 (Vincenty, пусто для почти антиподных точек), а также азимуты
+// в градусах в начальной и конечной точках.
+type Distance struct {
+	Haversine      float64  `json:"haversine"`
+	Vincenty       *float64 `json:"vincenty,omitempty"`
+	InitialBearing float64  `json:"initial_bearing"`
+	FinalBearing   float64  `json:"final_bearing"`
+}
+
+// DistanceResponse содержит координаты точек и расстояние между ними.
+type DistanceResponse struct {
+	From GeoPoint `json:"from"`
+	To   GeoPoint `json:"to"`
+	Distance
+}
+
+// MatrixRequest представляет запрос матрицы расстояний от каждой точки
+// Origins до каждой точки Destinations.
+type MatrixRequest struct {
+	Origins      []Waypoint `json:"origins"`
+	Destinations []Waypoint `json:"destinations"`
+}
+
+// MatrixResponse содержит координаты точек и матрицу расстояний:
+// Rows[i][j] — от Origins[i] до Destinations[j].
+type MatrixResponse struct {
+	Origins      []GeoPoint   `json:"origins"`
+	Destinations []GeoPoint   `json:"destinations"`
+	Rows         [][]Distance `json:"rows"`
+}
+
+// Geometry — геометрия GeoJSON (RFC 7946). Coordinates разбираются
+// по Type, Geometries заполняется для GeometryCollection.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []Geometry      `json:"geometries,omitempty"`
+}
+
+// GeometryRequest представляет запрос с одной геометрией.
+type GeometryRequest struct {
+	Geometry Geometry `json:"geometry"`
+}
+
+// GeometryResponse содержит геометрию — результат операции.
+type GeometryResponse struct {
+	Geometry Geometry `json:"geometry"`
+}
+
+// MeasureResponse содержит площадь в квадратных метрах, длину линий или
+// периметр полигонов в метрах, центроид и охватывающий прямоугольник.
+type MeasureResponse struct {
+	Area     float64     `json:"area"`
+	Length   float64     `json:"length"`
+	Centroid GeoPoint    `json:"centroid"`
+	BBox     BoundingBox `json:"bbox"`
+}
+
+// SimplifyRequest представляет запрос на упрощение геометрии алгоритмом
+// Дугласа — Пекера. Tolerance — допустимое отклонение в метрах.
+type SimplifyRequest struct {
+	Geometry  Geometry `json:"geometry"`
+	Tolerance float64  `json:"tolerance"`
+}
+
+// SimplifyResponse содержит упрощенную геометрию и число вершин до и после.
+type SimplifyResponse struct {
+	Geometry     Geometry `json:"geometry"`
+	PointsBefore int      `json:"points_before"`
+	PointsAfter  int      `json:"points_after"`
+}
+
+// BufferRequest представляет запрос на построение буфера на расстоянии
+// Distance метров. Segments — число отрезков, аппроксимирующих окружность.
+type BufferRequest struct {
+	Geometry Geometry `json:"geometry"`
+	Distance float64  `json:"distance"`
+	Segments int      `json:"segments,omitempty"`
+}
+
+// TransformRequest представляет запрос на пересчет координат между системами.
+// From и To — коды EPSG, например "EPSG:4326", "EPSG:32637", "EPSG:28407".
+// Points — пары [x, y] в порядке GeoJSON: долгота и широта для географических
+// систем, восточное и северное смещения в метрах для проекций.
+type TransformRequest struct {
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Points   [][2]float64 `json:"points,omitempty"`
+	Geometry *Geometry    `json:"geometry,omitempty"`
+}
+
+// TransformResponse содержит пересчитанные точки и геометрию.
+type TransformResponse struct {
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Points   [][2]float64 `json:"points,omitempty"`
+	Geometry *Geometry    `json:"geometry,omitempty"`
+}
+
+// Cell — ячейка пространственной сетки: геохеш, plus code или S2.
+// Precision — длина геохеша, число значащих символов plus code или уровень S2.
+type Cell struct {
+	Encoding  string      `json:"encoding"`
+	Token     string      `json:"token"`
+	Precision int         `json:"precision"`
+	Center    GeoPoint    `json:"center"`
+	BBox      BoundingBox `json:"bbox"`
+}
+
+// CellEncodeRequest представляет запрос на вычисление ячейки точки.
+// Precision 0 выбирает точность по умолчанию для кодировки.
+type CellEncodeRequest struct {
+	Encoding  string   `json:"encoding"`
+	Point     GeoPoint `json:"point"`
+	Precision int      `json:"precision,omitempty"`
+}
+
+// CellRequest представляет запрос с токеном ячейки.
+type CellRequest struct {
+	Encoding string `json:"encoding"`
+	Token    string `json:"token"`
+}
+
+// CellNeighboursResponse содержит ячейку и соседние ячейки той же точности.
+type CellNeighboursResponse struct {
+	Cell       Cell   `json:"cell"`
+	Neighbours []Cell `json:"neighbours"`
+}
+
+// BoundaryRequest представляет запрос административного деления для точки
+// или адреса.
+type BoundaryRequest struct {
+	Point   *GeoPoint `json:"point,omitempty"`
+	Address string    `json:"address,omitempty"`
+}
+
+// AdminArea — административная единица. Code — код из исходных данных,
+// например ISO 3166-2 или ОКТМО.
+type AdminArea struct {
+	Name string `json:"name"`
+	Code string `json:"code,omitempty"`
+}
+
+// BoundaryResponse содержит административные единицы, в которые попала точка.
+type BoundaryResponse struct {
+	Point          GeoPoint   `json:"point"`
+	Country        *AdminArea `json:"country,omitempty"`
+	FederalSubject *AdminArea `json:"federal_subject,omitempty"`
+	Municipality   *AdminArea `json:"municipality,omitempty"`
+	District       *AdminArea `json:"district,omitempty"`
+}
+
+// TimezoneRequest представляет запрос часового пояса для точки или адреса.
+type TimezoneRequest struct {
+	Point   *GeoPoint `json:"point,omitempty"`
+	Address string    `json:"address,omitempty"`
+}
+
+// TimezoneResponse содержит часовой пояс IANA и текущее смещение от UTC.
+// Offset — смещение в секундах, UTCOffset — то же в виде ±hh:mm.
+// Nautical означает, что точка вне границ поясов и пояс определен
+// по долготе.
+type TimezoneResponse struct {
+	Point        GeoPoint `json:"point"`
+	Timezone     string   `json:"timezone"`
+	Abbreviation string   `json:"abbreviation"`
+	Offset       int      `json:"offset"`
+	UTCOffset    string   `json:"utc_offset"`
+	Nautical     bool     `json:"nautical,omitempty"`
+}
+
+// ElevationRequest представляет запрос высот точек или адресов.
+type ElevationRequest struct {
+	Points []Waypoint `json:"points"`
+}
+
+// ElevationPoint — точка и ее высота над уровнем моря в метрах.
+type ElevationPoint struct {
+	Point     GeoPoint `json:"point"`
+	Elevation float64  `json:"elevation"`
+}
+
+// ElevationResponse содержит высоты точек в порядке запроса.
+type ElevationResponse struct {
+	Results []ElevationPoint `json:"results"`
+}
+
+// ElevationProfileRequest представляет запрос профиля высот вдоль ломаной
+// Path. Samples — число точек профиля, равномерно распределенных по длине.
+type ElevationProfileRequest struct {
+	Path    []Waypoint `json:"path"`
+	Samples int        `json:"samples,omitempty"`
+}
+
+// ProfilePoint — точка профиля. Distance — расстояние от начала ломаной в метрах.
+type ProfilePoint struct {
+	Point     GeoPoint `json:"point"`
+	Distance  float64  `json:"distance"`
+	Elevation float64  `json:"elevation"`
+}
+
+// ElevationProfileResponse содержит профиль высот. Length — длина ломаной
+// в метрах, Ascent и Descent — суммарные подъем и спуск между точками
+// профиля, Min и Max — наименьшая и наибольшая высота.
+type ElevationProfileResponse struct {
+	Points  []ProfilePoint `json:"points"`
+	Length  float64        `json:"length"`
+	Ascent  float64        `json:"ascent"`
+	Descent float64        `json:"descent"`
+	Min     float64        `json:"min"`
+	Max     float64        `json:"max"`
+}
+
+// Geofence — именованный полигон пользователя, например зона доставки
+// или запретная территория. Properties — произвольные строковые атрибуты,
+// Geometry — Polygon или MultiPolygon.
+type Geofence struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Geometry   Geometry          `json:"geometry"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// GeofenceRequest представляет создание или замену геозоны.
+type GeofenceRequest struct {
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Geometry   Geometry          `json:"geometry"`
+}
+
+// GeofenceListResponse содержит геозоны пользователя.
+type GeofenceListResponse struct {
+	Geofences []Geofence `json:"geofences"`
+}
+
+// GeofenceCheckRequest представляет запрос геозон, содержащих точку или адрес.
+type GeofenceCheckRequest struct {
+	Point   *GeoPoint `json:"point,omitempty"`
+	Address string    `json:"address,omitempty"`
+}
+
+// GeofenceCheckResponse содержит геозоны пользователя, в которые попала
+// точка, от меньшей по площади к большей.
+type GeofenceCheckResponse struct {
+	Point     GeoPoint   `json:"point"`
+	Geofences []Geofence `json:"geofences"`
+}
+
+// Depot — склад, из которого выполняется доставка.
+type Depot struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name,omitempty"`
+	Point GeoPoint `json:"point"`
+}
+
+// DeliveryZone — зона обслуживания склада DepotID: полигон или круг вокруг
+// склада. Fee — стоимость доставки, ETATier — класс срока доставки,
+// например "express" или "next_day".
+type DeliveryZone struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name,omitempty"`
+	DepotID string  `json:"depot_id"`
+	Fee     float64 `json:"fee"`
+	ETATier string  `json:"eta_tier,omitempty"`
+}
+
+// DeliveryCheckRequest представляет проверку доставки по адресу или точке.
+type DeliveryCheckRequest struct {
+	Point   *GeoPoint `json:"point,omitempty"`
+	Address string    `json:"address,omitempty"`
+}
+
+// NearestDeliveryZone — ближайшая зона и расстояние до ее границы в метрах.
+type NearestDeliveryZone struct {
+	Zone     DeliveryZone `json:"zone"`
+	Distance float64      `json:"distance"`
+}
+
+// DeliveryCheckResponse содержит зоны, в которые попала точка, от дешевой
+// к дорогой, и склад первой из них. Если точка вне всех зон, Deliverable
+// ложно, а Nearest указывает ближайшую зону.
+type DeliveryCheckResponse struct {
+	Point       GeoPoint             `json:"point"`
+	Deliverable bool                 `json:"deliverable"`
+	Zones       []DeliveryZone       `json:"zones"`
+	Depot       *Depot               `json:"depot,omitempty"`
+	Nearest     *NearestDeliveryZone `json:"nearest,omitempty"`
+}
+
+// Location — точка сети: магазин или пункт выдачи. Tags — метки для
+// фильтрации, Hours — часы работы в формате opening_hours OpenStreetMap,
+// например "Mo-Fr 09:00-21:00; Sa,Su 10:00-18:00". Timezone — пояс IANA,
+// в котором заданы часы работы. Owner — пользователь, создавший точку:
+// изменять и удалять ее может только он.
+type Location struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Address    string            `json:"address,omitempty"`
+	Point      GeoPoint          `json:"point"`
+	Tags       []string          `json:"tags,omitempty"`
+	Hours      string            `json:"hours,omitempty"`
+	Timezone   string            `json:"timezone,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Owner      string            `json:"owner,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// LocationRequest представляет создание или замену точки. Если Point
+// не задан, координаты определяются геокодированием Address.
+type LocationRequest struct {
+	Name       string            `json:"name"`
+	Address    string            `json:"address,omitempty"`
+	Point      *GeoPoint         `json:"point,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Hours      string            `json:"hours,omitempty"`
+	Timezone   string            `json:"timezone,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// LocationImportResponse содержит число добавленных и замененных точек.
+type LocationImportResponse struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// NearestLocationsRequest представляет поиск ближайших к точке или адресу
+// точек сети. Limit — сколько вернуть, MaxDistance — радиус поиска в метрах,
+// Tags — метки, которые должны быть у точки все. OpenAt оставляет точки,
+// открытые в этот момент, OpenNow — открытые сейчас.
+type NearestLocationsRequest struct {
+	Point       *GeoPoint  `json:"point,omitempty"`
+	Address     string     `json:"address,omitempty"`
+	Limit       int        `json:"limit,omitempty"`
+	MaxDistance float64    `json:"max_distance,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	OpenAt      *time.Time `json:"open_at,omitempty"`
+	OpenNow     bool       `json:"open_now,omitempty"`
+}
+
+// NearestLocation — точка сети и расстояние до нее в метрах по большому кругу.
+type NearestLocation struct {
+	Location Location `json:"location"`
+	Distance float64  `json:"distance"`
+}
+
+// NearestLocationsResponse содержит точки сети от ближайшей к дальней.
+type NearestLocationsResponse struct {
+	Point     GeoPoint          `json:"point"`
+	Locations []NearestLocation `json:"locations"`
+}
+
+// LocationCluster — группа точек сети на карте: Point — центр масс точек,
+// Bounds — охватывающая их область. Location заполнено, если точка
+// в кластере одна.
+type LocationCluster struct {
+	Count    int         `json:"count"`
+	Point    GeoPoint    `json:"point"`
+	Bounds   BoundingBox `json:"bounds"`
+	Location *Location   `json:"location,omitempty"`
+}
+
+// LocationClustersResponse содержит кластеры точек видимой области карты
+// от больших к меньшим. Truncated — кластеров больше, чем вернули.
+type LocationClustersResponse struct {
+	Zoom      int               `json:"zoom"`
+	Clusters  []LocationCluster `json:"clusters"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+// Layer — слой карты: набор объектов GeoJSON, который ведет пользователь
+// или команда. Owner управляет слоем, Editors меняют объекты, Viewers
+// только читают; Public открывает чтение всем пользователям.
+type Layer struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description,omitempty"`
+	Owner        string    `json:"owner"`
+	Public       bool      `json:"public"`
+	Editors      []string  `json:"editors,omitempty"`
+	Viewers      []string  `json:"viewers,omitempty"`
+	FeatureCount int       `json:"feature_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// LayerRequest представляет создание или замену слоя.
+type LayerRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Public      bool     `json:"public,omitempty"`
+	Editors     []string `json:"editors,omitempty"`
+	Viewers     []string `json:"viewers,omitempty"`
+}
+
+// LayerListResponse содержит слои, доступные пользователю.
+type LayerListResponse struct {
+	Layers []Layer `json:"layers"`
+}
+
+// Feature — объект GeoJSON (RFC 7946). Distance — расстояние в метрах
+// до точки запроса ближайших объектов.
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+	Distance   *float64               `json:"distance,omitempty"`
+}
+
+// FeatureCollection — коллекция объектов GeoJSON.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// LayerQueryRequest представляет поиск объектов слоя. Условия складываются:
+// BBox — пересечение охватывающего прямоугольника объекта с областью,
+// Intersects — общие точки с геометрией, Within — объект целиком внутри
+// полигонов геометрии. Nearest упорядочивает объекты по расстоянию до
+// точки или адреса. Limit — наибольшее число объектов.
+type LayerQueryRequest struct {
+	BBox       *BoundingBox `json:"bbox,omitempty"`
+	Intersects *Geometry    `json:"intersects,omitempty"`
+	Within     *Geometry    `json:"within,omitempty"`
+	Nearest    *Waypoint    `json:"nearest,omitempty"`
+	Limit      int          `json:"limit,omitempty"`
+}
+
+// Действия в журнале изменений.
+const (
+	RevisionCreate = "create"
+	RevisionUpdate = "update"
+	RevisionDelete = "delete"
+)
+
+// Revision — запись журнала изменений объекта. Revision — номер с 1 для
+// каждого объекта, Data — состояние объекта после изменения (у удаления
+// нет), RevertedTo — номер ревизии, к которой объект вернули.
+type Revision struct {
+	Revision   int             `json:"revision"`
+	Action     string          `json:"action"`
+	Author     string          `json:"author"`
+	Timestamp  time.Time       `json:"timestamp"`
+	RevertedTo int             `json:"reverted_to,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
+// RevisionListResponse содержит ревизии объекта от первой к последней
+// без состояний объекта.
+type RevisionListResponse struct {
+	Revisions []Revision `json:"revisions"`
+}
+
+// RevertRequest представляет возврат объекта к ревизии.
+type RevertRequest struct {
+	Revision int `json:"revision"`
 }
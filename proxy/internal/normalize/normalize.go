@@ -0,0 +1,353 @@
+package normalize
+
+import (
+	"encoding/json"
+	"geo-controller/proxy/internal/translit"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// abbreviations раскрывает сокращения адресных элементов.
+var abbreviations = map[string]string{
+	"г":     "город",
+	"гор":   "город",
+	"обл":   "область",
+	"р-н":   "район",
+	"р-он":  "район",
+	"пос":   "поселок",
+	"дер":   "деревня",
+	"ул":    "улица",
+	"пр-т":  "проспект",
+	"пр-кт": "проспект",
+	"просп": "проспект",
+	"пр":    "проспект",
+	"пер":   "переулок",
+	"б-р":   "бульвар",
+	"бул":   "бульвар",
+	"ш":     "шоссе",
+	"наб":   "набережная",
+	"пл":    "площадь",
+	"туп":   "тупик",
+	"мкр":   "микрорайон",
+	"мкр-н": "микрорайон",
+	"корп":  "корпус",
+	"к":     "корпус",
+	"стр":   "строение",
+	"кв":    "квартира",
+	"оф":    "офис",
+	"пом":   "помещение",
+	"а/я":   "абонентский ящик",
+	"пр-д":  "проезд",
+	"респ":  "республика",
+	"ао":    "автономный округ",
+	"тер":   "территория",
+}
+
+// contextAbbreviations — однобуквенные сокращения, смысл которых зависит
+// от соседей: перед названием "с Ивановка" — село, после номера "д 7 с 1" —
+// строение. После улицы название не ожидается: "ул Ленина д 7" — дом.
+// otherwise — форма во всех остальных случаях. Пустое значение — слово
+// остается как есть.
+var contextAbbreviations = map[string]struct{ place, afterNumber, otherwise string }{
+	"с": {place: "село", afterNumber: "строение"},
+	"п": {place: "поселок"},
+	"д": {place: "деревня", otherwise: "дом"},
+}
+
+// romanizedWords — латинские записи адресных терминов, по которым запрос
+// узнается как транслитерация русского адреса.
+var romanizedWords = map[string]bool{
+	"ul": true, "ulitsa": true, "ulica": true, "prospekt": true, "prosp": true,
+	"pereulok": true, "per": true, "shosse": true, "naberezhnaya": true, "nab": true,
+	"ploshchad": true, "bulvar": true, "proezd": true, "tupik": true, "mikrorayon": true,
+	"mkr": true, "dom": true, "korpus": true, "korp": true, "stroenie": true, "kv": true,
+	"g": true, "gorod": true, "oblast": true, "obl": true, "rayon": true, "poselok": true,
+	"selo": true, "derevnya": true,
+}
+
+// romanizedInfixes и romanizedSuffixes — сочетания букв, редкие
+// в английских словах и обычные в латинской записи русских: "zh", "kh",
+// окончания прилагательных "-skaya", "-sky", родительного падежа "-ova",
+// "-ina" и топонимов "-ovo", "-ino", "-grad".
+var (
+	romanizedInfixes  = []string{"shch", "zh", "kh", "yy", "tsa", "tse", "tsi", "tso", "tsy"}
+	romanizedSuffixes = []string{"aya", "iy", "oy", "sky", "oye", "ovo", "evo", "ino", "ova", "eva", "ina", "ogo", "ego", "ovka", "evka", "grad"}
+)
+
+// englishWords переводит английские адресные термины, которые не стоит транслитерировать.
+var englishWords = map[string]string{
+	"st":         "улица",
+	"street":     "улица",
+	"str":        "улица",
+	"ave":        "проспект",
+	"avenue":     "проспект",
+	"prospect":   "проспект",
+	"lane":       "переулок",
+	"ln":         "переулок",
+	"blvd":       "бульвар",
+	"boulevard":  "бульвар",
+	"sq":         "площадь",
+	"square":     "площадь",
+	"emb":        "набережная",
+	"embankment": "набережная",
+	"hwy":        "шоссе",
+	"highway":    "шоссе",
+	"city":       "город",
+	"region":     "область",
+	"bld":        "строение",
+	"bldg":       "строение",
+	"building":   "строение",
+	"apt":        "квартира",
+	"flat":       "квартира",
+	"house":      "дом",
+}
+
+// defaultSynonyms — встроенный словарь синонимов, дополняется конфигурацией.
+var defaultSynonyms = map[string]string{
+	"спб":       "санкт-петербург",
+	"питер":     "санкт-петербург",
+	"петербург": "санкт-петербург",
+	"мск":       "москва",
+	"екб":       "екатеринбург",
+	"нск":       "новосибирск",
+	"мо":        "московская область",
+	"ло":        "ленинградская область",
+	// английские названия, которые не совпадают с транслитерацией
+	"moscow":     "москва",
+	"petersburg": "санкт-петербург",
+}
+
+// streetTypes — типы, которые ставятся перед названием ("ленина улица" → "улица ленина").
+var streetTypes = map[string]bool{
+	"улица": true, "проспект": true, "переулок": true, "бульвар": true,
+	"шоссе": true, "набережная": true, "площадь": true, "тупик": true,
+	"проезд": true, "микрорайон": true,
+}
+
+// Normalizer приводит поисковый запрос к каноническому виду, чтобы разные
+// написания одного адреса давали одинаковый запрос и одинаковый ключ кэша.
+type Normalizer struct {
+	synonyms map[string][]string
+}
+
+// New создает Normalizer со встроенными синонимами, дополненными synonyms.
+func New(synonyms map[string]string) *Normalizer {
+	n := &Normalizer{synonyms: make(map[string][]string)}
+	for from, to := range defaultSynonyms {
+		n.addSynonym(from, to)
+	}
+	for from, to := range synonyms {
+		n.addSynonym(from, to)
+	}
+	return n
+}
+
+// LoadSynonyms читает словарь синонимов из JSON-файла вида {"питер": "санкт-петербург"}.
+func LoadSynonyms(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	synonyms := make(map[string]string)
+	if err := json.Unmarshal(data, &synonyms); err != nil {
+		return nil, err
+	}
+	return synonyms, nil
+}
+
+// addSynonym добавляет фразу from. Латинская фраза попадает в словарь
+// в двух видах — как есть и транслитерированной: в запросе она может
+// встретиться и среди английских слов, и в транслитерации русского адреса.
+func (n *Normalizer) addSynonym(from, to string) {
+	tokens := fold(from)
+	target := fold(to)
+	target = expand(target, isRomanized(target))
+	for _, romanized := range []bool{false, true} {
+		if key := strings.Join(expand(tokens, romanized), " "); key != "" {
+			n.synonyms[key] = target
+		}
+	}
+}
+
+// Normalize выполняет конвейер: приведение регистра и пробелов,
+// обратную транслитерацию латиницы, раскрытие сокращений, синонимы
+// и перенос типа улицы перед названием. Части адреса через запятую
+// обрабатываются отдельно. Латиница переводится в кириллицу, только если
+// запрос похож на транслитерацию русского адреса ("Tverskaya ul");
+// английские названия ("Red Square") остаются как есть.
+func (n *Normalizer) Normalize(query string) string {
+	var parts [][]string
+	var all []string
+	for _, part := range strings.Split(query, ",") {
+		if tokens := fold(part); len(tokens) > 0 {
+			parts = append(parts, tokens)
+			all = append(all, tokens...)
+		}
+	}
+	romanized := isRomanized(all)
+
+	var segments []string
+	for _, tokens := range parts {
+		tokens = expand(tokens, romanized)
+		tokens = n.applySynonyms(tokens)
+		tokens = reorderStreetType(tokens)
+		segments = append(segments, strings.Join(tokens, " "))
+	}
+	return strings.Join(segments, ", ")
+}
+
+// Word приводит одно слово к канонической форме без учета синонимов:
+// регистр, ё, обратная транслитерация и раскрытие сокращений.
+// Используется локальными индексами, чтобы слова документов и запросов совпадали.
+// В локальных индексах только русские адреса, поэтому латиница
+// транслитерируется всегда. Однобуквенные сокращения без соседей
+// не раскрываются.
+func Word(word string) string {
+	word = strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+	return strings.Join(expand([]string{word}, true), " ")
+}
+
+// fold приводит строку к нижнему регистру, заменяет ё на е и разбивает на слова.
+// Дефис и слеш внутри слова сохраняются ("пр-т", "а/я", "7/1"),
+// слитное сокращение с номером ("д7") разделяется.
+func fold(s string) []string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '/' || r == '\'')
+	})
+
+	var tokens []string
+	for _, field := range fields {
+		field = strings.Trim(field, "-/'")
+		if field == "" {
+			continue
+		}
+		tokens = append(tokens, splitHouseNumber(field)...)
+	}
+	return tokens
+}
+
+// splitHouseNumber разделяет слитное сокращение и номер: "д7" → "д", "7".
+func splitHouseNumber(token string) []string {
+	runes := []rune(token)
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsDigit(runes[i]) && unicode.IsLetter(runes[i-1]) {
+			prefix := string(runes[:i])
+			_, abbreviation := abbreviations[prefix]
+			_, contextual := contextAbbreviations[prefix]
+			if abbreviation || contextual {
+				return []string{prefix, string(runes[i:])}
+			}
+			return []string{token}
+		}
+	}
+	return []string{token}
+}
+
+// expand раскрывает сокращения. Если romanized, латинские слова
+// переводятся: адресные термины — по словарю, остальные — обратной
+// транслитерацией; иначе остаются как есть. Однобуквенные сокращения
+// раскрываются по соседям: после номера — как часть номера дома, перед
+// названием, если в части адреса еще нет улицы, — как тип населенного пункта.
+func expand(tokens []string, romanized bool) []string {
+	var result []string
+	for i, token := range tokens {
+		if romanized && translit.HasLatin(token) {
+			if word, ok := englishWords[token]; ok {
+				token = word
+			} else {
+				token = translit.ToCyrillic(token)
+			}
+		}
+		if full, ok := abbreviations[token]; ok {
+			result = append(result, strings.Fields(full)...)
+			continue
+		}
+		if forms, ok := contextAbbreviations[token]; ok {
+			form := forms.otherwise
+			switch {
+			case i > 0 && startsWithDigit(tokens[i-1]):
+				if forms.afterNumber != "" {
+					form = forms.afterNumber
+				}
+			case i+1 < len(tokens) && !startsWithDigit(tokens[i+1]) && !hasStreetType(result):
+				form = forms.place
+			}
+			if form != "" {
+				token = form
+			}
+		}
+		result = append(result, token)
+	}
+	return result
+}
+
+// isRomanized сообщает, похожи ли латинские слова на транслитерацию
+// русского адреса: достаточно одного адресного термина или слова
+// с характерными сочетаниями букв.
+func isRomanized(tokens []string) bool {
+	for _, token := range tokens {
+		if !translit.IsLatin(token) {
+			continue
+		}
+		if romanizedWords[token] {
+			return true
+		}
+		for _, infix := range romanizedInfixes {
+			if strings.Contains(token, infix) {
+				return true
+			}
+		}
+		for _, suffix := range romanizedSuffixes {
+			if len(token) >= len(suffix)+2 && strings.HasSuffix(token, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasStreetType сообщает, есть ли среди слов тип улицы.
+func hasStreetType(tokens []string) bool {
+	for _, token := range tokens {
+		if streetTypes[token] {
+			return true
+		}
+	}
+	return false
+}
+
+func startsWithDigit(token string) bool {
+	return token != "" && unicode.IsDigit([]rune(token)[0])
+}
+
+// applySynonyms заменяет фразы из словаря, начиная с самых длинных совпадений.
+func (n *Normalizer) applySynonyms(tokens []string) []string {
+	var result []string
+	for i := 0; i < len(tokens); {
+		replaced := false
+		for end := len(tokens); end > i; end-- {
+			if to, ok := n.synonyms[strings.Join(tokens[i:end], " ")]; ok {
+				result = append(result, to...)
+				i = end
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, tokens[i])
+			i++
+		}
+	}
+	return result
+}
+
+// reorderStreetType переносит тип улицы из конца части адреса в начало.
+func reorderStreetType(tokens []string) []string {
+	last := len(tokens) - 1
+	if last < 1 || !streetTypes[tokens[last]] || streetTypes[tokens[0]] {
+		return tokens
+	}
+	return append([]string{tokens[last]}, tokens[:last]...)
+}
@@ -0,0 +1,101 @@
+package normalize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	normalizer := New(map[string]string{"первопрестольная": "москва"})
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{"ул Ленина", "улица ленина"},
+		{"улица Ленина", "улица ленина"},
+		{"  УЛ.   Ленина ", "улица ленина"},
+		{"Lenina st.", "улица ленина"},
+		{"ul. Lenina", "улица ленина"},
+		{"Ленина ул", "улица ленина"},
+		{"г Москва, ул Тверская, д 7 кв 12", "город москва, улица тверская, дом 7 квартира 12"},
+		{"г. Москва, Тверская ул., д.7", "город москва, улица тверская, дом 7"},
+		{"Москва, Тверская д7", "москва, тверская дом 7"},
+		{"пр-т Мира", "проспект мира"},
+		{"Prospekt Mira", "проспект мира"},
+		{"Nevsky ave", "проспект невский"},
+		{"Питер, Невский пр-т", "санкт-петербург, проспект невский"},
+		{"Спб", "санкт-петербург"},
+		{"Красная пл", "площадь красная"},
+		{"Первопрестольная, Ленинградский пр-т", "москва, проспект ленинградский"},
+		{"Ёлочная", "елочная"},
+		{"Tverskaya 7a", "тверская 7а"},
+		{"Moscow, Tverskaya 7", "москва, тверская 7"},
+		{"Moskva, Tverskaya ul", "москва, улица тверская"},
+		{"Москва, д 7 с 1", "москва, дом 7 строение 1"},
+		{"Москва, Тверская 7с2", "москва, тверская 7с2"},
+		{"с Ивановка", "село ивановка"},
+		{"п Мирный, ул Ленина", "поселок мирный, улица ленина"},
+		{"кв 5 п 2", "квартира 5 п 2"},
+		{"д Ивановка", "деревня ивановка"},
+		{"Тверская обл, д Ивановка, д 5", "тверская область, деревня ивановка, дом 5"},
+		{"ул Ленина д 7", "улица ленина дом 7"},
+		{"Ленина ул, д", "улица ленина, дом"},
+		{"пр-т Мира д а", "проспект мира дом а"},
+		// английские названия не похожи на транслитерацию и остаются как есть
+		{"Red Square", "red square"},
+		{"Victory Park", "victory park"},
+		{"Moscow, Red Square", "москва, red square"},
+		{"Baker Street, London", "baker street, london"},
+		{"Times Square, New York", "times square, new york"},
+		{"", ""},
+		{" , ,", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			if got := normalizer.Normalize(tc.query); got != tc.expected {
+				t.Errorf("Normalize(%q): expected %q, got %q", tc.query, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWord(t *testing.T) {
+	testCases := map[string]string{
+		"Ул":     "улица",
+		"д":      "дом",
+		"Ленина": "ленина",
+		"Lenina": "ленина",
+		"st":     "улица",
+		"ЁЛКИ":   "елки",
+		"12":     "12",
+	}
+
+	for word, expected := range testCases {
+		if got := Word(word); got != expected {
+			t.Errorf("Word(%q): expected %q, got %q", word, expected, got)
+		}
+	}
+}
+
+func TestLoadSynonyms(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.json")
+	if err := os.WriteFile(path, []byte(`{"Нижний": "нижний новгород"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	synonyms, err := LoadSynonyms(path)
+	if err != nil {
+		t.Fatalf("LoadSynonyms failed: %v", err)
+	}
+
+	if got := New(synonyms).Normalize("нижний"); got != "нижний новгород" {
+		t.Errorf("expected configured synonym, got %q", got)
+	}
+
+	if _, err := LoadSynonyms(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file, but got nil")
+	}
+}
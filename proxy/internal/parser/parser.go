@@ -0,0 +1,452 @@
+package parser
+
+import (
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/normalize"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// kind — вид адресного элемента, который обозначает слово-маркер.
+type kind int
+
+const (
+	kindNone kind = iota
+	kindRegion
+	kindArea
+	kindCity
+	kindStreet
+	kindHouse
+	kindBuilding
+	kindFlat
+)
+
+// markers сопоставляет каноническую форму типа (см. normalize.Word) с видом элемента.
+var markers = map[string]kind{
+	"область":          kindRegion,
+	"край":             kindRegion,
+	"республика":       kindRegion,
+	"автономный округ": kindRegion,
+	"район":            kindArea,
+	"городской округ":  kindArea,
+	"город":            kindCity,
+	"поселок":          kindCity,
+	"пгт":              kindCity,
+	"село":             kindCity,
+	"деревня":          kindCity,
+	"станица":          kindCity,
+	"хутор":            kindCity,
+	"улица":            kindStreet,
+	"проспект":         kindStreet,
+	"переулок":         kindStreet,
+	"бульвар":          kindStreet,
+	"шоссе":            kindStreet,
+	"набережная":       kindStreet,
+	"площадь":          kindStreet,
+	"тупик":            kindStreet,
+	"проезд":           kindStreet,
+	"микрорайон":       kindStreet,
+	"аллея":            kindStreet,
+	"линия":            kindStreet,
+	"тракт":            kindStreet,
+	"дом":              kindHouse,
+	"владение":         kindHouse,
+	"корпус":           kindBuilding,
+	"строение":         kindBuilding,
+	"квартира":         kindFlat,
+	"офис":             kindFlat,
+	"помещение":        kindFlat,
+}
+
+// extraMarkers — формы, которых нет среди сокращений normalize. Однобуквенные
+// "с" и "п" normalize раскрывает только по соседям; здесь они означают
+// населенный пункт, а "с" после номера дома разбирается в parseSegment.
+var extraMarkers = map[string]string{
+	"с":     "село",
+	"п":     "поселок",
+	"вл":    "владение",
+	"помещ": "помещение",
+	"ст-ца": "станица",
+	"х":     "хутор",
+	"ал":    "аллея",
+	"лн":    "линия",
+}
+
+// compoundMarkers — сокращения из двух слов, которые разбиваются на части
+// при разборе: "г.о." → "г", "о".
+var compoundMarkers = map[string]string{
+	"г о": "городской округ",
+}
+
+// federalCities одновременно являются регионом и городом.
+var federalCities = map[string]bool{
+	"москва":          true,
+	"санкт-петербург": true,
+	"севастополь":     true,
+}
+
+// cities — крупные города, которые узнаются в начале адреса и без маркера
+// "г": "Москва Тверская 7". Ключи в нижнем регистре, "ё" заменена на "е".
+var cities = map[string]bool{
+	"москва": true, "санкт-петербург": true, "севастополь": true, "новосибирск": true,
+	"екатеринбург": true, "казань": true, "нижний новгород": true, "челябинск": true,
+	"самара": true, "омск": true, "ростов-на-дону": true, "уфа": true, "красноярск": true,
+	"воронеж": true, "пермь": true, "волгоград": true, "краснодар": true, "саратов": true,
+	"тюмень": true, "тольятти": true, "ижевск": true, "барнаул": true, "ульяновск": true,
+	"иркутск": true, "хабаровск": true, "ярославль": true, "владивосток": true,
+	"махачкала": true, "томск": true, "оренбург": true, "кемерово": true, "рязань": true,
+	"астрахань": true, "пенза": true, "липецк": true, "киров": true, "чебоксары": true,
+	"калининград": true, "тула": true, "курск": true, "ставрополь": true, "сочи": true,
+	"тверь": true, "иваново": true, "брянск": true, "белгород": true, "сургут": true,
+	"архангельск": true, "смоленск": true, "калуга": true, "мурманск": true, "вологда": true,
+	"великий новгород": true, "набережные челны": true, "химки": true, "подольск": true,
+	"балашиха": true, "мытищи": true, "королев": true, "зеленоград": true,
+}
+
+var countries = map[string]string{
+	"россия": "Россия",
+	"рф":     "Россия",
+}
+
+var (
+	postalCodeRe = regexp.MustCompile(`^\d{6}$`)
+	// houseRe — номер дома: "7", "7а", "7/1", "12-14".
+	houseRe = regexp.MustCompile(`^\d+[а-яa-z]?(?:[/-]\d+[а-яa-z]?)?$`)
+	// houseBuildingRe — слитная запись "7к2", "7стр1", "7с1".
+	houseBuildingRe = regexp.MustCompile(`^(\d+[а-яa-z]?)(к|корп|стр|с)(\d+[а-яa-z]?)$`)
+	// gluedBuildingTypes — типы корпуса и строения в слитной записи номера.
+	gluedBuildingTypes = map[string]string{"к": "корпус", "корп": "корпус", "стр": "строение", "с": "строение"}
+	// ordinalRe — порядковые названия улиц: "1-я", "8-го", "2-й".
+	ordinalRe = regexp.MustCompile(`^\d+-(?:я|й|ая|ый|ий|го|е|ое)$`)
+)
+
+type token struct {
+	text  string
+	lower string
+	kind  kind
+	typ   string
+}
+
+// Parse разбирает адрес в свободной форме на компоненты без обращения к внешним сервисам.
+// Названия сохраняют написание из исходной строки, типы приводятся к полной форме.
+func Parse(input string) *models.ParsedAddress {
+	p := &state{result: &models.ParsedAddress{}}
+	for _, segment := range strings.Split(input, ",") {
+		p.parseSegment(tokenize(segment))
+	}
+	p.result.Unparsed = strings.Join(p.unparsed, " ")
+	return p.result
+}
+
+type state struct {
+	result   *models.ParsedAddress
+	unparsed []string
+}
+
+// parseSegment обрабатывает часть адреса между запятыми. Маркер типа
+// может стоять перед названием ("ул Тверская") или после него ("Тверская ул").
+func (p *state) parseSegment(tokens []token) {
+	var pending []string
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		// "с" после номера — строение, а не село: "д 7 с 1"
+		if tok.lower == "с" && i > 0 && isNumber(tokens[i-1]) {
+			tok.kind, tok.typ = kindBuilding, "строение"
+		}
+
+		switch {
+		case tok.kind == kindHouse || tok.kind == kindBuilding || tok.kind == kindFlat:
+			if i+1 < len(tokens) && isNumber(tokens[i+1]) {
+				p.flushPending(pending)
+				pending = nil
+				i++
+				p.assignNumber(tok.kind, tok.typ, tokens[i].text)
+				continue
+			}
+			if tok.typ == "дом" && i+1 < len(tokens) {
+				// "д Бор" — деревня, а не дом
+				tok.kind, tok.typ = kindCity, "деревня"
+				tokens[i] = tok
+				i--
+				continue
+			}
+			pending = append(pending, tok.text)
+
+		case tok.kind != kindNone:
+			var name []string
+			j := i + 1
+			for ; j < len(tokens) && tokens[j].kind == kindNone; j++ {
+				// номер сразу после маркера — часть названия: "ул 8 Марта", "мкр 5"
+				numbered := tok.kind == kindStreet && len(name) == 0 &&
+					(tok.typ == "микрорайон" || j+1 < len(tokens) && !isNumber(tokens[j+1]) && tokens[j+1].kind == kindNone)
+				if isNumber(tokens[j]) && !numbered {
+					break
+				}
+				name = append(name, tokens[j].text)
+			}
+			if len(name) == 0 && len(pending) > 0 {
+				name, pending = pending, nil
+				if tok.kind == kindStreet {
+					name = p.takeCity(name)
+				}
+			}
+			p.flushPending(pending)
+			pending = nil
+			p.assignPlace(tok.kind, tok.typ, strings.Join(name, " "))
+			i = j - 1
+
+		case postalCodeRe.MatchString(tok.lower) && p.result.PostalCode == "":
+			p.result.PostalCode = tok.text
+
+		case isNumber(tok):
+			if pending = p.takeCity(pending); len(pending) > 0 && !p.streetTaken() {
+				p.assignPlace(kindStreet, "", strings.Join(pending, " "))
+				pending = nil
+			}
+			p.flushPending(pending)
+			pending = nil
+			p.assignBareNumber(tok.text)
+
+		default:
+			pending = append(pending, tok.text)
+		}
+	}
+	p.flushPending(pending)
+}
+
+// flushPending распределяет слова без маркера: страна, затем город, затем улица.
+func (p *state) flushPending(words []string) {
+	if words = p.takeCity(words); len(words) == 0 {
+		return
+	}
+	name := strings.Join(words, " ")
+	lower := strings.ToLower(name)
+
+	switch {
+	case countries[lower] != "" && p.result.Country == "":
+		p.result.Country = countries[lower]
+	case p.result.City == "" && p.result.Street == "":
+		p.assignPlace(kindCity, "", name)
+	case !p.streetTaken() && p.result.House == "":
+		p.assignPlace(kindStreet, "", name)
+	default:
+		p.unparsed = append(p.unparsed, name)
+	}
+}
+
+func (p *state) assignPlace(k kind, typ, name string) {
+	if name == "" {
+		p.unparsed = append(p.unparsed, typ)
+		return
+	}
+	r := p.result
+	switch k {
+	case kindRegion:
+		if r.Region != "" {
+			p.unparsed = append(p.unparsed, name)
+			return
+		}
+		r.Region, r.RegionType = name, typ
+	case kindArea:
+		r.Area, r.AreaType = name, typ
+	case kindCity:
+		if r.City != "" {
+			// город уже известен — второй населенный пункт считаем районом
+			r.Area, r.AreaType = name, typ
+			return
+		}
+		if typ == "" {
+			typ = "город"
+		}
+		r.City, r.CityType = name, typ
+		if federalCities[strings.ToLower(name)] && r.Region == "" {
+			r.Region, r.RegionType = name, "город"
+		}
+	case kindStreet:
+		switch {
+		case r.Street == "":
+		case typ != "микрорайон" && r.StreetType == "микрорайон" && r.Settlement == "":
+			// улица важнее микрорайона: он переезжает в Settlement
+			r.Settlement, r.SettlementType = r.Street, r.StreetType
+		case typ == "микрорайон" && r.Settlement == "":
+			r.Settlement, r.SettlementType = name, typ
+			return
+		default:
+			p.unparsed = append(p.unparsed, name)
+			return
+		}
+		if typ == "" {
+			typ = "улица"
+		}
+		r.Street, r.StreetType = name, typ
+	}
+}
+
+// streetTaken сообщает, занята ли улица. Микрорайон занимает ее, только
+// пока в адресе нет улицы.
+func (p *state) streetTaken() bool {
+	r := p.result
+	return r.Street != "" && (r.StreetType != "микрорайон" || r.Settlement != "")
+}
+
+// takeCity отделяет известный город в начале слов без маркера и возвращает
+// оставшиеся слова. Город выделяется, только если он еще не заполнен и после
+// него есть другие слова.
+func (p *state) takeCity(words []string) []string {
+	if p.result.City != "" {
+		return words
+	}
+	for n := 2; n >= 1; n-- {
+		if len(words) <= n {
+			continue
+		}
+		name := strings.Join(words[:n], " ")
+		if cities[strings.ReplaceAll(strings.ToLower(name), "ё", "е")] {
+			p.assignPlace(kindCity, "", name)
+			return words[n:]
+		}
+	}
+	return words
+}
+
+func (p *state) assignNumber(k kind, typ, value string) {
+	r := p.result
+	switch k {
+	case kindHouse:
+		if m := houseBuildingRe.FindStringSubmatch(strings.ToLower(value)); m != nil {
+			r.House = m[1]
+			r.BuildingType, r.Building = gluedBuildingTypes[m[2]], m[3]
+			return
+		}
+		r.House = value
+	case kindBuilding:
+		r.BuildingType, r.Building = typ, value
+	case kindFlat:
+		r.FlatType, r.Flat = typ, value
+	}
+}
+
+// assignBareNumber заполняет номер без маркера: сначала дом, затем квартиру.
+func (p *state) assignBareNumber(value string) {
+	switch {
+	case p.result.House == "":
+		p.assignNumber(kindHouse, "дом", value)
+	case p.result.Flat == "":
+		p.assignNumber(kindFlat, "квартира", value)
+	default:
+		p.unparsed = append(p.unparsed, value)
+	}
+}
+
+// tokenize делит часть адреса на слова, отделяя точки и склеенные
+// с номером маркеры ("д7" → "д", "7").
+func tokenize(segment string) []token {
+	fields := strings.FieldsFunc(segment, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '/')
+	})
+
+	var tokens []token
+	for i := 0; i < len(fields); i++ {
+		field := strings.Trim(fields[i], "-/")
+		if field == "" {
+			continue
+		}
+		// литера дома, записанная отдельно: "7 А" → "7А"
+		if n := len(tokens); n > 0 && isHouseLetter(field) && isDigits(tokens[n-1].lower) {
+			tokens[n-1] = newToken(tokens[n-1].text + field)
+			continue
+		}
+		for _, part := range splitGlued(field) {
+			tokens = append(tokens, newToken(part))
+		}
+		// составные типы: "автономный округ", "городской округ", "г.о."
+		if n := len(tokens); n >= 2 {
+			pair := strings.ToLower(tokens[n-2].text + " " + tokens[n-1].text)
+			if typ, ok := compoundMarkers[pair]; ok {
+				pair = typ
+			}
+			if k, ok := markers[pair]; ok {
+				tokens = append(tokens[:n-2], token{text: pair, lower: pair, kind: k, typ: pair})
+			}
+		}
+	}
+	return tokens
+}
+
+// splitGlued отделяет маркер, записанный слитно с номером: "д7", "кв12".
+func splitGlued(field string) []string {
+	runes := []rune(field)
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsDigit(runes[i]) {
+			continue
+		}
+		if !unicode.IsLetter(runes[i-1]) {
+			return []string{field}
+		}
+		prefix := string(runes[:i])
+		if k, _ := markerKind(prefix); k == kindHouse || k == kindBuilding || k == kindFlat {
+			return []string{prefix, string(runes[i:])}
+		}
+		return []string{field}
+	}
+	return []string{field}
+}
+
+func newToken(text string) token {
+	lower := strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	k, typ := markerKind(text)
+	return token{text: text, lower: lower, kind: k, typ: typ}
+}
+
+// markerKind определяет, является ли слово маркером типа адресного элемента.
+func markerKind(word string) (kind, string) {
+	lower := strings.ToLower(word)
+	if hasDigit(lower) {
+		return kindNone, ""
+	}
+	canonical, ok := extraMarkers[lower]
+	if !ok {
+		canonical = normalize.Word(lower)
+	}
+	if k, ok := markers[canonical]; ok {
+		return k, canonical
+	}
+	return kindNone, ""
+}
+
+// isNumber сообщает, похоже ли слово на номер дома, корпуса или квартиры.
+func isNumber(tok token) bool {
+	if ordinalRe.MatchString(tok.lower) {
+		return false
+	}
+	return houseRe.MatchString(tok.lower) || houseBuildingRe.MatchString(tok.lower)
+}
+
+// isHouseLetter сообщает, является ли слово одиночной буквой, которая
+// не обозначает тип элемента.
+func isHouseLetter(word string) bool {
+	if len([]rune(word)) != 1 || !unicode.IsLetter([]rune(word)[0]) {
+		return false
+	}
+	k, _ := markerKind(word)
+	return k == kindNone
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func hasDigit(s string) bool {
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
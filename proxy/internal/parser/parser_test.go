@@ -0,0 +1,351 @@
+package parser
+
+import (
+	"geo-controller/proxy/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected models.ParsedAddress
+	}{
+		// полные адреса с маркерами перед названием
+		{"г Москва, ул Тверская, д 7 кв 12", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7", Flat: "12", FlatType: "квартира"}},
+		{"г. Москва, ул. Тверская, д. 7, кв. 12", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7", Flat: "12", FlatType: "квартира"}},
+		{"г Москва ул Тверская д 7 кв 12", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7", Flat: "12", FlatType: "квартира"}},
+		{"город Москва, улица Тверская, дом 7, квартира 12", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7", Flat: "12", FlatType: "квартира"}},
+		{"125009, г Москва, ул Тверская, д 7", models.ParsedAddress{
+			PostalCode: "125009", Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7"}},
+		{"Россия, 190000, г Санкт-Петербург, Невский пр-кт, д 28", models.ParsedAddress{
+			PostalCode: "190000", Country: "Россия", Region: "Санкт-Петербург", RegionType: "город",
+			City: "Санкт-Петербург", CityType: "город", Street: "Невский", StreetType: "проспект", House: "28"}},
+
+		// маркер после названия
+		{"Московская обл, г Химки, Ленинградское ш, д 1", models.ParsedAddress{
+			Region: "Московская", RegionType: "область", City: "Химки", CityType: "город",
+			Street: "Ленинградское", StreetType: "шоссе", House: "1"}},
+		{"Тверская ул., 7", models.ParsedAddress{
+			City: "", Street: "Тверская", StreetType: "улица", House: "7"}},
+		{"Невский проспект 28", models.ParsedAddress{
+			Street: "Невский", StreetType: "проспект", House: "28"}},
+		{"Респ Татарстан, г Казань, ул Баумана, д 1", models.ParsedAddress{
+			Region: "Татарстан", RegionType: "республика", City: "Казань", CityType: "город",
+			Street: "Баумана", StreetType: "улица", House: "1"}},
+		{"Татарстан Респ, Казань г, Баумана ул, 1", models.ParsedAddress{
+			Region: "Татарстан", RegionType: "республика", City: "Казань", CityType: "город",
+			Street: "Баумана", StreetType: "улица", House: "1"}},
+		{"Краснодарский край, г Сочи, Курортный пр-кт, д 50", models.ParsedAddress{
+			Region: "Краснодарский", RegionType: "край", City: "Сочи", CityType: "город",
+			Street: "Курортный", StreetType: "проспект", House: "50"}},
+		{"Ханты-Мансийский автономный округ, г Сургут", models.ParsedAddress{
+			Region: "Ханты-Мансийский", RegionType: "автономный округ", City: "Сургут", CityType: "город"}},
+
+		// без маркеров
+		{"Москва, Тверская 7", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7"}},
+		{"Москва, Тверская, 7, 12", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7", Flat: "12", FlatType: "квартира"}},
+		{"Казань", models.ParsedAddress{City: "Казань", CityType: "город"}},
+		{"Тверская 7", models.ParsedAddress{Street: "Тверская", StreetType: "улица", House: "7"}},
+
+		// номера домов, корпуса, строения
+		{"ул Ленина, д 7а", models.ParsedAddress{Street: "Ленина", StreetType: "улица", House: "7а"}},
+		{"ул Ленина, д 7 А", models.ParsedAddress{Street: "Ленина", StreetType: "улица", House: "7А"}},
+		{"ул Ленина 7 б, кв 3", models.ParsedAddress{Street: "Ленина", StreetType: "улица", House: "7б", Flat: "3", FlatType: "квартира"}},
+		{"ул Ленина, д 7/1", models.ParsedAddress{Street: "Ленина", StreetType: "улица", House: "7/1"}},
+		{"ул Ленина, д 7 корп 2", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "7", Building: "2", BuildingType: "корпус"}},
+		{"ул Ленина, д 7 к 2", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "7", Building: "2", BuildingType: "корпус"}},
+		{"ул Ленина, д 7 стр 1", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "7", Building: "1", BuildingType: "строение"}},
+		{"ул Ленина, д 7к2", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "7", Building: "2", BuildingType: "корпус"}},
+		{"ул Ленина, д 7стр1", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "7", Building: "1", BuildingType: "строение"}},
+		{"ул Ленина, д7, кв12", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "7", Flat: "12", FlatType: "квартира"}},
+		{"ул Ленина, вл 3", models.ParsedAddress{Street: "Ленина", StreetType: "улица", House: "3"}},
+		{"ул Ленина 5, оф 301", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "5", Flat: "301", FlatType: "офис"}},
+		{"ул Ленина 5, пом 4", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "5", Flat: "4", FlatType: "помещение"}},
+		{"ул Ленина 12-14", models.ParsedAddress{Street: "Ленина", StreetType: "улица", House: "12-14"}},
+
+		// типы улиц
+		{"пр-т Мира, 1", models.ParsedAddress{Street: "Мира", StreetType: "проспект", House: "1"}},
+		{"просп. Мира 1", models.ParsedAddress{Street: "Мира", StreetType: "проспект", House: "1"}},
+		{"пер Сивцев Вражек, 3", models.ParsedAddress{Street: "Сивцев Вражек", StreetType: "переулок", House: "3"}},
+		{"Гоголевский б-р 10", models.ParsedAddress{Street: "Гоголевский", StreetType: "бульвар", House: "10"}},
+		{"наб Фонтанки, 20", models.ParsedAddress{Street: "Фонтанки", StreetType: "набережная", House: "20"}},
+		{"Красная пл, 1", models.ParsedAddress{Street: "Красная", StreetType: "площадь", House: "1"}},
+		{"мкр Северный, д 5", models.ParsedAddress{Street: "Северный", StreetType: "микрорайон", House: "5"}},
+		{"Кутузовский проезд 8", models.ParsedAddress{Street: "Кутузовский", StreetType: "проезд", House: "8"}},
+		{"туп Глухой 2", models.ParsedAddress{Street: "Глухой", StreetType: "тупик", House: "2"}},
+		{"Никитская аллея 4", models.ParsedAddress{Street: "Никитская", StreetType: "аллея", House: "4"}},
+		{"Сибирский тракт 3", models.ParsedAddress{Street: "Сибирский", StreetType: "тракт", House: "3"}},
+
+		// названия улиц с числами
+		{"ул 8 Марта, д 3", models.ParsedAddress{Street: "8 Марта", StreetType: "улица", House: "3"}},
+		{"ул 1-я Тверская-Ямская, д 2", models.ParsedAddress{Street: "1-я Тверская-Ямская", StreetType: "улица", House: "2"}},
+		{"ул 26 Бакинских Комиссаров 9", models.ParsedAddress{Street: "26 Бакинских Комиссаров", StreetType: "улица", House: "9"}},
+
+		// населенные пункты
+		{"Московская обл, Одинцовский р-н, д Бор, д 5", models.ParsedAddress{
+			Region: "Московская", RegionType: "область", Area: "Одинцовский", AreaType: "район",
+			City: "Бор", CityType: "деревня", House: "5"}},
+		{"с Ленино, ул Мира 3", models.ParsedAddress{
+			City: "Ленино", CityType: "село", Street: "Мира", StreetType: "улица", House: "3"}},
+		{"пос Солнечный, ул Лесная, д 1", models.ParsedAddress{
+			City: "Солнечный", CityType: "поселок", Street: "Лесная", StreetType: "улица", House: "1"}},
+		{"пгт Янтарный", models.ParsedAddress{City: "Янтарный", CityType: "пгт"}},
+		{"ст-ца Каневская, ул Горького 5", models.ParsedAddress{
+			City: "Каневская", CityType: "станица", Street: "Горького", StreetType: "улица", House: "5"}},
+		{"х Ольховый", models.ParsedAddress{City: "Ольховый", CityType: "хутор"}},
+		{"г Москва, г Зеленоград, к 1106", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Area: "Зеленоград", AreaType: "город", Building: "1106", BuildingType: "корпус"}},
+		{"Нижний Новгород, ул Большая Покровская 1", models.ParsedAddress{
+			City: "Нижний Новгород", CityType: "город", Street: "Большая Покровская", StreetType: "улица", House: "1"}},
+
+		// регистр, ё и латиница
+		{"Г МОСКВА, УЛ ТВЕРСКАЯ, Д 7", models.ParsedAddress{
+			Region: "МОСКВА", RegionType: "город", City: "МОСКВА", CityType: "город",
+			Street: "ТВЕРСКАЯ", StreetType: "улица", House: "7"}},
+		{"ул Ёлочная, д 1", models.ParsedAddress{Street: "Ёлочная", StreetType: "улица", House: "1"}},
+		{"Moscow, Tverskaya st 7", models.ParsedAddress{
+			City: "Moscow", CityType: "город", Street: "Tverskaya", StreetType: "улица", House: "7"}},
+		{"ul. Lenina, d. 5, kv. 3", models.ParsedAddress{
+			Street: "Lenina", StreetType: "улица", House: "5", Flat: "3", FlatType: "квартира"}},
+
+		// микрорайон и улица
+		{"Московская обл, г Химки, мкр Сходня, ул Горная, д 1", models.ParsedAddress{
+			Region: "Московская", RegionType: "область", City: "Химки", CityType: "город", Settlement: "Сходня",
+			SettlementType: "микрорайон", Street: "Горная", StreetType: "улица", House: "1"}},
+		{"Московская обл, г Химки, ул Горная, мкр Сходня, д 1", models.ParsedAddress{
+			Region: "Московская", RegionType: "область", City: "Химки", CityType: "город", Settlement: "Сходня",
+			SettlementType: "микрорайон", Street: "Горная", StreetType: "улица", House: "1"}},
+		{"г Химки, мкр Сходня, д 5", models.ParsedAddress{
+			City: "Химки", CityType: "город", Street: "Сходня", StreetType: "микрорайон", House: "5"}},
+		{"мкр Сходня, Горная 1", models.ParsedAddress{
+			Settlement: "Сходня", SettlementType: "микрорайон", Street: "Горная", StreetType: "улица", House: "1"}},
+		{"Москва, мкр Северный, ул Лесная, д 3", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Settlement: "Северный",
+			SettlementType: "микрорайон", Street: "Лесная", StreetType: "улица", House: "3"}},
+		{"Балашиха мкр Железнодорожный ул Советская 5", models.ParsedAddress{
+			City: "Балашиха", CityType: "город", Settlement: "Железнодорожный", SettlementType: "микрорайон",
+			Street: "Советская", StreetType: "улица", House: "5"}},
+		{"Иркутск, мкр Университетский, д 34", models.ParsedAddress{
+			City: "Иркутск", CityType: "город", Street: "Университетский", StreetType: "микрорайон", House: "34"}},
+		{"мкр 5, д 12", models.ParsedAddress{
+			Street: "5", StreetType: "микрорайон", House: "12"}},
+		{"г Тольятти, мкр 5, ул Ленина 3", models.ParsedAddress{
+			City: "Тольятти", CityType: "город", Settlement: "5", SettlementType: "микрорайон", Street: "Ленина",
+			StreetType: "улица", House: "3"}},
+
+		// известный город без маркера
+		{"Москва Тверская 7", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Тверская",
+			StreetType: "улица", House: "7"}},
+		{"Москва Тверская ул 7", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Тверская",
+			StreetType: "улица", House: "7"}},
+		{"Москва ул Тверская 7", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Тверская",
+			StreetType: "улица", House: "7"}},
+		{"Москва Тверская 7 кв 5", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Тверская",
+			StreetType: "улица", House: "7", Flat: "5", FlatType: "квартира"}},
+		{"Москва Тверская 7 к 2", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Тверская",
+			StreetType: "улица", House: "7", Building: "2", BuildingType: "корпус"}},
+		{"Москва Тверская", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Тверская",
+			StreetType: "улица"}},
+		{"Москва Пресненская набережная 12", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Пресненская",
+			StreetType: "набережная", House: "12"}},
+		{"Санкт-Петербург Невский пр 28", models.ParsedAddress{
+			Region: "Санкт-Петербург", RegionType: "город", City: "Санкт-Петербург", CityType: "город",
+			Street: "Невский", StreetType: "проспект", House: "28"}},
+		{"Санкт-Петербург Невский 28", models.ParsedAddress{
+			Region: "Санкт-Петербург", RegionType: "город", City: "Санкт-Петербург", CityType: "город",
+			Street: "Невский", StreetType: "улица", House: "28"}},
+		{"Нижний Новгород Большая Покровская 1", models.ParsedAddress{
+			City: "Нижний Новгород", CityType: "город", Street: "Большая Покровская", StreetType: "улица",
+			House: "1"}},
+		{"Ростов-на-Дону Большая Садовая ул 47", models.ParsedAddress{
+			City: "Ростов-на-Дону", CityType: "город", Street: "Большая Садовая", StreetType: "улица", House: "47"}},
+		{"Казань Баумана 1", models.ParsedAddress{
+			City: "Казань", CityType: "город", Street: "Баумана", StreetType: "улица", House: "1"}},
+		{"Казань Кремлевская 18 к 1", models.ParsedAddress{
+			City: "Казань", CityType: "город", Street: "Кремлевская", StreetType: "улица", House: "18",
+			Building: "1", BuildingType: "корпус"}},
+		{"Екатеринбург Малышева 51 оф 1401", models.ParsedAddress{
+			City: "Екатеринбург", CityType: "город", Street: "Малышева", StreetType: "улица", House: "51",
+			Flat: "1401", FlatType: "офис"}},
+		{"Воронеж Плехановская 53", models.ParsedAddress{
+			City: "Воронеж", CityType: "город", Street: "Плехановская", StreetType: "улица", House: "53"}},
+		{"Пермь Ленина 50", models.ParsedAddress{
+			City: "Пермь", CityType: "город", Street: "Ленина", StreetType: "улица", House: "50"}},
+		{"Самара ул Молодогвардейская 194", models.ParsedAddress{
+			City: "Самара", CityType: "город", Street: "Молодогвардейская", StreetType: "улица", House: "194"}},
+		{"Химки Ленинградское ш 1", models.ParsedAddress{
+			City: "Химки", CityType: "город", Street: "Ленинградское", StreetType: "шоссе", House: "1"}},
+		{"Тверь", models.ParsedAddress{
+			City: "Тверь", CityType: "город"}},
+
+		// корпуса, строения, помещения
+		{"Москва, д 7 с 1", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", House: "7", Building: "1",
+			BuildingType: "строение"}},
+		{"г Москва, ул Ильинка, д 4 с 1", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Ильинка",
+			StreetType: "улица", House: "4", Building: "1", BuildingType: "строение"}},
+		{"г Москва, Кутузовский пр-кт, д 32 к 1", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Кутузовский",
+			StreetType: "проспект", House: "32", Building: "1", BuildingType: "корпус"}},
+		{"г Москва, ул Новый Арбат, д 21 стр 1, оф 5", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Новый Арбат",
+			StreetType: "улица", House: "21", Building: "1", BuildingType: "строение", Flat: "5",
+			FlatType: "офис"}},
+		{"Москва, Ленинградский пр-т 39 стр 80", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Ленинградский",
+			StreetType: "проспект", House: "39", Building: "80", BuildingType: "строение"}},
+		{"г Москва, Варшавское ш, д 26 стр 9, помещ 1", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Варшавское",
+			StreetType: "шоссе", House: "26", Building: "9", BuildingType: "строение", Flat: "1",
+			FlatType: "помещение"}},
+		{"г. Санкт-Петербург, пр. Просвещения, д. 87, корп. 1, кв. 245", models.ParsedAddress{
+			Region: "Санкт-Петербург", RegionType: "город", City: "Санкт-Петербург", CityType: "город",
+			Street: "Просвещения", StreetType: "проспект", House: "87", Building: "1", BuildingType: "корпус",
+			Flat: "245", FlatType: "квартира"}},
+		{"Свердловская область, город Екатеринбург, улица Ленина, дом 24/8, квартира 5", models.ParsedAddress{
+			Region: "Свердловская", RegionType: "область", City: "Екатеринбург", CityType: "город",
+			Street: "Ленина", StreetType: "улица", House: "24/8", Flat: "5", FlatType: "квартира"}},
+		{"Новосибирск, ул. Ленина, 21/1", models.ParsedAddress{
+			City: "Новосибирск", CityType: "город", Street: "Ленина", StreetType: "улица", House: "21/1"}},
+		{"Москва, Ленинский проспект, 32А", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Ленинский",
+			StreetType: "проспект", House: "32А"}},
+		{"ул. Ленина 5 кв. 3", models.ParsedAddress{
+			Street: "Ленина", StreetType: "улица", House: "5", Flat: "3", FlatType: "квартира"}},
+
+		// реальные запросы с запятыми
+		{"101000, Москва, Мясницкая ул, 10", models.ParsedAddress{
+			PostalCode: "101000", Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Мясницкая", StreetType: "улица", House: "10"}},
+		{"620014, Екатеринбург, ул. 8 Марта, 12", models.ParsedAddress{
+			PostalCode: "620014", City: "Екатеринбург", CityType: "город", Street: "8 Марта",
+			StreetType: "улица", House: "12"}},
+		{"Россия, Москва, Тверская, 7", models.ParsedAddress{
+			Country: "Россия", Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7"}},
+		{"РФ, г Казань, ул Пушкина 12, кв 45", models.ParsedAddress{
+			Country: "Россия", City: "Казань", CityType: "город", Street: "Пушкина", StreetType: "улица",
+			House: "12", Flat: "45", FlatType: "квартира"}},
+		{"Москва, ул. Льва Толстого, 16", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Льва Толстого",
+			StreetType: "улица", House: "16"}},
+		{"Москва, Пресненская наб., 12", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Пресненская",
+			StreetType: "набережная", House: "12"}},
+		{"Москва, Красная пл 1", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Красная",
+			StreetType: "площадь", House: "1"}},
+		{"Москва, Проспект Мира, 1", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "Мира",
+			StreetType: "проспект", House: "1"}},
+		{"Москва, 2-я Брестская ул., 8", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "2-я Брестская",
+			StreetType: "улица", House: "8"}},
+		{"Москва, ул 2-я Брестская, д 8", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город", Street: "2-я Брестская",
+			StreetType: "улица", House: "8"}},
+		{"Санкт-Петербург, 7-я линия, 34", models.ParsedAddress{
+			Region: "Санкт-Петербург", RegionType: "город", City: "Санкт-Петербург", CityType: "город",
+			Street: "7-я", StreetType: "линия", House: "34"}},
+		{"Екатеринбург, Ленина 5", models.ParsedAddress{
+			City: "Екатеринбург", CityType: "город", Street: "Ленина", StreetType: "улица", House: "5"}},
+		{"Ростов-на-Дону, Большая Садовая 47", models.ParsedAddress{
+			City: "Ростов-на-Дону", CityType: "город", Street: "Большая Садовая", StreetType: "улица", House: "47"}},
+		{"Казань, ул. Петербургская, д. 1, оф. 301", models.ParsedAddress{
+			City: "Казань", CityType: "город", Street: "Петербургская", StreetType: "улица", House: "1",
+			Flat: "301", FlatType: "офис"}},
+		{"Краснодар, ул им. Максима Горького, 104", models.ParsedAddress{
+			City: "Краснодар", CityType: "город", Street: "им Максима Горького", StreetType: "улица", House: "104"}},
+		{"Волгоград, пр-т им. Ленина, 15", models.ParsedAddress{
+			City: "Волгоград", CityType: "город", Street: "им Ленина", StreetType: "проспект", House: "15"}},
+		{"Калининград, Ленинский пр-кт 1", models.ParsedAddress{
+			City: "Калининград", CityType: "город", Street: "Ленинский", StreetType: "проспект", House: "1"}},
+		{"Сочи, Курортный проспект 50", models.ParsedAddress{
+			City: "Сочи", CityType: "город", Street: "Курортный", StreetType: "проспект", House: "50"}},
+		{"г Уфа, Проспект Октября, 10", models.ParsedAddress{
+			City: "Уфа", CityType: "город", Street: "Октября", StreetType: "проспект", House: "10"}},
+		{"г Новосибирск, Красный пр-кт, д 1", models.ParsedAddress{
+			City: "Новосибирск", CityType: "город", Street: "Красный", StreetType: "проспект", House: "1"}},
+		{"Тюмень, Мельникайте 99, кв 12", models.ParsedAddress{
+			City: "Тюмень", CityType: "город", Street: "Мельникайте", StreetType: "улица", House: "99",
+			Flat: "12", FlatType: "квартира"}},
+
+		// регионы, районы и поселения
+		{"Республика Башкортостан, г. Уфа, ул. Ленина, д. 2", models.ParsedAddress{
+			Region: "Башкортостан", RegionType: "республика", City: "Уфа", CityType: "город", Street: "Ленина",
+			StreetType: "улица", House: "2"}},
+		{"обл Московская, г Подольск, ул Кирова, д 1", models.ParsedAddress{
+			Region: "Московская", RegionType: "область", City: "Подольск", CityType: "город", Street: "Кирова",
+			StreetType: "улица", House: "1"}},
+		{"Московская область, Мытищи, Олимпийский пр-т, 29", models.ParsedAddress{
+			Region: "Московская", RegionType: "область", City: "Мытищи", CityType: "город",
+			Street: "Олимпийский", StreetType: "проспект", House: "29"}},
+		{"Новосибирская обл, р-н Новосибирский, с Криводановка, ул Садовая 17", models.ParsedAddress{
+			Region: "Новосибирская", RegionType: "область", Area: "Новосибирский", AreaType: "район",
+			City: "Криводановка", CityType: "село", Street: "Садовая", StreetType: "улица", House: "17"}},
+		{"с Криводановка, Садовая 17", models.ParsedAddress{
+			City: "Криводановка", CityType: "село", Street: "Садовая", StreetType: "улица", House: "17"}},
+		{"Ленинградская обл, Всеволожский р-н, г Мурино, б-р Менделеева, д 9 к 1", models.ParsedAddress{
+			Region: "Ленинградская", RegionType: "область", Area: "Всеволожский", AreaType: "район",
+			City: "Мурино", CityType: "город", Street: "Менделеева", StreetType: "бульвар", House: "9",
+			Building: "1", BuildingType: "корпус"}},
+		{"Московская обл, г.о. Красногорск, д Путилково, ул Сходненская 19", models.ParsedAddress{
+			Region: "Московская", RegionType: "область", Area: "Красногорск", AreaType: "городской округ",
+			City: "Путилково", CityType: "деревня", Street: "Сходненская", StreetType: "улица", House: "19"}},
+		{"Тульская обл, п Ленинский, ул Ленина 1", models.ParsedAddress{
+			Region: "Тульская", RegionType: "область", City: "Ленинский", CityType: "поселок", Street: "Ленина",
+			StreetType: "улица", House: "1"}},
+		{"Москва, пос. Внуковское, ул. Бориса Пастернака, д. 30", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", Area: "Внуковское", AreaType: "поселок", City: "Москва",
+			CityType: "город", Street: "Бориса Пастернака", StreetType: "улица", House: "30"}},
+
+		// лишние и нераспознанные части
+		{"г Москва, ул Тверская, д 7, кв 12, домофон 12К", models.ParsedAddress{
+			Region: "Москва", RegionType: "город", City: "Москва", CityType: "город",
+			Street: "Тверская", StreetType: "улица", House: "7", Flat: "12", FlatType: "квартира", Unparsed: "домофон 12К"}},
+		{"ул Тверская, ул Ленина", models.ParsedAddress{Street: "Тверская", StreetType: "улица", Unparsed: "Ленина"}},
+		{"ул", models.ParsedAddress{Unparsed: "улица"}},
+		{"", models.ParsedAddress{}},
+		{"  ,  , ", models.ParsedAddress{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got := Parse(tc.input)
+			if !reflect.DeepEqual(*got, tc.expected) {
+				t.Errorf("Parse(%q)\n got: %+v\nwant: %+v", tc.input, *got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package projection
+
+import "math"
+
+// Helmert — параметры семипараметрического преобразования Гельмерта
+// в соглашении coordinate frame rotation (EPSG:1032): сдвиги в метрах,
+// повороты в угловых секундах, масштаб в миллионных долях.
+type Helmert struct {
+	TX, TY, TZ float64
+	RX, RY, RZ float64
+	S          float64
+}
+
+// apply преобразует геоцентрические координаты. При inverse применяются
+// параметры с обратным знаком: для поворотов в доли секунды ошибка такого
+// обращения — доли миллиметра.
+func (h *Helmert) apply(x, y, z float64, inverse bool) (float64, float64, float64) {
+	sign := 1.0
+	if inverse {
+		sign = -1
+	}
+	const arcsec = math.Pi / (180 * 3600)
+	tx, ty, tz := sign*h.TX, sign*h.TY, sign*h.TZ
+	rx, ry, rz := sign*h.RX*arcsec, sign*h.RY*arcsec, sign*h.RZ*arcsec
+	m := 1 + sign*h.S*1e-6
+
+	return tx + m*(x+rz*y-ry*z),
+		ty + m*(-rz*x+y+rx*z),
+		tz + m*(ry*x-rx*y+z)
+}
+
+// toWGS84 переводит долготу и широту датума в WGS 84. Высота над
+// эллипсоидом принимается нулевой.
+func (d Datum) toWGS84(lon, lat float64) (float64, float64) {
+	if d.ToWGS84 == nil {
+		return lon, lat
+	}
+	x, y, z := toGeocentric(d.Ellipsoid, lon, lat)
+	x, y, z = d.ToWGS84.apply(x, y, z, false)
+	return fromGeocentric(WGS84Ellipsoid, x, y, z)
+}
+
+// fromWGS84 — обратное к toWGS84 преобразование.
+func (d Datum) fromWGS84(lon, lat float64) (float64, float64) {
+	if d.ToWGS84 == nil {
+		return lon, lat
+	}
+	x, y, z := toGeocentric(WGS84Ellipsoid, lon, lat)
+	x, y, z = d.ToWGS84.apply(x, y, z, true)
+	return fromGeocentric(d.Ellipsoid, x, y, z)
+}
+
+// toGeocentric переводит долготу и широту точки на поверхности эллипсоида
+// в геоцентрические координаты X, Y, Z.
+func toGeocentric(e Ellipsoid, lon, lat float64) (float64, float64, float64) {
+	phi, lambda := radians(lat), radians(lon)
+	sinPhi, cosPhi := math.Sincos(phi)
+	n := e.A / math.Sqrt(1-e.e2()*sinPhi*sinPhi)
+	return n * cosPhi * math.Cos(lambda),
+		n * cosPhi * math.Sin(lambda),
+		n * (1 - e.e2()) * sinPhi
+}
+
+// fromGeocentric — обратное к toGeocentric преобразование итерациями
+// по широте; высота отбрасывается.
+func fromGeocentric(e Ellipsoid, x, y, z float64) (float64, float64) {
+	p := math.Hypot(x, y)
+	phi := math.Atan2(z, p*(1-e.e2()))
+	for i := 0; i < 10; i++ {
+		sinPhi := math.Sin(phi)
+		n := e.A / math.Sqrt(1-e.e2()*sinPhi*sinPhi)
+		next := math.Atan2(z+e.e2()*n*sinPhi, p)
+		if math.Abs(next-phi) < 1e-14 {
+			phi = next
+			break
+		}
+		phi = next
+	}
+	return degrees(math.Atan2(y, x)), degrees(phi)
+}
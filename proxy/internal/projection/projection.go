@@ -0,0 +1,144 @@
+package projection
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/geo"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Коды поддерживаемых систем координат. Зоны UTM и Гаусса — Крюгера
+// задаются диапазонами кодов, см. Lookup.
+const (
+	WGS84Code       = "EPSG:4326"
+	WebMercatorCode = "EPSG:3857"
+	Pulkovo42Code   = "EPSG:4284"
+)
+
+var (
+	// ErrUnknownCRS возвращается для неподдерживаемого кода системы координат.
+	ErrUnknownCRS = errors.New("unknown coordinate reference system")
+	// ErrOutOfRange возвращается для координат вне области определения системы.
+	ErrOutOfRange = errors.New("coordinates out of range")
+)
+
+// Ellipsoid — параметры эллипсоида: большая полуось в метрах и сжатие.
+type Ellipsoid struct {
+	A, F float64
+}
+
+var (
+	WGS84Ellipsoid      = Ellipsoid{A: geo.WGS84A, F: geo.WGS84F}
+	KrassowskyEllipsoid = Ellipsoid{A: 6378245, F: 1 / 298.3}
+)
+
+// e2 — квадрат первого эксцентриситета.
+func (e Ellipsoid) e2() float64 {
+	return e.F * (2 - e.F)
+}
+
+// Datum — эллипсоид и параметры перехода к WGS 84; nil для самого WGS 84.
+type Datum struct {
+	Ellipsoid Ellipsoid
+	ToWGS84   *Helmert
+}
+
+var (
+	WGS84Datum = Datum{Ellipsoid: WGS84Ellipsoid}
+	// Pulkovo42Datum — СК-42 с параметрами ГОСТ Р 51794-2008 (EPSG:5044).
+	Pulkovo42Datum = Datum{Ellipsoid: KrassowskyEllipsoid, ToWGS84: &Helmert{
+		TX: 23.57, TY: -140.95, TZ: -79.8, RX: 0, RY: -0.35, RZ: -0.79, S: -0.22,
+	}}
+)
+
+// CRS — система координат. Координаты x, y передаются в порядке GeoJSON:
+// для географических систем это долгота и широта в градусах, для проекций —
+// восточное и северное смещения в метрах.
+type CRS interface {
+	// ToWGS84 переводит координаты системы в долготу и широту WGS 84.
+	ToWGS84(x, y float64) (lon, lat float64, err error)
+	// FromWGS84 переводит долготу и широту WGS 84 в координаты системы.
+	FromWGS84(lon, lat float64) (x, y float64, err error)
+}
+
+// Lookup возвращает систему координат по коду EPSG:
+//   - EPSG:4326 — WGS 84, долгота и широта;
+//   - EPSG:3857 — Web Mercator;
+//   - EPSG:32601–32660, EPSG:32701–32760 — зоны UTM северного и южного полушарий;
+//   - EPSG:4284 — Пулково 1942 (СК-42), долгота и широта;
+//   - EPSG:28402–28432 — СК-42, зоны Гаусса — Крюгера 2–32.
+//
+// Регистр и пробелы вокруг кода не важны.
+func Lookup(code string) (CRS, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	if !strings.HasPrefix(normalized, "EPSG:") {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCRS, code)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(normalized, "EPSG:"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCRS, code)
+	}
+
+	switch {
+	case n == 4326:
+		return geographic{WGS84Datum}, nil
+	case n == 3857:
+		return webMercator{}, nil
+	case n == 4284:
+		return geographic{Pulkovo42Datum}, nil
+	case n >= 32601 && n <= 32660:
+		return UTM(n-32600, true), nil
+	case n >= 32701 && n <= 32760:
+		return UTM(n-32700, false), nil
+	case n >= 28402 && n <= 28432:
+		return GaussKruger(n - 28400), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownCRS, code)
+}
+
+// Transform переводит координаты из одной системы в другую через WGS 84.
+func Transform(from, to CRS, x, y float64) (float64, float64, error) {
+	lon, lat, err := from.ToWGS84(x, y)
+	if err != nil {
+		return 0, 0, err
+	}
+	return to.FromWGS84(lon, lat)
+}
+
+// geographic — долгота и широта на эллипсоиде датума.
+type geographic struct {
+	datum Datum
+}
+
+func (g geographic) ToWGS84(lon, lat float64) (float64, float64, error) {
+	if err := checkGeographic(lon, lat); err != nil {
+		return 0, 0, err
+	}
+	lon, lat = g.datum.toWGS84(lon, lat)
+	return lon, lat, nil
+}
+
+func (g geographic) FromWGS84(lon, lat float64) (float64, float64, error) {
+	if err := checkGeographic(lon, lat); err != nil {
+		return 0, 0, err
+	}
+	lon, lat = g.datum.fromWGS84(lon, lat)
+	return lon, lat, nil
+}
+
+func checkGeographic(lon, lat float64) error {
+	if lon < -180 || lon > 180 || lat < -90 || lat > 90 || math.IsNaN(lon) || math.IsNaN(lat) {
+		return fmt.Errorf("%w: %g, %g", ErrOutOfRange, lon, lat)
+	}
+	return nil
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
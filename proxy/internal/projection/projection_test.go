@@ -0,0 +1,107 @@
+package projection
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/geo"
+	"math"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	valid := []string{"EPSG:4326", "epsg:3857", " EPSG:4284 ", "EPSG:32637", "EPSG:32760", "EPSG:28407", "EPSG:28432"}
+	for _, code := range valid {
+		if _, err := Lookup(code); err != nil {
+			t.Errorf("%s: unexpected error %v", code, err)
+		}
+	}
+
+	invalid := []string{"", "4326", "EPSG:", "EPSG:abc", "EPSG:32600", "EPSG:32661", "EPSG:28401", "EPSG:2154"}
+	for _, code := range invalid {
+		if _, err := Lookup(code); !errors.Is(err, ErrUnknownCRS) {
+			t.Errorf("%q: expected ErrUnknownCRS, got %v", code, err)
+		}
+	}
+}
+
+func TestWebMercator(t *testing.T) {
+	crs, _ := Lookup(WebMercatorCode)
+
+	testCases := []struct {
+		name     string
+		lon, lat float64
+		x, y     float64
+	}{
+		{"origin", 0, 0, 0, 0},
+		{"world corner", 180, webMercatorMaxLat, 20037508.342789244, 20037508.342789244},
+		{"Moscow", 37.6176, 55.7558, 4187572.08, 7509955.14},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			x, y, err := crs.FromWGS84(tc.lon, tc.lat)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(x-tc.x) > 0.01 || math.Abs(y-tc.y) > 0.01 {
+				t.Errorf("expected %.2f, %.2f, got %.2f, %.2f", tc.x, tc.y, x, y)
+			}
+			lon, lat, err := crs.ToWGS84(x, y)
+			if err != nil || math.Abs(lon-tc.lon) > 1e-9 || math.Abs(lat-tc.lat) > 1e-9 {
+				t.Errorf("round trip: expected %g, %g, got %g, %g (%v)", tc.lon, tc.lat, lon, lat, err)
+			}
+		})
+	}
+
+	if _, _, err := crs.FromWGS84(0, 89); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("expected ErrOutOfRange near the pole, got %v", err)
+	}
+	if _, _, err := crs.ToWGS84(3e7, 0); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("expected ErrOutOfRange outside the world, got %v", err)
+	}
+}
+
+func TestPulkovo42(t *testing.T) {
+	crs, _ := Lookup(Pulkovo42Code)
+
+	// в европейской части России СК-42 расходится с WGS 84 на 100–150 м,
+	// в основном по долготе
+	lon, lat, err := crs.FromWGS84(37.6176, 55.7558)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shift := geo.Haversine(lat, lon, 55.7558, 37.6176); shift < 100 || shift > 150 {
+		t.Errorf("expected datum shift of 100-150 m, got %.1f m", shift)
+	}
+	if lon <= 37.6176 {
+		t.Errorf("expected SK-42 longitude east of WGS 84, got %.7f", lon)
+	}
+
+	backLon, backLat, err := crs.ToWGS84(lon, lat)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := geo.Haversine(backLat, backLon, 55.7558, 37.6176); d > 0.001 {
+		t.Errorf("round trip error %.4f m", d)
+	}
+
+	if _, _, err := crs.ToWGS84(200, 0); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("expected ErrOutOfRange, got %v", err)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	utm, _ := Lookup("EPSG:32637")
+	mercator, _ := Lookup(WebMercatorCode)
+
+	x, y, err := Transform(utm, mercator, 413000, 6180000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x, y, err = Transform(mercator, utm, x, y)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(x-413000) > 1e-3 || math.Abs(y-6180000) > 1e-3 {
+		t.Errorf("round trip: expected 413000, 6180000, got %.4f, %.4f", x, y)
+	}
+}
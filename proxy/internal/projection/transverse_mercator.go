@@ -0,0 +1,143 @@
+package projection
+
+import (
+	"fmt"
+	"math"
+)
+
+// TransverseMercator — поперечная проекция Меркатора на эллипсоиде датума.
+// Расчет ведется по рядам Крюгера до n⁶ (Karney, "Transverse Mercator with
+// an accuracy of a few nanometers", 2011): в пределах зоны ошибка меньше
+// миллиметра.
+type TransverseMercator struct {
+	Datum           Datum
+	CentralMeridian float64 // градусы
+	Scale           float64 // масштаб на осевом меридиане
+	FalseEasting    float64 // метры
+	FalseNorthing   float64 // метры
+
+	radius      float64 // A — радиус спрямляющей сферы
+	alpha, beta [6]float64
+	e           float64
+}
+
+// maxZoneOffset — наибольшее удаление от осевого меридиана в градусах.
+// Ряды Крюгера теряют точность далеко за пределами зоны.
+const maxZoneOffset = 30
+
+// UTM возвращает зону UTM на WGS 84.
+func UTM(zone int, north bool) *TransverseMercator {
+	falseNorthing := 0.0
+	if !north {
+		falseNorthing = 10000000
+	}
+	return NewTransverseMercator(WGS84Datum, float64(6*zone-183), 0.9996, 500000, falseNorthing)
+}
+
+// GaussKruger возвращает шестиградусную зону Гаусса — Крюгера СК-42.
+// Номер зоны входит в восточное смещение: x = zone·10⁶ + 500000.
+func GaussKruger(zone int) *TransverseMercator {
+	return NewTransverseMercator(Pulkovo42Datum, float64(6*zone-3), 1, float64(zone)*1e6+500000, 0)
+}
+
+func NewTransverseMercator(datum Datum, centralMeridian, scale, falseEasting, falseNorthing float64) *TransverseMercator {
+	tm := &TransverseMercator{
+		Datum:           datum,
+		CentralMeridian: centralMeridian,
+		Scale:           scale,
+		FalseEasting:    falseEasting,
+		FalseNorthing:   falseNorthing,
+		e:               math.Sqrt(datum.Ellipsoid.e2()),
+	}
+
+	f := datum.Ellipsoid.F
+	n := f / (2 - f)
+	n2, n3, n4, n5, n6 := n*n, n*n*n, n*n*n*n, n*n*n*n*n, n*n*n*n*n*n
+	tm.radius = datum.Ellipsoid.A / (1 + n) * (1 + n2/4 + n4/64 + n6/256)
+	tm.alpha = [6]float64{
+		n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180 - 127*n5/288 + 7891*n6/37800,
+		13*n2/48 - 3*n3/5 + 557*n4/1440 + 281*n5/630 - 1983433*n6/1935360,
+		61*n3/240 - 103*n4/140 + 15061*n5/26880 + 167603*n6/181440,
+		49561*n4/161280 - 179*n5/168 + 6601661*n6/7257600,
+		34729*n5/80640 - 3418889*n6/1995840,
+		212378941 * n6 / 319334400,
+	}
+	tm.beta = [6]float64{
+		n/2 - 2*n2/3 + 37*n3/96 - n4/360 - 81*n5/512 + 96199*n6/604800,
+		n2/48 + n3/15 - 437*n4/1440 + 46*n5/105 - 1118711*n6/3870720,
+		17*n3/480 - 37*n4/840 - 209*n5/4480 + 5569*n6/90720,
+		4397*n4/161280 - 11*n5/504 - 830251*n6/7257600,
+		4583*n5/161280 - 108847*n6/3991680,
+		20648693 * n6 / 638668800,
+	}
+	return tm
+}
+
+// FromWGS84 переводит долготу и широту WGS 84 в прямоугольные координаты.
+func (tm *TransverseMercator) FromWGS84(lon, lat float64) (float64, float64, error) {
+	if err := checkGeographic(lon, lat); err != nil {
+		return 0, 0, err
+	}
+	lon, lat = tm.Datum.fromWGS84(lon, lat)
+	offset := math.Mod(lon-tm.CentralMeridian+540, 360) - 180
+	if math.Abs(offset) > maxZoneOffset {
+		return 0, 0, fmt.Errorf("%w: longitude %g is too far from central meridian %g", ErrOutOfRange, lon, tm.CentralMeridian)
+	}
+
+	phi, lambda := radians(lat), radians(offset)
+	tau := math.Tan(phi)
+	sigma := math.Sinh(tm.e * math.Atanh(tm.e*tau/math.Sqrt(1+tau*tau)))
+	tauPrime := tau*math.Sqrt(1+sigma*sigma) - sigma*math.Sqrt(1+tau*tau)
+
+	xiPrime := math.Atan2(tauPrime, math.Cos(lambda))
+	etaPrime := math.Asinh(math.Sin(lambda) / math.Sqrt(tauPrime*tauPrime+math.Cos(lambda)*math.Cos(lambda)))
+
+	xi, eta := xiPrime, etaPrime
+	for j, a := range tm.alpha {
+		k := 2 * float64(j+1)
+		xi += a * math.Sin(k*xiPrime) * math.Cosh(k*etaPrime)
+		eta += a * math.Cos(k*xiPrime) * math.Sinh(k*etaPrime)
+	}
+
+	x := tm.Scale*tm.radius*eta + tm.FalseEasting
+	y := tm.Scale*tm.radius*xi + tm.FalseNorthing
+	return x, y, nil
+}
+
+// ToWGS84 переводит прямоугольные координаты в долготу и широту WGS 84.
+func (tm *TransverseMercator) ToWGS84(x, y float64) (float64, float64, error) {
+	eta := (x - tm.FalseEasting) / (tm.Scale * tm.radius)
+	xi := (y - tm.FalseNorthing) / (tm.Scale * tm.radius)
+	if math.IsNaN(eta) || math.IsNaN(xi) || math.Abs(xi) > math.Pi/2 || math.Abs(eta) > 1 {
+		return 0, 0, fmt.Errorf("%w: %g, %g", ErrOutOfRange, x, y)
+	}
+
+	xiPrime, etaPrime := xi, eta
+	for j, b := range tm.beta {
+		k := 2 * float64(j+1)
+		xiPrime -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaPrime -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+
+	sinhEta := math.Sinh(etaPrime)
+	sinXi, cosXi := math.Sincos(xiPrime)
+	tauPrime := sinXi / math.Sqrt(sinhEta*sinhEta+cosXi*cosXi)
+
+	// широта по приведенной — итерации Ньютона
+	e2 := tm.e * tm.e
+	tau := tauPrime
+	for i := 0; i < 10; i++ {
+		sigma := math.Sinh(tm.e * math.Atanh(tm.e*tau/math.Sqrt(1+tau*tau)))
+		t := tau*math.Sqrt(1+sigma*sigma) - sigma*math.Sqrt(1+tau*tau)
+		delta := (tauPrime - t) / math.Sqrt(1+t*t) * (1 + (1-e2)*tau*tau) / ((1 - e2) * math.Sqrt(1+tau*tau))
+		tau += delta
+		if math.Abs(delta) < 1e-12 {
+			break
+		}
+	}
+
+	lat := degrees(math.Atan(tau))
+	lon := math.Mod(tm.CentralMeridian+degrees(math.Atan2(sinhEta, cosXi))+540, 360) - 180
+	lon, lat = tm.Datum.toWGS84(lon, lat)
+	return lon, lat, nil
+}
@@ -0,0 +1,85 @@
+package projection
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/geo"
+	"math"
+	"testing"
+)
+
+func TestUTM(t *testing.T) {
+	testCases := []struct {
+		name     string
+		zone     int
+		north    bool
+		lon, lat float64
+		x, y     float64
+	}{
+		// Эйфелева башня, пример из документации movable-type.co.uk
+		{"Eiffel Tower", 31, true, 2.2945, 48.8582, 448251.795, 5411932.678},
+		{"central meridian on equator", 37, true, 39, 0, 500000, 0},
+		{"southern hemisphere on equator", 37, false, 39, 0, 500000, 10000000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			crs := UTM(tc.zone, tc.north)
+			x, y, err := crs.FromWGS84(tc.lon, tc.lat)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(x-tc.x) > 0.001 || math.Abs(y-tc.y) > 0.001 {
+				t.Errorf("expected %.3f, %.3f, got %.3f, %.3f", tc.x, tc.y, x, y)
+			}
+			lon, lat, err := crs.ToWGS84(x, y)
+			if err != nil || math.Abs(lon-tc.lon) > 1e-9 || math.Abs(lat-tc.lat) > 1e-9 {
+				t.Errorf("round trip: expected %g, %g, got %g, %g (%v)", tc.lon, tc.lat, lon, lat, err)
+			}
+		})
+	}
+}
+
+func TestUTM_RoundTrip(t *testing.T) {
+	crs := UTM(37, true)
+	for _, p := range [][2]float64{{36, 0}, {33.5, 45}, {42, 70}, {39, 84}, {30, 60}} {
+		x, y, err := crs.FromWGS84(p[0], p[1])
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", p, err)
+		}
+		lon, lat, err := crs.ToWGS84(x, y)
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", p, err)
+		}
+		if d := geo.Haversine(lat, lon, p[1], p[0]); d > 1e-6 {
+			t.Errorf("%v: round trip error %.9f m", p, d)
+		}
+	}
+
+	if _, _, err := crs.FromWGS84(100, 0); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("expected ErrOutOfRange far from the zone, got %v", err)
+	}
+}
+
+func TestGaussKruger(t *testing.T) {
+	crs, err := Lookup("EPSG:28407")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, y, err := crs.FromWGS84(37.6176, 55.7558)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// номер зоны — старшие разряды восточного смещения
+	if int(x/1e6) != 7 || math.Abs(x-7413324.4) > 1 || math.Abs(y-6182340.7) > 1 {
+		t.Errorf("unexpected coordinates %.1f, %.1f", x, y)
+	}
+
+	lon, lat, err := crs.ToWGS84(x, y)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := geo.Haversine(lat, lon, 55.7558, 37.6176); d > 0.001 {
+		t.Errorf("round trip error %.4f m", d)
+	}
+}
@@ -0,0 +1,37 @@
+package projection
+
+import (
+	"fmt"
+	"math"
+)
+
+// webMercatorMaxLat — широта, на которой проекция становится квадратом
+// со стороной 2π·a; за ней тайловые карты не определены.
+const webMercatorMaxLat = 85.051128779806592
+
+// webMercator — сферическая проекция Меркатора с радиусом, равным большой
+// полуоси WGS 84, как в тайловых картах (EPSG:3857).
+type webMercator struct{}
+
+func (webMercator) FromWGS84(lon, lat float64) (float64, float64, error) {
+	if err := checkGeographic(lon, lat); err != nil {
+		return 0, 0, err
+	}
+	if math.Abs(lat) > webMercatorMaxLat {
+		return 0, 0, fmt.Errorf("%w: latitude %g is beyond ±%.4f", ErrOutOfRange, lat, webMercatorMaxLat)
+	}
+	x := WGS84Ellipsoid.A * radians(lon)
+	y := WGS84Ellipsoid.A * math.Log(math.Tan(math.Pi/4+radians(lat)/2))
+	return x, y, nil
+}
+
+func (webMercator) ToWGS84(x, y float64) (float64, float64, error) {
+	limit := math.Pi * WGS84Ellipsoid.A
+	// допуск на округление координат границы мира
+	if math.Abs(x) > limit+1e-3 || math.Abs(y) > limit+1e-3 || math.IsNaN(x) || math.IsNaN(y) {
+		return 0, 0, fmt.Errorf("%w: %g, %g", ErrOutOfRange, x, y)
+	}
+	lon := degrees(x / WGS84Ellipsoid.A)
+	lat := degrees(2*math.Atan(math.Exp(y/WGS84Ellipsoid.A)) - math.Pi/2)
+	return math.Max(-180, math.Min(180, lon)), lat, nil
+}
@@ -39,11 +39,21 @@ func (r *Responder) ErrorForbidden(w http.ResponseWriter, err error) {
 	r.sendError(w, http.StatusForbidden, err)
 }
 
+// ErrorNotFound отправляет ответ с ошибкой 404 Not Found
+func (r *Responder) ErrorNotFound(w http.ResponseWriter, err error) {
+	r.sendError(w, http.StatusNotFound, err)
+}
+
 // ErrorInternal отправляет ответ с ошибкой 500 Internal Server Error
 func (r *Responder) ErrorInternal(w http.ResponseWriter, err error) {
 	r.sendError(w, http.StatusInternalServerError, err)
 }
 
+// ErrorServiceUnavailable отправляет ответ с ошибкой 503 Service Unavailable
+func (r *Responder) ErrorServiceUnavailable(w http.ResponseWriter, err error) {
+	r.sendError(w, http.StatusServiceUnavailable, err)
+}
+
 // sendError общий метод для отправки ошибок
 func (r *Responder) sendError(w http.ResponseWriter, code int, err error) {
 	w.WriteHeader(code)
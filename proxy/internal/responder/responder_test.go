@@ -30,6 +30,12 @@ func TestResponder_ErrorResponses(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "test error",
 		},
+		{
+			name:           "Not Found Error",
+			errorFunc:      responder.ErrorNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "test error",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -106,6 +112,30 @@ func TestResponder_ErrorInternal(t *testing.T) {
 	}
 }
 
+func TestResponder_ErrorServiceUnavailable(t *testing.T) {
+	responder := NewResponder()
+	rr := httptest.NewRecorder()
+	testError := errors.New("data is not loaded")
+
+	responder.ErrorServiceUnavailable(rr, testError)
+
+	// Проверяем статус-код ответа
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusServiceUnavailable)
+	}
+
+	// Проверяем содержимое ответа
+	var response map[string]string
+	err := json.Unmarshal(rr.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errorMsg, exists := response["error"]; !exists || errorMsg != testError.Error() {
+		t.Errorf("expected error message '%s', got '%s'", testError.Error(), errorMsg)
+	}
+}
+
 func TestResponder_OutputJSON_Error(t *testing.T) {
 	responder := NewResponder()
 	rr := httptest.NewRecorder()
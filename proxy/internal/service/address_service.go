@@ -1,100 +1,508 @@
 package service
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/cache"
+	"geo-controller/proxy/internal/formatter"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/geocell"
+	"geo-controller/proxy/internal/location"
+	"geo-controller/proxy/internal/matcher"
 	"geo-controller/proxy/internal/models"
-	"net/http"
+	"geo-controller/proxy/internal/normalize"
+	"geo-controller/proxy/internal/parser"
+	"geo-controller/proxy/internal/speller"
+	"geo-controller/proxy/internal/timezone"
+	"geo-controller/proxy/internal/translit"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBiasRadius — масштаб в метрах, на котором затухает бонус за близость,
+// если точка смещения задана без области карты.
+const defaultBiasRadius = 50000
 
-	"github.com/ekomobile/dadata/v2"
-	"github.com/ekomobile/dadata/v2/api/suggest"
-	"github.com/ekomobile/dadata/v2/client"
+// Параметры кэша результатов провайдера по умолчанию.
+const (
+	defaultSearchCacheTTL  = 5 * time.Minute
+	defaultSearchCacheSize = 1000
 )
 
+// geocodeCachePrecision — длина геохеша в ключе кэша геокодирования:
+// точки в одной ячейке около 5×5 м получают один ответ.
+const geocodeCachePrecision = 9
+
+// maxDidYouMean — число вариантов исправления в ответе.
+const maxDidYouMean = 3
+
+// ErrAddressNotFound возвращается, если у адреса не нашлось координат.
+var ErrAddressNotFound = errors.New("address not found")
+
+// maxDedupeAddresses — наибольший размер списка для поиска дубликатов.
+const maxDedupeAddresses = 500
+
+// AddressProvider — источник адресных данных для AddressService.
+type AddressProvider interface {
+	SearchAddress(request models.SearchRequest) ([]*models.Address, error)
+	Geocode(request models.GeocodeRequest) (*models.GeocodeResponse, error)
+}
+
+// DictionarySource — провайдер, который может заранее наполнить словарь
+// исправлений известными ему названиями.
+type DictionarySource interface {
+	DictionaryNames() []string
+}
+
 type AddressService struct {
-	daDataApiKey    string
-	daDataSecretKey string
+	provider   AddressProvider
+	geoIP      *GeoIPService
+	normalizer *normalize.Normalizer
+	cache      *cache.Cache[[]models.Address]
+	geocache   *cache.Cache[[]models.Suggestion]
+	dictionary *speller.Dictionary
+	timezones  *timezone.Index
 }
 
-func NewAddressService(apiKey, secretKey string) *AddressService {
-	return &AddressService{
-		daDataApiKey:    apiKey,
-		daDataSecretKey: secretKey,
+// AddressServiceOption настраивает AddressService.
+type AddressServiceOption func(*AddressService)
+
+// WithProvider заменяет провайдер DaData другим источником адресов.
+func WithProvider(provider AddressProvider) AddressServiceOption {
+	return func(s *AddressService) {
+		s.provider = provider
 	}
 }
 
-func (s *AddressService) SearchAddress(query string) (*models.SearchResponse, error) {
-	creds := client.Credentials{
-		ApiKeyValue:    s.daDataApiKey,
-		SecretKeyValue: s.daDataSecretKey,
+// WithGeoIP позволяет смещать поиск по местоположению IP клиента.
+func WithGeoIP(geoIP *GeoIPService) AddressServiceOption {
+	return func(s *AddressService) {
+		s.geoIP = geoIP
 	}
+}
 
-	api := dadata.NewSuggestApi(client.WithCredentialProvider(&creds))
+// WithNormalizer задает нормализатор запросов, например с синонимами из конфигурации.
+func WithNormalizer(normalizer *normalize.Normalizer) AddressServiceOption {
+	return func(s *AddressService) {
+		s.normalizer = normalizer
+	}
+}
 
-	params := suggest.RequestParams{
-		Query: query,
+// WithSearchCache задает кэш результатов провайдера, nil отключает кэширование.
+func WithSearchCache(searchCache *cache.Cache[[]models.Address]) AddressServiceOption {
+	return func(s *AddressService) {
+		s.cache = searchCache
 	}
+}
+
+// WithGeocodeCache задает кэш ответов геокодирования, nil отключает кэширование.
+func WithGeocodeCache(geocodeCache *cache.Cache[[]models.Suggestion]) AddressServiceOption {
+	return func(s *AddressService) {
+		s.geocache = geocodeCache
+	}
+}
 
-	suggestions, err := api.Address(context.Background(), &params)
+// WithDictionary задает словарь для подсказок "возможно, вы имели в виду".
+func WithDictionary(dictionary *speller.Dictionary) AddressServiceOption {
+	return func(s *AddressService) {
+		s.dictionary = dictionary
+	}
+}
+
+// WithTimezones задает индекс границ часовых поясов.
+func WithTimezones(timezones *timezone.Index) AddressServiceOption {
+	return func(s *AddressService) {
+		s.timezones = timezones
+	}
+}
+
+func NewAddressService(apiKey, secretKey string, opts ...AddressServiceOption) *AddressService {
+	s := &AddressService{
+		provider:   NewDaDataProvider(apiKey, secretKey),
+		normalizer: normalize.New(nil),
+		cache:      cache.New[[]models.Address](defaultSearchCacheTTL, defaultSearchCacheSize),
+		geocache:   cache.New[[]models.Suggestion](defaultSearchCacheTTL, defaultSearchCacheSize),
+		dictionary: speller.NewDictionary(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if source, ok := s.provider.(DictionarySource); ok && s.dictionary != nil {
+		for _, name := range source.DictionaryNames() {
+			s.dictionary.Add(name)
+		}
+	}
+	return s
+}
+
+func (s *AddressService) SearchAddress(request models.SearchRequest) (*models.SearchResponse, error) {
+	if s.normalizer != nil {
+		request.Query = s.normalizer.Normalize(request.Query)
+	}
+
+	page, err := parseSearchPage(request)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := resolveLanguage(request.Language, request.Transliteration)
 	if err != nil {
 		return nil, err
 	}
+	if request.Timezone && s.timezones == nil {
+		return nil, ErrTimezonesUnavailable
+	}
+	original := request
 
-	searchResp := &models.SearchResponse{}
-	for _, s := range suggestions {
-		addr := models.Address{
-			Result:     s.Value,
-			PostalCode: s.Data.PostalCode,
-			Country:    s.Data.Country,
-			Region:     s.Data.Region,
-			Street:     s.Data.Street,
-			GeoLat:     s.Data.GeoLat,
-			GeoLon:     s.Data.GeoLon,
+	bias, radius := s.resolveBias(request)
+	if page.sort == models.SortDistance && bias == nil {
+		return nil, errors.New("sort by distance requires location, viewport or use_ip_location")
+	}
+	if err := page.bind(request, bias); err != nil {
+		return nil, err
+	}
+	request.Location = bias
+	request.Limit = maxSearchResults
+
+	addresses, err := s.searchProvider(request)
+	if err != nil {
+		return nil, err
+	}
+	if request.Language == models.LanguageEnglish {
+		for _, addr := range addresses {
+			transliterateAddress(addr, scheme)
 		}
-		searchResp.Addresses = append(searchResp.Addresses, &addr)
 	}
 
+	searchResp := &models.SearchResponse{Bias: bias, NormalizedQuery: request.Query}
+	if bias != nil {
+		rankByDistance(addresses, *bias, radius, request.Viewport)
+	}
+	page.apply(addresses, searchResp)
+	if request.Timezone {
+		s.addTimezones(searchResp.Addresses)
+	}
+
+	if len(searchResp.Addresses) == 0 && request.Cursor == "" {
+		return s.suggestCorrections(original, searchResp)
+	}
 	return searchResp, nil
 }
 
+// suggestCorrections добавляет к пустому ответу варианты исправления запроса
+// и, если разрешено, повторяет поиск с первым из них.
+func (s *AddressService) suggestCorrections(request models.SearchRequest, searchResp *models.SearchResponse) (*models.SearchResponse, error) {
+	if s.dictionary == nil {
+		return searchResp, nil
+	}
+	searchResp.DidYouMean = s.dictionary.Suggest(request.Query, maxDidYouMean)
+	if !request.AutoCorrect || len(searchResp.DidYouMean) == 0 {
+		return searchResp, nil
+	}
+
+	request.Query = searchResp.DidYouMean[0]
+	request.AutoCorrect = false
+	corrected, err := s.SearchAddress(request)
+	if err != nil || len(corrected.Addresses) == 0 {
+		return searchResp, nil
+	}
+	corrected.CorrectedQuery = request.Query
+	corrected.DidYouMean = searchResp.DidYouMean
+	return corrected, nil
+}
+
 func (s *AddressService) Geocode(request models.GeocodeRequest) (*models.GeocodeResponse, error) {
+	if request.Location != "" {
+		if request.Lat != "" || request.Lng != "" {
+			return nil, errors.New("location cannot be combined with latitude and longitude")
+		}
+		point, _, err := location.Parse(request.Location)
+		if err != nil {
+			return nil, err
+		}
+		request.Lat = strconv.FormatFloat(point.Lat, 'f', -1, 64)
+		request.Lng = strconv.FormatFloat(point.Lon, 'f', -1, 64)
+		request.Location = ""
+	}
 	if request.Lat == "" || request.Lng == "" {
 		return nil, errors.New("latitude and longitude cannot be empty")
 	}
 
-	requestData := map[string]string{
-		"lat": request.Lat,
-		"lon": request.Lng,
+	scheme, err := resolveLanguage(request.Language, request.Transliteration)
+	if err != nil {
+		return nil, err
 	}
 
-	requestBody, err := json.Marshal(requestData)
+	geocodeResp, err := s.geocodeProvider(request)
+	if err != nil || request.Language != models.LanguageEnglish {
+		return geocodeResp, err
+	}
+	for _, suggestion := range geocodeResp.Suggestions {
+		if translit.HasCyrillic(suggestion.Value) {
+			suggestion.Value = translit.Transliterate(suggestion.Value, scheme)
+		}
+	}
+	return geocodeResp, nil
+}
+
+// LocateAddress возвращает координаты первого найденного адреса с координатами.
+func (s *AddressService) LocateAddress(query string) (*models.GeoPoint, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("address cannot be empty")
+	}
+	if s.normalizer != nil {
+		query = s.normalizer.Normalize(query)
+	}
+
+	addresses, err := s.searchProvider(models.SearchRequest{Query: query, Limit: maxSearchResults})
 	if err != nil {
 		return nil, err
 	}
+	for _, addr := range addresses {
+		if lat, lon, ok := addressPoint(addr); ok {
+			return &models.GeoPoint{Lat: lat, Lon: lon}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAddressNotFound, query)
+}
+
+// locateWaypoint возвращает координаты точки, геокодируя адрес, если
+// координаты не заданы.
+func (s *AddressService) locateWaypoint(waypoint models.Waypoint) (*models.GeoPoint, error) {
+	if waypoint.Point != nil {
+		if err := validatePoint(*waypoint.Point); err != nil {
+			return nil, err
+		}
+		return waypoint.Point, nil
+	}
+	if waypoint.Address == "" {
+		return nil, errors.New("waypoint must have point or address")
+	}
+	return s.LocateAddress(waypoint.Address)
+}
+
+// ParseAddress разбирает адрес на компоненты локально, без обращения
+// к провайдеру.
+func (s *AddressService) ParseAddress(request models.ParseRequest) (*models.ParsedAddress, error) {
+	if strings.TrimSpace(request.Query) == "" {
+		return nil, errors.New("query cannot be empty")
+	}
+
+	return parser.Parse(request.Query), nil
+}
+
+// CompareAddresses сообщает, обозначают ли два адреса одно место.
+func (s *AddressService) CompareAddresses(request models.CompareRequest) *models.CompareResponse {
+	resp := matcher.Compare(&request.First, &request.Second)
+	return &resp
+}
+
+// DedupeAddresses группирует дубликаты в списке адресов. Сравнение попарное,
+// поэтому размер списка ограничен maxDedupeAddresses.
+func (s *AddressService) DedupeAddresses(request models.DedupeRequest) (*models.DedupeResponse, error) {
+	if len(request.Addresses) == 0 {
+		return nil, errors.New("addresses cannot be empty")
+	}
+	if len(request.Addresses) > maxDedupeAddresses {
+		return nil, fmt.Errorf("too many addresses: %d, at most %d", len(request.Addresses), maxDedupeAddresses)
+	}
 
-	url := "http://suggestions.dadata.ru/suggestions/api/4_1/rs/geolocate/address"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	addresses := make([]*models.Address, len(request.Addresses))
+	for i := range request.Addresses {
+		addresses[i] = &request.Addresses[i]
+	}
+	resp := matcher.Dedupe(addresses)
+	return &resp, nil
+}
+
+// FormatAddress форматирует адрес в заданном стиле.
+func (s *AddressService) FormatAddress(request models.FormatRequest) (*models.FormatResponse, error) {
+	style := request.Style
+	if style == "" {
+		style = models.FormatCompact
+	}
+
+	lines, err := formatter.Format(&request.Address, style)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", "Token "+s.daDataApiKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	return &models.FormatResponse{Style: style, Text: strings.Join(lines, "\n"), Lines: lines}, nil
+}
+
+// searchProvider запрашивает провайдера через кэш. Из кэша возвращаются
+// копии адресов, так как ранжирование изменяет их. Новые результаты
+// пополняют словарь исправлений.
+func (s *AddressService) searchProvider(request models.SearchRequest) ([]*models.Address, error) {
+	key := searchCacheKey(request)
+	if s.cache != nil {
+		if cached, ok := s.cache.Get(key); ok {
+			return copyAddresses(cached), nil
+		}
+	}
+
+	addresses, err := s.provider.SearchAddress(request)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var geocodeResp models.GeocodeResponse
-	err = json.NewDecoder(resp.Body).Decode(&geocodeResp)
+	// Словарь исправлений строится по русским названиям.
+	if s.dictionary != nil && request.Language != models.LanguageEnglish {
+		for _, addr := range addresses {
+			s.dictionary.Add(addr.Result)
+		}
+	}
+	if s.cache != nil {
+		stored := make([]models.Address, len(addresses))
+		for i, addr := range addresses {
+			stored[i] = *addr
+		}
+		s.cache.Set(key, stored)
+	}
+	return addresses, nil
+}
+
+// searchCacheKey строится по нормализованному запросу и точке смещения,
+// округленной примерно до 100 метров.
+func searchCacheKey(request models.SearchRequest) string {
+	key := fmt.Sprintf("%s|%d|%s", request.Query, request.Limit, request.Language)
+	if request.Location != nil {
+		key += fmt.Sprintf("|%.3f,%.3f", request.Location.Lat, request.Location.Lon)
+	}
+	return key
+}
+
+func copyAddresses(stored []models.Address) []*models.Address {
+	addresses := make([]*models.Address, len(stored))
+	for i := range stored {
+		addr := stored[i]
+		addresses[i] = &addr
+	}
+	return addresses
+}
+
+// geocodeProvider запрашивает провайдера через кэш. Ключ — геохеш точки,
+// тот же, что возвращает /api/geo/cell/encode. Из кэша возвращаются копии
+// подсказок, так как транслитерация изменяет их.
+func (s *AddressService) geocodeProvider(request models.GeocodeRequest) (*models.GeocodeResponse, error) {
+	key, ok := geocodeCacheKey(request)
+	if s.geocache == nil || !ok {
+		return s.provider.Geocode(request)
+	}
+	if cached, ok := s.geocache.Get(key); ok {
+		return &models.GeocodeResponse{Suggestions: copySuggestions(cached)}, nil
+	}
+
+	geocodeResp, err := s.provider.Geocode(request)
+	if err != nil || geocodeResp == nil {
+		return geocodeResp, err
+	}
+	stored := make([]models.Suggestion, len(geocodeResp.Suggestions))
+	for i, suggestion := range geocodeResp.Suggestions {
+		stored[i] = *suggestion
+	}
+	s.geocache.Set(key, stored)
+	return geocodeResp, nil
+}
+
+// geocodeCacheKey строится по геохешу точки и языку ответа. Второе значение
+// ложно, если координаты не разбираются: такой запрос не кэшируется.
+func geocodeCacheKey(request models.GeocodeRequest) (string, bool) {
+	lat, latErr := strconv.ParseFloat(request.Lat, 64)
+	lon, lonErr := strconv.ParseFloat(request.Lng, 64)
+	if latErr != nil || lonErr != nil {
+		return "", false
+	}
+	cell, err := geocell.Encode(geocell.EncodingGeohash, lat, lon, geocodeCachePrecision)
 	if err != nil {
-		return nil, err
+		return "", false
 	}
+	return cell.Token + "|" + request.Language, true
+}
+
+func copySuggestions(stored []models.Suggestion) []*models.Suggestion {
+	suggestions := make([]*models.Suggestion, len(stored))
+	for i := range stored {
+		suggestion := stored[i]
+		suggestions[i] = &suggestion
+	}
+	return suggestions
+}
 
-	return &geocodeResp, nil
+// resolveBias определяет точку смещения: явная точка, центр области карты
+// или местоположение по IP клиента. Второе значение — масштаб затухания в метрах.
+func (s *AddressService) resolveBias(request models.SearchRequest) (*models.GeoPoint, float64) {
+	if request.Location != nil {
+		return request.Location, defaultBiasRadius
+	}
+
+	if box := request.Viewport; box != nil {
+		east := box.East
+		if box.West > east {
+			// область пересекает антимеридиан
+			east += 360
+		}
+		lon := (box.West + east) / 2
+		if lon > 180 {
+			lon -= 360
+		}
+		center := &models.GeoPoint{Lat: (box.South + box.North) / 2, Lon: lon}
+		radius := geo.Haversine(center.Lat, center.Lon, box.North, box.East)
+		return center, math.Max(radius, 1000)
+	}
+
+	if request.UseIPLocation && request.ClientIP != "" && s.geoIP != nil {
+		location, err := s.geoIP.Locate(request.ClientIP)
+		if err != nil {
+			return nil, 0
+		}
+		lat, errLat := strconv.ParseFloat(location.GeoLat, 64)
+		lon, errLon := strconv.ParseFloat(location.GeoLon, 64)
+		if errLat != nil || errLon != nil {
+			return nil, 0
+		}
+		return &models.GeoPoint{Lat: lat, Lon: lon}, defaultBiasRadius
+	}
+
+	return nil, 0
+}
+
+// rankByDistance проставляет расстояние до точки смещения и поднимает
+// ближайшие адреса, сохраняя исходный порядок провайдера как вторую составляющую.
+func rankByDistance(addresses []*models.Address, bias models.GeoPoint, radius float64, viewport *models.BoundingBox) {
+	n := len(addresses)
+	scores := make(map[*models.Address]float64, n)
+	for i, addr := range addresses {
+		relevance := 1 - float64(i)/float64(n)
+		proximity := 0.0
+
+		if lat, lon, ok := addressPoint(addr); ok {
+			distance := geo.Haversine(bias.Lat, bias.Lon, lat, lon)
+			addr.Distance = &distance
+			proximity = math.Exp(-distance / radius)
+			if viewport != nil && inBoundingBox(*viewport, lat, lon) {
+				proximity = 1
+			}
+		}
+
+		scores[addr] = relevance + 2*proximity
+	}
+
+	sort.SliceStable(addresses, func(i, j int) bool {
+		return scores[addresses[i]] > scores[addresses[j]]
+	})
+}
+
+func inBoundingBox(box models.BoundingBox, lat, lon float64) bool {
+	if lat < box.South || lat > box.North {
+		return false
+	}
+	if box.West <= box.East {
+		return lon >= box.West && lon <= box.East
+	}
+	// область пересекает антимеридиан
+	return lon >= box.West || lon <= box.East
 }
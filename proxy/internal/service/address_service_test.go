@@ -2,17 +2,60 @@ package service
 
 import (
 	"geo-controller/proxy/internal/models"
+	"math"
+	"net"
+	"strconv"
+	"strings"
 	"testing"
 )
 
+// stubProvider отдает заранее заданные адреса и запоминает последний запрос.
+type stubProvider struct {
+	addresses   []*models.Address
+	geocode     *models.GeocodeResponse
+	err         error
+	last        models.SearchRequest
+	lastGeocode models.GeocodeRequest
+	calls       int
+}
+
+func (p *stubProvider) SearchAddress(request models.SearchRequest) ([]*models.Address, error) {
+	p.last = request
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	var addresses []*models.Address
+	for _, addr := range p.addresses {
+		copied := *addr
+		addresses = append(addresses, &copied)
+	}
+	return addresses, nil
+}
+
+func (p *stubProvider) Geocode(request models.GeocodeRequest) (*models.GeocodeResponse, error) {
+	p.lastGeocode = request
+	p.calls++
+	return p.geocode, p.err
+}
+
+func leninStreets() []*models.Address {
+	return []*models.Address{
+		{Result: "г Москва, ул Ленина", Street: "Ленина", GeoLat: "55.75", GeoLon: "37.61"},
+		{Result: "г Новосибирск, ул Ленина", Street: "Ленина", GeoLat: "55.03", GeoLon: "82.92"},
+		{Result: "г Санкт-Петербург, ул Ленина", Street: "Ленина", GeoLat: "59.96", GeoLon: "30.31"},
+		{Result: "ул Ленина", Street: "Ленина"},
+	}
+}
+
 func TestAddressService_SearchAddress(t *testing.T) {
 	// Используйте тестовые ключи API или замените их на моки
 	addressService := NewAddressService("c4aab5f0a277fbaa6de6613c4c78930552172d28", "e1e61bbed8ab858bc7153ba44fc8344ba7681526")
 
 	query := "Moscow"
-	resp, err := addressService.SearchAddress(query)
+	resp, err := addressService.SearchAddress(models.SearchRequest{Query: query})
 	if err != nil {
-		t.Errorf("SearchAddress failed: %v", err)
+		t.Fatalf("SearchAddress failed: %v", err)
 	}
 
 	if resp == nil || len(resp.Addresses) == 0 {
@@ -39,3 +82,412 @@ func TestAddressService_Geocode_EmptyCoordinates(t *testing.T) {
 		t.Error("Expected error when geocoding with empty coordinates, but got nil")
 	}
 }
+
+func TestAddressService_SearchAddress_LocationBias(t *testing.T) {
+	provider := &stubProvider{addresses: leninStreets()}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	resp, err := addressService.SearchAddress(models.SearchRequest{
+		Query:    "Ленина",
+		Location: &models.GeoPoint{Lat: 59.93, Lon: 30.36},
+	})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+
+	if resp.Addresses[0].Result != "г Санкт-Петербург, ул Ленина" {
+		t.Errorf("expected Saint Petersburg first, got %s", resp.Addresses[0].Result)
+	}
+	if resp.Addresses[0].Distance == nil || *resp.Addresses[0].Distance > 10000 {
+		t.Errorf("expected distance under 10 km, got %v", resp.Addresses[0].Distance)
+	}
+	if resp.Bias == nil || resp.Bias.Lat != 59.93 {
+		t.Errorf("expected bias point in response, got %+v", resp.Bias)
+	}
+	for _, addr := range resp.Addresses {
+		if addr.GeoLat == "" && addr.Distance != nil {
+			t.Error("Expected no distance for address without coordinates")
+		}
+	}
+	if provider.last.Location == nil {
+		t.Error("Expected bias point to be passed to provider")
+	}
+}
+
+func TestAddressService_SearchAddress_ViewportBias(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(&stubProvider{addresses: leninStreets()}))
+
+	resp, err := addressService.SearchAddress(models.SearchRequest{
+		Query:    "Ленина",
+		Viewport: &models.BoundingBox{South: 54.9, West: 82.7, North: 55.1, East: 83.1},
+	})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+
+	if resp.Addresses[0].Result != "г Новосибирск, ул Ленина" {
+		t.Errorf("expected Novosibirsk first, got %s", resp.Addresses[0].Result)
+	}
+}
+
+func TestAddressService_ResolveBias_Antimeridian(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(&stubProvider{}))
+
+	testCases := []struct {
+		name     string
+		viewport models.BoundingBox
+		lon      float64
+	}{
+		{"west of antimeridian", models.BoundingBox{South: 60, West: 170, North: 70, East: -160}, -175},
+		{"east of antimeridian", models.BoundingBox{South: 60, West: 160, North: 70, East: -170}, 175},
+		{"centered on antimeridian", models.BoundingBox{South: 60, West: 170, North: 70, East: -170}, 180},
+		{"plain", models.BoundingBox{South: 54.9, West: 82.7, North: 55.1, East: 83.1}, 82.9},
+	}
+	for _, tc := range testCases {
+		viewport := tc.viewport
+		center, radius := addressService.resolveBias(models.SearchRequest{Viewport: &viewport})
+		if center == nil || math.Abs(center.Lon-tc.lon) > 1e-9 || center.Lat != (viewport.South+viewport.North)/2 {
+			t.Errorf("%s: expected center at lon %g, got %+v", tc.name, tc.lon, center)
+		}
+		// радиус — половина диагонали, а не расстояние через полмира
+		if radius > 2000000 {
+			t.Errorf("%s: expected radius of a small viewport, got %g m", tc.name, radius)
+		}
+	}
+}
+
+func TestAddressService_SearchAddress_IPFallback(t *testing.T) {
+	geoIP := NewGeoIPService(&stubIPLocator{location: &models.IPLocation{GeoLat: "55.03", GeoLon: "82.92"}})
+	addressService := NewAddressService("", "", WithProvider(&stubProvider{addresses: leninStreets()}), WithGeoIP(geoIP))
+
+	request := models.SearchRequest{Query: "Ленина", ClientIP: net.ParseIP("203.0.113.7").String()}
+	resp, err := addressService.SearchAddress(request)
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if resp.Bias != nil || resp.Addresses[0].Result != "г Москва, ул Ленина" {
+		t.Error("Expected provider order without use_ip_location")
+	}
+
+	request.UseIPLocation = true
+	resp, err = addressService.SearchAddress(request)
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if resp.Addresses[0].Result != "г Новосибирск, ул Ленина" {
+		t.Errorf("expected Novosibirsk first, got %s", resp.Addresses[0].Result)
+	}
+}
+
+func TestAddressService_SearchAddress_NormalizedQueriesShareCache(t *testing.T) {
+	provider := &stubProvider{addresses: leninStreets()}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	var first *models.SearchResponse
+	for _, query := range []string{"ул Ленина", "улица Ленина", "Lenina st."} {
+		resp, err := addressService.SearchAddress(models.SearchRequest{Query: query})
+		if err != nil {
+			t.Fatalf("SearchAddress(%q) failed: %v", query, err)
+		}
+		if resp.NormalizedQuery != "улица ленина" {
+			t.Errorf("expected normalized query 'улица ленина', got %q", resp.NormalizedQuery)
+		}
+		if first == nil {
+			first = resp
+			continue
+		}
+		if len(resp.Addresses) != len(first.Addresses) || resp.Addresses[0].Result != first.Addresses[0].Result {
+			t.Errorf("expected identical results for %q", query)
+		}
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("expected a single provider call, got %d", provider.calls)
+	}
+	if provider.last.Query != "улица ленина" {
+		t.Errorf("expected provider to receive normalized query, got %q", provider.last.Query)
+	}
+}
+
+func TestAddressService_SearchAddress_CachedResultsAreCopied(t *testing.T) {
+	provider := &stubProvider{addresses: leninStreets()}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	request := models.SearchRequest{Query: "Ленина", Location: &models.GeoPoint{Lat: 59.93, Lon: 30.36}}
+	first, err := addressService.SearchAddress(request)
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	first.Addresses[0].Result = "changed by caller"
+
+	second, err := addressService.SearchAddress(request)
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if second.Addresses[0].Result != "г Санкт-Петербург, ул Ленина" || provider.calls != 1 {
+		t.Errorf("expected untouched cached result, got %s after %d calls", second.Addresses[0].Result, provider.calls)
+	}
+}
+
+// queryProvider отдает адреса только для известных нормализованных запросов.
+type queryProvider map[string][]*models.Address
+
+func (p queryProvider) SearchAddress(request models.SearchRequest) ([]*models.Address, error) {
+	return p[request.Query], nil
+}
+
+func (p queryProvider) Geocode(request models.GeocodeRequest) (*models.GeocodeResponse, error) {
+	return &models.GeocodeResponse{}, nil
+}
+
+func TestAddressService_SearchAddress_DidYouMean(t *testing.T) {
+	provider := queryProvider{"улица ленина": leninStreets()}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	// первый поиск наполняет словарь названиями из результатов
+	if _, err := addressService.SearchAddress(models.SearchRequest{Query: "ул Ленина"}); err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+
+	resp, err := addressService.SearchAddress(models.SearchRequest{Query: "ул Линина"})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if len(resp.Addresses) != 0 || len(resp.DidYouMean) == 0 || resp.DidYouMean[0] != "улица ленина" {
+		t.Fatalf("expected empty result with suggestion, got %d addresses and %q", len(resp.Addresses), resp.DidYouMean)
+	}
+	if resp.CorrectedQuery != "" {
+		t.Error("Expected no automatic correction without auto_correct")
+	}
+
+	resp, err = addressService.SearchAddress(models.SearchRequest{Query: "ул Линина", AutoCorrect: true})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if len(resp.Addresses) != 4 || resp.CorrectedQuery != "улица ленина" {
+		t.Errorf("expected corrected search results, got %d addresses for %q", len(resp.Addresses), resp.CorrectedQuery)
+	}
+}
+
+func TestAddressService_SearchAddress_DidYouMeanFromLocalIndex(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(NewLocalProvider(localAddresses())))
+
+	resp, err := addressService.SearchAddress(models.SearchRequest{Query: "Казнь, Гагарина"})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if len(resp.DidYouMean) == 0 || resp.DidYouMean[0] != "казань, гагарина" {
+		t.Errorf("expected suggestion from indexed names, got %q", resp.DidYouMean)
+	}
+}
+
+func TestAddressService_ParseAddress(t *testing.T) {
+	provider := &stubProvider{}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	parsed, err := addressService.ParseAddress(models.ParseRequest{Query: "г Москва, ул Тверская, д 7 кв 12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.City != "Москва" || parsed.Street != "Тверская" || parsed.House != "7" || parsed.Flat != "12" {
+		t.Errorf("unexpected parse result: %+v", parsed)
+	}
+	if provider.calls != 0 {
+		t.Errorf("expected no provider calls, got %d", provider.calls)
+	}
+
+	if _, err := addressService.ParseAddress(models.ParseRequest{Query: "  "}); err == nil {
+		t.Error("Expected error when parsing empty query, but got nil")
+	}
+}
+
+func TestAddressService_FormatAddress(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(&stubProvider{}))
+
+	resp, err := addressService.FormatAddress(models.FormatRequest{
+		Address: models.Address{City: "Казань", CityType: "г", Street: "Баумана", StreetType: "ул", House: "1", PostalCode: "420111"},
+		Style:   models.FormatPost,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "ул. Баумана, д. 1\nг. Казань\n420111" || len(resp.Lines) != 3 {
+		t.Errorf("unexpected formatted address: %+v", resp)
+	}
+
+	resp, err = addressService.FormatAddress(models.FormatRequest{Address: models.Address{Result: "г Казань, ул Баумана, д 1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Style != models.FormatCompact || resp.Text != "г Казань, ул Баумана, д 1" {
+		t.Errorf("unexpected default format: %+v", resp)
+	}
+
+	if _, err := addressService.FormatAddress(models.FormatRequest{Style: "envelope", Address: models.Address{City: "Казань"}}); err == nil {
+		t.Error("Expected error for unknown style, but got nil")
+	}
+}
+
+func TestAddressService_SearchAddress_English(t *testing.T) {
+	provider := &stubProvider{addresses: []*models.Address{
+		{Result: "г Москва, ул Тверская", Country: "Россия", City: "Москва", CityType: "г", Street: "Тверская", StreetType: "ул"},
+		{Result: "Moscow, Tverskaya st", City: "Moscow", Street: "Tverskaya"},
+	}}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	resp, err := addressService.SearchAddress(models.SearchRequest{Query: "Тверская", Language: models.LanguageEnglish})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.last.Language != models.LanguageEnglish {
+		t.Errorf("expected language to be passed to provider, got %q", provider.last.Language)
+	}
+	first := resp.Addresses[0]
+	if first.Result != "g Moskva, ul Tverskaia" || first.Country != "Rossiia" || first.City != "Moskva" || first.StreetType != "ul" {
+		t.Errorf("expected ICAO transliteration, got %+v", first)
+	}
+	if resp.Addresses[1].Result != "Moscow, Tverskaya st" {
+		t.Errorf("expected provider english result to be kept, got %q", resp.Addresses[1].Result)
+	}
+
+	resp, err = addressService.SearchAddress(models.SearchRequest{Query: "Тверская", Language: models.LanguageEnglish, Transliteration: models.TransliterationGOST})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Addresses[0].Result != "g Moskva, ul Tverskaya" {
+		t.Errorf("expected GOST transliteration, got %q", resp.Addresses[0].Result)
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected cached provider results, got %d calls", provider.calls)
+	}
+
+	resp, err = addressService.SearchAddress(models.SearchRequest{Query: "Тверская"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Addresses[0].Result != "г Москва, ул Тверская" || provider.calls != 2 {
+		t.Errorf("expected separate russian results, got %q after %d calls", resp.Addresses[0].Result, provider.calls)
+	}
+
+	if _, err := addressService.SearchAddress(models.SearchRequest{Query: "Тверская", Language: "de"}); err == nil {
+		t.Error("Expected error for unsupported language, but got nil")
+	}
+	if _, err := addressService.SearchAddress(models.SearchRequest{Query: "Тверская", Transliteration: "bgn"}); err == nil {
+		t.Error("Expected error for unsupported transliteration, but got nil")
+	}
+}
+
+func TestAddressService_Geocode_English(t *testing.T) {
+	provider := &stubProvider{geocode: &models.GeocodeResponse{Suggestions: []*models.Suggestion{
+		{Value: "г Казань, ул Баумана, д 1", GeoLat: "55.79", GeoLon: "49.11"},
+	}}}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	resp, err := addressService.Geocode(models.GeocodeRequest{Lat: "55.79", Lng: "49.11", Language: models.LanguageEnglish})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Suggestions[0].Value != "g Kazan, ul Baumana, d 1" {
+		t.Errorf("expected transliterated suggestion, got %q", resp.Suggestions[0].Value)
+	}
+}
+
+func TestAddressService_Geocode_Location(t *testing.T) {
+	provider := &stubProvider{geocode: &models.GeocodeResponse{}}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	testCases := []struct {
+		location string
+		lat, lng float64
+	}{
+		{"55°45′21″N 37°37′04″E", 55.755833, 37.617778},
+		{"37.6176, 55.7558", 37.6176, 55.7558},
+		{"-122.4194 37.7749", 37.7749, -122.4194},
+		{"8FVC9G8F+6X", 47.365563, 8.524938},
+	}
+	for _, tc := range testCases {
+		if _, err := addressService.Geocode(models.GeocodeRequest{Location: tc.location}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.location, err)
+		}
+		lat, _ := strconv.ParseFloat(provider.lastGeocode.Lat, 64)
+		lng, _ := strconv.ParseFloat(provider.lastGeocode.Lng, 64)
+		if math.Abs(lat-tc.lat) > 1e-6 || math.Abs(lng-tc.lng) > 1e-6 {
+			t.Errorf("%s: expected %g, %g, got %s, %s", tc.location, tc.lat, tc.lng, provider.lastGeocode.Lat, provider.lastGeocode.Lng)
+		}
+	}
+
+	if _, err := addressService.Geocode(models.GeocodeRequest{Location: "55°75′N 37°E"}); err == nil ||
+		!strings.Contains(err.Error(), "minutes must be less than 60") {
+		t.Errorf("expected minutes error, got %v", err)
+	}
+	if _, err := addressService.Geocode(models.GeocodeRequest{Location: "55.7, 37.6", Lat: "55.7"}); err == nil {
+		t.Error("expected error when location is combined with lat, got nil")
+	}
+}
+
+func TestAddressService_Geocode_Cache(t *testing.T) {
+	provider := &stubProvider{geocode: &models.GeocodeResponse{Suggestions: []*models.Suggestion{
+		{GeoLat: "55.7558", GeoLon: "37.6176", Value: "г Москва, Красная пл"},
+	}}}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	// обе точки в ячейке геохеша ucfv0n031
+	resp, err := addressService.Geocode(models.GeocodeRequest{Lat: "55.75581", Lng: "37.6176", Language: models.LanguageEnglish})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := resp.Suggestions[0].Value; strings.Contains(value, "Москва") {
+		t.Errorf("expected transliterated value, got %q", value)
+	}
+	provider.geocode = &models.GeocodeResponse{Suggestions: []*models.Suggestion{
+		{GeoLat: "55.7558", GeoLon: "37.6176", Value: "г Москва, Красная пл"},
+	}}
+	resp, err = addressService.Geocode(models.GeocodeRequest{Lat: "55.7558", Lng: "37.61759", Language: models.LanguageEnglish})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected cached response, provider called %d times", provider.calls)
+	}
+	if value := resp.Suggestions[0].Value; strings.Contains(value, "Москва") {
+		t.Errorf("expected transliterated value, got %q", value)
+	}
+
+	// на русском ключ другой, а кэш не изменен транслитерацией
+	resp, err = addressService.Geocode(models.GeocodeRequest{Lat: "55.75581", Lng: "37.6176"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 2 || resp.Suggestions[0].Value != "г Москва, Красная пл" {
+		t.Errorf("unexpected response after %d calls: %q", provider.calls, resp.Suggestions[0].Value)
+	}
+	if _, err := addressService.Geocode(models.GeocodeRequest{Lat: "55.75581", Lng: "37.6176", Language: models.LanguageEnglish}); err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 2 {
+		t.Errorf("expected cached response, provider called %d times", provider.calls)
+	}
+}
+
+func TestAddressService_DedupeAddresses(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(&stubProvider{}))
+
+	resp, err := addressService.DedupeAddresses(models.DedupeRequest{Addresses: []models.Address{
+		{Result: "г Казань, ул Баумана, д 1"},
+		{Result: "Казань, Баумана ул, 1"},
+		{Result: "г Казань, ул Баумана, д 2"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Groups) != 1 || len(resp.Groups[0].Indices) != 2 || len(resp.Unique) != 1 || resp.Unique[0] != 2 {
+		t.Errorf("unexpected groups: %+v", resp)
+	}
+
+	if _, err := addressService.DedupeAddresses(models.DedupeRequest{}); err == nil {
+		t.Error("Expected error for empty list, but got nil")
+	}
+	if _, err := addressService.DedupeAddresses(models.DedupeRequest{Addresses: make([]models.Address, maxDedupeAddresses+1)}); err == nil {
+		t.Error("Expected error for too many addresses, but got nil")
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"geo-controller/proxy/internal/models"
+	"net/http"
+
+	"github.com/ekomobile/dadata/v2"
+	"github.com/ekomobile/dadata/v2/api/suggest"
+	"github.com/ekomobile/dadata/v2/client"
+)
+
+// daDataMaxCount — максимальное число подсказок, которое отдает DaData.
+const daDataMaxCount = 20
+
+// DaDataProvider получает адреса из API подсказок DaData.
+type DaDataProvider struct {
+	daDataApiKey    string
+	daDataSecretKey string
+}
+
+func NewDaDataProvider(apiKey, secretKey string) *DaDataProvider {
+	return &DaDataProvider{
+		daDataApiKey:    apiKey,
+		daDataSecretKey: secretKey,
+	}
+}
+
+func (p *DaDataProvider) SearchAddress(request models.SearchRequest) ([]*models.Address, error) {
+	creds := client.Credentials{
+		ApiKeyValue:    p.daDataApiKey,
+		SecretKeyValue: p.daDataSecretKey,
+	}
+
+	api := dadata.NewSuggestApi(client.WithCredentialProvider(&creds))
+
+	params := suggest.RequestParams{
+		Query:    request.Query,
+		Count:    request.Limit,
+		Language: request.Language,
+	}
+	if params.Count > daDataMaxCount {
+		params.Count = daDataMaxCount
+	}
+
+	suggestions, err := api.Address(context.Background(), &params)
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []*models.Address
+	for _, s := range suggestions {
+		addr := models.Address{
+			Result:     s.Value,
+			PostalCode: s.Data.PostalCode,
+			Country:    s.Data.Country,
+			Region:     s.Data.Region,
+			Street:     s.Data.Street,
+			GeoLat:     s.Data.GeoLat,
+			GeoLon:     s.Data.GeoLon,
+
+			RegionType:     s.Data.RegionType,
+			Area:           s.Data.Area,
+			AreaType:       s.Data.AreaType,
+			City:           s.Data.City,
+			CityType:       s.Data.CityType,
+			Settlement:     s.Data.Settlement,
+			SettlementType: s.Data.SettlementType,
+			StreetType:     s.Data.StreetType,
+			House:          s.Data.House,
+			HouseType:      s.Data.HouseType,
+			Block:          s.Data.Block,
+			BlockType:      s.Data.BlockType,
+			Flat:           s.Data.Flat,
+			FlatType:       s.Data.FlatType,
+			FiasID:         s.Data.FiasID,
+		}
+		addresses = append(addresses, &addr)
+	}
+
+	return addresses, nil
+}
+
+func (p *DaDataProvider) Geocode(request models.GeocodeRequest) (*models.GeocodeResponse, error) {
+	requestData := map[string]string{
+		"lat": request.Lat,
+		"lon": request.Lng,
+	}
+	if request.Language != "" {
+		requestData["language"] = request.Language
+	}
+
+	requestBody, err := json.Marshal(requestData)
+	if err != nil {
+		return nil, err
+	}
+
+	url := "http://suggestions.dadata.ru/suggestions/api/4_1/rs/geolocate/address"
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Token "+p.daDataApiKey)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var geocodeResp models.GeocodeResponse
+	err = json.NewDecoder(resp.Body).Decode(&geocodeResp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &geocodeResp, nil
+}
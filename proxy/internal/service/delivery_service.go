@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/delivery"
+	"geo-controller/proxy/internal/models"
+)
+
+// ErrDeliveryUnavailable возвращается, если зоны обслуживания не загружены.
+var ErrDeliveryUnavailable = errors.New("delivery zones are not loaded")
+
+// DeliveryService отвечает, доставляем ли мы по адресу и с какого склада,
+// по зонам обслуживания из конфигурации.
+type DeliveryService struct {
+	zones          *delivery.Index
+	addressService *AddressService
+}
+
+func NewDeliveryService(zones *delivery.Index, addressService *AddressService) *DeliveryService {
+	return &DeliveryService{zones: zones, addressService: addressService}
+}
+
+// Check возвращает зоны, содержащие точку или адрес, и склад самой дешевой
+// из них. Для точки вне всех зон возвращается ближайшая зона
+// и расстояние до ее границы.
+func (s *DeliveryService) Check(request models.DeliveryCheckRequest) (*models.DeliveryCheckResponse, error) {
+	if s.zones == nil {
+		return nil, ErrDeliveryUnavailable
+	}
+	point, err := s.addressService.locateWaypoint(models.Waypoint(request))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.DeliveryCheckResponse{Point: *point, Zones: []models.DeliveryZone{}}
+	zones := s.zones.Containing(point.Lat, point.Lon)
+	for _, zone := range zones {
+		resp.Zones = append(resp.Zones, zone.DeliveryZone)
+	}
+	if len(zones) > 0 {
+		depot := *zones[0].Depot
+		resp.Deliverable, resp.Depot = true, &depot
+		return resp, nil
+	}
+	if zone, distance, ok := s.zones.Nearest(point.Lat, point.Lon); ok {
+		resp.Nearest = &models.NearestDeliveryZone{Zone: zone.DeliveryZone, Distance: distance}
+	}
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/delivery"
+	"geo-controller/proxy/internal/models"
+	"testing"
+)
+
+func testDeliveryZones(t *testing.T) *delivery.Index {
+	t.Helper()
+	zones, err := delivery.New(delivery.Config{
+		Depots: []models.Depot{{ID: "msk", Name: "Москва", Point: models.GeoPoint{Lat: 55.75, Lon: 37.62}}},
+		Zones: []delivery.ZoneConfig{
+			{DeliveryZone: models.DeliveryZone{ID: "ring", DepotID: "msk", Fee: 300, ETATier: "next_day"}, Radius: 30000},
+			{DeliveryZone: models.DeliveryZone{ID: "center", DepotID: "msk", ETATier: "express"}, Radius: 5000},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zones
+}
+
+func TestDeliveryService_Check(t *testing.T) {
+	deliveryService := NewDeliveryService(testDeliveryZones(t), newTestGeoService().addressService)
+
+	resp, err := deliveryService.Check(models.DeliveryCheckRequest{Address: "Москва"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Deliverable || len(resp.Zones) != 2 || resp.Zones[0].ID != "center" || resp.Depot == nil || resp.Depot.ID != "msk" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.Nearest != nil {
+		t.Errorf("expected no nearest zone for a deliverable point, got %+v", resp.Nearest)
+	}
+
+	resp, err = deliveryService.Check(models.DeliveryCheckRequest{Address: "Санкт-Петербург"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Deliverable || len(resp.Zones) != 0 || resp.Depot != nil {
+		t.Errorf("expected no delivery to Saint Petersburg, got %+v", resp)
+	}
+	if resp.Nearest == nil || resp.Nearest.Zone.ID != "ring" || resp.Nearest.Distance < 600000 {
+		t.Errorf("expected ring as nearest zone over 600 km away, got %+v", resp.Nearest)
+	}
+
+	if _, err := deliveryService.Check(models.DeliveryCheckRequest{Address: "деревня бор"}); !errors.Is(err, ErrAddressNotFound) {
+		t.Errorf("expected ErrAddressNotFound, got %v", err)
+	}
+	if _, err := deliveryService.Check(models.DeliveryCheckRequest{}); err == nil {
+		t.Error("expected error for empty request")
+	}
+
+	unavailable := NewDeliveryService(nil, newTestGeoService().addressService)
+	if _, err := unavailable.Check(models.DeliveryCheckRequest{Address: "Москва"}); !errors.Is(err, ErrDeliveryUnavailable) {
+		t.Errorf("expected ErrDeliveryUnavailable, got %v", err)
+	}
+}
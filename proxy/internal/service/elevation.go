@@ -0,0 +1,136 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/elevation"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/models"
+	"math"
+)
+
+const (
+	// maxElevationPoints — наибольшее число точек в запросе высот.
+	maxElevationPoints = 500
+	// maxProfileWaypoints — наибольшее число вершин ломаной профиля.
+	maxProfileWaypoints = 100
+	// defaultProfileSamples и maxProfileSamples — число точек профиля
+	// по умолчанию и наибольшее.
+	defaultProfileSamples = 100
+	maxProfileSamples     = 1000
+)
+
+// Ошибки поиска высот по локальным тайлам. ErrNoElevationCoverage
+// возвращается для точек вне тайлов и в пустотах данных.
+var (
+	ErrElevationUnavailable = errors.New("elevation tiles are not loaded")
+	ErrNoElevationCoverage  = elevation.ErrNoCoverage
+)
+
+// Elevation возвращает высоты точек или адресов над уровнем моря.
+func (s *GeoService) Elevation(request models.ElevationRequest) (*models.ElevationResponse, error) {
+	if s.elevation == nil {
+		return nil, ErrElevationUnavailable
+	}
+	if len(request.Points) == 0 {
+		return nil, errors.New("points cannot be empty")
+	}
+	if len(request.Points) > maxElevationPoints {
+		return nil, fmt.Errorf("too many points: at most %d", maxElevationPoints)
+	}
+
+	results := make([]models.ElevationPoint, len(request.Points))
+	for i, waypoint := range request.Points {
+		point, err := s.resolve(waypoint)
+		if err != nil {
+			return nil, err
+		}
+		value, err := s.elevation.Elevation(point.Lat, point.Lon)
+		if err != nil {
+			return nil, fmt.Errorf("point %d: %w", i, err)
+		}
+		results[i] = models.ElevationPoint{Point: *point, Elevation: value}
+	}
+	return &models.ElevationResponse{Results: results}, nil
+}
+
+// ElevationProfile возвращает профиль высот вдоль ломаной: точки идут
+// равномерно по длине, между вершинами — по большому кругу.
+func (s *GeoService) ElevationProfile(request models.ElevationProfileRequest) (*models.ElevationProfileResponse, error) {
+	if s.elevation == nil {
+		return nil, ErrElevationUnavailable
+	}
+	if len(request.Path) < 2 {
+		return nil, errors.New("path must have at least 2 points")
+	}
+	if len(request.Path) > maxProfileWaypoints {
+		return nil, fmt.Errorf("too many path points: at most %d", maxProfileWaypoints)
+	}
+	samples := request.Samples
+	if samples == 0 {
+		samples = defaultProfileSamples
+	}
+	if samples < 2 || samples > maxProfileSamples {
+		return nil, fmt.Errorf("samples must be between 2 and %d", maxProfileSamples)
+	}
+
+	path := make([]models.GeoPoint, len(request.Path))
+	for i, waypoint := range request.Path {
+		point, err := s.resolve(waypoint)
+		if err != nil {
+			return nil, err
+		}
+		path[i] = *point
+	}
+
+	resp := &models.ElevationProfileResponse{Min: math.Inf(1), Max: math.Inf(-1)}
+	resp.Points, resp.Length = samplePath(path, samples)
+	for i := range resp.Points {
+		p := &resp.Points[i]
+		value, err := s.elevation.Elevation(p.Point.Lat, p.Point.Lon)
+		if err != nil {
+			return nil, fmt.Errorf("profile point at %.0f m: %w", p.Distance, err)
+		}
+		p.Elevation = value
+		resp.Min = math.Min(resp.Min, value)
+		resp.Max = math.Max(resp.Max, value)
+		if i > 0 {
+			if climb := value - resp.Points[i-1].Elevation; climb > 0 {
+				resp.Ascent += climb
+			} else {
+				resp.Descent -= climb
+			}
+		}
+	}
+	return resp, nil
+}
+
+// samplePath расставляет samples точек на равных расстояниях вдоль ломаной,
+// включая ее концы, и возвращает их вместе с длиной ломаной в метрах.
+func samplePath(path []models.GeoPoint, samples int) ([]models.ProfilePoint, float64) {
+	cumulative := make([]float64, len(path))
+	for i := 1; i < len(path); i++ {
+		cumulative[i] = cumulative[i-1] + geo.Haversine(path[i-1].Lat, path[i-1].Lon, path[i].Lat, path[i].Lon)
+	}
+	length := cumulative[len(path)-1]
+
+	points := make([]models.ProfilePoint, samples)
+	segment := 0
+	for i := range points {
+		distance := length * float64(i) / float64(samples-1)
+		for segment < len(path)-2 && cumulative[segment+1] < distance {
+			segment++
+		}
+		from, to := path[segment], path[segment+1]
+		point := from
+		switch offset := distance - cumulative[segment]; {
+		case i == samples-1:
+			point = path[len(path)-1]
+		case offset > 0:
+			bearing := geo.InitialBearing(from.Lat, from.Lon, to.Lat, to.Lon)
+			point.Lat, point.Lon = geo.Destination(from.Lat, from.Lon, bearing, offset)
+		}
+		points[i] = models.ProfilePoint{Point: point, Distance: distance}
+	}
+	return points, length
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/binary"
+	"errors"
+	"geo-controller/proxy/internal/elevation"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/models"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testElevation — тайл N55E037 3×3, высота растет на восток от 100 до 300 м.
+func testElevation(t *testing.T) *elevation.Store {
+	t.Helper()
+	dir := t.TempDir()
+	data := make([]byte, 18)
+	for i := 0; i < 9; i++ {
+		binary.BigEndian.PutUint16(data[2*i:], uint16(100+100*(i%3)))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "N55E037.hgt"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := elevation.Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestGeoService_Elevation(t *testing.T) {
+	geoService := newTestGeoService()
+	request := models.ElevationRequest{Points: []models.Waypoint{
+		{Address: "Москва"},
+		{Point: &models.GeoPoint{Lat: 55.1, Lon: 37.25}},
+	}}
+	if _, err := geoService.Elevation(request); !errors.Is(err, ErrElevationUnavailable) {
+		t.Errorf("expected ErrElevationUnavailable, got %v", err)
+	}
+
+	geoService = NewGeoService(geoService.addressService, WithElevation(testElevation(t)))
+	resp, err := geoService.Elevation(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{100 + 200*0.6176, 150}
+	for i, result := range resp.Results {
+		if math.Abs(result.Elevation-expected[i]) > 1e-6 {
+			t.Errorf("point %d: expected %g, got %g", i, expected[i], result.Elevation)
+		}
+	}
+	if resp.Results[0].Point != (models.GeoPoint{Lat: 55.7558, Lon: 37.6176}) {
+		t.Errorf("expected geocoded point, got %+v", resp.Results[0].Point)
+	}
+
+	_, err = geoService.Elevation(models.ElevationRequest{Points: []models.Waypoint{{Address: "Санкт-Петербург"}}})
+	if !errors.Is(err, ErrNoElevationCoverage) {
+		t.Errorf("expected ErrNoElevationCoverage, got %v", err)
+	}
+	if _, err := geoService.Elevation(models.ElevationRequest{}); err == nil {
+		t.Error("expected error for empty points")
+	}
+}
+
+func TestGeoService_ElevationProfile(t *testing.T) {
+	geoService := NewGeoService(newTestGeoService().addressService, WithElevation(testElevation(t)))
+
+	path := []models.Waypoint{
+		{Point: &models.GeoPoint{Lat: 55.5, Lon: 37}},
+		{Point: &models.GeoPoint{Lat: 55.5, Lon: 38}},
+		{Point: &models.GeoPoint{Lat: 55.5, Lon: 37.5}},
+	}
+	resp, err := geoService.ElevationProfile(models.ElevationProfileRequest{Path: path, Samples: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leg := geo.Haversine(55.5, 37, 55.5, 38)
+	if len(resp.Points) != 7 || math.Abs(resp.Length-1.5*leg) > 1 {
+		t.Fatalf("expected 7 points over %.0f m, got %d over %.0f m", 1.5*leg, len(resp.Points), resp.Length)
+	}
+	// шаг — четверть первого отрезка, четвертая точка — вторая вершина
+	for i, expected := range []float64{100, 150, 200, 250, 300, 250, 200} {
+		p := resp.Points[i]
+		if math.Abs(p.Elevation-expected) > 0.5 || math.Abs(p.Distance-float64(i)*leg/4) > 1 {
+			t.Errorf("point %d: expected %g m at %.0f m, got %+v", i, expected, float64(i)*leg/4, p)
+		}
+	}
+	if resp.Points[6].Point != *path[2].Point {
+		t.Errorf("profile must end at the last vertex, got %+v", resp.Points[6].Point)
+	}
+	if math.Abs(resp.Ascent-200) > 0.5 || math.Abs(resp.Descent-100) > 0.5 || resp.Min != 100 || math.Abs(resp.Max-300) > 0.5 {
+		t.Errorf("unexpected summary: ascent %g, descent %g, min %g, max %g", resp.Ascent, resp.Descent, resp.Min, resp.Max)
+	}
+
+	for _, request := range []models.ElevationProfileRequest{
+		{Path: path[:1]},
+		{Path: path, Samples: 1},
+		{Path: path, Samples: maxProfileSamples + 1},
+	} {
+		if _, err := geoService.ElevationProfile(request); err == nil {
+			t.Errorf("expected error for %+v", request)
+		}
+	}
+	outside := []models.Waypoint{path[0], {Point: &models.GeoPoint{Lat: 54.5, Lon: 37}}}
+	if _, err := geoService.ElevationProfile(models.ElevationProfileRequest{Path: outside}); !errors.Is(err, ErrNoElevationCoverage) {
+		t.Errorf("expected ErrNoElevationCoverage, got %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/boundary"
+	"geo-controller/proxy/internal/elevation"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/geocell"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/projection"
+	"time"
+)
+
+const (
+	// maxMatrixWaypoints — наибольшее число точек с каждой стороны матрицы расстояний.
+	maxMatrixWaypoints = 50
+	// maxTransformPoints — наибольшее число отдельных точек в запросе пересчета координат.
+	maxTransformPoints = 10000
+)
+
+// Ошибки поиска по локальным административным границам.
+var (
+	ErrBoundariesUnavailable = errors.New("administrative boundaries are not loaded")
+	ErrBoundaryNotFound      = errors.New("point is outside of known administrative boundaries")
+)
+
+// GeoService выполняет геометрические расчеты. Адреса в запросах
+// геокодируются через AddressService.
+type GeoService struct {
+	addressService *AddressService
+	boundaries     *boundary.Index
+	elevation      *elevation.Store
+}
+
+// GeoServiceOption настраивает GeoService.
+type GeoServiceOption func(*GeoService)
+
+// WithBoundaries задает индекс административных границ для Boundaries.
+func WithBoundaries(boundaries *boundary.Index) GeoServiceOption {
+	return func(s *GeoService) {
+		s.boundaries = boundaries
+	}
+}
+
+// WithElevation задает каталог тайлов высот для Elevation и ElevationProfile.
+func WithElevation(store *elevation.Store) GeoServiceOption {
+	return func(s *GeoService) {
+		s.elevation = store
+	}
+}
+
+func NewGeoService(addressService *AddressService, opts ...GeoServiceOption) *GeoService {
+	s := &GeoService{addressService: addressService}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Distance возвращает расстояние и азимуты между двумя точками.
+func (s *GeoService) Distance(request models.DistanceRequest) (*models.DistanceResponse, error) {
+	from, err := s.resolve(request.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.resolve(request.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DistanceResponse{From: *from, To: *to, Distance: distance(*from, *to)}, nil
+}
+
+// Matrix возвращает расстояния от каждой исходной точки до каждой конечной.
+// Одинаковые адреса геокодируются один раз.
+func (s *GeoService) Matrix(request models.MatrixRequest) (*models.MatrixResponse, error) {
+	if len(request.Origins) == 0 || len(request.Destinations) == 0 {
+		return nil, errors.New("origins and destinations cannot be empty")
+	}
+	if len(request.Origins) > maxMatrixWaypoints || len(request.Destinations) > maxMatrixWaypoints {
+		return nil, fmt.Errorf("too many waypoints: at most %d origins and %d destinations", maxMatrixWaypoints, maxMatrixWaypoints)
+	}
+
+	located := map[string]models.GeoPoint{}
+	resolveAll := func(waypoints []models.Waypoint) ([]models.GeoPoint, error) {
+		points := make([]models.GeoPoint, len(waypoints))
+		for i, waypoint := range waypoints {
+			if point, ok := located[waypoint.Address]; ok && waypoint.Point == nil {
+				points[i] = point
+				continue
+			}
+			point, err := s.resolve(waypoint)
+			if err != nil {
+				return nil, err
+			}
+			if waypoint.Point == nil {
+				located[waypoint.Address] = *point
+			}
+			points[i] = *point
+		}
+		return points, nil
+	}
+
+	origins, err := resolveAll(request.Origins)
+	if err != nil {
+		return nil, err
+	}
+	destinations, err := resolveAll(request.Destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]models.Distance, len(origins))
+	for i, from := range origins {
+		rows[i] = make([]models.Distance, len(destinations))
+		for j, to := range destinations {
+			rows[i][j] = distance(from, to)
+		}
+	}
+	return &models.MatrixResponse{Origins: origins, Destinations: destinations, Rows: rows}, nil
+}
+
+// resolve возвращает координаты точки, геокодируя адрес, если координаты не заданы.
+func (s *GeoService) resolve(waypoint models.Waypoint) (*models.GeoPoint, error) {
+	return s.addressService.locateWaypoint(waypoint)
+}
+
+func validatePoint(point models.GeoPoint) error {
+	if point.Lat < -90 || point.Lat > 90 || point.Lon < -180 || point.Lon > 180 {
+		return fmt.Errorf("coordinates out of range: %g, %g", point.Lat, point.Lon)
+	}
+	return nil
+}
+
+// distance считает расстояние по сфере и по эллипсоиду. Если формула Винсенти
+// не сошлась, азимуты берутся по большому кругу.
+func distance(from, to models.GeoPoint) models.Distance {
+	d := models.Distance{Haversine: geo.Haversine(from.Lat, from.Lon, to.Lat, to.Lon)}
+
+	inverse, err := geo.Vincenty(from.Lat, from.Lon, to.Lat, to.Lon)
+	if err != nil {
+		d.InitialBearing = geo.InitialBearing(from.Lat, from.Lon, to.Lat, to.Lon)
+		d.FinalBearing = geo.FinalBearing(from.Lat, from.Lon, to.Lat, to.Lon)
+		return d
+	}
+	d.Vincenty = &inverse.Distance
+	d.InitialBearing, d.FinalBearing = inverse.InitialBearing, inverse.FinalBearing
+	return d
+}
+
+// Measure возвращает площадь, длину, центроид и охватывающий прямоугольник геометрии.
+func (s *GeoService) Measure(request models.GeometryRequest) (*models.MeasureResponse, error) {
+	g, err := geometry.Decode(request.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MeasureResponse{
+		Area:     geometry.Area(g),
+		Length:   geometry.Length(g),
+		Centroid: geometry.Centroid(g),
+		BBox:     geometry.BBox(g),
+	}, nil
+}
+
+// ConvexHull возвращает выпуклую оболочку геометрии.
+func (s *GeoService) ConvexHull(request models.GeometryRequest) (*models.GeometryResponse, error) {
+	g, err := geometry.Decode(request.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GeometryResponse{Geometry: geometry.ConvexHull(g).Encode()}, nil
+}
+
+// Simplify упрощает геометрию с допуском в метрах.
+func (s *GeoService) Simplify(request models.SimplifyRequest) (*models.SimplifyResponse, error) {
+	if request.Tolerance <= 0 {
+		return nil, errors.New("tolerance must be positive")
+	}
+	g, err := geometry.Decode(request.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	simplified := geometry.Simplify(g, request.Tolerance)
+	return &models.SimplifyResponse{
+		Geometry:     simplified.Encode(),
+		PointsBefore: g.NumPoints(),
+		PointsAfter:  simplified.NumPoints(),
+	}, nil
+}
+
+// Buffer строит геодезический буфер вокруг геометрии.
+func (s *GeoService) Buffer(request models.BufferRequest) (*models.GeometryResponse, error) {
+	g, err := geometry.Decode(request.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	buffer, err := geometry.Buffer(g, request.Distance, request.Segments)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GeometryResponse{Geometry: buffer.Encode()}, nil
+}
+
+// Transform пересчитывает точки и геометрию из одной системы координат
+// в другую. Сдвиг датума (например, СК-42 — WGS 84) учитывается.
+func (s *GeoService) Transform(request models.TransformRequest) (*models.TransformResponse, error) {
+	from, err := projection.Lookup(request.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := projection.Lookup(request.To)
+	if err != nil {
+		return nil, err
+	}
+	if len(request.Points) == 0 && request.Geometry == nil {
+		return nil, errors.New("points or geometry must be set")
+	}
+	if len(request.Points) > maxTransformPoints {
+		return nil, fmt.Errorf("too many points: at most %d", maxTransformPoints)
+	}
+
+	transform := func(p geometry.Point) (geometry.Point, error) {
+		x, y, err := projection.Transform(from, to, p[0], p[1])
+		return geometry.Point{x, y}, err
+	}
+
+	resp := &models.TransformResponse{From: request.From, To: request.To}
+	for _, p := range request.Points {
+		transformed, err := transform(p)
+		if err != nil {
+			return nil, err
+		}
+		resp.Points = append(resp.Points, transformed)
+	}
+
+	if request.Geometry != nil {
+		g, err := geometry.DecodeProjected(*request.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		if g, err = g.Transform(transform); err != nil {
+			return nil, err
+		}
+		encoded := g.Encode()
+		resp.Geometry = &encoded
+	}
+	return resp, nil
+}
+
+// EncodeCell возвращает ячейку сетки, содержащую точку.
+func (s *GeoService) EncodeCell(request models.CellEncodeRequest) (*models.Cell, error) {
+	cell, err := geocell.Encode(request.Encoding, request.Point.Lat, request.Point.Lon, request.Precision)
+	if err != nil {
+		return nil, err
+	}
+	return &cell, nil
+}
+
+// DecodeCell возвращает центр и границы ячейки по токену.
+func (s *GeoService) DecodeCell(request models.CellRequest) (*models.Cell, error) {
+	cell, err := geocell.Decode(request.Encoding, request.Token)
+	if err != nil {
+		return nil, err
+	}
+	return &cell, nil
+}
+
+// CellNeighbours возвращает ячейку и соседние ячейки той же точности.
+func (s *GeoService) CellNeighbours(request models.CellRequest) (*models.CellNeighboursResponse, error) {
+	cell, err := geocell.Decode(request.Encoding, request.Token)
+	if err != nil {
+		return nil, err
+	}
+	neighbours, err := geocell.Neighbours(request.Encoding, request.Token)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CellNeighboursResponse{Cell: cell, Neighbours: neighbours}, nil
+}
+
+// Boundaries определяет страну, субъект федерации, муниципальное образование
+// и район по локальным полигонам, без обращения к провайдеру для точек.
+func (s *GeoService) Boundaries(request models.BoundaryRequest) (*models.BoundaryResponse, error) {
+	if s.boundaries == nil {
+		return nil, ErrBoundariesUnavailable
+	}
+	point, err := s.resolve(models.Waypoint(request))
+	if err != nil {
+		return nil, err
+	}
+
+	found := s.boundaries.Lookup(point.Lat, point.Lon)
+	if len(found) == 0 {
+		return nil, ErrBoundaryNotFound
+	}
+	area := func(level string) *models.AdminArea {
+		if b := found[level]; b != nil {
+			return &models.AdminArea{Name: b.Name, Code: b.Code}
+		}
+		return nil
+	}
+	return &models.BoundaryResponse{
+		Point:          *point,
+		Country:        area(boundary.LevelCountry),
+		FederalSubject: area(boundary.LevelFederalSubject),
+		Municipality:   area(boundary.LevelMunicipality),
+		District:       area(boundary.LevelDistrict),
+	}, nil
+}
+
+// Timezone возвращает часовой пояс точки или адреса и текущее смещение от UTC.
+func (s *GeoService) Timezone(request models.TimezoneRequest) (*models.TimezoneResponse, error) {
+	if s.addressService.timezones == nil {
+		return nil, ErrTimezonesUnavailable
+	}
+	point, err := s.resolve(models.Waypoint(request))
+	if err != nil {
+		return nil, err
+	}
+	return s.addressService.Timezone(*point, time.Now())
+}
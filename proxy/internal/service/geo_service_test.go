@@ -0,0 +1,327 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/boundary"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/models"
+	"math"
+	"testing"
+)
+
+func newTestGeoService() *GeoService {
+	provider := queryProvider{
+		"москва":          {{Result: "г Москва", GeoLat: "55.7558", GeoLon: "37.6176"}},
+		"санкт-петербург": {{Result: "г Санкт-Петербург", GeoLat: "59.9311", GeoLon: "30.3609"}},
+		"деревня бор":     {{Result: "д Бор"}},
+	}
+	return NewGeoService(NewAddressService("", "", WithProvider(provider)))
+}
+
+func TestGeoService_Distance(t *testing.T) {
+	geoService := newTestGeoService()
+
+	resp, err := geoService.Distance(models.DistanceRequest{
+		From: models.Waypoint{Address: "Москва"},
+		To:   models.Waypoint{Point: &models.GeoPoint{Lat: 59.9311, Lon: 30.3609}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.From != (models.GeoPoint{Lat: 55.7558, Lon: 37.6176}) {
+		t.Errorf("expected geocoded origin, got %+v", resp.From)
+	}
+	if math.Abs(resp.Haversine-634000) > 3000 || resp.Vincenty == nil || math.Abs(*resp.Vincenty-resp.Haversine) > 0.005*resp.Haversine {
+		t.Errorf("unexpected distances: haversine %.0f, vincenty %v", resp.Haversine, resp.Vincenty)
+	}
+	if resp.InitialBearing < 315 || resp.InitialBearing > 325 || resp.FinalBearing < 310 || resp.FinalBearing > 320 {
+		t.Errorf("unexpected bearings %.1f/%.1f", resp.InitialBearing, resp.FinalBearing)
+	}
+}
+
+func TestGeoService_Distance_NearlyAntipodal(t *testing.T) {
+	resp, err := newTestGeoService().Distance(models.DistanceRequest{
+		From: models.Waypoint{Point: &models.GeoPoint{Lat: 0, Lon: 0}},
+		To:   models.Waypoint{Point: &models.GeoPoint{Lat: 0.5, Lon: 179.7}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Vincenty != nil || resp.Haversine < 19900000 {
+		t.Errorf("expected haversine only, got %+v", resp.Distance)
+	}
+}
+
+func TestGeoService_Distance_Errors(t *testing.T) {
+	geoService := newTestGeoService()
+	spb := models.Waypoint{Address: "Санкт-Петербург"}
+
+	testCases := []struct {
+		name     string
+		from     models.Waypoint
+		notFound bool
+	}{
+		{"empty waypoint", models.Waypoint{}, false},
+		{"latitude out of range", models.Waypoint{Point: &models.GeoPoint{Lat: 91, Lon: 0}}, false},
+		{"longitude out of range", models.Waypoint{Point: &models.GeoPoint{Lat: 0, Lon: 181}}, false},
+		{"unknown address", models.Waypoint{Address: "Атлантида"}, true},
+		{"address without coordinates", models.Waypoint{Address: "д Бор"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := geoService.Distance(models.DistanceRequest{From: tc.from, To: spb})
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if errors.Is(err, ErrAddressNotFound) != tc.notFound {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestGeoService_Matrix(t *testing.T) {
+	geoService := newTestGeoService()
+
+	resp, err := geoService.Matrix(models.MatrixRequest{
+		Origins: []models.Waypoint{{Address: "Москва"}, {Point: &models.GeoPoint{Lat: 59.9311, Lon: 30.3609}}},
+		Destinations: []models.Waypoint{
+			{Address: "Санкт-Петербург"}, {Address: "Москва"}, {Point: &models.GeoPoint{Lat: 55.7558, Lon: 37.6176}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Rows) != 2 || len(resp.Rows[0]) != 3 || len(resp.Destinations) != 3 {
+		t.Fatalf("unexpected matrix shape: %+v", resp)
+	}
+	if resp.Rows[0][1].Haversine != 0 || resp.Rows[1][0].Haversine != 0 {
+		t.Errorf("expected zero distance for the same point, got %+v", resp.Rows)
+	}
+	if math.Abs(resp.Rows[0][0].Haversine-resp.Rows[1][2].Haversine) > 1e-6 {
+		t.Errorf("expected symmetric distances, got %.3f and %.3f", resp.Rows[0][0].Haversine, resp.Rows[1][2].Haversine)
+	}
+
+	if _, err := geoService.Matrix(models.MatrixRequest{Origins: []models.Waypoint{{Address: "Москва"}}}); err == nil {
+		t.Error("expected error for empty destinations, got nil")
+	}
+	tooMany := make([]models.Waypoint, maxMatrixWaypoints+1)
+	if _, err := geoService.Matrix(models.MatrixRequest{Origins: tooMany, Destinations: tooMany}); err == nil {
+		t.Error("expected error for too many waypoints, got nil")
+	}
+}
+
+// square — квадрат 0.01°×0.01° у экватора, около 1.1 км на сторону.
+var square = models.Geometry{Type: "Polygon", Coordinates: []byte(`[[[0,0],[0.01,0],[0.01,0.01],[0,0.01],[0,0]]]`)}
+
+func TestGeoService_Measure(t *testing.T) {
+	resp, err := newTestGeoService().Measure(models.GeometryRequest{Geometry: square})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(resp.Area-1.2364e6) > 1e3 || math.Abs(resp.Length-4*1110) > 10 {
+		t.Errorf("unexpected area %.0f m² and perimeter %.0f m", resp.Area, resp.Length)
+	}
+	if math.Abs(resp.Centroid.Lat-0.005) > 1e-9 || math.Abs(resp.Centroid.Lon-0.005) > 1e-9 {
+		t.Errorf("unexpected centroid %+v", resp.Centroid)
+	}
+	if resp.BBox != (models.BoundingBox{South: 0, West: 0, North: 0.01, East: 0.01}) {
+		t.Errorf("unexpected bbox %+v", resp.BBox)
+	}
+
+	if _, err := newTestGeoService().Measure(models.GeometryRequest{Geometry: models.Geometry{Type: "Circle"}}); err == nil {
+		t.Error("expected error for unsupported geometry, got nil")
+	}
+}
+
+func TestGeoService_Simplify(t *testing.T) {
+	line := models.Geometry{Type: "LineString", Coordinates: []byte(`[[0,0],[0.01,0.0001],[0.02,0]]`)}
+
+	resp, err := newTestGeoService().Simplify(models.SimplifyRequest{Geometry: line, Tolerance: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.PointsBefore != 3 || resp.PointsAfter != 2 || string(resp.Geometry.Coordinates) != `[[0,0],[0.02,0]]` {
+		t.Errorf("unexpected simplification %+v", resp)
+	}
+
+	if _, err := newTestGeoService().Simplify(models.SimplifyRequest{Geometry: line}); err == nil {
+		t.Error("expected error for zero tolerance, got nil")
+	}
+}
+
+func TestGeoService_ConvexHullAndBuffer(t *testing.T) {
+	geoService := newTestGeoService()
+
+	hull, err := geoService.ConvexHull(models.GeometryRequest{Geometry: square})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hull.Geometry.Type != "Polygon" || string(hull.Geometry.Coordinates) != string(square.Coordinates) {
+		t.Errorf("expected square hull, got %s %s", hull.Geometry.Type, hull.Geometry.Coordinates)
+	}
+
+	buffer, err := geoService.Buffer(models.BufferRequest{Geometry: square, Distance: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	measured, err := geoService.Measure(models.GeometryRequest{Geometry: buffer.Geometry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if measured.Area <= 1.2364e6 || measured.BBox.South >= 0 || measured.BBox.North <= 0.01 {
+		t.Errorf("expected buffer to cover the square, got %+v", measured)
+	}
+
+	if _, err := geoService.Buffer(models.BufferRequest{Geometry: square}); err == nil {
+		t.Error("expected error for zero distance, got nil")
+	}
+}
+
+func TestGeoService_Transform(t *testing.T) {
+	geoService := newTestGeoService()
+	polygon := models.Geometry{Type: "Polygon", Coordinates: []byte(`[[[413000,6180000],[414000,6180000],[414000,6181000],[413000,6180000]]]`)}
+
+	resp, err := geoService.Transform(models.TransformRequest{
+		From:     "EPSG:32637",
+		To:       "EPSG:4326",
+		Points:   [][2]float64{{500000, 0}},
+		Geometry: &polygon,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Points) != 1 || math.Abs(resp.Points[0][0]-39) > 1e-9 || math.Abs(resp.Points[0][1]) > 1e-9 {
+		t.Errorf("expected central meridian on equator, got %v", resp.Points)
+	}
+	if resp.Geometry == nil || resp.Geometry.Type != "Polygon" {
+		t.Fatalf("expected transformed polygon, got %+v", resp.Geometry)
+	}
+
+	back, err := geoService.Transform(models.TransformRequest{From: "EPSG:4326", To: "EPSG:32637", Geometry: resp.Geometry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	measured, err := geoService.Measure(models.GeometryRequest{Geometry: *resp.Geometry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if measured.BBox.West < 37 || measured.BBox.East > 38 || measured.BBox.South < 55 || measured.BBox.North > 56 {
+		t.Errorf("expected polygon near Moscow, got %+v", measured.BBox)
+	}
+	if back.Geometry.Type != "Polygon" {
+		t.Errorf("expected polygon, got %s", back.Geometry.Type)
+	}
+
+	testCases := []struct {
+		name    string
+		request models.TransformRequest
+	}{
+		{"unknown source", models.TransformRequest{From: "EPSG:2154", To: "EPSG:4326", Points: [][2]float64{{0, 0}}}},
+		{"unknown target", models.TransformRequest{From: "EPSG:4326", To: "UTM", Points: [][2]float64{{0, 0}}}},
+		{"nothing to transform", models.TransformRequest{From: "EPSG:4326", To: "EPSG:3857"}},
+		{"out of range", models.TransformRequest{From: "EPSG:4326", To: "EPSG:3857", Points: [][2]float64{{0, 89}}}},
+		{"too many points", models.TransformRequest{From: "EPSG:4326", To: "EPSG:3857", Points: make([][2]float64, maxTransformPoints+1)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := geoService.Transform(tc.request); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestGeoService_CellNeighbours(t *testing.T) {
+	geoService := newTestGeoService()
+
+	cell, err := geoService.EncodeCell(models.CellEncodeRequest{Encoding: "plus_code", Point: models.GeoPoint{Lat: 47.365590, Lon: 8.524997}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := geoService.CellNeighbours(models.CellRequest{Encoding: cell.Encoding, Token: cell.Token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Cell.Token != "8FVC9G8F+6X" || len(resp.Neighbours) != 8 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	for _, n := range resp.Neighbours {
+		if n.Precision != resp.Cell.Precision {
+			t.Errorf("neighbour %s has precision %d", n.Token, n.Precision)
+		}
+	}
+
+	if _, err := geoService.DecodeCell(models.CellRequest{Encoding: "plus_code", Token: "9G8F+6X"}); err == nil {
+		t.Error("expected error for short plus code")
+	}
+}
+
+func testBoundaries(t *testing.T) *boundary.Index {
+	t.Helper()
+	var boundaries []*boundary.Boundary
+	for _, b := range []struct {
+		properties  map[string]string
+		coordinates string
+	}{
+		{map[string]string{"name": "Россия", "admin_level": "2", "ISO3166-1": "RU"}, `[[[19,41],[180,41],[180,82],[19,82],[19,41]]]`},
+		{map[string]string{"name": "Москва", "admin_level": "4", "ISO3166-2": "RU-MOW"}, `[[[37,55],[38,55],[38,56],[37,56],[37,55]]]`},
+	} {
+		g, err := geometry.Decode(models.Geometry{Type: "Polygon", Coordinates: []byte(b.coordinates)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := boundary.NewBoundary(b.properties, g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		boundaries = append(boundaries, parsed)
+	}
+	return boundary.NewIndex(boundaries)
+}
+
+func TestGeoService_Boundaries(t *testing.T) {
+	geoService := newTestGeoService()
+	if _, err := geoService.Boundaries(models.BoundaryRequest{Address: "Москва"}); !errors.Is(err, ErrBoundariesUnavailable) {
+		t.Errorf("expected ErrBoundariesUnavailable, got %v", err)
+	}
+
+	geoService = NewGeoService(geoService.addressService, WithBoundaries(testBoundaries(t)))
+	resp, err := geoService.Boundaries(models.BoundaryRequest{Address: "Москва"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Country == nil || resp.Country.Code != "RU" || resp.FederalSubject == nil || resp.FederalSubject.Name != "Москва" ||
+		resp.Municipality != nil || resp.District != nil {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	resp, err = geoService.Boundaries(models.BoundaryRequest{Point: &models.GeoPoint{Lat: 59.9311, Lon: 30.3609}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Country == nil || resp.FederalSubject != nil {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if _, err := geoService.Boundaries(models.BoundaryRequest{Point: &models.GeoPoint{Lat: 0, Lon: 0}}); !errors.Is(err, ErrBoundaryNotFound) {
+		t.Errorf("expected ErrBoundaryNotFound, got %v", err)
+	}
+}
+
+func TestGeoService_Timezone(t *testing.T) {
+	geoService := newTestGeoService()
+	if _, err := geoService.Timezone(models.TimezoneRequest{Address: "Москва"}); !errors.Is(err, ErrTimezonesUnavailable) {
+		t.Errorf("expected ErrTimezonesUnavailable, got %v", err)
+	}
+
+	geoService.addressService.timezones = testTimezones(t)
+	resp, err := geoService.Timezone(models.TimezoneRequest{Address: "Москва"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Point != (models.GeoPoint{Lat: 55.7558, Lon: 37.6176}) || resp.Timezone != "Europe/Moscow" || resp.UTCOffset != "+03:00" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"geo-controller/proxy/internal/geofence"
+	"geo-controller/proxy/internal/models"
+	"time"
+)
+
+// Ошибки геозон: ErrGeofenceNotFound — зоны нет у пользователя,
+// ErrInvalidGeofence — неверное название или геометрия, ErrGeofenceStorage —
+// изменение не удалось записать на диск.
+var (
+	ErrGeofenceNotFound = geofence.ErrNotFound
+	ErrInvalidGeofence  = geofence.ErrInvalid
+	ErrGeofenceStorage  = geofence.ErrStorage
+)
+
+// GeofenceService управляет геозонами пользователей. Все методы принимают
+// имя пользователя из токена: чужие геозоны не видны.
+type GeofenceService struct {
+	store          *geofence.Store
+	addressService *AddressService
+}
+
+func NewGeofenceService(store *geofence.Store, addressService *AddressService) *GeofenceService {
+	return &GeofenceService{store: store, addressService: addressService}
+}
+
+func (s *GeofenceService) List(owner string) *models.GeofenceListResponse {
+	return &models.GeofenceListResponse{Geofences: s.store.List(owner)}
+}
+
+func (s *GeofenceService) Get(owner, id string) (*models.Geofence, error) {
+	return s.store.Get(owner, id)
+}
+
+func (s *GeofenceService) Create(owner string, request models.GeofenceRequest) (*models.Geofence, error) {
+	return s.store.Create(owner, request)
+}
+
+func (s *GeofenceService) Update(owner, id string, request models.GeofenceRequest) (*models.Geofence, error) {
+	return s.store.Update(owner, id, request)
+}
+
+func (s *GeofenceService) Delete(owner, id string) error {
+	return s.store.Delete(owner, id)
+}
+
+// Revisions возвращает журнал изменений геозоны.
+func (s *GeofenceService) Revisions(owner, id string) (*models.RevisionListResponse, error) {
+	revisions, err := s.store.Revisions(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RevisionListResponse{Revisions: revisions}, nil
+}
+
+func (s *GeofenceService) Revision(owner, id string, number int) (*models.Revision, error) {
+	return s.store.Revision(owner, id, number)
+}
+
+// At возвращает геозону в том виде, какой она была в момент at.
+func (s *GeofenceService) At(owner, id string, at time.Time) (*models.Geofence, error) {
+	return s.store.At(owner, id, at)
+}
+
+// Revert возвращает геозону к ревизии. Для возврата к удалению результат nil.
+func (s *GeofenceService) Revert(owner, id string, request models.RevertRequest) (*models.Geofence, error) {
+	return s.store.Revert(owner, id, request.Revision)
+}
+
+// Check возвращает геозоны пользователя, содержащие точку или адрес.
+func (s *GeofenceService) Check(owner string, request models.GeofenceCheckRequest) (*models.GeofenceCheckResponse, error) {
+	point, err := s.addressService.locateWaypoint(models.Waypoint(request))
+	if err != nil {
+		return nil, err
+	}
+	return &models.GeofenceCheckResponse{Point: *point, Geofences: s.store.Containing(owner, point.Lat, point.Lon)}, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/geofence"
+	"geo-controller/proxy/internal/models"
+	"testing"
+)
+
+func TestGeofenceService_Check(t *testing.T) {
+	store, err := geofence.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	geofenceService := NewGeofenceService(store, newTestGeoService().addressService)
+	created, err := geofenceService.Create("alice", models.GeofenceRequest{
+		Name:     "Москва",
+		Geometry: models.Geometry{Type: "Polygon", Coordinates: []byte(`[[[37,55],[38,55],[38,56],[37,56],[37,55]]]`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := geofenceService.Check("alice", models.GeofenceCheckRequest{Address: "Москва"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Point != (models.GeoPoint{Lat: 55.7558, Lon: 37.6176}) || len(resp.Geofences) != 1 || resp.Geofences[0].ID != created.ID {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	resp, err = geofenceService.Check("bob", models.GeofenceCheckRequest{Address: "Москва"})
+	if err != nil || len(resp.Geofences) != 0 {
+		t.Errorf("expected no geofences for another user, got %+v (%v)", resp, err)
+	}
+	resp, err = geofenceService.Check("alice", models.GeofenceCheckRequest{Point: &models.GeoPoint{Lat: 59.9311, Lon: 30.3609}})
+	if err != nil || len(resp.Geofences) != 0 {
+		t.Errorf("expected no geofences outside, got %+v (%v)", resp, err)
+	}
+
+	if _, err := geofenceService.Check("alice", models.GeofenceCheckRequest{Address: "деревня бор"}); !errors.Is(err, ErrAddressNotFound) {
+		t.Errorf("expected ErrAddressNotFound, got %v", err)
+	}
+	if _, err := geofenceService.Check("alice", models.GeofenceCheckRequest{}); err == nil {
+		t.Error("expected error for empty request")
+	}
+}
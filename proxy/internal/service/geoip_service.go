@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"net"
+	"strconv"
+
+	"github.com/ekomobile/dadata/v2"
+	"github.com/ekomobile/dadata/v2/client"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// ErrIPNotFound возвращается, если ни один источник не знает местоположение IP.
+var ErrIPNotFound = errors.New("ip location not found")
+
+// IPLocator определяет приблизительное местоположение IP-адреса.
+type IPLocator interface {
+	LocateIP(ip net.IP) (*models.IPLocation, error)
+}
+
+type GeoIPService struct {
+	locators []IPLocator
+}
+
+// NewGeoIPService создает сервис, опрашивающий источники по порядку
+// до первого найденного местоположения.
+func NewGeoIPService(locators ...IPLocator) *GeoIPService {
+	return &GeoIPService{
+		locators: locators,
+	}
+}
+
+func (s *GeoIPService) Locate(ip string) (*models.IPLocation, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, errors.New("invalid ip address: " + ip)
+	}
+
+	var lastErr error = ErrIPNotFound
+	for _, locator := range s.locators {
+		location, err := locator.LocateIP(parsed)
+		if err != nil {
+			if !errors.Is(err, ErrIPNotFound) {
+				lastErr = err
+			}
+			continue
+		}
+		location.IP = parsed.String()
+		return location, nil
+	}
+
+	return nil, lastErr
+}
+
+// MaxMindLocator ищет IP в локальной базе формата MaxMind (GeoLite2/GeoIP2 City).
+type MaxMindLocator struct {
+	reader *maxminddb.Reader
+}
+
+func NewMaxMindLocator(path string) (*MaxMindLocator, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MaxMindLocator{reader: reader}, nil
+}
+
+type maxMindCity struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		Latitude       float64 `maxminddb:"latitude"`
+		Longitude      float64 `maxminddb:"longitude"`
+		AccuracyRadius uint16  `maxminddb:"accuracy_radius"`
+	} `maxminddb:"location"`
+}
+
+func (l *MaxMindLocator) LocateIP(ip net.IP) (*models.IPLocation, error) {
+	var record maxMindCity
+	_, ok, err := l.reader.LookupNetwork(ip, &record)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || (record.Location.Latitude == 0 && record.Location.Longitude == 0) {
+		return nil, ErrIPNotFound
+	}
+
+	location := &models.IPLocation{
+		Country:  localizedName(record.Country.Names),
+		City:     localizedName(record.City.Names),
+		GeoLat:   strconv.FormatFloat(record.Location.Latitude, 'f', -1, 64),
+		GeoLon:   strconv.FormatFloat(record.Location.Longitude, 'f', -1, 64),
+		Accuracy: int(record.Location.AccuracyRadius),
+		Source:   "maxmind",
+	}
+	if len(record.Subdivisions) > 0 {
+		location.Region = localizedName(record.Subdivisions[0].Names)
+	}
+	return location, nil
+}
+
+func (l *MaxMindLocator) Close() error {
+	return l.reader.Close()
+}
+
+// localizedName выбирает русское название, иначе английское.
+func localizedName(names map[string]string) string {
+	if name, ok := names["ru"]; ok {
+		return name
+	}
+	return names["en"]
+}
+
+// DaDataIPLocator использует метод iplocate/address DaData.
+type DaDataIPLocator struct {
+	daDataApiKey    string
+	daDataSecretKey string
+}
+
+func NewDaDataIPLocator(apiKey, secretKey string) *DaDataIPLocator {
+	return &DaDataIPLocator{
+		daDataApiKey:    apiKey,
+		daDataSecretKey: secretKey,
+	}
+}
+
+func (l *DaDataIPLocator) LocateIP(ip net.IP) (*models.IPLocation, error) {
+	creds := client.Credentials{
+		ApiKeyValue:    l.daDataApiKey,
+		SecretKeyValue: l.daDataSecretKey,
+	}
+
+	api := dadata.NewSuggestApi(client.WithCredentialProvider(&creds))
+
+	result, err := api.GeoIP(context.Background(), ip.String())
+	if err != nil {
+		return nil, err
+	}
+	if result.Location == nil || result.Location.Data == nil {
+		return nil, ErrIPNotFound
+	}
+
+	data := result.Location.Data
+	return &models.IPLocation{
+		Country: data.Country,
+		Region:  data.Region,
+		City:    data.City,
+		GeoLat:  data.GeoLat,
+		GeoLon:  data.GeoLon,
+		Source:  "dadata",
+	}, nil
+}
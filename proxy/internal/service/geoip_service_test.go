@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"net"
+	"testing"
+)
+
+type stubIPLocator struct {
+	location *models.IPLocation
+	err      error
+	calls    int
+}
+
+func (l *stubIPLocator) LocateIP(ip net.IP) (*models.IPLocation, error) {
+	l.calls++
+	if l.err != nil {
+		return nil, l.err
+	}
+	location := *l.location
+	return &location, nil
+}
+
+func TestGeoIPService_Locate_FallsBackToNextLocator(t *testing.T) {
+	local := &stubIPLocator{err: ErrIPNotFound}
+	remote := &stubIPLocator{location: &models.IPLocation{City: "Москва", GeoLat: "55.75", GeoLon: "37.61", Source: "dadata"}}
+	geoIPService := NewGeoIPService(local, remote)
+
+	location, err := geoIPService.Locate("203.0.113.7")
+	if err != nil {
+		t.Fatalf("Locate failed: %v", err)
+	}
+	if location.City != "Москва" || location.IP != "203.0.113.7" {
+		t.Errorf("unexpected location: %+v", location)
+	}
+	if local.calls != 1 || remote.calls != 1 {
+		t.Errorf("expected both locators to be called once, got %d and %d", local.calls, remote.calls)
+	}
+}
+
+func TestGeoIPService_Locate_StopsOnFirstMatch(t *testing.T) {
+	local := &stubIPLocator{location: &models.IPLocation{City: "Санкт-Петербург", Source: "maxmind"}}
+	remote := &stubIPLocator{location: &models.IPLocation{City: "Москва", Source: "dadata"}}
+	geoIPService := NewGeoIPService(local, remote)
+
+	location, err := geoIPService.Locate("203.0.113.7")
+	if err != nil {
+		t.Fatalf("Locate failed: %v", err)
+	}
+	if location.Source != "maxmind" || remote.calls != 0 {
+		t.Errorf("expected local result without remote call, got %+v (remote calls %d)", location, remote.calls)
+	}
+}
+
+func TestGeoIPService_Locate_Errors(t *testing.T) {
+	geoIPService := NewGeoIPService(&stubIPLocator{err: errors.New("upstream unavailable")})
+
+	if _, err := geoIPService.Locate("not-an-ip"); err == nil {
+		t.Error("Expected error for invalid ip, but got nil")
+	}
+
+	_, err := geoIPService.Locate("203.0.113.7")
+	if err == nil || err.Error() != "upstream unavailable" {
+		t.Errorf("expected upstream error, got %v", err)
+	}
+
+	_, err = NewGeoIPService().Locate("203.0.113.7")
+	if !errors.Is(err, ErrIPNotFound) {
+		t.Errorf("expected ErrIPNotFound without locators, got %v", err)
+	}
+}
+
+func TestNewMaxMindLocator_MissingFile(t *testing.T) {
+	if _, err := NewMaxMindLocator("testdata/missing.mmdb"); err == nil {
+		t.Error("Expected error for missing database file, but got nil")
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/layer"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"time"
+)
+
+const (
+	// defaultLayerFeatures и maxLayerFeatures — число объектов слоя в ответе
+	// по умолчанию и наибольшее.
+	defaultLayerFeatures = 1000
+	maxLayerFeatures     = 10000
+)
+
+// Ошибки слоев карты: ErrLayerNotFound — слоя или объекта нет либо слой
+// пользователю не виден, ErrLayerForbidden — слой виден, но прав
+// на изменение нет, ErrInvalidLayer — неверные поля, объекты или условия
+// поиска, ErrLayerStorage — изменение не удалось записать на диск.
+var (
+	ErrLayerNotFound  = layer.ErrNotFound
+	ErrLayerForbidden = layer.ErrForbidden
+	ErrInvalidLayer   = layer.ErrInvalid
+	ErrLayerStorage   = layer.ErrStorage
+)
+
+// LayerService управляет слоями карты и их объектами. Все методы принимают
+// имя пользователя из токена: права проверяет хранилище.
+type LayerService struct {
+	store          *layer.Store
+	addressService *AddressService
+}
+
+func NewLayerService(store *layer.Store, addressService *AddressService) *LayerService {
+	return &LayerService{store: store, addressService: addressService}
+}
+
+func (s *LayerService) Layers(user string) *models.LayerListResponse {
+	return &models.LayerListResponse{Layers: s.store.Layers(user)}
+}
+
+func (s *LayerService) Layer(user, id string) (*models.Layer, error) {
+	return s.store.Layer(user, id)
+}
+
+func (s *LayerService) CreateLayer(user string, request models.LayerRequest) (*models.Layer, error) {
+	return s.store.CreateLayer(user, request)
+}
+
+func (s *LayerService) UpdateLayer(user, id string, request models.LayerRequest) (*models.Layer, error) {
+	return s.store.UpdateLayer(user, id, request)
+}
+
+func (s *LayerService) DeleteLayer(user, id string) error {
+	return s.store.DeleteLayer(user, id)
+}
+
+func (s *LayerService) Feature(user, layerID, id string) (*models.Feature, error) {
+	return s.store.Feature(user, layerID, id)
+}
+
+// PutFeatures добавляет в слой Feature или FeatureCollection GeoJSON
+// и возвращает сохраненные объекты.
+func (s *LayerService) PutFeatures(user, layerID string, data []byte) (*models.FeatureCollection, error) {
+	features, err := layer.ParseFeatures(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(features) == 0 {
+		return nil, fmt.Errorf("%w: no features in request", ErrInvalidLayer)
+	}
+	saved, err := s.store.PutFeatures(user, layerID, features)
+	if err != nil {
+		return nil, err
+	}
+	return featureCollection(saved), nil
+}
+
+// UpdateFeature заменяет объект слоя одним Feature GeoJSON. Идентификатор
+// берется из пути, а не из тела запроса.
+func (s *LayerService) UpdateFeature(user, layerID, id string, data []byte) (*models.Feature, error) {
+	features, err := layer.ParseFeatures(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(features) != 1 {
+		return nil, fmt.Errorf("%w: expected a single feature", ErrInvalidLayer)
+	}
+	return s.store.UpdateFeature(user, layerID, id, features[0])
+}
+
+func (s *LayerService) DeleteFeature(user, layerID, id string) error {
+	return s.store.DeleteFeature(user, layerID, id)
+}
+
+// FeatureRevisions возвращает журнал изменений объекта слоя.
+func (s *LayerService) FeatureRevisions(user, layerID, id string) (*models.RevisionListResponse, error) {
+	revisions, err := s.store.FeatureRevisions(user, layerID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RevisionListResponse{Revisions: revisions}, nil
+}
+
+func (s *LayerService) FeatureRevision(user, layerID, id string, number int) (*models.Revision, error) {
+	return s.store.FeatureRevision(user, layerID, id, number)
+}
+
+// FeatureAt возвращает объект в том виде, какой он был в момент at.
+func (s *LayerService) FeatureAt(user, layerID, id string, at time.Time) (*models.Feature, error) {
+	return s.store.FeatureAt(user, layerID, id, at)
+}
+
+// RevertFeature возвращает объект к ревизии. Для возврата к удалению
+// результат nil.
+func (s *LayerService) RevertFeature(user, layerID, id string, request models.RevertRequest) (*models.Feature, error) {
+	return s.store.RevertFeature(user, layerID, id, request.Revision)
+}
+
+// Query возвращает объекты слоя, подходящие под условия запроса.
+func (s *LayerService) Query(user, layerID string, request models.LayerQueryRequest) (*models.FeatureCollection, error) {
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultLayerFeatures
+	}
+	if limit < 0 || limit > maxLayerFeatures {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidLayer, maxLayerFeatures)
+	}
+
+	query := layer.Query{Limit: limit}
+	if request.BBox != nil {
+		rects, err := bboxRects(*request.BBox)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLayer, err)
+		}
+		query.BBoxes = rects
+	}
+	var err error
+	if query.Intersects, err = decodeQueryGeometry("intersects", request.Intersects); err != nil {
+		return nil, err
+	}
+	if query.Within, err = decodeQueryGeometry("within", request.Within); err != nil {
+		return nil, err
+	}
+	if request.Nearest != nil {
+		point, err := s.addressService.locateWaypoint(*request.Nearest)
+		if err != nil {
+			return nil, err
+		}
+		query.Near = &geometry.Point{point.Lon, point.Lat}
+	}
+
+	features, err := s.store.Query(user, layerID, query)
+	if err != nil {
+		return nil, err
+	}
+	return featureCollection(features), nil
+}
+
+// bboxRects переводит область карты в прямоугольники индекса. Долготы
+// приводятся к [-180, 180]: Leaflet на мелком масштабе отдает область
+// шире мира или за пределами ±180. Область шириной 360° и больше — весь мир,
+// область, у которой западная граница восточнее восточной или которая
+// после приведения выходит за 180°, пересекает антимеридиан и делится
+// на две.
+func bboxRects(box models.BoundingBox) ([]spatial.Rect, error) {
+	if box.South > box.North || math.Abs(box.South) > 90 || math.Abs(box.North) > 90 ||
+		math.IsNaN(box.West) || math.IsInf(box.West, 0) || math.IsNaN(box.East) || math.IsInf(box.East, 0) {
+		return nil, fmt.Errorf("invalid bbox %g,%g,%g,%g", box.West, box.South, box.East, box.North)
+	}
+	span := box.East - box.West
+	if span < 0 {
+		span += 360
+	}
+	if span >= 360 {
+		return []spatial.Rect{{MinX: -180, MinY: box.South, MaxX: 180, MaxY: box.North}}, nil
+	}
+	west := box.West
+	if west < -180 || west > 180 {
+		west = math.Mod(west+180, 360)
+		if west < 0 {
+			west += 360
+		}
+		west -= 180
+	}
+	east := west + span
+	if east <= 180 {
+		return []spatial.Rect{{MinX: west, MinY: box.South, MaxX: east, MaxY: box.North}}, nil
+	}
+	return []spatial.Rect{
+		{MinX: west, MinY: box.South, MaxX: 180, MaxY: box.North},
+		{MinX: -180, MinY: box.South, MaxX: east - 360, MaxY: box.North},
+	}, nil
+}
+
+func decodeQueryGeometry(name string, g *models.Geometry) (*geometry.Geometry, error) {
+	if g == nil {
+		return nil, nil
+	}
+	decoded, err := geometry.Decode(*g)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidLayer, name, err)
+	}
+	return decoded, nil
+}
+
+func featureCollection(features []models.Feature) *models.FeatureCollection {
+	if features == nil {
+		features = []models.Feature{}
+	}
+	return &models.FeatureCollection{Type: layer.FeatureCollectionType, Features: features}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/layer"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLayerService_Query(t *testing.T) {
+	store, err := layer.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layerService := NewLayerService(store, newTestGeoService().addressService)
+	created, err := layerService.CreateLayer("alice", models.LayerRequest{Name: "Офисы", Viewers: []string{"bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = layerService.PutFeatures("alice", created.ID, []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[37.62,55.75]},"properties":{"name":"Москва"}},
+		{"type":"Feature","id":2,"geometry":{"type":"Point","coordinates":[30.36,59.93]},"properties":{"name":"Петербург"}},
+		{"type":"Feature","id":3,"geometry":{"type":"Point","coordinates":[-179.5,65]},"properties":{"name":"Чукотка"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := layerService.Query("bob", created.ID, models.LayerQueryRequest{Nearest: &models.Waypoint{Address: "Санкт-Петербург"}, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Type != "FeatureCollection" || len(resp.Features) != 2 || resp.Features[0].ID != "2" || resp.Features[1].ID != "1" {
+		t.Errorf("unexpected nearest features: %+v", resp)
+	}
+
+	// область через антимеридиан
+	resp, err = layerService.Query("alice", created.ID, models.LayerQueryRequest{BBox: &models.BoundingBox{South: 60, West: 170, North: 70, East: -170}})
+	if err != nil || len(resp.Features) != 1 || resp.Features[0].ID != "3" {
+		t.Errorf("expected feature across antimeridian, got %+v (%v)", resp, err)
+	}
+
+	resp, err = layerService.Query("alice", created.ID, models.LayerQueryRequest{Within: &models.Geometry{
+		Type: "Polygon", Coordinates: []byte(`[[[37,55],[38,55],[38,56],[37,56],[37,55]]]`)}})
+	if err != nil || len(resp.Features) != 1 || resp.Features[0].ID != "1" {
+		t.Errorf("expected feature within Moscow, got %+v (%v)", resp, err)
+	}
+
+	for _, request := range []models.LayerQueryRequest{
+		{Limit: -1},
+		{BBox: &models.BoundingBox{South: 10, North: 0}},
+		{Intersects: &models.Geometry{Type: "Circle"}},
+	} {
+		if _, err := layerService.Query("alice", created.ID, request); !errors.Is(err, ErrInvalidLayer) {
+			t.Errorf("%+v: expected ErrInvalidLayer, got %v", request, err)
+		}
+	}
+	if _, err := layerService.Query("alice", created.ID, models.LayerQueryRequest{Nearest: &models.Waypoint{Address: "деревня бор"}}); !errors.Is(err, ErrAddressNotFound) {
+		t.Errorf("expected ErrAddressNotFound, got %v", err)
+	}
+	if _, err := layerService.PutFeatures("bob", created.ID, []byte(`{"type":"FeatureCollection","features":[]}`)); !errors.Is(err, ErrInvalidLayer) {
+		t.Errorf("expected ErrInvalidLayer for empty collection, got %v", err)
+	}
+	if _, err := layerService.UpdateFeature("bob", created.ID, "1", []byte(`{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":{}}`)); !errors.Is(err, ErrLayerForbidden) {
+		t.Errorf("expected ErrLayerForbidden for viewer, got %v", err)
+	}
+}
+
+func TestBBoxRects(t *testing.T) {
+	world := []spatial.Rect{{MinX: -180, MinY: -80, MaxX: 180, MaxY: 80}}
+	testCases := []struct {
+		name     string
+		box      models.BoundingBox
+		expected []spatial.Rect
+	}{
+		{"plain", models.BoundingBox{West: 37, South: 55, East: 38, North: 56}, []spatial.Rect{{MinX: 37, MinY: 55, MaxX: 38, MaxY: 56}}},
+		{"across antimeridian", models.BoundingBox{West: 170, South: -10, East: -170, North: 10},
+			[]spatial.Rect{{MinX: 170, MinY: -10, MaxX: 180, MaxY: 10}, {MinX: -180, MinY: -10, MaxX: -170, MaxY: 10}}},
+		// так Leaflet отдает видимую область на мелком масштабе
+		{"wider than world", models.BoundingBox{West: -250, South: -80, East: 250, North: 80}, world},
+		{"exactly world", models.BoundingBox{West: -180, South: -80, East: 180, North: 80}, world},
+		{"east beyond 180", models.BoundingBox{West: 170, South: -10, East: 190, North: 10},
+			[]spatial.Rect{{MinX: 170, MinY: -10, MaxX: 180, MaxY: 10}, {MinX: -180, MinY: -10, MaxX: -170, MaxY: 10}}},
+		{"west beyond -180", models.BoundingBox{West: -190, South: -10, East: -170, North: 10},
+			[]spatial.Rect{{MinX: 170, MinY: -10, MaxX: 180, MaxY: 10}, {MinX: -180, MinY: -10, MaxX: -170, MaxY: 10}}},
+		{"wrapped copy of the world", models.BoundingBox{West: 397, South: 55, East: 398, North: 56}, []spatial.Rect{{MinX: 37, MinY: 55, MaxX: 38, MaxY: 56}}},
+	}
+	for _, tc := range testCases {
+		rects, err := bboxRects(tc.box)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(rects, tc.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tc.name, tc.expected, rects)
+		}
+	}
+
+	for _, box := range []models.BoundingBox{
+		{West: 37, South: 56, East: 38, North: 55},
+		{West: 37, South: -91, East: 38, North: 55},
+		{West: math.NaN(), South: 55, East: 38, North: 56},
+		{West: 37, South: 55, East: math.Inf(1), North: 56},
+	} {
+		if _, err := bboxRects(box); err == nil {
+			t.Errorf("%+v: expected error", box)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"encoding/json"
+	"geo-controller/proxy/internal/fuzzy"
+	"geo-controller/proxy/internal/geo"
+	"geo-controller/proxy/internal/models"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// localGeocodeRadius — радиус поиска ближайших адресов при геокодировании, в метрах.
+const localGeocodeRadius = 1000
+
+// LocalProvider ищет адреса в локальном нечетком индексе без обращения к внешним API.
+type LocalProvider struct {
+	index     *fuzzy.Index
+	addresses []*models.Address
+}
+
+func NewLocalProvider(addresses []*models.Address) *LocalProvider {
+	p := &LocalProvider{index: fuzzy.NewIndex()}
+	for _, addr := range addresses {
+		doc := fuzzy.Document{ID: len(p.addresses), Text: addr.Result}
+		if lat, lon, ok := addressPoint(addr); ok {
+			doc.Lat, doc.Lon, doc.HasGeo = lat, lon, true
+		}
+		p.index.Add(doc)
+		p.addresses = append(p.addresses, addr)
+	}
+	return p
+}
+
+// LoadLocalProvider читает адреса из JSON-файла со списком models.Address.
+func LoadLocalProvider(path string) (*LocalProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []*models.Address
+	if err := json.Unmarshal(data, &addresses); err != nil {
+		return nil, err
+	}
+	return NewLocalProvider(addresses), nil
+}
+
+func (p *LocalProvider) SearchAddress(request models.SearchRequest) ([]*models.Address, error) {
+	opts := fuzzy.SearchOptions{Limit: request.Limit}
+	if request.Location != nil {
+		opts.HasBias = true
+		opts.BiasLat = request.Location.Lat
+		opts.BiasLon = request.Location.Lon
+		opts.BiasScale = defaultBiasRadius
+	}
+
+	var addresses []*models.Address
+	for _, result := range p.index.Search(request.Query, opts) {
+		addr := *p.addresses[result.Document.ID]
+		for _, span := range result.Highlights {
+			addr.Highlights = append(addr.Highlights, models.Highlight{Offset: span.Offset, Length: span.Length})
+		}
+		addresses = append(addresses, &addr)
+	}
+	return addresses, nil
+}
+
+// DictionaryNames отдает строки проиндексированных адресов для словаря исправлений.
+func (p *LocalProvider) DictionaryNames() []string {
+	names := make([]string, len(p.addresses))
+	for i, addr := range p.addresses {
+		names[i] = addr.Result
+	}
+	return names
+}
+
+// Geocode возвращает проиндексированные адреса в радиусе localGeocodeRadius,
+// ближайшие первыми.
+func (p *LocalProvider) Geocode(request models.GeocodeRequest) (*models.GeocodeResponse, error) {
+	lat, err := strconv.ParseFloat(request.Lat, 64)
+	if err != nil {
+		return nil, err
+	}
+	lon, err := strconv.ParseFloat(request.Lng, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	type nearby struct {
+		addr     *models.Address
+		distance float64
+	}
+	var found []nearby
+	for _, addr := range p.addresses {
+		addrLat, addrLon, ok := addressPoint(addr)
+		if !ok {
+			continue
+		}
+		if distance := geo.Haversine(lat, lon, addrLat, addrLon); distance <= localGeocodeRadius {
+			found = append(found, nearby{addr: addr, distance: distance})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].distance < found[j].distance
+	})
+
+	geocodeResp := &models.GeocodeResponse{Suggestions: []*models.Suggestion{}}
+	for _, f := range found {
+		geocodeResp.Suggestions = append(geocodeResp.Suggestions, &models.Suggestion{
+			GeoLat: f.addr.GeoLat,
+			GeoLon: f.addr.GeoLon,
+			Value:  f.addr.Result,
+		})
+	}
+	return geocodeResp, nil
+}
+
+func addressPoint(addr *models.Address) (float64, float64, bool) {
+	lat, errLat := strconv.ParseFloat(addr.GeoLat, 64)
+	lon, errLon := strconv.ParseFloat(addr.GeoLon, 64)
+	if errLat != nil || errLon != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
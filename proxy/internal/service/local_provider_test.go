@@ -0,0 +1,91 @@
+package service
+
+import (
+	"geo-controller/proxy/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func localAddresses() []*models.Address {
+	return []*models.Address{
+		{Result: "г Москва, ул Тверская, д 7", Region: "Москва", Street: "Тверская", GeoLat: "55.7575", GeoLon: "37.6133"},
+		{Result: "г Москва, ул Ленина, д 1", Region: "Москва", Street: "Ленина", GeoLat: "55.7500", GeoLon: "37.6100"},
+		{Result: "г Санкт-Петербург, ул Ленина, д 1", Region: "Санкт-Петербург", Street: "Ленина", GeoLat: "59.9600", GeoLon: "30.3100"},
+		{Result: "г Казань, ул Ленина, д 12", Region: "Татарстан", Street: "Ленина"},
+	}
+}
+
+func TestLocalProvider_SearchAddress(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(NewLocalProvider(localAddresses())))
+
+	resp, err := addressService.SearchAddress(models.SearchRequest{Query: "Tverskya st"})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if len(resp.Addresses) != 1 || resp.Addresses[0].Street != "Тверская" {
+		t.Fatalf("expected Тверская for latin query with typo, got %+v", resp.Addresses)
+	}
+
+	addr := resp.Addresses[0]
+	if len(addr.Highlights) != 2 {
+		t.Fatalf("expected 2 highlights, got %+v", addr.Highlights)
+	}
+	text := []rune(addr.Result)
+	if got := string(text[addr.Highlights[0].Offset : addr.Highlights[0].Offset+addr.Highlights[0].Length]); got != "ул" {
+		t.Errorf("expected street type highlighted, got %q", got)
+	}
+}
+
+func TestLocalProvider_SearchAddress_Locality(t *testing.T) {
+	provider := NewLocalProvider(localAddresses())
+
+	addresses, err := provider.SearchAddress(models.SearchRequest{
+		Query:    "ленина",
+		Limit:    1,
+		Location: &models.GeoPoint{Lat: 59.94, Lon: 30.3},
+	})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if len(addresses) != 1 || addresses[0].Region != "Санкт-Петербург" {
+		t.Errorf("expected nearest Ленина in Saint Petersburg, got %+v", addresses)
+	}
+}
+
+func TestLocalProvider_Geocode(t *testing.T) {
+	provider := NewLocalProvider(localAddresses())
+
+	resp, err := provider.Geocode(models.GeocodeRequest{Lat: "55.7558", Lng: "37.6176"})
+	if err != nil {
+		t.Fatalf("Geocode failed: %v", err)
+	}
+	if len(resp.Suggestions) != 2 || resp.Suggestions[0].Value != "г Москва, ул Тверская, д 7" {
+		t.Errorf("unexpected geocode suggestions: %+v", resp.Suggestions)
+	}
+
+	if _, err := provider.Geocode(models.GeocodeRequest{Lat: "north", Lng: "37.6"}); err == nil {
+		t.Error("Expected error for invalid latitude, but got nil")
+	}
+}
+
+func TestLoadLocalProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addresses.json")
+	data := `[{"result": "г Казань, ул Баумана, д 1", "lat": "55.79", "lon": "49.11"}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := LoadLocalProvider(path)
+	if err != nil {
+		t.Fatalf("LoadLocalProvider failed: %v", err)
+	}
+	addresses, _ := provider.SearchAddress(models.SearchRequest{Query: "бауман"})
+	if len(addresses) != 1 {
+		t.Errorf("expected 1 address, got %d", len(addresses))
+	}
+
+	if _, err := LoadLocalProvider(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file, but got nil")
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/translit"
+	"unicode/utf8"
+)
+
+// resolveLanguage проверяет язык и схему транслитерации запроса.
+// По умолчанию используется ICAO.
+func resolveLanguage(language, transliteration string) (translit.Scheme, error) {
+	switch language {
+	case "", models.LanguageRussian, models.LanguageEnglish:
+	default:
+		return "", errors.New("unsupported language: " + language)
+	}
+
+	switch transliteration {
+	case "", models.TransliterationICAO:
+		return translit.ICAO, nil
+	case models.TransliterationGOST:
+		return translit.GOST, nil
+	}
+	return "", errors.New("unsupported transliteration: " + transliteration)
+}
+
+// transliterateAddress переводит в латиницу текстовые поля адреса, в которых
+// осталась кириллица: провайдер мог не поддерживать английский или вернуть
+// на нем не все поля. Highlights пересчитываются под новый Result.
+func transliterateAddress(addr *models.Address, scheme translit.Scheme) {
+	if translit.HasCyrillic(addr.Result) {
+		addr.Highlights = remapHighlights(addr.Result, addr.Highlights, scheme)
+	}
+
+	fields := []*string{
+		&addr.Result, &addr.Country, &addr.Region, &addr.RegionType,
+		&addr.Area, &addr.AreaType, &addr.City, &addr.CityType,
+		&addr.Settlement, &addr.SettlementType, &addr.Street, &addr.StreetType,
+		&addr.House, &addr.HouseType, &addr.Block, &addr.BlockType,
+		&addr.Flat, &addr.FlatType,
+	}
+	for _, field := range fields {
+		if translit.HasCyrillic(*field) {
+			*field = translit.Transliterate(*field, scheme)
+		}
+	}
+}
+
+// remapHighlights переводит смещения фрагментов исходной строки в смещения
+// в ее транслитерации: одна буква кириллицы может стать несколькими латинскими.
+func remapHighlights(result string, highlights []models.Highlight, scheme translit.Scheme) []models.Highlight {
+	if len(highlights) == 0 {
+		return highlights
+	}
+
+	runes := []rune(result)
+	offset := func(i int) int {
+		if i > len(runes) {
+			i = len(runes)
+		}
+		return utf8.RuneCountInString(translit.Transliterate(string(runes[:i]), scheme))
+	}
+
+	remapped := make([]models.Highlight, len(highlights))
+	for i, h := range highlights {
+		start := offset(h.Offset)
+		remapped[i] = models.Highlight{Offset: start, Length: offset(h.Offset+h.Length) - start}
+	}
+	return remapped
+}
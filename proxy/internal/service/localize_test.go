@@ -0,0 +1,29 @@
+package service
+
+import (
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/translit"
+	"reflect"
+	"testing"
+)
+
+func TestTransliterateAddress_Highlights(t *testing.T) {
+	addr := &models.Address{
+		Result:     "Казань, Щорса",
+		Highlights: []models.Highlight{{Offset: 8, Length: 5}, {Offset: 0, Length: 6}},
+	}
+
+	transliterateAddress(addr, translit.ICAO)
+
+	if addr.Result != "Kazan, Shchorsa" {
+		t.Fatalf("unexpected result %q", addr.Result)
+	}
+	expected := []models.Highlight{{Offset: 7, Length: 8}, {Offset: 0, Length: 5}}
+	if !reflect.DeepEqual(addr.Highlights, expected) {
+		t.Errorf("expected %+v, got %+v", expected, addr.Highlights)
+	}
+	runes := []rune(addr.Result)
+	if got := string(runes[7:15]); got != "Shchorsa" {
+		t.Errorf("highlight points to %q", got)
+	}
+}
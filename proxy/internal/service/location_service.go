@@ -0,0 +1,209 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/locator"
+	"geo-controller/proxy/internal/models"
+	"time"
+)
+
+const (
+	// defaultNearestLocations и maxNearestLocations — число ближайших точек
+	// по умолчанию и наибольшее.
+	defaultNearestLocations = 10
+	maxNearestLocations     = 100
+
+	// maxClusterZoom — наибольший масштаб карты для кластеров, как в Leaflet.
+	maxClusterZoom = 22
+	// maxLocationClusters ограничивает число кластеров в ответе.
+	maxLocationClusters = 5000
+)
+
+// Форматы загрузки точек для ImportLocations.
+const (
+	LocationFormatCSV     = "csv"
+	LocationFormatGeoJSON = "geojson"
+)
+
+// Ошибки реестра точек: ErrLocationNotFound — точки нет, ErrInvalidLocation —
+// неверные поля или файл загрузки, ErrLocationForbidden — точка
+// принадлежит другому пользователю, ErrLocationStorage — изменение
+// не удалось записать на диск.
+var (
+	ErrLocationNotFound  = locator.ErrNotFound
+	ErrInvalidLocation   = locator.ErrInvalid
+	ErrLocationForbidden = locator.ErrForbidden
+	ErrLocationStorage   = locator.ErrStorage
+)
+
+// LocationService управляет реестром магазинов и пунктов выдачи и ищет
+// ближайшие к адресу. Реестр общий для всех пользователей.
+type LocationService struct {
+	registry       *locator.Registry
+	addressService *AddressService
+	now            func() time.Time
+}
+
+func NewLocationService(registry *locator.Registry, addressService *AddressService) *LocationService {
+	return &LocationService{registry: registry, addressService: addressService, now: time.Now}
+}
+
+func (s *LocationService) Get(id string) (*models.Location, error) {
+	return s.registry.Get(id)
+}
+
+// Create добавляет точку. author — пользователь, от имени которого
+// изменение записывается в журнал.
+func (s *LocationService) Create(author string, request models.LocationRequest) (*models.Location, error) {
+	location, err := s.location(request)
+	if err != nil {
+		return nil, err
+	}
+	return s.registry.Create(author, *location)
+}
+
+func (s *LocationService) Update(author, id string, request models.LocationRequest) (*models.Location, error) {
+	location, err := s.location(request)
+	if err != nil {
+		return nil, err
+	}
+	return s.registry.Update(author, id, *location)
+}
+
+func (s *LocationService) Delete(author, id string) error {
+	return s.registry.Delete(author, id)
+}
+
+// Revisions возвращает журнал изменений точки.
+func (s *LocationService) Revisions(id string) (*models.RevisionListResponse, error) {
+	revisions, err := s.registry.Revisions(id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RevisionListResponse{Revisions: revisions}, nil
+}
+
+func (s *LocationService) Revision(id string, number int) (*models.Revision, error) {
+	return s.registry.Revision(id, number)
+}
+
+// At возвращает точку в том виде, какой она была в момент at.
+func (s *LocationService) At(id string, at time.Time) (*models.Location, error) {
+	return s.registry.At(id, at)
+}
+
+// Revert возвращает точку к ревизии. Для возврата к удалению результат nil.
+func (s *LocationService) Revert(author, id string, request models.RevertRequest) (*models.Location, error) {
+	return s.registry.Revert(author, id, request.Revision)
+}
+
+// ImportLocations загружает точки из CSV или GeoJSON. Координаты в файле
+// обязательны: геокодировать тысячи адресов при загрузке слишком долго.
+func (s *LocationService) ImportLocations(author, format string, data []byte) (*models.LocationImportResponse, error) {
+	var locations []models.Location
+	var err error
+	switch format {
+	case LocationFormatCSV:
+		locations, err = locator.ParseCSV(data)
+	case LocationFormatGeoJSON:
+		locations, err = locator.ParseGeoJSON(data)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidLocation, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("%w: no locations in file", ErrInvalidLocation)
+	}
+	for i := range locations {
+		s.addTimezone(&locations[i])
+	}
+
+	created, updated, err := s.registry.Import(author, locations)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LocationImportResponse{Created: created, Updated: updated}, nil
+}
+
+// Nearest возвращает ближайшие к точке или адресу точки сети.
+func (s *LocationService) Nearest(request models.NearestLocationsRequest) (*models.NearestLocationsResponse, error) {
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultNearestLocations
+	}
+	if limit < 0 || limit > maxNearestLocations {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxNearestLocations)
+	}
+	if request.MaxDistance < 0 {
+		return nil, errors.New("max_distance cannot be negative")
+	}
+	point, err := s.addressService.locateWaypoint(models.Waypoint{Point: request.Point, Address: request.Address})
+	if err != nil {
+		return nil, err
+	}
+
+	query := locator.Query{Lat: point.Lat, Lon: point.Lon, Limit: limit, MaxDistance: request.MaxDistance, Tags: request.Tags}
+	if request.OpenAt != nil {
+		query.OpenAt = *request.OpenAt
+	} else if request.OpenNow {
+		query.OpenAt = s.now()
+	}
+	return &models.NearestLocationsResponse{Point: *point, Locations: s.registry.Nearest(query)}, nil
+}
+
+// Clusters возвращает кластеры точек в видимой области карты на масштабе
+// zoom. Если кластеров больше maxLocationClusters, возвращаются самые
+// крупные.
+func (s *LocationService) Clusters(box models.BoundingBox, zoom int) (*models.LocationClustersResponse, error) {
+	if zoom < 0 || zoom > maxClusterZoom {
+		return nil, fmt.Errorf("%w: zoom must be between 0 and %d", ErrInvalidLocation, maxClusterZoom)
+	}
+	rects, err := bboxRects(box)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLocation, err)
+	}
+
+	clusters := s.registry.Clusters(zoom, rects)
+	response := &models.LocationClustersResponse{Zoom: zoom, Clusters: clusters}
+	if len(clusters) > maxLocationClusters {
+		response.Clusters, response.Truncated = clusters[:maxLocationClusters], true
+	}
+	return response, nil
+}
+
+// location собирает точку из запроса, геокодируя адрес, если координаты
+// не заданы.
+func (s *LocationService) location(request models.LocationRequest) (*models.Location, error) {
+	location := &models.Location{
+		Name:       request.Name,
+		Address:    request.Address,
+		Tags:       request.Tags,
+		Hours:      request.Hours,
+		Timezone:   request.Timezone,
+		Properties: request.Properties,
+	}
+	if request.Point == nil && request.Address == "" {
+		return nil, fmt.Errorf("%w: point or address is required", ErrInvalidLocation)
+	}
+	point, err := s.addressService.locateWaypoint(models.Waypoint{Point: request.Point, Address: request.Address})
+	if err != nil {
+		return nil, err
+	}
+	location.Point = *point
+	s.addTimezone(location)
+	return location, nil
+}
+
+// addTimezone определяет пояс часов работы по границам поясов, если он
+// не задан и границы загружены.
+func (s *LocationService) addTimezone(location *models.Location) {
+	if location.Timezone != "" || location.Hours == "" || s.addressService.timezones == nil {
+		return
+	}
+	if zone, ok := s.addressService.timezones.Lookup(location.Point.Lat, location.Point.Lon); ok {
+		location.Timezone = zone.ID
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/locator"
+	"geo-controller/proxy/internal/models"
+	"testing"
+	"time"
+)
+
+func newTestLocationService(t *testing.T) *LocationService {
+	t.Helper()
+	registry, err := locator.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := newTestGeoService().addressService.provider
+	return NewLocationService(registry, NewAddressService("", "", WithProvider(provider), WithTimezones(testTimezones(t))))
+}
+
+func TestLocationService(t *testing.T) {
+	locationService := newTestLocationService(t)
+
+	created, err := locationService.Create("admin", models.LocationRequest{Name: "Центр", Address: "Москва", Hours: "Mo-Su 10:00-22:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Point != (models.GeoPoint{Lat: 55.7558, Lon: 37.6176}) || created.Timezone != "Europe/Moscow" {
+		t.Errorf("expected geocoded point and timezone, got %+v", created)
+	}
+	if _, err := locationService.Create("admin", models.LocationRequest{Name: "Бор", Address: "деревня бор"}); !errors.Is(err, ErrAddressNotFound) {
+		t.Errorf("expected ErrAddressNotFound, got %v", err)
+	}
+	if _, err := locationService.Create("admin", models.LocationRequest{Name: "Пусто"}); !errors.Is(err, ErrInvalidLocation) {
+		t.Errorf("expected ErrInvalidLocation, got %v", err)
+	}
+
+	imported, err := locationService.ImportLocations("admin", LocationFormatCSV, []byte("name,lat,lon,tags\nПитер,59.93,30.36,pickup\nТверь,56.86,35.9,pickup\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Created != 2 {
+		t.Errorf("expected 2 created, got %+v", imported)
+	}
+	if _, err := locationService.ImportLocations("admin", "xml", nil); !errors.Is(err, ErrInvalidLocation) {
+		t.Errorf("expected ErrInvalidLocation, got %v", err)
+	}
+	if _, err := locationService.ImportLocations("admin", LocationFormatGeoJSON, []byte(`{"type":"FeatureCollection","features":[]}`)); !errors.Is(err, ErrInvalidLocation) {
+		t.Errorf("expected ErrInvalidLocation for empty file, got %v", err)
+	}
+
+	resp, err := locationService.Nearest(models.NearestLocationsRequest{Address: "Москва", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Locations) != 2 || resp.Locations[0].Location.ID != created.ID || resp.Locations[0].Distance != 0 ||
+		resp.Locations[1].Location.Name != "Тверь" {
+		t.Errorf("unexpected nearest locations: %+v", resp)
+	}
+
+	resp, err = locationService.Nearest(models.NearestLocationsRequest{Address: "Москва", Tags: []string{"pickup"}})
+	if err != nil || len(resp.Locations) != 2 || resp.Locations[0].Location.Name != "Тверь" {
+		t.Errorf("expected pickup locations from Tver, got %+v (%v)", resp, err)
+	}
+
+	// 20:00 UTC — 23:00 по Москве, магазин закрыт
+	locationService.now = func() time.Time { return time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC) }
+	resp, err = locationService.Nearest(models.NearestLocationsRequest{Address: "Москва", OpenNow: true})
+	if err != nil || len(resp.Locations) != 0 {
+		t.Errorf("expected no open locations, got %+v (%v)", resp, err)
+	}
+	at := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	resp, err = locationService.Nearest(models.NearestLocationsRequest{Address: "Москва", OpenAt: &at})
+	if err != nil || len(resp.Locations) != 1 {
+		t.Errorf("expected one open location, got %+v (%v)", resp, err)
+	}
+
+	for _, request := range []models.NearestLocationsRequest{
+		{Address: "Москва", Limit: maxNearestLocations + 1},
+		{Address: "Москва", MaxDistance: -1},
+		{},
+	} {
+		if _, err := locationService.Nearest(request); err == nil {
+			t.Errorf("%+v: expected error", request)
+		}
+	}
+}
+
+func TestLocationService_Clusters(t *testing.T) {
+	locationService := newTestLocationService(t)
+	_, err := locationService.ImportLocations("admin", LocationFormatCSV, []byte("name,lat,lon\nТверская,55.76,37.61\nАрбат,55.75,37.59\nПитер,59.93,30.36\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := locationService.Clusters(models.BoundingBox{West: 20, South: 50, East: 40, North: 65}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, c := range resp.Clusters {
+		total += c.Count
+	}
+	if resp.Zoom != 3 || total != 3 || resp.Truncated {
+		t.Errorf("unexpected clusters: %+v", resp)
+	}
+
+	// на мелком масштабе Leaflet отдает долготы за пределами ±180
+	resp, err = locationService.Clusters(models.BoundingBox{West: -250, South: -80, East: 250, North: 80}, 1)
+	if err != nil || len(resp.Clusters) == 0 {
+		t.Errorf("expected clusters for a bbox wider than the world, got %+v (%v)", resp, err)
+	}
+
+	resp, err = locationService.Clusters(models.BoundingBox{West: 37, South: 55, East: 38, North: 56}, 18)
+	if err != nil || len(resp.Clusters) != 2 || resp.Clusters[0].Location == nil {
+		t.Errorf("expected two separate Moscow locations, got %+v (%v)", resp, err)
+	}
+
+	for _, tc := range []struct {
+		box  models.BoundingBox
+		zoom int
+	}{
+		{models.BoundingBox{West: 37, South: 55, East: 38, North: 56}, -1},
+		{models.BoundingBox{West: 37, South: 55, East: 38, North: 56}, maxClusterZoom + 1},
+		{models.BoundingBox{West: 37, South: 56, East: 38, North: 55}, 10},
+	} {
+		if _, err := locationService.Clusters(tc.box, tc.zoom); !errors.Is(err, ErrInvalidLocation) {
+			t.Errorf("%+v, zoom %d: expected ErrInvalidLocation, got %v", tc.box, tc.zoom, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultSearchLimit — размер страницы, если limit не указан.
+	defaultSearchLimit = 10
+	// maxSearchResults — окно выдачи, которое запрашивается у провайдера.
+	// Больше 20 подсказок DaData не отдает, поэтому все страницы
+	// и сортировки строятся внутри этого окна.
+	maxSearchResults = 20
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// searchCursor — содержимое непрозрачного курсора пагинации.
+type searchCursor struct {
+	Offset int    `json:"o"`
+	Query  uint32 `json:"q"`
+}
+
+// searchPage — разобранные параметры страницы.
+type searchPage struct {
+	limit  int
+	offset int
+	sort   string
+	query  uint32
+}
+
+// parseSearchPage разбирает размер страницы и сортировку. Курсор проверяется
+// отдельно в bind, когда известна точка смещения.
+func parseSearchPage(request models.SearchRequest) (searchPage, error) {
+	page := searchPage{
+		limit: request.Limit,
+		sort:  request.Sort,
+	}
+
+	if page.limit < 0 {
+		return page, errors.New("limit cannot be negative")
+	}
+	if page.limit == 0 {
+		page.limit = defaultSearchLimit
+	}
+	if page.limit > maxSearchResults {
+		page.limit = maxSearchResults
+	}
+
+	switch page.sort {
+	case "":
+		page.sort = models.SortRelevance
+	case models.SortRelevance, models.SortDistance, models.SortAlphabetical:
+	default:
+		return page, fmt.Errorf("unknown sort %q", page.sort)
+	}
+
+	return page, nil
+}
+
+// bind связывает страницу с запросом и точкой смещения и разбирает курсор.
+func (p *searchPage) bind(request models.SearchRequest, bias *models.GeoPoint) error {
+	p.query = queryFingerprint(request, bias)
+	if request.Cursor == "" {
+		return nil
+	}
+	offset, err := decodeCursor(request.Cursor, p.query)
+	if err != nil {
+		return err
+	}
+	p.offset = offset
+	return nil
+}
+
+// apply сортирует окно выдачи и возвращает страницу с курсором на следующую.
+func (p searchPage) apply(addresses []*models.Address, resp *models.SearchResponse) {
+	sortAddresses(addresses, p.sort)
+
+	resp.TotalEstimate = len(addresses)
+	if p.offset >= len(addresses) {
+		resp.Addresses = []*models.Address{}
+		return
+	}
+
+	end := p.offset + p.limit
+	if end < len(addresses) {
+		resp.NextCursor = encodeCursor(searchCursor{Offset: end, Query: p.query})
+	} else {
+		end = len(addresses)
+	}
+	resp.Addresses = addresses[p.offset:end]
+}
+
+func sortAddresses(addresses []*models.Address, by string) {
+	switch by {
+	case models.SortDistance:
+		sort.SliceStable(addresses, func(i, j int) bool {
+			a, b := addresses[i].Distance, addresses[j].Distance
+			if a == nil || b == nil {
+				return a != nil
+			}
+			return *a < *b
+		})
+	case models.SortAlphabetical:
+		sort.SliceStable(addresses, func(i, j int) bool {
+			return strings.ToLower(addresses[i].Result) < strings.ToLower(addresses[j].Result)
+		})
+	}
+}
+
+// queryFingerprint связывает курсор с запросом, языком и точкой смещения,
+// чтобы курсор от одного поиска нельзя было применить к другому: от них
+// зависят и окно провайдера, и порядок выдачи.
+func queryFingerprint(request models.SearchRequest, bias *models.GeoPoint) uint32 {
+	language := request.Language
+	if language == "" {
+		language = models.LanguageRussian
+	}
+	h := fnv.New32a()
+	h.Write([]byte(request.Query))
+	h.Write([]byte{0})
+	h.Write([]byte(request.Sort))
+	h.Write([]byte{0})
+	h.Write([]byte(language))
+	h.Write([]byte{0})
+	h.Write([]byte(request.Transliteration))
+	if bias != nil {
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.FormatFloat(bias.Lat, 'g', -1, 64)))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.FormatFloat(bias.Lon, 'g', -1, 64)))
+	}
+	return h.Sum32()
+}
+
+func encodeCursor(cursor searchCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string, query uint32) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	var cursor searchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return 0, errInvalidCursor
+	}
+	if cursor.Query != query {
+		return 0, errors.New("cursor does not match query")
+	}
+	return cursor.Offset, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"geo-controller/proxy/internal/models"
+	"testing"
+)
+
+func numberedAddresses(n int) []*models.Address {
+	var addresses []*models.Address
+	for i := 0; i < n; i++ {
+		addresses = append(addresses, &models.Address{Result: fmt.Sprintf("ул Ленина, д %02d", i)})
+	}
+	return addresses
+}
+
+func TestAddressService_SearchAddress_Pagination(t *testing.T) {
+	provider := &stubProvider{addresses: numberedAddresses(15)}
+	addressService := NewAddressService("", "", WithProvider(provider))
+
+	request := models.SearchRequest{Query: "Ленина", Limit: 6}
+	var seen []string
+	for page := 0; page < 5; page++ {
+		resp, err := addressService.SearchAddress(request)
+		if err != nil {
+			t.Fatalf("SearchAddress failed: %v", err)
+		}
+		if resp.TotalEstimate != 15 {
+			t.Errorf("expected total estimate 15, got %d", resp.TotalEstimate)
+		}
+		for _, addr := range resp.Addresses {
+			seen = append(seen, addr.Result)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		request.Cursor = resp.NextCursor
+	}
+
+	if len(seen) != 15 {
+		t.Fatalf("expected 15 addresses across pages, got %d", len(seen))
+	}
+	if seen[0] != "ул Ленина, д 00" || seen[14] != "ул Ленина, д 14" {
+		t.Errorf("unexpected order across pages: first %s, last %s", seen[0], seen[14])
+	}
+	if provider.last.Limit != maxSearchResults {
+		t.Errorf("expected provider limit %d, got %d", maxSearchResults, provider.last.Limit)
+	}
+}
+
+func TestAddressService_SearchAddress_Sort(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(&stubProvider{addresses: leninStreets()}))
+
+	resp, err := addressService.SearchAddress(models.SearchRequest{Query: "Ленина", Sort: models.SortAlphabetical})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	expected := []string{"г Москва, ул Ленина", "г Новосибирск, ул Ленина", "г Санкт-Петербург, ул Ленина", "ул Ленина"}
+	for i, addr := range resp.Addresses {
+		if addr.Result != expected[i] {
+			t.Errorf("alphabetical position %d: expected %s, got %s", i, expected[i], addr.Result)
+		}
+	}
+
+	resp, err = addressService.SearchAddress(models.SearchRequest{
+		Query:    "Ленина",
+		Sort:     models.SortDistance,
+		Location: &models.GeoPoint{Lat: 55.79, Lon: 49.12},
+	})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	expected = []string{"г Москва, ул Ленина", "г Санкт-Петербург, ул Ленина", "г Новосибирск, ул Ленина", "ул Ленина"}
+	for i, addr := range resp.Addresses {
+		if addr.Result != expected[i] {
+			t.Errorf("distance position %d: expected %s, got %s", i, expected[i], addr.Result)
+		}
+	}
+}
+
+func TestAddressService_SearchAddress_InvalidPage(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(&stubProvider{addresses: numberedAddresses(15)}))
+
+	first, err := addressService.SearchAddress(models.SearchRequest{Query: "Ленина", Limit: 5})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	moscow := &models.GeoPoint{Lat: 55.75, Lon: 37.62}
+	biased, err := addressService.SearchAddress(models.SearchRequest{Query: "Ленина", Limit: 5, Location: moscow})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if _, err := addressService.SearchAddress(models.SearchRequest{Query: "Ленина", Limit: 5, Location: moscow, Cursor: biased.NextCursor}); err != nil {
+		t.Errorf("cursor with the same location rejected: %v", err)
+	}
+	english, err := addressService.SearchAddress(models.SearchRequest{Query: "Ленина", Limit: 5, Language: models.LanguageEnglish})
+	if err != nil {
+		t.Fatalf("SearchAddress failed: %v", err)
+	}
+	if _, err := addressService.SearchAddress(models.SearchRequest{Query: "Ленина", Limit: 5, Cursor: first.NextCursor,
+		Language: models.LanguageRussian}); err != nil {
+		t.Errorf("cursor with the default language rejected for ru: %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		request models.SearchRequest
+	}{
+		{name: "negative limit", request: models.SearchRequest{Query: "Ленина", Limit: -1}},
+		{name: "unknown sort", request: models.SearchRequest{Query: "Ленина", Sort: "random"}},
+		{name: "distance without bias", request: models.SearchRequest{Query: "Ленина", Sort: models.SortDistance}},
+		{name: "garbage cursor", request: models.SearchRequest{Query: "Ленина", Cursor: "!!!"}},
+		{name: "cursor from another query", request: models.SearchRequest{Query: "Тверская", Cursor: first.NextCursor}},
+		{name: "cursor from another location", request: models.SearchRequest{Query: "Ленина", Cursor: biased.NextCursor,
+			Location: &models.GeoPoint{Lat: 59.93, Lon: 30.36}}},
+		{name: "cursor from another viewport", request: models.SearchRequest{Query: "Ленина", Cursor: biased.NextCursor,
+			Viewport: &models.BoundingBox{South: 59, West: 30, North: 60, East: 31}}},
+		{name: "biased cursor without bias", request: models.SearchRequest{Query: "Ленина", Cursor: biased.NextCursor}},
+		{name: "cursor from another language", request: models.SearchRequest{Query: "Ленина", Cursor: english.NextCursor,
+			Language: models.LanguageRussian}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := addressService.SearchAddress(tc.request); err == nil {
+				t.Error("Expected error, but got nil")
+			}
+		})
+	}
+}
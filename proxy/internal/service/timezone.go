@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/timezone"
+	"time"
+)
+
+// ErrTimezonesUnavailable возвращается, если границы часовых поясов не загружены.
+var ErrTimezonesUnavailable = errors.New("timezone boundaries are not loaded")
+
+// Timezone возвращает часовой пояс точки и его смещение от UTC на момент at.
+// Для точки вне границ поясов, например в открытом море, возвращается
+// морской пояс по долготе.
+func (s *AddressService) Timezone(point models.GeoPoint, at time.Time) (*models.TimezoneResponse, error) {
+	if s.timezones == nil {
+		return nil, ErrTimezonesUnavailable
+	}
+	resp := &models.TimezoneResponse{Point: point}
+	location := timezone.Nautical(point.Lon)
+	if zone, ok := s.timezones.Lookup(point.Lat, point.Lon); ok {
+		location = zone.Location
+	} else {
+		resp.Nautical = true
+	}
+	resp.Timezone = location.String()
+	resp.Abbreviation, resp.Offset = timezone.Offset(location, at)
+	resp.UTCOffset = timezone.FormatOffset(resp.Offset)
+	return resp, nil
+}
+
+// addTimezones заполняет часовой пояс адресов с координатами. Морской пояс
+// для адресов не подставляется: точка на суше вне границ означает пробел
+// в данных, и пояс остается пустым.
+func (s *AddressService) addTimezones(addresses []*models.Address) {
+	for _, addr := range addresses {
+		lat, lon, ok := addressPoint(addr)
+		if !ok {
+			continue
+		}
+		if zone, ok := s.timezones.Lookup(lat, lon); ok {
+			addr.Timezone = zone.ID
+		}
+	}
+}
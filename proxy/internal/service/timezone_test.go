@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/models"
+	"geo-controller/proxy/internal/timezone"
+	"testing"
+	"time"
+)
+
+func testTimezones(t *testing.T) *timezone.Index {
+	t.Helper()
+	g, err := geometry.Decode(models.Geometry{Type: "Polygon", Coordinates: []byte(`[[[37,55],[38,55],[38,56],[37,56],[37,55]]]`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone, err := timezone.NewZone("Europe/Moscow", g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return timezone.NewIndex([]*timezone.Zone{zone})
+}
+
+func TestAddressService_Timezone(t *testing.T) {
+	at := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	addressService := NewAddressService("", "")
+	if _, err := addressService.Timezone(models.GeoPoint{Lat: 55.75, Lon: 37.62}, at); !errors.Is(err, ErrTimezonesUnavailable) {
+		t.Errorf("expected ErrTimezonesUnavailable, got %v", err)
+	}
+
+	addressService = NewAddressService("", "", WithTimezones(testTimezones(t)))
+	resp, err := addressService.Timezone(models.GeoPoint{Lat: 55.75, Lon: 37.62}, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Timezone != "Europe/Moscow" || resp.Offset != 10800 || resp.UTCOffset != "+03:00" || resp.Nautical {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	// открытое море вне границ поясов
+	resp, err = addressService.Timezone(models.GeoPoint{Lat: 40, Lon: -150}, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Timezone != "Etc/GMT+10" || resp.Offset != -36000 || resp.UTCOffset != "-10:00" || !resp.Nautical {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestAddressService_SearchAddress_Timezone(t *testing.T) {
+	provider := &stubProvider{addresses: []*models.Address{
+		{Result: "г Москва, ул Тверская", GeoLat: "55.7558", GeoLon: "37.6176"},
+		{Result: "г Владивосток", GeoLat: "43.1155", GeoLon: "131.8855"},
+		{Result: "д Бор"},
+	}}
+
+	addressService := NewAddressService("", "", WithProvider(provider))
+	if _, err := addressService.SearchAddress(models.SearchRequest{Query: "москва", Timezone: true}); !errors.Is(err, ErrTimezonesUnavailable) {
+		t.Errorf("expected ErrTimezonesUnavailable, got %v", err)
+	}
+
+	addressService = NewAddressService("", "", WithProvider(provider), WithTimezones(testTimezones(t)))
+	resp, err := addressService.SearchAddress(models.SearchRequest{Query: "москва"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Addresses[0].Timezone != "" {
+		t.Errorf("timezone must be empty unless requested, got %q", resp.Addresses[0].Timezone)
+	}
+
+	resp, err = addressService.SearchAddress(models.SearchRequest{Query: "москва", Timezone: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// вне границ поясов и без координат пояс не заполняется
+	expected := []string{"Europe/Moscow", "", ""}
+	for i, addr := range resp.Addresses {
+		if addr.Timezone != expected[i] {
+			t.Errorf("%s: expected timezone %q, got %q", addr.Result, expected[i], addr.Timezone)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"geo-controller/proxy/internal/formatter"
+	"geo-controller/proxy/internal/matcher"
+	"geo-controller/proxy/internal/models"
+)
+
+// notFoundReasons — ключевые компоненты в порядке от общего к частному
+// и причины, если компонент не нашелся у провайдера.
+var notFoundReasons = []struct {
+	component string
+	reason    string
+}{
+	{"city", models.ReasonCityNotFound},
+	{"street", models.ReasonStreetNotFound},
+	{"house", models.ReasonHouseNotFound},
+}
+
+// candidate — адрес провайдера, сравненный с проверяемым.
+type candidate struct {
+	address    *models.Address
+	profile    *matcher.Profile
+	comparison models.CompareResponse
+}
+
+// ValidateAddress проверяет существование адреса: ищет его у провайдера
+// и сравнивает с лучшим найденным вариантом. Если адрес неверен или
+// сомнителен, найденный вариант возвращается как исправление.
+func (s *AddressService) ValidateAddress(request models.ValidateRequest) (*models.ValidateResponse, error) {
+	input := &request.Address
+	query := input.Result
+	if query == "" {
+		lines, err := formatter.Format(input, models.FormatCompact)
+		if err != nil {
+			return nil, errors.New("address cannot be empty")
+		}
+		query = lines[0]
+	}
+	if s.normalizer != nil {
+		query = s.normalizer.Normalize(query)
+	}
+
+	addresses, err := s.searchProvider(models.SearchRequest{Query: query, Limit: maxSearchResults})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.ValidateResponse{Reasons: []string{}}
+	if len(addresses) == 0 {
+		resp.Status = models.ValidationInvalid
+		resp.Reasons = append(resp.Reasons, models.ReasonNotFound)
+		return resp, nil
+	}
+
+	profile := matcher.NewProfile(input)
+	best, ambiguous := bestCandidate(profile, addresses)
+	resp.Score = best.comparison.Score
+	resp.Reasons = validationReasons(profile, best)
+	if ambiguous {
+		resp.Reasons = append(resp.Reasons, models.ReasonAmbiguous)
+	}
+
+	resp.Status = validationStatus(resp.Reasons)
+	if resp.Status != models.ValidationValid {
+		resp.Suggestion = best.address
+	}
+	return resp, nil
+}
+
+// bestCandidate выбирает вариант с лучшим вердиктом и оценкой; при равенстве
+// побеждает более релевантный для провайдера. Выбор неоднозначен, если
+// другой вариант оценен так же, но обозначает другое место.
+func bestCandidate(profile *matcher.Profile, addresses []*models.Address) (candidate, bool) {
+	candidates := make([]candidate, len(addresses))
+	best := 0
+	for i, addr := range addresses {
+		p := matcher.NewProfile(addr)
+		candidates[i] = candidate{address: addr, profile: p, comparison: matcher.CompareProfiles(profile, p)}
+		if better(candidates[i].comparison, candidates[best].comparison) {
+			best = i
+		}
+	}
+
+	for i, c := range candidates {
+		if i == best || better(candidates[best].comparison, c.comparison) {
+			continue
+		}
+		if matcher.CompareProfiles(candidates[best].profile, c.profile).Verdict != models.MatchSame {
+			return candidates[best], true
+		}
+	}
+	return candidates[best], false
+}
+
+func better(a, b models.CompareResponse) bool {
+	if verdictRank(a.Verdict) != verdictRank(b.Verdict) {
+		return verdictRank(a.Verdict) > verdictRank(b.Verdict)
+	}
+	return a.Score > b.Score
+}
+
+func verdictRank(verdict string) int {
+	switch verdict {
+	case models.MatchSame:
+		return 2
+	case models.MatchPossible:
+		return 1
+	}
+	return 0
+}
+
+// validationReasons сравнивает проверяемый адрес с лучшим вариантом.
+// Сообщается только самый общий ненайденный компонент: если нет улицы,
+// о доме не говорится.
+func validationReasons(profile *matcher.Profile, best candidate) []string {
+	reasons := []string{}
+	for _, r := range notFoundReasons {
+		if profile.Has(r.component) && (!best.profile.Has(r.component) || contains(best.comparison.Mismatched, r.component)) {
+			reasons = append(reasons, r.reason)
+			break
+		}
+	}
+	if !profile.Has("house") {
+		reasons = append(reasons, models.ReasonHouseMissing)
+	}
+	if contains(best.comparison.Mismatched, "postal_code") {
+		reasons = append(reasons, models.ReasonPostalCodeMismatch)
+	}
+	return reasons
+}
+
+// validationStatus: ненайденный компонент делает адрес неверным,
+// остальные причины — сомнительным.
+func validationStatus(reasons []string) string {
+	if len(reasons) == 0 {
+		return models.ValidationValid
+	}
+	for _, reason := range reasons {
+		for _, r := range notFoundReasons {
+			if reason == r.reason {
+				return models.ValidationInvalid
+			}
+		}
+	}
+	return models.ValidationUncertain
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"geo-controller/proxy/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestAddressService_ValidateAddress(t *testing.T) {
+	tverskaya7 := &models.Address{
+		Result: "г Москва, ул Тверская, д 7", PostalCode: "125009", Region: "Москва", RegionType: "г",
+		City: "Москва", CityType: "г", Street: "Тверская", StreetType: "ул", House: "7", HouseType: "д",
+	}
+	tverskaya := &models.Address{
+		Result: "г Москва, ул Тверская", Region: "Москва", RegionType: "г",
+		City: "Москва", CityType: "г", Street: "Тверская", StreetType: "ул",
+	}
+	moscow := &models.Address{Result: "г Москва", Region: "Москва", RegionType: "г", City: "Москва", CityType: "г"}
+	kazan := &models.Address{Result: "г Казань, ул Ленина, д 1", City: "Казань", CityType: "г", Street: "Ленина", StreetType: "ул", House: "1"}
+	perm := &models.Address{Result: "г Пермь, ул Ленина, д 1", City: "Пермь", CityType: "г", Street: "Ленина", StreetType: "ул", House: "1"}
+
+	testCases := []struct {
+		name       string
+		address    models.Address
+		candidates []*models.Address
+		status     string
+		reasons    []string
+		suggestion *models.Address
+	}{
+		{"valid free-form", models.Address{Result: "Москва, Тверская ул., 7"},
+			[]*models.Address{tverskaya7}, models.ValidationValid, []string{}, nil},
+		{"valid structured", models.Address{City: "Москва", Street: "Тверская", House: "7", PostalCode: "125009"},
+			[]*models.Address{tverskaya, tverskaya7}, models.ValidationValid, []string{}, nil},
+		{"house not found", models.Address{Result: "г Москва, ул Тверская, д 99"},
+			[]*models.Address{tverskaya7, tverskaya}, models.ValidationInvalid, []string{models.ReasonHouseNotFound}, tverskaya},
+		{"street unknown in city", models.Address{Result: "г Москва, ул Несуществующая, д 1"},
+			[]*models.Address{moscow}, models.ValidationInvalid, []string{models.ReasonStreetNotFound}, moscow},
+		{"city not found", models.Address{Result: "г Казань, ул Тверская, д 7"},
+			[]*models.Address{tverskaya7}, models.ValidationInvalid, []string{models.ReasonCityNotFound}, tverskaya7},
+		{"postal code mismatch", models.Address{Result: "190000, г Москва, ул Тверская, д 7"},
+			[]*models.Address{tverskaya7}, models.ValidationUncertain, []string{models.ReasonPostalCodeMismatch}, tverskaya7},
+		{"house missing", models.Address{Result: "г Москва, ул Тверская"},
+			[]*models.Address{tverskaya}, models.ValidationUncertain, []string{models.ReasonHouseMissing}, tverskaya},
+		{"ambiguous", models.Address{Result: "ул Ленина, д 1"},
+			[]*models.Address{kazan, perm}, models.ValidationUncertain, []string{models.ReasonAmbiguous}, kazan},
+		{"nothing found", models.Address{Result: "г Москва, ул Тверская, д 7"},
+			nil, models.ValidationInvalid, []string{models.ReasonNotFound}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addressService := NewAddressService("", "", WithProvider(&stubProvider{addresses: tc.candidates}))
+
+			resp, err := addressService.ValidateAddress(models.ValidateRequest{Address: tc.address})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != tc.status || !reflect.DeepEqual(resp.Reasons, tc.reasons) {
+				t.Errorf("expected %s %q, got %s %q", tc.status, tc.reasons, resp.Status, resp.Reasons)
+			}
+			if !reflect.DeepEqual(resp.Suggestion, tc.suggestion) {
+				t.Errorf("expected suggestion %+v, got %+v", tc.suggestion, resp.Suggestion)
+			}
+		})
+	}
+}
+
+func TestAddressService_ValidateAddress_Empty(t *testing.T) {
+	addressService := NewAddressService("", "", WithProvider(&stubProvider{}))
+
+	if _, err := addressService.ValidateAddress(models.ValidateRequest{}); err == nil {
+		t.Error("Expected error when validating empty address, but got nil")
+	}
+}
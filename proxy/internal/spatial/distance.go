@@ -0,0 +1,65 @@
+package spatial
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"math"
+)
+
+// GreatCircleDistance возвращает нижнюю оценку расстояния в метрах по
+// большому кругу от точки до прямоугольника долгот и широт, а для
+// вырожденного прямоугольника точки — точное расстояние (алгоритм
+// geokdbush, Agafonkin). Подходит как метрика для RTree.Nearest.
+// Если долгота точки вне прямоугольника, ближайшая точка лежит на одном
+// из его меридианов: на широте, где большой круг из запроса касается
+// меридиана, или в углу.
+func (r Rect) GreatCircleDistance(lat, lon float64) float64 {
+	if lon >= r.MinX && lon <= r.MaxX {
+		switch {
+		case lat < r.MinY:
+			return angularDistance(haversin(radians(r.MinY - lat)))
+		case lat > r.MaxY:
+			return angularDistance(haversin(radians(lat - r.MaxY)))
+		}
+		return 0
+	}
+
+	havDLon := math.Min(haversin(radians(r.MinX-lon)), haversin(radians(r.MaxX-lon)))
+	extremum := vertexLat(lat, havDLon)
+	if extremum > r.MinY && extremum < r.MaxY {
+		return angularDistance(partialHaversin(havDLon, lat, extremum))
+	}
+	return angularDistance(math.Min(partialHaversin(havDLon, lat, r.MinY), partialHaversin(havDLon, lat, r.MaxY)))
+}
+
+// vertexLat — широта, на которой расстояние от точки до меридиана,
+// отстоящего на dLon, наименьшее.
+func vertexLat(lat, havDLon float64) float64 {
+	cosDLon := 1 - 2*havDLon
+	if cosDLon <= 0 {
+		if lat > 0 {
+			return 90
+		}
+		return -90
+	}
+	return math.Atan(math.Tan(radians(lat))/cosDLon) * 180 / math.Pi
+}
+
+// partialHaversin — гаверсинус центрального угла между точками с широтами
+// lat1, lat2 и гаверсинусом разности долгот havDLon.
+func partialHaversin(havDLon, lat1, lat2 float64) float64 {
+	return haversin(radians(lat2-lat1)) + math.Cos(radians(lat1))*math.Cos(radians(lat2))*havDLon
+}
+
+func haversin(theta float64) float64 {
+	s := math.Sin(theta / 2)
+	return s * s
+}
+
+// angularDistance переводит гаверсинус центрального угла в метры.
+func angularDistance(h float64) float64 {
+	return 2 * geo.EarthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,32 @@
+package spatial
+
+import (
+	"geo-controller/proxy/internal/geo"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRect_GreatCircleDistance(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+	for k := 0; k < 2000; k++ {
+		lat, lon := random.Float64()*180-90, random.Float64()*360-180
+		x, y := random.Float64()*340-170, random.Float64()*160-80
+		rect := Rect{MinX: x, MinY: y, MaxX: x + random.Float64()*10, MaxY: y + random.Float64()*10}
+		bound := rect.GreatCircleDistance(lat, lon)
+		// ни одна точка прямоугольника не ближе оценки
+		for i := 0; i <= 10; i++ {
+			for j := 0; j <= 10; j++ {
+				px := rect.MinX + (rect.MaxX-rect.MinX)*float64(i)/10
+				py := rect.MinY + (rect.MaxY-rect.MinY)*float64(j)/10
+				if d := geo.Haversine(lat, lon, py, px); d < bound-1e-6 {
+					t.Fatalf("point %v,%v: rect %+v bound %.3f exceeds distance %.3f to %v,%v", lat, lon, rect, bound, d, py, px)
+				}
+			}
+		}
+		// для точки оценка точная
+		if d, exact := PointRect(x, y).GreatCircleDistance(lat, lon), geo.Haversine(lat, lon, y, x); math.Abs(d-exact) > 1e-3 {
+			t.Fatalf("point rect: expected %.3f, got %.3f", exact, d)
+		}
+	}
+}
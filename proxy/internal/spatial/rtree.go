@@ -0,0 +1,351 @@
+package spatial
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Параметры узлов R-дерева по умолчанию.
+const (
+	defaultMaxEntries = 16
+	defaultMinEntries = 6
+)
+
+// Rect — прямоугольник на плоскости: X — долгота, Y — широта.
+type Rect struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// PointRect возвращает вырожденный прямоугольник точки.
+func PointRect(x, y float64) Rect {
+	return Rect{MinX: x, MinY: y, MaxX: x, MaxY: y}
+}
+
+func (r Rect) Intersects(other Rect) bool {
+	return r.MinX <= other.MaxX && other.MinX <= r.MaxX && r.MinY <= other.MaxY && other.MinY <= r.MaxY
+}
+
+func (r Rect) ContainsRect(other Rect) bool {
+	return r.MinX <= other.MinX && other.MaxX <= r.MaxX && r.MinY <= other.MinY && other.MaxY <= r.MaxY
+}
+
+// Union возвращает наименьший прямоугольник, содержащий оба.
+func (r Rect) Union(other Rect) Rect {
+	return Rect{
+		MinX: math.Min(r.MinX, other.MinX),
+		MinY: math.Min(r.MinY, other.MinY),
+		MaxX: math.Max(r.MaxX, other.MaxX),
+		MaxY: math.Max(r.MaxY, other.MaxY),
+	}
+}
+
+func (r Rect) Area() float64 {
+	return (r.MaxX - r.MinX) * (r.MaxY - r.MinY)
+}
+
+// Distance возвращает евклидово расстояние от точки до прямоугольника,
+// 0 для точки внутри.
+func (r Rect) Distance(x, y float64) float64 {
+	dx := math.Max(0, math.Max(r.MinX-x, x-r.MaxX))
+	dy := math.Max(0, math.Max(r.MinY-y, y-r.MaxY))
+	return math.Hypot(dx, dy)
+}
+
+// enlargement — прирост площади r при добавлении other.
+func (r Rect) enlargement(other Rect) float64 {
+	return r.Union(other).Area() - r.Area()
+}
+
+// RTree — R-дерево Гуттмана с квадратичным разбиением узлов (Guttman,
+// "R-Trees: A Dynamic Index Structure for Spatial Searching", 1984).
+// Хранит значения с охватывающими прямоугольниками и находит пересекающиеся
+// с запросом. Дерево не потокобезопасно: изменения нужно защищать блокировкой.
+type RTree[T any] struct {
+	root       *node[T]
+	height     int // 0 — корень является листом
+	size       int
+	maxEntries int
+	minEntries int
+}
+
+type node[T any] struct {
+	entries []entry[T]
+}
+
+// entry — запись узла: во внутренних узлах заполнено child, в листьях — value.
+type entry[T any] struct {
+	rect  Rect
+	child *node[T]
+	value T
+}
+
+func NewRTree[T any]() *RTree[T] {
+	return &RTree[T]{root: &node[T]{}, maxEntries: defaultMaxEntries, minEntries: defaultMinEntries}
+}
+
+// Len возвращает число значений в дереве.
+func (t *RTree[T]) Len() int {
+	return t.size
+}
+
+// Insert добавляет значение с охватывающим прямоугольником.
+func (t *RTree[T]) Insert(rect Rect, value T) {
+	t.insert(entry[T]{rect: rect, value: value})
+	t.size++
+}
+
+func (t *RTree[T]) insert(e entry[T]) {
+	split := t.insertNode(t.root, e, t.height)
+	if split == nil {
+		return
+	}
+	// корень разделился: дерево растет на уровень
+	old := t.root
+	t.root = &node[T]{entries: []entry[T]{{rect: old.bounds(), child: old}, {rect: split.bounds(), child: split}}}
+	t.height++
+}
+
+// insertNode спускается к листу по записям с наименьшим приростом площади.
+// Возвращает новый узел, если n пришлось разделить.
+func (t *RTree[T]) insertNode(n *node[T], e entry[T], level int) *node[T] {
+	if level == 0 {
+		n.entries = append(n.entries, e)
+	} else {
+		i := chooseSubtree(n, e.rect)
+		split := t.insertNode(n.entries[i].child, e, level-1)
+		n.entries[i].rect = n.entries[i].child.bounds()
+		if split != nil {
+			n.entries = append(n.entries, entry[T]{rect: split.bounds(), child: split})
+		}
+	}
+	if len(n.entries) > t.maxEntries {
+		return t.split(n)
+	}
+	return nil
+}
+
+func chooseSubtree[T any](n *node[T], rect Rect) int {
+	best := 0
+	bestEnlargement, bestArea := math.Inf(1), math.Inf(1)
+	for i, e := range n.entries {
+		enlargement, area := e.rect.enlargement(rect), e.rect.Area()
+		if enlargement < bestEnlargement || (enlargement == bestEnlargement && area < bestArea) {
+			best, bestEnlargement, bestArea = i, enlargement, area
+		}
+	}
+	return best
+}
+
+// split делит переполненный узел квадратичным алгоритмом: затравками
+// становятся две записи, вместе занимающие больше всего пустого места,
+// остальные по очереди добавляются туда, где прирост площади меньше.
+// n сохраняет первую группу, вторая возвращается новым узлом.
+func (t *RTree[T]) split(n *node[T]) *node[T] {
+	entries := n.entries
+	seedA, seedB := pickSeeds(entries)
+	groupA, groupB := []entry[T]{entries[seedA]}, []entry[T]{entries[seedB]}
+	rectA, rectB := entries[seedA].rect, entries[seedB].rect
+
+	remaining := make([]entry[T], 0, len(entries)-2)
+	for i, e := range entries {
+		if i != seedA && i != seedB {
+			remaining = append(remaining, e)
+		}
+	}
+
+	for len(remaining) > 0 {
+		// в группе должно остаться не меньше minEntries записей
+		if len(groupA)+len(remaining) == t.minEntries {
+			groupA = append(groupA, remaining...)
+			break
+		}
+		if len(groupB)+len(remaining) == t.minEntries {
+			groupB = append(groupB, remaining...)
+			break
+		}
+
+		next, maxDifference := 0, -1.0
+		for i, e := range remaining {
+			if difference := math.Abs(rectA.enlargement(e.rect) - rectB.enlargement(e.rect)); difference > maxDifference {
+				next, maxDifference = i, difference
+			}
+		}
+		e := remaining[next]
+		remaining = append(remaining[:next], remaining[next+1:]...)
+
+		enlargementA, enlargementB := rectA.enlargement(e.rect), rectB.enlargement(e.rect)
+		toA := enlargementA < enlargementB ||
+			(enlargementA == enlargementB && (rectA.Area() < rectB.Area() ||
+				(rectA.Area() == rectB.Area() && len(groupA) <= len(groupB))))
+		if toA {
+			groupA, rectA = append(groupA, e), rectA.Union(e.rect)
+		} else {
+			groupB, rectB = append(groupB, e), rectB.Union(e.rect)
+		}
+	}
+
+	n.entries = groupA
+	return &node[T]{entries: groupB}
+}
+
+func pickSeeds[T any](entries []entry[T]) (int, int) {
+	seedA, seedB, maxWaste := 0, 1, math.Inf(-1)
+	for i := 0; i < len(entries); i++ {
+		for j := i + 1; j < len(entries); j++ {
+			waste := entries[i].rect.Union(entries[j].rect).Area() - entries[i].rect.Area() - entries[j].rect.Area()
+			if waste > maxWaste {
+				seedA, seedB, maxWaste = i, j, waste
+			}
+		}
+	}
+	return seedA, seedB
+}
+
+// Search вызывает fn для значений, прямоугольники которых пересекают rect.
+// Обход прекращается, если fn возвращает false.
+func (t *RTree[T]) Search(rect Rect, fn func(T) bool) {
+	search(t.root, rect, fn)
+}
+
+func search[T any](n *node[T], rect Rect, fn func(T) bool) bool {
+	for _, e := range n.entries {
+		if !e.rect.Intersects(rect) {
+			continue
+		}
+		if e.child == nil {
+			if !fn(e.value) {
+				return false
+			}
+		} else if !search(e.child, rect, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Delete удаляет первое значение с прямоугольником rect, для которого match
+// возвращает true. Записи опустевших узлов вставляются заново.
+func (t *RTree[T]) Delete(rect Rect, match func(T) bool) bool {
+	var orphans []entry[T]
+	if !t.delete(t.root, rect, match, t.height, &orphans) {
+		return false
+	}
+	t.size--
+
+	if t.height > 0 && len(t.root.entries) == 0 {
+		t.root, t.height = &node[T]{}, 0
+	}
+	for _, e := range orphans {
+		t.insert(e)
+	}
+	// корень с одной записью заменяется ее узлом
+	for t.height > 0 && len(t.root.entries) == 1 {
+		t.root = t.root.entries[0].child
+		t.height--
+	}
+	return true
+}
+
+func (t *RTree[T]) delete(n *node[T], rect Rect, match func(T) bool, level int, orphans *[]entry[T]) bool {
+	if level == 0 {
+		for i, e := range n.entries {
+			if e.rect == rect && match(e.value) {
+				n.entries = append(n.entries[:i], n.entries[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range n.entries {
+		e := &n.entries[i]
+		if !e.rect.ContainsRect(rect) || !t.delete(e.child, rect, match, level-1, orphans) {
+			continue
+		}
+		if len(e.child.entries) < t.minEntries {
+			*orphans = append(*orphans, e.child.leaves(nil)...)
+			n.entries = append(n.entries[:i], n.entries[i+1:]...)
+		} else {
+			e.rect = e.child.bounds()
+		}
+		return true
+	}
+	return false
+}
+
+// leaves собирает записи листьев поддерева.
+func (n *node[T]) leaves(result []entry[T]) []entry[T] {
+	for _, e := range n.entries {
+		if e.child == nil {
+			result = append(result, e)
+		} else {
+			result = e.child.leaves(result)
+		}
+	}
+	return result
+}
+
+func (n *node[T]) bounds() Rect {
+	rect := n.entries[0].rect
+	for _, e := range n.entries[1:] {
+		rect = rect.Union(e.rect)
+	}
+	return rect
+}
+
+// Nearest вызывает fn для значений в порядке возрастания расстояния до
+// запроса, пока fn не вернет false (Hjaltason, Samet, "Distance Browsing
+// in Spatial Databases", 1999). distance задает метрику: для прямоугольника
+// узла она должна давать нижнюю оценку расстояния до любого значения
+// внутри, для прямоугольника значения — точное расстояние до него.
+// Для протяженных значений, например полигонов, расстояние до
+// прямоугольника значения — лишь нижняя оценка: вызывающий сам уточняет
+// расстояние и прекращает обход, когда оценка превысит найденное.
+func (t *RTree[T]) Nearest(distance func(Rect) float64, fn func(value T, distance float64) bool) {
+	queue := &nearestQueue[T]{}
+	heap.Push(queue, nearestItem[T]{node: t.root})
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(nearestItem[T])
+		if item.node == nil {
+			if !fn(item.value, item.distance) {
+				return
+			}
+			continue
+		}
+		for _, e := range item.node.entries {
+			heap.Push(queue, nearestItem[T]{node: e.child, value: e.value, distance: distance(e.rect)})
+		}
+	}
+}
+
+// nearestItem — узел или значение в очереди Nearest: у значения node пуст.
+type nearestItem[T any] struct {
+	node     *node[T]
+	value    T
+	distance float64
+}
+
+// nearestQueue — очередь с приоритетом по расстоянию для container/heap.
+type nearestQueue[T any] []nearestItem[T]
+
+func (q nearestQueue[T]) Len() int { return len(q) }
+
+// Less при равном расстоянии ставит значения раньше узлов, чтобы
+// найденное значение выдавалось без лишнего раскрытия узлов.
+func (q nearestQueue[T]) Less(i, j int) bool {
+	if q[i].distance != q[j].distance {
+		return q[i].distance < q[j].distance
+	}
+	return q[i].node == nil && q[j].node != nil
+}
+
+func (q nearestQueue[T]) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *nearestQueue[T]) Push(x interface{}) { *q = append(*q, x.(nearestItem[T])) }
+
+func (q *nearestQueue[T]) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,137 @@
+package spatial
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestRTree(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	tree := NewRTree[int]()
+	rects := make([]Rect, 2000)
+	for i := range rects {
+		x, y := random.Float64()*360-180, random.Float64()*180-90
+		rects[i] = Rect{MinX: x, MinY: y, MaxX: x + random.Float64()*5, MaxY: y + random.Float64()*5}
+		tree.Insert(rects[i], i)
+	}
+
+	check := func(deleted map[int]bool) {
+		for k := 0; k < 200; k++ {
+			x, y := random.Float64()*360-180, random.Float64()*180-90
+			query := Rect{MinX: x, MinY: y, MaxX: x + 10, MaxY: y + 10}
+
+			var expected, got []int
+			for i, r := range rects {
+				if !deleted[i] && r.Intersects(query) {
+					expected = append(expected, i)
+				}
+			}
+			tree.Search(query, func(i int) bool {
+				got = append(got, i)
+				return true
+			})
+			sort.Ints(got)
+			if len(got) != len(expected) {
+				t.Fatalf("query %+v: expected %d values, got %d", query, len(expected), len(got))
+			}
+			for i := range got {
+				if got[i] != expected[i] {
+					t.Fatalf("query %+v: expected %v, got %v", query, expected, got)
+				}
+			}
+		}
+	}
+	check(nil)
+
+	deleted := map[int]bool{}
+	for i := 0; i < len(rects); i += 2 {
+		value := i
+		if !tree.Delete(rects[i], func(v int) bool { return v == value }) {
+			t.Fatalf("value %d not deleted", i)
+		}
+		deleted[i] = true
+	}
+	if tree.Len() != len(rects)/2 {
+		t.Errorf("expected %d values, got %d", len(rects)/2, tree.Len())
+	}
+	if tree.Delete(rects[0], func(v int) bool { return v == 0 }) {
+		t.Error("deleted value must not be found")
+	}
+	check(deleted)
+
+	for i := 1; i < len(rects); i += 2 {
+		value := i
+		tree.Delete(rects[i], func(v int) bool { return v == value })
+	}
+	found := false
+	tree.Search(Rect{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}, func(int) bool {
+		found = true
+		return false
+	})
+	if found || tree.Len() != 0 || tree.height != 0 {
+		t.Errorf("expected empty tree, got %d values of height %d", tree.Len(), tree.height)
+	}
+}
+
+func TestRTree_SearchStop(t *testing.T) {
+	tree := NewRTree[string]()
+	tree.Insert(PointRect(1, 1), "a")
+	tree.Insert(PointRect(2, 2), "b")
+
+	calls := 0
+	tree.Search(Rect{MinX: 0, MinY: 0, MaxX: 3, MaxY: 3}, func(string) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("expected search to stop after first value, got %d calls", calls)
+	}
+}
+
+func TestRTree_Nearest(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	tree := NewRTree[int]()
+	points := make([][2]float64, 3000)
+	for i := range points {
+		points[i] = [2]float64{random.Float64() * 100, random.Float64() * 100}
+		tree.Insert(PointRect(points[i][0], points[i][1]), i)
+	}
+
+	for k := 0; k < 50; k++ {
+		x, y := random.Float64()*120-10, random.Float64()*120-10
+		distance := func(r Rect) float64 { return r.Distance(x, y) }
+
+		expected := make([]float64, len(points))
+		for i, p := range points {
+			expected[i] = PointRect(p[0], p[1]).Distance(x, y)
+		}
+		sort.Float64s(expected)
+
+		var got []float64
+		tree.Nearest(distance, func(i int, d float64) bool {
+			if d != distance(PointRect(points[i][0], points[i][1])) {
+				t.Fatalf("value %d: reported distance %v does not match", i, d)
+			}
+			got = append(got, d)
+			return len(got) < 10
+		})
+		if len(got) != 10 {
+			t.Fatalf("expected 10 values, got %d", len(got))
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				t.Fatalf("query %v,%v: expected %v, got %v", x, y, expected[:10], got)
+			}
+		}
+	}
+
+	count := 0
+	NewRTree[int]().Nearest(func(Rect) float64 { return 0 }, func(int, float64) bool {
+		count++
+		return true
+	})
+	if count != 0 {
+		t.Errorf("expected no values in an empty tree, got %d", count)
+	}
+}
@@ -0,0 +1,168 @@
+package speller
+
+import (
+	"geo-controller/proxy/internal/fuzzy"
+	"geo-controller/proxy/internal/normalize"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// minWordLength — слова короче не исправляются и не попадают в словарь.
+const minWordLength = 3
+
+// Dictionary — словарь названий улиц и населенных пунктов с частотами
+// и триграммным индексом для поиска похожих слов.
+type Dictionary struct {
+	mu    sync.RWMutex
+	freq  map[string]int
+	grams map[string][]string
+}
+
+func NewDictionary() *Dictionary {
+	return &Dictionary{
+		freq:  make(map[string]int),
+		grams: make(map[string][]string),
+	}
+}
+
+// Add добавляет в словарь слова из названия, например "Санкт-Петербург" или "Ленина".
+func (d *Dictionary) Add(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, word := range strings.FieldsFunc(name, isSeparator) {
+		word = normalize.Word(word)
+		if !isWord(word) {
+			continue
+		}
+		if d.freq[word] == 0 {
+			for _, gram := range fuzzy.Trigrams(word) {
+				d.grams[gram] = append(d.grams[gram], word)
+			}
+		}
+		d.freq[word]++
+	}
+}
+
+// Len возвращает число слов в словаре.
+func (d *Dictionary) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.freq)
+}
+
+// Suggest предлагает до n вариантов запроса, в которых незнакомые слова
+// заменены ближайшими словами словаря. Запрос ожидается нормализованным.
+func (d *Dictionary) Suggest(query string, n int) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	words := strings.Fields(query)
+	candidates := make([][]string, len(words))
+	corrected := false
+	for i, word := range words {
+		core := strings.TrimRight(word, ",")
+		if !isWord(core) || d.freq[core] > 0 {
+			continue
+		}
+		candidates[i] = d.corrections(core, n)
+		if len(candidates[i]) > 0 {
+			corrected = true
+		}
+	}
+	if !corrected {
+		return nil
+	}
+
+	// первый вариант — лучшие исправления всех слов, далее меняем по одному слову
+	var suggestions []string
+	seen := make(map[string]bool)
+	add := func(choice []int) {
+		phrase := make([]string, len(words))
+		for i, word := range words {
+			phrase[i] = word
+			if len(candidates[i]) > 0 {
+				phrase[i] = candidates[i][choice[i]] + word[len(strings.TrimRight(word, ",")):]
+			}
+		}
+		suggestion := strings.Join(phrase, " ")
+		if !seen[suggestion] && len(suggestions) < n {
+			seen[suggestion] = true
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	choice := make([]int, len(words))
+	add(choice)
+	for i := range words {
+		for alt := 1; alt < len(candidates[i]); alt++ {
+			variant := append([]int(nil), choice...)
+			variant[i] = alt
+			add(variant)
+		}
+	}
+	return suggestions
+}
+
+// corrections возвращает слова словаря в пределах допустимого числа опечаток,
+// упорядоченные по расстоянию и частоте.
+func (d *Dictionary) corrections(word string, n int) []string {
+	maxEdits := fuzzy.MaxEdits(word)
+	if maxEdits == 0 {
+		maxEdits = 1
+	}
+
+	type candidate struct {
+		word     string
+		distance int
+		freq     int
+	}
+	seen := make(map[string]bool)
+	var found []candidate
+	for _, gram := range fuzzy.Trigrams(word) {
+		for _, known := range d.grams[gram] {
+			if seen[known] {
+				continue
+			}
+			seen[known] = true
+			if distance := fuzzy.Levenshtein(word, known); distance <= maxEdits {
+				found = append(found, candidate{word: known, distance: distance, freq: d.freq[known]})
+			}
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].distance != found[j].distance {
+			return found[i].distance < found[j].distance
+		}
+		if found[i].freq != found[j].freq {
+			return found[i].freq > found[j].freq
+		}
+		return found[i].word < found[j].word
+	})
+
+	var words []string
+	for i := 0; i < len(found) && i < n; i++ {
+		words = append(words, found[i].word)
+	}
+	return words
+}
+
+func isSeparator(r rune) bool {
+	return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-')
+}
+
+// isWord отсекает числа и слишком короткие слова.
+func isWord(word string) bool {
+	if len([]rune(word)) < minWordLength {
+		return false
+	}
+	for _, r := range word {
+		if unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
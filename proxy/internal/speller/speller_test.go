@@ -0,0 +1,52 @@
+package speller
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testDictionary() *Dictionary {
+	d := NewDictionary()
+	for _, name := range []string{"Ленина", "Ленина", "Ленино", "Тверская", "Москва", "Санкт-Петербург", "улица", "город"} {
+		d.Add(name)
+	}
+	return d
+}
+
+func TestDictionary_Suggest(t *testing.T) {
+	d := testDictionary()
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "single typo", query: "улица линина", expected: []string{"улица ленина"}},
+		{name: "frequent word first", query: "ленинв", expected: []string{"ленина", "ленино"}},
+		{name: "several words", query: "город моксва, улица тверкая дом 7", expected: []string{"город москва, улица тверская дом 7"}},
+		{name: "known words", query: "улица ленина", expected: nil},
+		{name: "too far", query: "гагарина", expected: nil},
+		{name: "hyphenated name", query: "санкт-питербург", expected: []string{"санкт-петербург"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := d.Suggest(tc.query, 3); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDictionary_Add(t *testing.T) {
+	d := NewDictionary()
+	d.Add("ул. Ленина, д 12")
+	d.Add("Ёлочная")
+
+	if d.Len() != 4 {
+		t.Errorf("expected 4 words (улица, ленина, дом, елочная), got %d", d.Len())
+	}
+	if got := d.Suggest("елочнай", 1); len(got) != 1 || got[0] != "елочная" {
+		t.Errorf("expected ё folded to е, got %q", got)
+	}
+}
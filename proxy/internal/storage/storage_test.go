@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "store.json")
+
+	value := map[string][]int{"default": {1}}
+	exists, err := ReadJSON(path, &value)
+	if err != nil || exists || len(value["default"]) != 1 {
+		t.Fatalf("missing file must leave value unchanged: %v, %v, %v", value, exists, err)
+	}
+
+	if err := WriteJSON(path, map[string][]int{"a": {1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteJSON(path, map[string][]int{"b": {3}}); err != nil {
+		t.Fatal(err)
+	}
+	var loaded map[string][]int
+	exists, err = ReadJSON(path, &loaded)
+	if err != nil || !exists {
+		t.Fatalf("expected stored value, got %v, %v", exists, err)
+	}
+	if len(loaded) != 1 || len(loaded["b"]) != 1 || loaded["b"][0] != 3 {
+		t.Errorf("unexpected value %v", loaded)
+	}
+
+	// временные файлы не остаются в каталоге
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only store.json, got %d files", len(entries))
+	}
+}
+
+func TestNewID(t *testing.T) {
+	first, second := NewID(), NewID()
+	if len(first) != 16 || strings.Trim(first, "0123456789abcdef") != "" {
+		t.Errorf("expected 16 hex digits, got %q", first)
+	}
+	if first == second {
+		t.Errorf("expected distinct ids, got %q twice", first)
+	}
+}
@@ -0,0 +1,145 @@
+package timezone
+
+import (
+	"fmt"
+	"geo-controller/proxy/internal/geofile"
+	"geo-controller/proxy/internal/geometry"
+	"geo-controller/proxy/internal/spatial"
+	"math"
+	"time"
+
+	// база часовых поясов встраивается в бинарник: в контейнере
+	// системного zoneinfo может не быть
+	_ "time/tzdata"
+)
+
+// idKeys — свойства с идентификатором пояса IANA, по порядку предпочтения.
+// tzid используют timezone-boundary-builder и OpenStreetMap.
+var idKeys = []string{"tzid", "TZID", "timezone", "time_zone"}
+
+// Zone — часовой пояс IANA с полигонами в долготе и широте.
+type Zone struct {
+	ID       string
+	Location *time.Location
+	Geometry *geometry.Geometry
+
+	area float64
+}
+
+// NewZone создает пояс по идентификатору IANA и полигонам.
+func NewZone(id string, g *geometry.Geometry) (*Zone, error) {
+	location, err := time.LoadLocation(id)
+	if err != nil {
+		return nil, err
+	}
+	return &Zone{ID: id, Location: location, Geometry: g, area: geometry.Area(g)}, nil
+}
+
+// Index находит часовой пояс по точке. Как и индекс административных
+// границ, хранит полигоны в R-дереве и после построения только читается.
+type Index struct {
+	tree *spatial.RTree[*part]
+	size int
+}
+
+type part struct {
+	zone    *Zone
+	polygon [][]geometry.Point
+}
+
+func NewIndex(zones []*Zone) *Index {
+	index := &Index{tree: spatial.NewRTree[*part]()}
+	for _, z := range zones {
+		for _, polygon := range z.Geometry.PolygonParts() {
+			index.tree.Insert(geometry.RingRect(polygon[0]), &part{zone: z, polygon: polygon})
+		}
+		index.size++
+	}
+	return index
+}
+
+// Load читает границы поясов из файлов GeoJSON и шейп-файлов. Объекты без
+// идентификатора пояса или без полигонов пропускаются, неизвестный
+// идентификатор считается ошибкой данных.
+func Load(paths ...string) (*Index, error) {
+	var zones []*Zone
+	for _, path := range paths {
+		features, err := geofile.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for i, f := range features {
+			id := f.Property(idKeys...)
+			if id == "" || geometry.Area(f.Geometry) == 0 {
+				continue
+			}
+			zone, err := NewZone(id, f.Geometry)
+			if err != nil {
+				return nil, fmt.Errorf("%s: feature %d: %w", path, i, err)
+			}
+			zones = append(zones, zone)
+		}
+	}
+	return NewIndex(zones), nil
+}
+
+// Len возвращает число поясов в индексе.
+func (index *Index) Len() int {
+	return index.size
+}
+
+// Lookup возвращает пояс, содержащий точку. На стыке поясов, а также при
+// вложенных полигонах выбирается наименьший по площади.
+func (index *Index) Lookup(lat, lon float64) (*Zone, bool) {
+	point := geometry.Point{lon, lat}
+	var found *Zone
+	index.tree.Search(spatial.PointRect(lon, lat), func(p *part) bool {
+		if found != nil && found.area <= p.zone.area {
+			return true
+		}
+		if geometry.PolygonContains(p.polygon, point) {
+			found = p.zone
+		}
+		return true
+	})
+	return found, found != nil
+}
+
+// Nautical возвращает морской пояс Etc/GMT±N для точки вне полигонов,
+// например в открытом море: смещение — долгота, деленная на 15 градусов.
+// Знак в идентификаторах Etc/GMT обратный: восточнее Гринвича — Etc/GMT-N.
+func Nautical(lon float64) *time.Location {
+	hours := int(math.Round(lon / 15))
+	if hours > 12 {
+		hours = 12
+	}
+	if hours < -12 {
+		hours = -12
+	}
+	id := "Etc/GMT"
+	if hours > 0 {
+		id = fmt.Sprintf("Etc/GMT-%d", hours)
+	} else if hours < 0 {
+		id = fmt.Sprintf("Etc/GMT+%d", -hours)
+	}
+	location, err := time.LoadLocation(id)
+	if err != nil {
+		return time.FixedZone(id, hours*3600)
+	}
+	return location
+}
+
+// Offset возвращает сокращение и смещение от UTC в секундах на момент t.
+func Offset(location *time.Location, t time.Time) (string, int) {
+	return t.In(location).Zone()
+}
+
+// FormatOffset записывает смещение в виде ±hh:mm.
+func FormatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
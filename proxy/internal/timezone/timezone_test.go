@@ -0,0 +1,120 @@
+package timezone
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testZones: Москва вложена в общий полигон Europe/Samara, объект без tzid
+// пропускается.
+const testZones = `{"type":"FeatureCollection","features":[
+	{"type":"Feature","properties":{"tzid":"Europe/Samara"},
+	 "geometry":{"type":"Polygon","coordinates":[[[30,50],[60,50],[60,60],[30,60],[30,50]]]}},
+	{"type":"Feature","properties":{"tzid":"Europe/Moscow"},
+	 "geometry":{"type":"Polygon","coordinates":[[[35,54],[40,54],[40,57],[35,57],[35,54]]]}},
+	{"type":"Feature","properties":{"TZID":"Asia/Vladivostok"},
+	 "geometry":{"type":"MultiPolygon","coordinates":[[[[130,42],[135,42],[135,46],[130,46],[130,42]]]]}},
+	{"type":"Feature","properties":{"name":"без пояса"},
+	 "geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}}
+]}`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestIndex_Lookup(t *testing.T) {
+	index, err := Load(writeFile(t, "timezones.geojson", testZones))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Len() != 3 {
+		t.Errorf("expected 3 zones, got %d", index.Len())
+	}
+
+	testCases := []struct {
+		lat, lon float64
+		expected string
+	}{
+		{55.75, 37.62, "Europe/Moscow"},
+		{53.2, 50.15, "Europe/Samara"},
+		{43.12, 131.9, "Asia/Vladivostok"},
+		{0.5, 0.5, ""},
+	}
+	for _, tc := range testCases {
+		zone, ok := index.Lookup(tc.lat, tc.lon)
+		if tc.expected == "" {
+			if ok {
+				t.Errorf("%g, %g: expected no zone, got %s", tc.lat, tc.lon, zone.ID)
+			}
+			continue
+		}
+		if !ok || zone.ID != tc.expected {
+			t.Errorf("%g, %g: expected %s, got %+v", tc.lat, tc.lon, tc.expected, zone)
+		}
+	}
+}
+
+func TestLoad_UnknownZone(t *testing.T) {
+	path := writeFile(t, "timezones.geojson", `{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"tzid":"Europe/Atlantis"},
+		 "geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}}]}`)
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for unknown zone id")
+	}
+}
+
+func TestOffset(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	winter := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	summer := time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		location *time.Location
+		at       time.Time
+		offset   int
+		text     string
+	}{
+		{moscow, winter, 10800, "+03:00"},
+		{moscow, summer, 10800, "+03:00"},
+		{berlin, winter, 3600, "+01:00"},
+		{berlin, summer, 7200, "+02:00"},
+		{Nautical(-150), winter, -36000, "-10:00"},
+		{Nautical(5.5), winter, 0, "+00:00"},
+	}
+	for _, tc := range testCases {
+		_, offset := Offset(tc.location, tc.at)
+		if offset != tc.offset || FormatOffset(offset) != tc.text {
+			t.Errorf("%s at %s: expected %d (%s), got %d (%s)",
+				tc.location, tc.at.Format("2006-01-02"), tc.offset, tc.text, offset, FormatOffset(offset))
+		}
+	}
+}
+
+func TestNautical(t *testing.T) {
+	testCases := map[float64]string{
+		0:    "Etc/GMT",
+		37.6: "Etc/GMT-3",
+		-74:  "Etc/GMT+5",
+		179:  "Etc/GMT-12",
+		-180: "Etc/GMT+12",
+	}
+	for lon, expected := range testCases {
+		if got := Nautical(lon).String(); got != expected {
+			t.Errorf("%g: expected %s, got %s", lon, expected, got)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// latinToCyrillic — обратная транслитерация распространенных латинских
+// написаний русских слов. Сочетания проверяются раньше одиночных букв.
+var latinToCyrillic = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"}, {"sch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ju", "ю"}, {"ya", "я"}, {"ja", "я"}, {"yo", "е"}, {"ye", "е"},
+	{"a", "а"}, {"b", "б"}, {"v", "в"}, {"w", "в"}, {"g", "г"}, {"d", "д"},
+	{"e", "е"}, {"z", "з"}, {"i", "и"}, {"j", "й"}, {"k", "к"}, {"q", "к"},
+	{"l", "л"}, {"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"}, {"r", "р"},
+	{"s", "с"}, {"t", "т"}, {"u", "у"}, {"f", "ф"}, {"h", "х"}, {"c", "ц"},
+	{"x", "кс"}, {"'", "ь"},
+}
+
+// IsLatin сообщает, состоит ли слово только из латинских букв (и апострофов)
+// и содержит хотя бы одну букву.
+func IsLatin(word string) bool {
+	hasLetter := false
+	for _, r := range word {
+		switch {
+		case r == '\'':
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			hasLetter = true
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
+
+// HasLatin сообщает, есть ли в строке латинские буквы.
+func HasLatin(s string) bool {
+	for _, r := range s {
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToCyrillic переводит латинскую запись русского слова в кириллицу.
+// Символы, не являющиеся латинскими буквами, сохраняются как есть.
+func ToCyrillic(word string) string {
+	lower := strings.ToLower(word)
+	var b strings.Builder
+	for i := 0; i < len(lower); {
+		matched := false
+		for _, pair := range latinToCyrillic {
+			if strings.HasPrefix(lower[i:], pair.latin) {
+				b.WriteString(pair.cyrillic)
+				i += len(pair.latin)
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		if lower[i] == 'y' {
+			b.WriteString(cyrillicY(lower, i))
+		} else {
+			b.WriteByte(lower[i])
+		}
+		i++
+	}
+	return b.String()
+}
+
+// cyrillicY выбирает букву для одиночной "y": "й" после гласной,
+// окончание "-ий" после к/г/х в конце слова ("nevsky"), иначе "ы".
+func cyrillicY(word string, i int) string {
+	if i == 0 {
+		return "й"
+	}
+	prev := word[i-1]
+	last := i == len(word)-1
+	switch {
+	case strings.IndexByte("aeiou", prev) >= 0:
+		return "й"
+	case prev == 'y' || prev == 'i':
+		return "й"
+	case last && strings.IndexByte("kgh", prev) >= 0:
+		return "ий"
+	}
+	return "ы"
+}
+
+// Scheme — стандарт транслитерации кириллицы латиницей.
+type Scheme string
+
+const (
+	// ICAO — ICAO Doc 9303 (ГОСТ Р 52535.1-2006), по нему пишутся имена
+	// в загранпаспортах.
+	ICAO Scheme = "icao"
+	// GOST — ГОСТ 7.79-2000, система Б, обратимая транслитерация
+	// с апострофами и обратными кавычками.
+	GOST Scheme = "gost"
+)
+
+// cyrillicToLatin — таблицы транслитерации по схемам. Ц в ГОСТ 7.79
+// передается как "c" перед е, и, ы, й и как "cz" в остальных случаях.
+var cyrillicToLatin = map[Scheme]map[rune]string{
+	ICAO: {
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+		'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+		'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+		'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+		'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+	},
+	GOST: {
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+		'ж': "zh", 'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m",
+		'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+		'ф': "f", 'х': "x", 'ц': "cz", 'ч': "ch", 'ш': "sh", 'щ': "shh",
+		'ъ': "``", 'ы': "y'", 'ь': "`", 'э': "e`", 'ю': "yu", 'я': "ya",
+	},
+}
+
+// ToLatin переводит кириллицу в латиницу по ICAO, сохраняя регистр:
+// "Щукинская" → "Shchukinskaia", "ЩЕЛКОВО" → "SHCHELKOVO".
+// Остальные символы сохраняются как есть.
+func ToLatin(s string) string {
+	return Transliterate(s, ICAO)
+}
+
+// Transliterate переводит кириллицу в латиницу по схеме scheme, сохраняя регистр.
+// Неизвестная схема считается ICAO.
+func Transliterate(s string, scheme Scheme) string {
+	table, ok := cyrillicToLatin[scheme]
+	if !ok {
+		table = cyrillicToLatin[ICAO]
+	}
+
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+		latin, ok := table[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if scheme == GOST && lower == 'ц' && i+1 < len(runes) && strings.ContainsRune("еиыйЕИЫЙ", runes[i+1]) {
+			latin = "c"
+		}
+		if !unicode.IsUpper(r) || !unicode.IsLetter(rune(latin[0])) {
+			b.WriteString(latin)
+			continue
+		}
+		if upperContext(runes, i) {
+			b.WriteString(strings.ToUpper(latin))
+		} else {
+			b.WriteString(strings.ToUpper(latin[:1]) + latin[1:])
+		}
+	}
+	return b.String()
+}
+
+// HasCyrillic сообщает, есть ли в строке кириллические буквы.
+func HasCyrillic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// upperContext сообщает, написана ли заглавная буква runes[i] внутри слова
+// капслоком: соседняя буква тоже заглавная.
+func upperContext(runes []rune, i int) bool {
+	if i+1 < len(runes) && unicode.IsLetter(runes[i+1]) {
+		return unicode.IsUpper(runes[i+1])
+	}
+	return i > 0 && unicode.IsUpper(runes[i-1])
+}
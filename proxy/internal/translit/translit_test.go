@@ -0,0 +1,111 @@
+package translit
+
+import "testing"
+
+func TestToCyrillic(t *testing.T) {
+	testCases := []struct {
+		latin    string
+		expected string
+	}{
+		{"Lenina", "ленина"},
+		{"Tverskaya", "тверская"},
+		{"Shchukinskaya", "щукинская"},
+		{"Zhukova", "жукова"},
+		{"Khimki", "химки"},
+		{"Nevskiy", "невский"},
+		{"Nevsky", "невский"},
+		{"Nikolay", "николай"},
+		{"Krasnyy", "красный"},
+		{"Yakimanka", "якиманка"},
+		{"Kuznetsova", "кузнецова"},
+		{"Chaykovskogo", "чайковского"},
+		{"Mira", "мира"},
+		{"Mariya", "мария"},
+		{"7a", "7а"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.latin, func(t *testing.T) {
+			if got := ToCyrillic(tc.latin); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsLatin(t *testing.T) {
+	testCases := []struct {
+		word     string
+		expected bool
+	}{
+		{"Lenina", true},
+		{"d'Artagnan", true},
+		{"Ленина", false},
+		{"7a", false},
+		{"", false},
+		{"Leнина", false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsLatin(tc.word); got != tc.expected {
+			t.Errorf("IsLatin(%q): expected %v, got %v", tc.word, tc.expected, got)
+		}
+	}
+	if !HasLatin("7a") || HasLatin("7а") {
+		t.Error("HasLatin must detect only latin letters")
+	}
+}
+
+func TestToLatin(t *testing.T) {
+	testCases := []struct {
+		cyrillic string
+		expected string
+	}{
+		{"Тверская", "Tverskaia"},
+		{"Щукинская", "Shchukinskaia"},
+		{"Юрий", "Iurii"},
+		{"Объездная", "Obieezdnaia"},
+		{"Мясницкая", "Miasnitskaia"},
+		{"Хорошёво-Мнёвники", "Khoroshevo-Mnevniki"},
+		{"ЩЕЛКОВО", "SHCHELKOVO"},
+		{"ул. Чайковского, д. 7", "ul. Chaikovskogo, d. 7"},
+		{"Ц", "Ts"},
+		{"Lenina 5", "Lenina 5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cyrillic, func(t *testing.T) {
+			if got := ToLatin(tc.cyrillic); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTransliterate_GOST(t *testing.T) {
+	testCases := []struct {
+		cyrillic string
+		expected string
+	}{
+		{"Тверская", "Tverskaya"},
+		{"Щукинская", "Shhukinskaya"},
+		{"Хорошёво", "Xoroshyovo"},
+		{"Цветной", "Czvetnoj"},
+		{"Центральная", "Central`naya"},
+		{"Объездная", "Ob``ezdnaya"},
+		{"Малый", "Maly'j"},
+		{"Электрозаводская", "E`lektrozavodskaya"},
+		{"ЦВЕТНОЙ", "CZVETNOJ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cyrillic, func(t *testing.T) {
+			if got := Transliterate(tc.cyrillic, GOST); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+	if !HasCyrillic("Lenina ул") || HasCyrillic("Lenina 5") {
+		t.Error("HasCyrillic must detect only cyrillic letters")
+	}
+}
@@ -3,9 +3,23 @@ package main
 
 import (
 	"fmt"
+	"geo-controller/proxy/internal/boundary"
+	"geo-controller/proxy/internal/clientip"
 	"geo-controller/proxy/internal/controllers"
+	"geo-controller/proxy/internal/delivery"
+	"geo-controller/proxy/internal/elevation"
+	"geo-controller/proxy/internal/geofence"
+	"geo-controller/proxy/internal/history"
+	"geo-controller/proxy/internal/layer"
+	"geo-controller/proxy/internal/locator"
+	"geo-controller/proxy/internal/normalize"
 	"geo-controller/proxy/internal/service"
+	"geo-controller/proxy/internal/timezone"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -23,14 +37,165 @@ func init() {
 const daDataApiKey = "c4aab5f0a277fbaa6de6613c4c78930552172d28"
 const daDataSecretKey = "e1e61bbed8ab858bc7153ba44fc8344ba7681526"
 
+// Значения по умолчанию, переопределяются переменными окружения.
+const (
+	defaultGeoIPDatabasePath = "./data/GeoLite2-City.mmdb"
+	defaultTrustedProxies    = "127.0.0.1/32,::1/128"
+	defaultAddressIndexPath  = "./data/addresses.json"
+	defaultBoundariesPath    = "./data/boundaries.geojson"
+	defaultTimezonesPath     = "./data/timezones.geojson"
+	defaultElevationDir      = "./data/elevation"
+	defaultElevationTiles    = 8
+	defaultGeofencesPath     = "./data/geofences.json"
+	defaultDeliveryZonesPath = "./data/delivery_zones.json"
+	defaultLocationsPath     = "./data/locations.json"
+	defaultFeaturesPath      = "./data/features.json"
+	defaultHistoryPath       = "./data/history.jsonl"
+)
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func newGeoIPService() *service.GeoIPService {
+	var locators []service.IPLocator
+	maxMind, err := service.NewMaxMindLocator(getEnv("GEOIP_DB_PATH", defaultGeoIPDatabasePath))
+	if err != nil {
+		log.Printf("local geoip database disabled: %v", err)
+	} else {
+		locators = append(locators, maxMind)
+	}
+	if getEnv("GEOIP_DADATA", "true") == "true" {
+		locators = append(locators, service.NewDaDataIPLocator(daDataApiKey, daDataSecretKey))
+	}
+	return service.NewGeoIPService(locators...)
+}
+
+// newAddressProvider выбирает источник адресов: DaData (по умолчанию)
+// или локальный индекс из ADDRESS_INDEX_PATH.
+func newAddressProvider() service.AddressProvider {
+	if getEnv("ADDRESS_PROVIDER", "dadata") == "local" {
+		provider, err := service.LoadLocalProvider(getEnv("ADDRESS_INDEX_PATH", defaultAddressIndexPath))
+		if err == nil {
+			return provider
+		}
+		log.Printf("local address index disabled, falling back to DaData: %v", err)
+	}
+	return service.NewDaDataProvider(daDataApiKey, daDataSecretKey)
+}
+
+// newBoundaryIndex загружает административные границы из файлов GeoJSON
+// и шейп-файлов, перечисленных через запятую в ADMIN_BOUNDARIES_PATH.
+func newBoundaryIndex() *boundary.Index {
+	index, err := boundary.Load(strings.Split(getEnv("ADMIN_BOUNDARIES_PATH", defaultBoundariesPath), ",")...)
+	if err != nil {
+		log.Printf("administrative boundaries disabled: %v", err)
+		return nil
+	}
+	return index
+}
+
+// newTimezoneIndex загружает границы часовых поясов, например выгрузку
+// timezone-boundary-builder, из файлов в TIMEZONE_BOUNDARIES_PATH.
+func newTimezoneIndex() *timezone.Index {
+	index, err := timezone.Load(strings.Split(getEnv("TIMEZONE_BOUNDARIES_PATH", defaultTimezonesPath), ",")...)
+	if err != nil {
+		log.Printf("timezone boundaries disabled: %v", err)
+		return nil
+	}
+	return index
+}
+
+// newElevationStore индексирует тайлы SRTM и GeoTIFF из ELEVATION_TILES_DIR.
+// ELEVATION_CACHE_TILES ограничивает число тайлов в памяти.
+func newElevationStore() *elevation.Store {
+	maxTiles, err := strconv.Atoi(getEnv("ELEVATION_CACHE_TILES", strconv.Itoa(defaultElevationTiles)))
+	if err != nil || maxTiles < 0 {
+		log.Printf("invalid ELEVATION_CACHE_TILES, using %d", defaultElevationTiles)
+		maxTiles = defaultElevationTiles
+	}
+	store, err := elevation.Open(getEnv("ELEVATION_TILES_DIR", defaultElevationDir), maxTiles)
+	if err != nil {
+		log.Printf("elevation disabled: %v", err)
+		return nil
+	}
+	return store
+}
+
+// newHistoryLog открывает общий журнал изменений геозон, точек сети
+// и объектов слоев.
+// Без журнала изменения не сохраняются, поэтому сервер не запускается.
+func newHistoryLog() *history.Log {
+	journal, err := history.Open(getEnv("HISTORY_PATH", defaultHistoryPath))
+	if err != nil {
+		log.Fatalf("history storage: %v", err)
+	}
+	return journal
+}
+
+// newGeofenceStore открывает хранилище геозон пользователей. Поврежденный
+// файл не заменяется пустым хранилищем: сервер не запускается.
+func newGeofenceStore(changes *history.Log) *geofence.Store {
+	store, err := geofence.Open(getEnv("GEOFENCES_PATH", defaultGeofencesPath), geofence.WithHistory(changes))
+	if err != nil {
+		log.Fatalf("geofence storage: %v", err)
+	}
+	return store
+}
+
+// newLocationRegistry открывает реестр магазинов и пунктов выдачи.
+// Как и с геозонами, поврежденный файл останавливает запуск.
+func newLocationRegistry(changes *history.Log) *locator.Registry {
+	registry, err := locator.Open(getEnv("LOCATIONS_PATH", defaultLocationsPath), locator.WithHistory(changes))
+	if err != nil {
+		log.Fatalf("location storage: %v", err)
+	}
+	return registry
+}
+
+// newLayerStore открывает хранилище слоев карты. Как и с геозонами,
+// поврежденный файл останавливает запуск.
+func newLayerStore(changes *history.Log) *layer.Store {
+	store, err := layer.Open(getEnv("FEATURES_PATH", defaultFeaturesPath), layer.WithHistory(changes))
+	if err != nil {
+		log.Fatalf("feature storage: %v", err)
+	}
+	return store
+}
+
+// newDeliveryZones загружает склады и зоны обслуживания из DELIVERY_ZONES_PATH.
+func newDeliveryZones() *delivery.Index {
+	zones, err := delivery.Load(getEnv("DELIVERY_ZONES_PATH", defaultDeliveryZonesPath))
+	if err != nil {
+		log.Printf("delivery zones disabled: %v", err)
+		return nil
+	}
+	return zones
+}
+
+func newNormalizer() *normalize.Normalizer {
+	path := getEnv("SYNONYMS_PATH", "")
+	if path == "" {
+		return normalize.New(nil)
+	}
+	synonyms, err := normalize.LoadSynonyms(path)
+	if err != nil {
+		log.Printf("synonyms dictionary disabled: %v", err)
+	}
+	return normalize.New(synonyms)
+}
+
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, err := jwtauth.VerifyRequest(tokenAuth, r, jwtauth.TokenFromHeader)
+		token, err := jwtauth.VerifyRequest(tokenAuth, r, jwtauth.TokenFromHeader)
 		if err != nil {
 			http.Error(w, "Unauthorized: "+err.Error(), http.StatusForbidden)
 			return
 		}
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(jwtauth.NewContext(r.Context(), token, nil)))
 	})
 }
 
@@ -38,19 +203,45 @@ func setupRouter() *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:1313"},
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Authorization"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}).Handler)
 	r.Use(middleware.Logger)
 
+	ipResolver, err := clientip.NewResolver(strings.Split(getEnv("TRUSTED_PROXIES", defaultTrustedProxies), ","))
+	if err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+	r.Use(ipResolver.Middleware)
+
 	authService := service.NewAuthService()
 	authController := controllers.NewAuthController(authService, tokenAuth)
 
-	addressService := service.NewAddressService(daDataApiKey, daDataSecretKey)
+	geoIPService := newGeoIPService()
+	ipController := controllers.NewIPController(geoIPService)
+
+	addressService := service.NewAddressService(daDataApiKey, daDataSecretKey,
+		service.WithProvider(newAddressProvider()),
+		service.WithGeoIP(geoIPService),
+		service.WithNormalizer(newNormalizer()),
+		service.WithTimezones(newTimezoneIndex()),
+	)
 	addressController := controllers.NewAddressController(addressService)
 
+	geoController := controllers.NewGeoController(service.NewGeoService(addressService,
+		service.WithBoundaries(newBoundaryIndex()),
+		service.WithElevation(newElevationStore()),
+	))
+
+	changes := newHistoryLog()
+	geofenceController := controllers.NewGeofenceController(service.NewGeofenceService(newGeofenceStore(changes), addressService))
+
+	deliveryController := controllers.NewDeliveryController(service.NewDeliveryService(newDeliveryZones(), addressService))
+	locationController := controllers.NewLocationController(service.NewLocationService(newLocationRegistry(changes), addressService))
+	layerController := controllers.NewLayerController(service.NewLayerService(newLayerStore(changes), addressService))
+
 	r.Get("/swagger/doc.json", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "./swagger.json")
 	})
@@ -66,6 +257,60 @@ func setupRouter() *chi.Mux {
 		r.Use(AuthMiddleware)
 		r.Post("/api/address/search", addressController.AddressSearchHandler)
 		r.Post("/api/address/geocode", addressController.GeocodeHandler)
+		r.Post("/api/address/parse", addressController.ParseHandler)
+		r.Post("/api/address/format", addressController.FormatHandler)
+		r.Post("/api/address/compare", addressController.CompareHandler)
+		r.Post("/api/address/dedupe", addressController.DedupeHandler)
+		r.Post("/api/address/validate", addressController.ValidateHandler)
+		r.Post("/api/ip/locate", ipController.LocateHandler)
+		r.Post("/api/geo/distance", geoController.DistanceHandler)
+		r.Post("/api/geo/matrix", geoController.MatrixHandler)
+		r.Post("/api/geo/measure", geoController.MeasureHandler)
+		r.Post("/api/geo/hull", geoController.ConvexHullHandler)
+		r.Post("/api/geo/simplify", geoController.SimplifyHandler)
+		r.Post("/api/geo/buffer", geoController.BufferHandler)
+		r.Post("/api/geo/transform", geoController.TransformHandler)
+		r.Post("/api/geo/cell/encode", geoController.EncodeCellHandler)
+		r.Post("/api/geo/cell/decode", geoController.DecodeCellHandler)
+		r.Post("/api/geo/cell/neighbours", geoController.CellNeighboursHandler)
+		r.Post("/api/geo/boundaries", geoController.BoundariesHandler)
+		r.Post("/api/geo/timezone", geoController.TimezoneHandler)
+		r.Post("/api/geo/elevation", geoController.ElevationHandler)
+		r.Post("/api/geo/elevation/profile", geoController.ElevationProfileHandler)
+		r.Get("/api/geofences", geofenceController.ListHandler)
+		r.Post("/api/geofences", geofenceController.CreateHandler)
+		r.Post("/api/geofences/check", geofenceController.CheckHandler)
+		r.Get("/api/geofences/{id}", geofenceController.GetHandler)
+		r.Put("/api/geofences/{id}", geofenceController.UpdateHandler)
+		r.Delete("/api/geofences/{id}", geofenceController.DeleteHandler)
+		r.Get("/api/geofences/{id}/revisions", geofenceController.RevisionsHandler)
+		r.Get("/api/geofences/{id}/revisions/{rev}", geofenceController.RevisionHandler)
+		r.Post("/api/geofences/{id}/revert", geofenceController.RevertHandler)
+		r.Post("/api/delivery/check", deliveryController.CheckHandler)
+		r.Post("/api/locations", locationController.CreateHandler)
+		r.Post("/api/locations/import", locationController.ImportHandler)
+		r.Post("/api/locations/nearest", locationController.NearestHandler)
+		r.Get("/api/locations/clusters", locationController.ClustersHandler)
+		r.Get("/api/locations/{id}", locationController.GetHandler)
+		r.Put("/api/locations/{id}", locationController.UpdateHandler)
+		r.Delete("/api/locations/{id}", locationController.DeleteHandler)
+		r.Get("/api/locations/{id}/revisions", locationController.RevisionsHandler)
+		r.Get("/api/locations/{id}/revisions/{rev}", locationController.RevisionHandler)
+		r.Post("/api/locations/{id}/revert", locationController.RevertHandler)
+		r.Get("/api/layers", layerController.ListHandler)
+		r.Post("/api/layers", layerController.CreateHandler)
+		r.Get("/api/layers/{id}", layerController.GetHandler)
+		r.Put("/api/layers/{id}", layerController.UpdateHandler)
+		r.Delete("/api/layers/{id}", layerController.DeleteHandler)
+		r.Get("/api/layers/{id}/features", layerController.FeaturesHandler)
+		r.Post("/api/layers/{id}/features", layerController.PutFeaturesHandler)
+		r.Get("/api/layers/{id}/features/{fid}", layerController.GetFeatureHandler)
+		r.Put("/api/layers/{id}/features/{fid}", layerController.UpdateFeatureHandler)
+		r.Delete("/api/layers/{id}/features/{fid}", layerController.DeleteFeatureHandler)
+		r.Get("/api/layers/{id}/features/{fid}/revisions", layerController.FeatureRevisionsHandler)
+		r.Get("/api/layers/{id}/features/{fid}/revisions/{rev}", layerController.FeatureRevisionHandler)
+		r.Post("/api/layers/{id}/features/{fid}/revert", layerController.RevertFeatureHandler)
+		r.Post("/api/layers/{id}/query", layerController.QueryHandler)
 	})
 
 	return r
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"github.com/go-chi/chi"
+	"github.com/go-chi/jwtauth"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -24,6 +25,49 @@ func TestSetupRouter(t *testing.T) {
 		"/api/login",
 		"/api/address/search",
 		"/api/address/geocode",
+		"/api/address/parse",
+		"/api/address/format",
+		"/api/address/compare",
+		"/api/address/dedupe",
+		"/api/address/validate",
+		"/api/ip/locate",
+		"/api/geo/distance",
+		"/api/geo/matrix",
+		"/api/geo/measure",
+		"/api/geo/hull",
+		"/api/geo/simplify",
+		"/api/geo/buffer",
+		"/api/geo/transform",
+		"/api/geo/cell/encode",
+		"/api/geo/cell/decode",
+		"/api/geo/cell/neighbours",
+		"/api/geo/boundaries",
+		"/api/geo/timezone",
+		"/api/geo/elevation",
+		"/api/geo/elevation/profile",
+		"/api/geofences",
+		"/api/geofences/check",
+		"/api/geofences/{id}",
+		"/api/geofences/{id}/revisions",
+		"/api/geofences/{id}/revisions/{rev}",
+		"/api/geofences/{id}/revert",
+		"/api/delivery/check",
+		"/api/locations",
+		"/api/locations/import",
+		"/api/locations/nearest",
+		"/api/locations/clusters",
+		"/api/locations/{id}",
+		"/api/locations/{id}/revisions",
+		"/api/locations/{id}/revisions/{rev}",
+		"/api/locations/{id}/revert",
+		"/api/layers",
+		"/api/layers/{id}",
+		"/api/layers/{id}/features",
+		"/api/layers/{id}/features/{fid}",
+		"/api/layers/{id}/features/{fid}/revisions",
+		"/api/layers/{id}/features/{fid}/revisions/{rev}",
+		"/api/layers/{id}/features/{fid}/revert",
+		"/api/layers/{id}/query",
 	}
 
 	for _, route := range routes {
@@ -56,6 +100,18 @@ func TestAuthMiddleware(t *testing.T) {
 	rr = httptest.NewRecorder()
 	handler.ServeHTTP(rr, req)
 	assert.Equal(t, http.StatusOK, rr.Code, "Should return 200 when valid token is provided")
+
+	// Test that token claims are available to handlers
+	var username interface{}
+	handler = AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, claims, _ := jwtauth.FromContext(r.Context())
+		username = claims["username"]
+	}))
+	_, tokenString, _ = tokenAuth.Encode(map[string]interface{}{"username": "alice"})
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "alice", username, "Should pass token claims in request context")
 }
 
 // Helper function to check if a route exists in the router